@@ -3,14 +3,36 @@ package llmkit
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 )
 
 // message represents a conversation message (internal type).
 type message struct {
 	role       string
 	content    string
+	images     []Image // user messages only
+	files      []File  // user messages only
 	toolCalls  []toolCall
 	toolResult *toolResult
+	parts      []messagePart // assistant content in model order (optional)
+}
+
+// messagePart is one block of assistant content: text or a tool call (internal type).
+type messagePart struct {
+	text string
+	call *toolCall
+}
+
+// turn is a single model response within the tool loop (internal type).
+type turn struct {
+	text         string
+	calls        []toolCall
+	parts        []messagePart
+	usage        Usage
+	serviceTier  string
+	rateLimit    *RateLimit
+	finishReason FinishReason
 }
 
 // toolCall represents a tool invocation (internal type).
@@ -24,6 +46,7 @@ type toolCall struct {
 type toolResult struct {
 	toolUseID string
 	content   string
+	isError   bool
 }
 
 // Agent manages a multi-turn conversation with tool support.
@@ -73,31 +96,114 @@ func (a *Agent) Reset() {
 
 // Chat sends a message and returns the response.
 func (a *Agent) Chat(ctx context.Context, msg string) (Response, error) {
+	return a.chat(ctx, message{role: "user", content: msg})
+}
+
+// ChatWithImages sends a message with images, such as screenshots, for the
+// model to look at. The images stay in the history for later turns.
+func (a *Agent) ChatWithImages(ctx context.Context, msg string, images ...Image) (Response, error) {
+	return a.chat(ctx, message{role: "user", content: msg, images: images})
+}
+
+// ChatWithAudio sends a message with audio clips, such as voice notes, to a
+// model that listens to audio: Gemini, or an OpenAI audio model such as
+// gpt-4o-audio-preview (see WithModel). OpenAI takes inline WAV or MP3 clips.
+// Google takes any audio format; clips over 18 MB are uploaded through the
+// Files API, smaller ones are sent inline. A clip's format comes from its
+// MimeType, or else its Name.
+func (a *Agent) ChatWithAudio(ctx context.Context, msg string, clips ...File) (Response, error) {
+	files := make([]File, len(clips))
+	for i, f := range clips {
+		clip, err := a.audioClip(ctx, f)
+		if err != nil {
+			return Response{}, err
+		}
+		files[i] = clip
+	}
+	return a.chat(ctx, message{role: "user", content: msg, files: files})
+}
+
+// audioClip checks a clip for the agent's provider, uploading large clips to Google.
+func (a *Agent) audioClip(ctx context.Context, f File) (File, error) {
+	if f.MimeType == "" {
+		f.MimeType = detectMimeType(f.Name)
+	}
+	if !strings.HasPrefix(f.MimeType, "audio/") {
+		return File{}, &ValidationError{Field: "audio", Message: "not an audio file: " + f.MimeType}
+	}
+
+	switch a.provider.Name {
+	case OpenAI:
+		if !f.inline() {
+			return File{}, &ValidationError{Field: "audio", Message: "must contain inline data for " + OpenAI}
+		}
+		if openaiAudioFormat(f.MimeType) == "" {
+			return File{}, &ValidationError{Field: "audio", Message: "must be WAV or MP3 for " + OpenAI}
+		}
+		return f, nil
+	case Google:
+		if !f.inline() || len(f.Data) <= googleInlineAudioLimit {
+			return f, nil
+		}
+		uploaded, err := withPoolKey(ctx, a.provider, a.opts, func(p Provider) (File, error) {
+			return uploadGoogle(ctx, p, f.Data, audioFilename(f), f.MimeType, a.opts)
+		})
+		if err != nil {
+			return File{}, fmt.Errorf("google: upload audio: %w", err)
+		}
+		if uploaded.MimeType == "" {
+			uploaded.MimeType = f.MimeType
+		}
+		return uploaded, nil
+	default:
+		return File{}, &ValidationError{Field: "audio", Message: "audio input not supported by " + a.provider.Name}
+	}
+}
+
+// chat adds a user message to the history and answers it.
+func (a *Agent) chat(ctx context.Context, m message) (Response, error) {
+	// One correlation ID covers every request in this turn
+	ctx, _ = ensureRequestID(ctx, a.opts)
+
 	// Add user message to history
-	a.history = append(a.history, message{role: "user", content: msg})
+	a.history = append(a.history, m)
 
-	// If no tools registered, use simple path
+	// If no tools registered, use simple path; otherwise run the tool loop
+	var resp Response
+	var err error
 	if len(a.tools) == 0 {
-		return a.chatSimple(ctx)
+		resp, err = a.chatSimple(ctx)
+	} else {
+		resp, err = a.chatWithTools(ctx, nil)
+	}
+	if err != nil {
+		return Response{}, err
 	}
+	return a.opts.postProcessResponse(resp), nil
+}
 
-	// Tool loop
-	return a.chatWithTools(ctx)
+// ChatStream sends a message like Chat and streams assistant text to fn as it is generated.
+// Tool calls are executed between turns as usual, and text from intermediate
+// tool-use turns is streamed too. Providers without streaming support deliver
+// each turn's text in a single call to fn.
+func (a *Agent) ChatStream(ctx context.Context, msg string, fn StreamFunc) (Response, error) {
+	ctx, _ = ensureRequestID(ctx, a.opts)
+	a.history = append(a.history, message{role: "user", content: msg})
+	resp, err := a.chatWithTools(ctx, fn)
+	if err != nil {
+		return Response{}, err
+	}
+	return a.opts.postProcessResponse(resp), nil
 }
 
 // chatSimple handles chat without tools.
 func (a *Agent) chatSimple(ctx context.Context) (Response, error) {
-	messages := make([]Message, len(a.history))
-	for i, m := range a.history {
-		messages[i] = Message{Role: m.role, Content: m.content}
-	}
-
 	req := Request{
 		System:   a.system,
-		Messages: messages,
+		Messages: a.messages(),
 	}
 
-	resp, err := Prompt(ctx, a.provider, req, a.buildOpts()...)
+	resp, err := prompt(ctx, a.provider, req, a.promptOptions())
 	if err != nil {
 		return Response{}, err
 	}
@@ -106,109 +212,196 @@ func (a *Agent) chatSimple(ctx context.Context) (Response, error) {
 	return resp, nil
 }
 
+// messages converts the history to request messages, attachments included.
+func (a *Agent) messages() []Message {
+	messages := make([]Message, len(a.history))
+	for i, m := range a.history {
+		messages[i] = Message{Role: m.role, Content: m.content, Images: m.images, Files: m.files}
+	}
+	return messages
+}
+
 // chatWithTools handles chat with tool execution loop.
-func (a *Agent) chatWithTools(ctx context.Context) (Response, error) {
+// When stream is non-nil, assistant text is forwarded to it as it arrives.
+func (a *Agent) chatWithTools(ctx context.Context, stream StreamFunc) (Response, error) {
+	if err := validateOptions(a.provider, a.opts); err != nil {
+		return Response{}, err
+	}
+	if len(a.opts.fileSearch) > 0 {
+		return Response{}, &ValidationError{Field: "file_search", Message: "cannot be combined with function tools or ChatStream"}
+	}
+
 	maxIter := a.opts.maxToolIterations
 	if maxIter == 0 {
 		maxIter = 10 // safety default
 	}
 
 	var totalUsage Usage
+	var guardUsage Usage // injection classifier calls, priced by their own model
+	guardCost := 0.0
+	requestID := RequestIDFromContext(ctx)
 
 	for i := 0; i < maxIter; i++ {
-		text, calls, usage, err := a.sendRequest(ctx)
+		t, err := a.sendRequest(ctx, stream, totalUsage)
 		if err != nil {
-			return Response{}, err
+			return Response{}, stampRequestID(err, requestID)
 		}
 
-		totalUsage.Input += usage.Input
-		totalUsage.Output += usage.Output
+		totalUsage = totalUsage.Add(t.usage)
+		a.opts.reportUsage(totalUsage)
 
-		if len(calls) == 0 {
+		if len(t.calls) == 0 {
 			// No tool calls - return final response
-			a.history = append(a.history, message{role: "assistant", content: text})
-			return Response{Text: text, Tokens: totalUsage}, nil
+			a.history = append(a.history, message{role: "assistant", content: t.text})
+			return Response{
+				Text:         t.text,
+				Tokens:       totalUsage.Add(guardUsage),
+				Cost:         EstimateCost(totalUsage, a.model()) + guardCost,
+				RequestID:    requestID,
+				ServiceTier:  t.serviceTier,
+				RateLimit:    t.rateLimit,
+				FinishReason: t.finishReason,
+			}, nil
 		}
 
 		// Store assistant message with tool calls
-		a.history = append(a.history, message{role: "assistant", toolCalls: calls})
-
-		// Execute each tool
-		for _, call := range calls {
-			tool := a.findTool(call.name)
-			if tool == nil {
-				return Response{}, fmt.Errorf("unknown tool: %s", call.name)
-			}
-
-			result, err := tool.Run(call.input)
-			if err != nil {
-				result = fmt.Sprintf("error: %v", err)
-			}
-
-			a.history = append(a.history, message{
-				role: "user",
-				toolResult: &toolResult{
-					toolUseID: call.id,
-					content:   result,
-				},
-			})
+		a.history = append(a.history, message{
+			role:      "assistant",
+			content:   t.text,
+			toolCalls: t.calls,
+			parts:     t.parts,
+		})
+
+		// Execute each tool; failures are reported back so the model can recover
+		for _, call := range t.calls {
+			result, inj := a.guardToolResult(ctx, call, a.runTool(ctx, call))
+			guardUsage = guardUsage.Add(inj.Tokens)
+			guardCost += inj.Cost
+			a.history = append(a.history, message{role: "user", toolResult: result})
 		}
 	}
 
 	return Response{}, fmt.Errorf("exceeded max tool iterations (%d)", maxIter)
 }
 
+// promptWithTools answers req with a one-off agent that may call req.Tools.
+func promptWithTools(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
+	a := &Agent{provider: p, opts: o, tools: req.Tools, system: req.System}
+	for _, m := range req.Messages {
+		a.history = append(a.history, message{role: m.Role, content: m.Content, images: m.Images, files: m.Files})
+	}
+	if len(req.Messages) == 0 {
+		a.history = append(a.history, message{role: "user", content: req.User})
+	}
+	return a.chatWithTools(ctx, o.stream)
+}
+
+// runTool executes a tool call and returns its result.
+// Unknown tools and handler errors produce an error result instead of aborting the turn.
+func (a *Agent) runTool(ctx context.Context, call toolCall) *toolResult {
+	tool := a.findTool(call.name)
+	if tool == nil {
+		a.opts.log(ctx, slog.LevelWarn, "llmkit tool failed", "tool", call.name, "error", "unknown tool")
+		return &toolResult{toolUseID: call.id, content: "error: unknown tool: " + call.name, isError: true}
+	}
+
+	start := a.opts.clock.Now()
+	result, err := tool.Run(call.input)
+	elapsed := a.opts.clock.Now().Sub(start)
+	if err != nil {
+		a.opts.log(ctx, slog.LevelWarn, "llmkit tool failed", "tool", call.name, "duration", elapsed, "error", err)
+		return &toolResult{toolUseID: call.id, content: fmt.Sprintf("error: %v", err), isError: true}
+	}
+	a.opts.log(ctx, slog.LevelDebug, "llmkit tool call", "tool", call.name, "duration", elapsed)
+	return &toolResult{toolUseID: call.id, content: result}
+}
+
+// model returns the model the agent's requests use.
+func (a *Agent) model() string {
+	if a.opts.model != "" {
+		return a.opts.model
+	}
+	return a.provider.model()
+}
+
 // sendRequest dispatches to the provider-specific tool function.
-func (a *Agent) sendRequest(ctx context.Context) (string, []toolCall, Usage, error) {
-	switch a.provider.Name {
-	case Anthropic:
-		return sendAnthropicWithTools(ctx, a.provider, a.history, a.system, a.tools, a.opts)
-	case OpenAI, Grok:
-		return sendOpenAIWithTools(ctx, a.provider, a.history, a.system, a.tools, a.opts)
-	case Google:
-		return sendGoogleWithTools(ctx, a.provider, a.history, a.system, a.tools, a.opts)
-	default:
-		return "", nil, Usage{}, fmt.Errorf("tool support not implemented for provider: %s", a.provider.Name)
+// When stream is non-nil, the turn's text is streamed to it. Usage snapshots
+// reported while streaming are offset by spent, the usage of earlier turns.
+func (a *Agent) sendRequest(ctx context.Context, stream StreamFunc, spent Usage) (turn, error) {
+	o := a.opts
+	if stream != nil && support[a.provider.Name].streaming {
+		streamOpts := *a.opts
+		streamOpts.stream = stream
+		if fn := a.opts.onUsage; fn != nil {
+			streamOpts.onUsage = func(u Usage) { fn(spent.Add(u)) }
+		}
+		o = &streamOpts
+	}
+
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
 	}
+
+	p := a.provider
+	p.Model = a.model()
+	system := a.system
+	if o.language != "" {
+		system = languageInstruction(system, o.language, false)
+	}
+	if o.glossary != nil {
+		system = appendInstruction(system, o.glossary.instruction())
+	}
+
+	start := o.logRequest(ctx, p)
+	t, err := withPoolKey(ctx, p, o, func(p Provider) (turn, error) {
+		switch p.Name {
+		case Anthropic:
+			return sendAnthropicWithTools(ctx, p, a.history, system, a.tools, o)
+		case OpenAI, Grok, Ollama, OpenRouter, OpenAICompatible:
+			return sendOpenAIWithTools(ctx, p, a.history, system, a.tools, o)
+		case Google:
+			return sendGoogleWithTools(ctx, p, a.history, system, a.tools, o)
+		default:
+			return turn{}, fmt.Errorf("tool support not implemented for provider: %s", p.Name)
+		}
+	})
+	o.logResponse(ctx, p, start, t.usage, err)
+
+	// Providers without native streaming deliver the whole turn at once
+	if err == nil && stream != nil && !support[a.provider.Name].streaming && t.text != "" {
+		stream(t.text)
+	}
+	return t, err
 }
 
 // ChatWithSchema sends a message and returns structured output.
 func (a *Agent) ChatWithSchema(ctx context.Context, msg, schema string) (Response, error) {
+	ctx, _ = ensureRequestID(ctx, a.opts)
 	a.history = append(a.history, message{role: "user", content: msg})
 
-	// Build messages from history
-	messages := make([]Message, len(a.history))
-	for i, m := range a.history {
-		messages[i] = Message{Role: m.role, Content: m.content}
-	}
-
 	req := Request{
 		System:   a.system,
-		Messages: messages,
+		Messages: a.messages(),
 		Schema:   schema,
 	}
 
-	resp, err := Prompt(ctx, a.provider, req, a.buildOpts()...)
+	resp, err := prompt(ctx, a.provider, req, a.promptOptions())
 	if err != nil {
 		return Response{}, err
 	}
 
 	a.history = append(a.history, message{role: "assistant", content: resp.Text})
 
-	return resp, nil
+	return a.opts.postProcessResponse(resp), nil
 }
 
-// buildOpts returns options for the underlying Prompt call.
-func (a *Agent) buildOpts() []Option {
-	var opts []Option
-	if a.opts.httpClient != nil {
-		opts = append(opts, WithHTTPClient(a.opts.httpClient))
-	}
-	if a.opts.temperature != nil {
-		opts = append(opts, WithTemperature(*a.opts.temperature))
-	}
-	if a.opts.maxTokens != nil {
-		opts = append(opts, WithMaxTokens(*a.opts.maxTokens))
-	}
-	return opts
+// promptOptions returns a copy of the agent's options for a Prompt call.
+// Post-processing is left to the agent, which keeps the raw text in history.
+func (a *Agent) promptOptions() *options {
+	o := *a.opts
+	o.stream = nil
+	o.postProcess = nil
+	return &o
 }
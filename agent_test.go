@@ -2,8 +2,12 @@ package llmkit
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -313,3 +317,380 @@ func TestAgent_ChatWithTool_MaxIterations(t *testing.T) {
 		t.Errorf("expected 3 API calls, got %d", mock.calls)
 	}
 }
+
+// scriptedTransport replays canned responses in order and records request bodies.
+type scriptedTransport struct {
+	responses []string
+	bodies    []string
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	data, _ := io.ReadAll(req.Body)
+	s.bodies = append(s.bodies, string(data))
+	body := s.responses[len(s.bodies)-1]
+	return &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestAgent_ToolErrorReportedToModel(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"content": [
+			{"type": "text", "text": "Checking both."},
+			{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"city": "Paris"}},
+			{"type": "tool_use", "id": "toolu_2", "name": "get_time", "input": {}}
+		], "usage": {"input_tokens": 10, "output_tokens": 5}}`,
+		`{"content": [
+			{"type": "text", "text": "It is sunny."},
+			{"type": "text", "text": "Time is unavailable."}
+		], "usage": {"input_tokens": 10, "output_tokens": 5}}`,
+	}}
+
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	agent := NewAgent(p, WithHTTPClient(&http.Client{Transport: mock}))
+	agent.AddTool(Tool{
+		Name: "get_weather",
+		Run: func(map[string]any) (string, error) {
+			return "", errors.New("service down")
+		},
+	})
+
+	resp, err := agent.Chat(context.Background(), "Weather and time in Paris?")
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Text != "It is sunny.\n\nTime is unavailable." {
+		t.Errorf("Text = %q, want both text blocks", resp.Text)
+	}
+
+	var second struct {
+		Messages []anthropicMessage `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(mock.bodies[1]), &second); err != nil {
+		t.Fatalf("unmarshal request: %v", err)
+	}
+	if len(second.Messages) != 4 {
+		t.Fatalf("messages = %d, want 4", len(second.Messages))
+	}
+
+	assistant := second.Messages[1].Content
+	if len(assistant) != 3 || assistant[0].Type != "text" || assistant[1].Type != "tool_use" || assistant[2].Type != "tool_use" {
+		t.Errorf("assistant content = %+v, want text, tool_use, tool_use", assistant)
+	}
+
+	for i, id := range []string{"toolu_1", "toolu_2"} {
+		result := second.Messages[2+i].Content[0]
+		if result.ToolUseID != id || !result.IsError {
+			t.Errorf("tool_result %d = %+v, want is_error for %s", i, result, id)
+		}
+	}
+}
+
+func TestAgent_ChatStream(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"choices":[{"message":{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]}}],"usage":{"prompt_tokens":10,"completion_tokens":5}}`,
+		`{"choices":[{"message":{"content":"72°F and sunny"}}],"usage":{"prompt_tokens":20,"completion_tokens":5}}`,
+	}}
+
+	p := Provider{Name: Grok, APIKey: "test-key"}
+	agent := NewAgent(p, WithHTTPClient(&http.Client{Transport: mock}))
+	agent.AddTool(testWeatherTool())
+
+	var streamed strings.Builder
+	resp, err := agent.ChatStream(context.Background(), "Weather in Paris?", func(delta string) {
+		streamed.WriteString(delta)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if streamed.String() != resp.Text || resp.Text != "72°F and sunny" {
+		t.Errorf("streamed %q, response %q", streamed.String(), resp.Text)
+	}
+	if resp.Tokens.Input != 30 {
+		t.Errorf("Tokens.Input = %d, want 30", resp.Tokens.Input)
+	}
+	if len(agent.history) != 4 {
+		t.Errorf("history length = %d, want 4", len(agent.history))
+	}
+}
+
+func TestAgent_WithModel(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`,
+		`{"choices":[{"message":{"content":"sunny"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`,
+	}}
+	p := Provider{Name: OpenAI, APIKey: "test-key"}
+	agent := NewAgent(p, WithHTTPClient(&http.Client{Transport: mock}), WithModel("gpt-4.1-mini"))
+
+	if _, err := agent.Chat(context.Background(), "Hello"); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	agent.AddTool(testWeatherTool())
+	if _, err := agent.Chat(context.Background(), "Weather?"); err != nil {
+		t.Fatalf("Chat() with tools error = %v", err)
+	}
+
+	for i, raw := range mock.bodies {
+		var body openaiRequest
+		json.Unmarshal([]byte(raw), &body)
+		if body.Model != "gpt-4.1-mini" {
+			t.Errorf("request %d model = %q, want gpt-4.1-mini", i, body.Model)
+		}
+	}
+}
+
+func TestAgent_ChatStreamUsageSnapshots(t *testing.T) {
+	turns := [][]string{
+		{
+			`data: {"type":"message_start","message":{"usage":{"input_tokens":10,"output_tokens":1}}}`,
+			`data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+			`data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\":\"Paris\"}"}}`,
+			`data: {"type":"content_block_stop","index":0}`,
+			`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":5}}`,
+		},
+		{
+			`data: {"type":"message_start","message":{"usage":{"input_tokens":20,"output_tokens":1}}}`,
+			`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Sunny"}}`,
+			`data: {"type":"content_block_stop","index":0}`,
+			`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":3}}`,
+		},
+	}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, e := range turns[calls] {
+			w.Write([]byte(e + "\n\n"))
+		}
+		calls++
+	}))
+	defer server.Close()
+
+	var snapshots []Usage
+	p := Provider{Name: Anthropic, APIKey: "test-key", BaseURL: server.URL}
+	agent := NewAgent(p, WithUsageCallback(func(u Usage) { snapshots = append(snapshots, u) }))
+	agent.AddTool(testWeatherTool())
+
+	resp, err := agent.ChatStream(context.Background(), "Weather in Paris?", func(string) {})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if resp.Tokens != (Usage{Input: 30, Output: 8}) {
+		t.Errorf("Tokens = %+v, want 30 in / 8 out", resp.Tokens)
+	}
+
+	// message_start, message_delta and turn end for each turn, cumulative across turns
+	want := []Usage{
+		{Input: 10, Output: 1}, {Input: 10, Output: 5}, {Input: 10, Output: 5},
+		{Input: 30, Output: 6}, {Input: 30, Output: 8}, {Input: 30, Output: 8},
+	}
+	if len(snapshots) != len(want) {
+		t.Fatalf("snapshots = %+v, want %+v", snapshots, want)
+	}
+	for i := range want {
+		if snapshots[i] != want[i] {
+			t.Errorf("snapshot %d = %+v, want %+v", i, snapshots[i], want[i])
+		}
+	}
+}
+
+func TestAgent_StopSequences(t *testing.T) {
+	tests := []struct {
+		provider, key, reply string
+	}{
+		{Anthropic, `"stop_sequences":["END"]`, `{"content":[{"type":"text","text":"hi"}],"stop_reason":"end_turn"}`},
+		{OpenAI, `"stop":["END"]`, openaiHello},
+		{Google, `"stopSequences":["END"]`, `{"candidates":[{"content":{"parts":[{"text":"hi"}]},"finishReason":"STOP"}]}`},
+	}
+	for _, tt := range tests {
+		for _, withTools := range []bool{false, true} {
+			var body []byte
+			client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				body, _ = io.ReadAll(r.Body)
+				return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(tt.reply))}, nil
+			})}
+
+			agent := NewAgent(Provider{Name: tt.provider, APIKey: "k"}, WithHTTPClient(client), WithStopSequences("END"))
+			if withTools {
+				agent.AddTool(testWeatherTool())
+			}
+			if _, err := agent.Chat(context.Background(), "hello"); err != nil {
+				t.Fatalf("%s tools=%v: Chat() error = %v", tt.provider, withTools, err)
+			}
+			if !strings.Contains(string(body), tt.key) {
+				t.Errorf("%s tools=%v: body = %s, want %s", tt.provider, withTools, body, tt.key)
+			}
+		}
+	}
+}
+
+func TestAgent_ServiceTierWithoutTools(t *testing.T) {
+	reply := `{"content":[{"type":"text","text":"{}"}],"usage":{"input_tokens":1,"output_tokens":1}}`
+	mock := &scriptedTransport{responses: []string{reply, reply}}
+	agent := NewAgent(Provider{Name: Anthropic, APIKey: "k"}, WithHTTPClient(&http.Client{Transport: mock}), WithServiceTier("auto"))
+
+	if _, err := agent.Chat(context.Background(), "hello"); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if _, err := agent.ChatWithSchema(context.Background(), "as JSON", `{"type":"object"}`); err != nil {
+		t.Fatalf("ChatWithSchema() error = %v", err)
+	}
+	for i, body := range mock.bodies {
+		if !strings.Contains(body, `"service_tier":"auto"`) {
+			t.Errorf("request %d = %s, want service_tier", i, body)
+		}
+	}
+}
+
+func TestAgent_ChatWithImages(t *testing.T) {
+	img := Image{URL: "data:image/png;base64,iVBORw0KGgo=", MimeType: "image/png"}
+	tests := []struct {
+		name     string
+		provider string
+		reply    string
+		tools    bool
+		want     string
+	}{
+		{"anthropic", Anthropic, `{"content":[{"type":"text","text":"A login form."}],"usage":{"input_tokens":1,"output_tokens":1}}`,
+			false, `{"type":"image","source":{"type":"base64","media_type":"image/png","data":"iVBORw0KGgo="}}`},
+		{"anthropic tools", Anthropic, `{"content":[{"type":"text","text":"A login form."}],"usage":{"input_tokens":1,"output_tokens":1}}`,
+			true, `{"type":"image","source":{"type":"base64","media_type":"image/png","data":"iVBORw0KGgo="}}`},
+		{"openai", OpenAI, `{"choices":[{"message":{"content":"A login form."}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`,
+			false, `"image_url":{"url":"data:image/png;base64,iVBORw0KGgo=","detail":"auto"}`},
+		{"openai tools", OpenAI, `{"choices":[{"message":{"content":"A login form."}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`,
+			true, `"image_url":{"url":"data:image/png;base64,iVBORw0KGgo=","detail":"auto"}`},
+		{"google", Google, `{"candidates":[{"content":{"parts":[{"text":"A login form."}]}}],"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":1}}`,
+			false, `"inline_data":{"mime_type":"image/png","data":"iVBORw0KGgo="}`},
+		{"google tools", Google, `{"candidates":[{"content":{"parts":[{"text":"A login form."}]}}],"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":1}}`,
+			true, `"inline_data":{"mime_type":"image/png","data":"iVBORw0KGgo="}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &scriptedTransport{responses: []string{tt.reply, tt.reply}}
+			agent := NewAgent(Provider{Name: tt.provider, APIKey: "test-key"}, WithHTTPClient(&http.Client{Transport: mock}))
+			if tt.tools {
+				agent.AddTool(testWeatherTool())
+			}
+
+			resp, err := agent.ChatWithImages(context.Background(), "What does this screenshot show?", img)
+			if err != nil {
+				t.Fatalf("ChatWithImages() error = %v", err)
+			}
+			if resp.Text != "A login form." {
+				t.Errorf("Text = %q", resp.Text)
+			}
+			if _, err := agent.Chat(context.Background(), "And the button label?"); err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			for i, body := range mock.bodies {
+				if !strings.Contains(body, tt.want) || !strings.Contains(body, "What does this screenshot show?") {
+					t.Errorf("request %d = %s, want image %s", i+1, body, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAgent_ChatWithSchemaKeepsAttachments(t *testing.T) {
+	wav := wavFile([]byte{1, 2, 3, 4}, 16000)
+	reply := `{"candidates":[{"content":{"parts":[{"text":"{}"}]}}],"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":1}}`
+	mock := &scriptedTransport{responses: []string{reply, reply, reply}}
+	agent := NewAgent(Provider{Name: Google, APIKey: "test-key"}, WithHTTPClient(&http.Client{Transport: mock}))
+	ctx := context.Background()
+
+	if _, err := agent.ChatWithImages(ctx, "Describe the form", Image{URL: "data:image/png;base64,iVBORw0KGgo=", MimeType: "image/png"}); err != nil {
+		t.Fatalf("ChatWithImages() error = %v", err)
+	}
+	if _, err := agent.ChatWithAudio(ctx, "And the call?", File{MimeType: "audio/wav", Data: wav}); err != nil {
+		t.Fatalf("ChatWithAudio() error = %v", err)
+	}
+	if _, err := agent.ChatWithSchema(ctx, "List the fields", `{"type":"object"}`); err != nil {
+		t.Fatalf("ChatWithSchema() error = %v", err)
+	}
+	body := mock.bodies[2]
+	for _, want := range []string{`"mime_type":"image/png"`, `"mime_type":"audio/wav"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("ChatWithSchema request = %s, want %s", body, want)
+		}
+	}
+}
+
+func TestAgent_ChatWithAudio(t *testing.T) {
+	wav := wavFile([]byte{1, 2, 3, 4}, 16000)
+	b64 := base64.StdEncoding.EncodeToString(wav)
+
+	t.Run("openai", func(t *testing.T) {
+		mock := &scriptedTransport{responses: []string{
+			`{"choices":[{"message":{"content":"They asked for a refund."}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`,
+		}}
+		agent := NewAgent(Provider{Name: OpenAI, APIKey: "test-key"},
+			WithHTTPClient(&http.Client{Transport: mock}), WithModel("gpt-4o-audio-preview"))
+		resp, err := agent.ChatWithAudio(context.Background(), "What does the caller want?", File{Name: "call.wav", Data: wav})
+		if err != nil {
+			t.Fatalf("ChatWithAudio() error = %v", err)
+		}
+		if resp.Text != "They asked for a refund." {
+			t.Errorf("Text = %q", resp.Text)
+		}
+		if want := `{"type":"input_audio","input_audio":{"data":"` + b64 + `","format":"wav"}}`; !strings.Contains(mock.bodies[0], want) {
+			t.Errorf("request = %s, want %s", mock.bodies[0], want)
+		}
+	})
+
+	t.Run("google inline", func(t *testing.T) {
+		mock := &scriptedTransport{responses: []string{
+			`{"candidates":[{"content":{"parts":[{"text":"A refund."}]}}],"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":1}}`,
+		}}
+		agent := NewAgent(Provider{Name: Google, APIKey: "test-key"}, WithHTTPClient(&http.Client{Transport: mock}))
+		if _, err := agent.ChatWithAudio(context.Background(), "Summarize", File{MimeType: "audio/wav", Data: wav}); err != nil {
+			t.Fatalf("ChatWithAudio() error = %v", err)
+		}
+		if want := `"inline_data":{"mime_type":"audio/wav","data":"` + b64 + `"}`; !strings.Contains(mock.bodies[0], want) {
+			t.Errorf("request = %s, want %s", mock.bodies[0], want)
+		}
+	})
+
+	t.Run("google upload", func(t *testing.T) {
+		mock := &scriptedTransport{responses: []string{
+			`{"file":{"name":"files/abc","displayName":"audio.mp3","mimeType":"audio/mpeg","uri":"https://generativelanguage.googleapis.com/v1beta/files/abc"}}`,
+			`{"candidates":[{"content":{"parts":[{"text":"A long call."}]}}],"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":1}}`,
+		}}
+		defer func(n int) { googleInlineAudioLimit = n }(googleInlineAudioLimit)
+		googleInlineAudioLimit = 2
+		agent := NewAgent(Provider{Name: Google, APIKey: "test-key"}, WithHTTPClient(&http.Client{Transport: mock}))
+		big := File{MimeType: "audio/mpeg", Data: []byte("ID3 long call")}
+		if _, err := agent.ChatWithAudio(context.Background(), "Summarize", big); err != nil {
+			t.Fatalf("ChatWithAudio() error = %v", err)
+		}
+		if len(mock.bodies) != 2 {
+			t.Fatalf("requests = %d, want upload then generate", len(mock.bodies))
+		}
+		want := `"file_data":{"file_uri":"https://generativelanguage.googleapis.com/v1beta/files/abc","mime_type":"audio/mpeg"}`
+		if !strings.Contains(mock.bodies[1], want) || strings.Contains(mock.bodies[1], "inline_data") {
+			t.Errorf("request = %s, want %s", mock.bodies[1], want)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			provider string
+			clip     File
+		}{
+			{"unsupported provider", Anthropic, File{Name: "a.wav", Data: wav}},
+			{"openai flac", OpenAI, File{Name: "a.flac", Data: wav}},
+			{"openai uploaded", OpenAI, File{ID: "file-1", MimeType: "audio/wav"}},
+			{"not audio", Google, File{Name: "a.pdf", Data: wav}},
+		}
+		for _, tt := range tests {
+			agent := NewAgent(Provider{Name: tt.provider, APIKey: "test-key"})
+			_, err := agent.ChatWithAudio(context.Background(), "Hi", tt.clip)
+			var ve *ValidationError
+			if !errors.As(err, &ve) || ve.Field != "audio" {
+				t.Errorf("%s: error = %v, want audio ValidationError", tt.name, err)
+			}
+		}
+	})
+}
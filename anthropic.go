@@ -1,25 +1,37 @@
 package llmkit
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"net/http"
 	"strings"
+	"time"
 )
 
-const anthropicChatPath = "/v1/messages"
+const (
+	anthropicChatPath        = "/v1/messages"
+	anthropicCountTokensPath = "/v1/messages/count_tokens"
+	anthropicBatchesPath     = "/v1/messages/batches"
+)
 
 type anthropicRequest struct {
-	Model         string                 `json:"model"`
-	MaxTokens     int                    `json:"max_tokens"`
-	System        string                 `json:"system,omitempty"`
-	Messages      []anthropicMessage     `json:"messages"`
-	Tools         []anthropicTool        `json:"tools,omitempty"`
-	OutputFormat  *anthropicOutputFormat `json:"output_format,omitempty"`
-	Temperature   *float64               `json:"temperature,omitempty"`
-	TopP          *float64               `json:"top_p,omitempty"`
-	TopK          *int                   `json:"top_k,omitempty"`
-	StopSequences []string               `json:"stop_sequences,omitempty"`
-	Thinking      *anthropicThinking     `json:"thinking,omitempty"`
+	Model            string                 `json:"model,omitempty"`             // in the URL on Vertex AI
+	AnthropicVersion string                 `json:"anthropic_version,omitempty"` // Vertex AI only
+	MaxTokens        int                    `json:"max_tokens"`
+	System           string                 `json:"system,omitempty"`
+	Messages         []anthropicMessage     `json:"messages"`
+	Tools            []anthropicTool        `json:"tools,omitempty"`
+	ToolChoice       *anthropicToolChoice   `json:"tool_choice,omitempty"`
+	OutputFormat     *anthropicOutputFormat `json:"output_format,omitempty"`
+	Temperature      *float64               `json:"temperature,omitempty"`
+	TopP             *float64               `json:"top_p,omitempty"`
+	TopK             *int                   `json:"top_k,omitempty"`
+	StopSequences    []string               `json:"stop_sequences,omitempty"`
+	Thinking         *anthropicThinking     `json:"thinking,omitempty"`
+	ServiceTier      string                 `json:"service_tier,omitempty"`
+	Stream           bool                   `json:"stream,omitempty"`
 }
 
 type anthropicTool struct {
@@ -28,6 +40,11 @@ type anthropicTool struct {
 	InputSchema map[string]any `json:"input_schema"`
 }
 
+type anthropicToolChoice struct {
+	Type                   string `json:"type"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
 type anthropicThinking struct {
 	Type         string `json:"type"`
 	BudgetTokens int    `json:"budget_tokens,omitempty"`
@@ -52,52 +69,75 @@ type anthropicContent struct {
 	Input     map[string]any   `json:"input,omitempty"`       // for tool_use
 	ToolUseID string           `json:"tool_use_id,omitempty"` // for tool_result
 	Content   string           `json:"content,omitempty"`     // for tool_result
+	IsError   bool             `json:"is_error,omitempty"`    // for tool_result
 }
 
 type anthropicSource struct {
-	Type      string `json:"type"`                 // "base64", "url", or "file"
-	MediaType string `json:"media_type,omitempty"` // for base64
-	Data      string `json:"data,omitempty"`       // for base64
+	Type      string `json:"type"`                 // "base64", "text", "url", or "file"
+	MediaType string `json:"media_type,omitempty"` // for base64 and text
+	Data      string `json:"data,omitempty"`       // for base64 and text
 	URL       string `json:"url,omitempty"`        // for url
 	FileID    string `json:"file_id,omitempty"`    // for file
 }
 
-
 type anthropicResponse struct {
-	Content []struct {
-		Type  string         `json:"type"`
-		Text  string         `json:"text,omitempty"`
-		ID    string         `json:"id,omitempty"`
-		Name  string         `json:"name,omitempty"`
-		Input map[string]any `json:"input,omitempty"`
-	} `json:"content"`
-	StopReason string `json:"stop_reason"`
-	Usage      struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage"`
+	Model      string           `json:"model"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      anthropicUsage   `json:"usage"`
+
+	rateLimit *RateLimit // from response headers
 }
 
-func promptAnthropic(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
-	maxTokens := 4096
-	if o.maxTokens != nil {
-		maxTokens = *o.maxTokens
+type anthropicBlock struct {
+	Type  string         `json:"type"`
+	Text  string         `json:"text,omitempty"`
+	ID    string         `json:"id,omitempty"`
+	Name  string         `json:"name,omitempty"`
+	Input map[string]any `json:"input,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int    `json:"input_tokens"`
+	OutputTokens             int    `json:"output_tokens"`
+	CacheReadInputTokens     int    `json:"cache_read_input_tokens,omitempty"`
+	CacheCreationInputTokens int    `json:"cache_creation_input_tokens,omitempty"`
+	ServiceTier              string `json:"service_tier,omitempty"`
+}
+
+func (u anthropicUsage) usage() Usage {
+	return Usage{
+		Input:            u.InputTokens,
+		Output:           u.OutputTokens,
+		CacheReadTokens:  u.CacheReadInputTokens,
+		CacheWriteTokens: u.CacheCreationInputTokens,
 	}
+}
 
-	// Build content array
-	content := buildAnthropicContent(req)
+// anthropicStreamEvent is one server-sent event from the Messages API.
+type anthropicStreamEvent struct {
+	Type         string             `json:"type"`
+	Index        int                `json:"index"`
+	Message      *anthropicResponse `json:"message,omitempty"`       // message_start
+	ContentBlock *anthropicBlock    `json:"content_block,omitempty"` // content_block_start
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text,omitempty"`         // text_delta
+		PartialJSON string `json:"partial_json,omitempty"` // input_json_delta
+		StopReason  string `json:"stop_reason,omitempty"`  // message_delta
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage,omitempty"` // message_delta
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
 
-	// Build messages array
-	var messages []anthropicMessage
-	if len(req.Messages) > 0 {
-		for _, m := range req.Messages {
-			messages = append(messages, anthropicMessage{
-				Role:    m.Role,
-				Content: []anthropicContent{{Type: "text", Text: m.Content}},
-			})
-		}
-	} else {
-		messages = []anthropicMessage{{Role: "user", Content: content}}
+// anthropicPayload builds the Messages API request for req and the headers it needs.
+func anthropicPayload(p Provider, req Request, o *options) (anthropicRequest, map[string]string, error) {
+	maxTokens := 4096
+	if o.maxTokens != nil {
+		maxTokens = *o.maxTokens
 	}
 
 	payload := anthropicRequest{
@@ -108,7 +148,8 @@ func promptAnthropic(ctx context.Context, p Provider, req Request, o *options) (
 		TopP:          o.topP,
 		TopK:          o.topK,
 		StopSequences: o.stopSequences,
-		Messages:      messages,
+		Messages:      anthropicMessages(req),
+		ServiceTier:   o.serviceTier,
 	}
 
 	if o.thinkingBudget != nil {
@@ -118,15 +159,12 @@ func promptAnthropic(ctx context.Context, p Provider, req Request, o *options) (
 		}
 	}
 
-	headers := map[string]string{
-		"x-api-key":         p.APIKey,
-		"anthropic-version": "2023-06-01",
-	}
+	headers := anthropicHeaders(p)
 
 	if req.Schema != "" {
-		var schema any
-		if err := json.Unmarshal([]byte(req.Schema), &schema); err != nil {
-			return Response{}, err
+		schema, _, err := TranslateSchema(Anthropic, req.Schema)
+		if err != nil {
+			return anthropicRequest{}, nil, err
 		}
 		payload.OutputFormat = &anthropicOutputFormat{
 			Type:   "json_schema",
@@ -134,38 +172,256 @@ func promptAnthropic(ctx context.Context, p Provider, req Request, o *options) (
 		}
 		headers["anthropic-beta"] = "structured-outputs-2025-11-13"
 	}
+	return payload, headers, nil
+}
 
-	body, err := json.Marshal(payload)
+func promptAnthropic(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
+	payload, headers, err := anthropicPayload(p, req, o)
 	if err != nil {
 		return Response{}, err
 	}
 
-	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, p.buildURL(anthropicChatPath), body, headers)
+	resp, err := postAnthropic(ctx, p, payload, headers, o)
 	if err != nil {
 		return Response{}, err
 	}
 
+	return Response{
+		Text:         anthropicText(resp),
+		Tokens:       resp.Usage.usage(),
+		ServiceTier:  resp.Usage.ServiceTier,
+		RateLimit:    resp.rateLimit,
+		FinishReason: anthropicFinishReason(resp.StopReason),
+	}, nil
+}
+
+// anthropicFinishReason normalizes a Messages API stop_reason.
+func anthropicFinishReason(reason string) FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "end_turn", "stop_sequence":
+		return FinishStop
+	case "max_tokens", "model_context_window_exceeded":
+		return FinishLength
+	case "tool_use":
+		return FinishToolUse
+	case "refusal":
+		return FinishContentFilter
+	default:
+		return FinishOther
+	}
+}
+
+// anthropicMessages builds the messages array from req.Messages, or from req.User and its files.
+func anthropicMessages(req Request) []anthropicMessage {
+	if len(req.Messages) == 0 {
+		return []anthropicMessage{{Role: "user", Content: buildAnthropicContent(req)}}
+	}
+	var messages []anthropicMessage
+	for _, m := range req.Messages {
+		messages = append(messages, anthropicMessage{
+			Role:    m.Role,
+			Content: buildAnthropicContent(Request{User: m.Content, Images: m.Images, Files: m.Files}),
+		})
+	}
+	return messages
+}
+
+// countTokensAnthropic asks the count_tokens endpoint for the input tokens of req.
+func countTokensAnthropic(ctx context.Context, p Provider, req Request, o *options) (int, error) {
+	body, err := json.Marshal(struct {
+		Model    string             `json:"model"`
+		System   string             `json:"system,omitempty"`
+		Messages []anthropicMessage `json:"messages"`
+	}{p.model(), req.System, anthropicMessages(req)})
+	if err != nil {
+		return 0, err
+	}
+
+	headers := anthropicHeaders(p)
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, p.buildURL(anthropicCountTokensPath), body, headers)
+	if err != nil {
+		return 0, err
+	}
+	if statusCode >= 400 {
+		return 0, parseError(Anthropic, statusCode, respBody, respHeaders)
+	}
+
+	var resp struct {
+		InputTokens int `json:"input_tokens"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return 0, err
+	}
+	return resp.InputTokens, nil
+}
+
+// anthropicText joins the text blocks of a response, skipping thinking and tool blocks.
+func anthropicText(resp anthropicResponse) string {
+	var texts []string
+	for _, c := range resp.Content {
+		if c.Type == "text" {
+			texts = append(texts, c.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// postAnthropic sends a Messages API request and decodes the response.
+// When o.stream is set the response is streamed instead (see streamAnthropic).
+func postAnthropic(ctx context.Context, p Provider, payload anthropicRequest, headers map[string]string, o *options) (anthropicResponse, error) {
+	if o.stream != nil {
+		return streamAnthropic(ctx, p, payload, headers, o)
+	}
+
+	url := p.buildURL(anthropicChatPath)
+	if p.Vertex != nil {
+		var err error
+		if payload, headers, err = vertexRequest(ctx, p, payload, headers); err != nil {
+			return anthropicResponse{}, err
+		}
+		url = vertexURL(p, false)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return anthropicResponse{}, err
+	}
+
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, url, body, headers)
+	if err != nil {
+		return anthropicResponse{}, err
+	}
+
 	if statusCode >= 400 {
-		return Response{}, parseError(Anthropic, statusCode, respBody, nil)
+		return anthropicResponse{}, parseError(Anthropic, statusCode, respBody, respHeaders)
 	}
 
 	var resp anthropicResponse
 	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return Response{}, err
+		return anthropicResponse{}, err
 	}
+	resp.rateLimit = anthropicRateLimit(respHeaders)
+	return resp, nil
+}
 
-	text := ""
-	if len(resp.Content) > 0 {
-		text = resp.Content[0].Text
+// anthropicRateLimit reads the anthropic-ratelimit-* and anthropic-priority-* headers.
+// Returns nil when none are present.
+func anthropicRateLimit(h http.Header) *RateLimit {
+	rl := RateLimit{
+		RequestsLimit:     headerInt(h, "anthropic-ratelimit-requests-limit"),
+		RequestsRemaining: headerInt(h, "anthropic-ratelimit-requests-remaining"),
+		RequestsReset:     headerTime(h, "anthropic-ratelimit-requests-reset"),
+		TokensLimit:       headerInt(h, "anthropic-ratelimit-tokens-limit"),
+		TokensRemaining:   headerInt(h, "anthropic-ratelimit-tokens-remaining"),
+		TokensReset:       headerTime(h, "anthropic-ratelimit-tokens-reset"),
+
+		PriorityInputTokensRemaining:  headerInt(h, "anthropic-priority-input-tokens-remaining"),
+		PriorityOutputTokensRemaining: headerInt(h, "anthropic-priority-output-tokens-remaining"),
+	}
+	if rl == (RateLimit{}) {
+		return nil
 	}
+	return &rl
+}
 
-	return Response{
-		Text: text,
-		Tokens: Usage{
-			Input:  resp.Usage.InputTokens,
-			Output: resp.Usage.OutputTokens,
-		},
-	}, nil
+// streamAnthropic sends a streaming Messages API request, forwards text deltas
+// to o.stream, and assembles the final message from the event stream.
+func streamAnthropic(ctx context.Context, p Provider, payload anthropicRequest, headers map[string]string, o *options) (anthropicResponse, error) {
+	payload.Stream = true
+	url := p.buildURL(anthropicChatPath)
+	if p.Vertex != nil {
+		var err error
+		if payload, headers, err = vertexRequest(ctx, p, payload, headers); err != nil {
+			return anthropicResponse{}, err
+		}
+		url = vertexURL(p, true)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return anthropicResponse{}, err
+	}
+
+	httpResp, errBody, err := doPostStream(ctx, o.httpClient, url, body, headers)
+	if err != nil {
+		return anthropicResponse{}, err
+	}
+	if httpResp.StatusCode >= 400 {
+		return anthropicResponse{}, parseError(Anthropic, httpResp.StatusCode, errBody, httpResp.Header)
+	}
+	defer httpResp.Body.Close()
+
+	var resp anthropicResponse
+	var partialJSON []string // tool input fragments per content block
+	var hadText bool         // a previous text block produced output
+	err = readStream(ctx, httpResp.Body, func(event, data string) error {
+		var ev anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return err
+		}
+
+		switch ev.Type {
+		case "message_start":
+			if ev.Message != nil {
+				resp.Usage = ev.Message.Usage
+				o.reportUsage(resp.Usage.usage())
+			}
+			resp.rateLimit = anthropicRateLimit(httpResp.Header)
+		case "content_block_start":
+			for len(resp.Content) <= ev.Index {
+				resp.Content = append(resp.Content, anthropicBlock{})
+				partialJSON = append(partialJSON, "")
+			}
+			if ev.ContentBlock != nil {
+				resp.Content[ev.Index] = *ev.ContentBlock
+			}
+		case "content_block_delta":
+			if ev.Index >= len(resp.Content) {
+				return nil
+			}
+			switch ev.Delta.Type {
+			case "text_delta":
+				// Separate text blocks the same way anthropicText joins them
+				if resp.Content[ev.Index].Text == "" && hadText {
+					o.stream("\n\n")
+				}
+				resp.Content[ev.Index].Text += ev.Delta.Text
+				hadText = true
+				o.stream(ev.Delta.Text)
+			case "input_json_delta":
+				partialJSON[ev.Index] += ev.Delta.PartialJSON
+			}
+		case "content_block_stop":
+			if ev.Index < len(partialJSON) && partialJSON[ev.Index] != "" {
+				var input map[string]any
+				if err := json.Unmarshal([]byte(partialJSON[ev.Index]), &input); err != nil {
+					return err
+				}
+				resp.Content[ev.Index].Input = input
+			}
+		case "message_delta":
+			resp.StopReason = ev.Delta.StopReason
+			if ev.Usage != nil {
+				resp.Usage.OutputTokens = ev.Usage.OutputTokens
+				o.reportUsage(resp.Usage.usage())
+			}
+		case "error":
+			apiErr := &APIError{Provider: Anthropic, StatusCode: httpResp.StatusCode}
+			if ev.Error != nil {
+				apiErr.Type = ev.Error.Type
+				apiErr.Message = ev.Error.Message
+				apiErr.Retryable = ev.Error.Type == "overloaded_error"
+			}
+			return apiErr
+		}
+		return nil
+	})
+	if err != nil {
+		return anthropicResponse{}, err
+	}
+	return resp, nil
 }
 
 // buildAnthropicContent creates content array from request.
@@ -174,13 +430,27 @@ func buildAnthropicContent(req Request) []anthropicContent {
 
 	// Add files first
 	for _, f := range req.Files {
-		content = append(content, anthropicContent{
-			Type: "document",
-			Source: &anthropicSource{
+		c := anthropicContent{Type: "document"}
+		switch {
+		case f.inline() && isTextMimeType(f.MimeType):
+			c.Source = &anthropicSource{
+				Type:      "text",
+				MediaType: "text/plain",
+				Data:      string(f.Data),
+			}
+		case f.inline():
+			c.Source = &anthropicSource{
+				Type:      "base64",
+				MediaType: f.MimeType,
+				Data:      base64.StdEncoding.EncodeToString(f.Data),
+			}
+		default:
+			c.Source = &anthropicSource{
 				Type:   "file",
 				FileID: f.ID,
-			},
-		})
+			}
+		}
+		content = append(content, c)
 	}
 
 	// Add images
@@ -217,8 +487,18 @@ func extractBase64Data(dataURI string) string {
 	return dataURI
 }
 
+// anthropicToolUse converts a tool call to a tool_use content block.
+func anthropicToolUse(tc toolCall) anthropicContent {
+	return anthropicContent{
+		Type:  "tool_use",
+		ID:    tc.id,
+		Name:  tc.name,
+		Input: tc.input,
+	}
+}
+
 // sendAnthropicWithTools sends a request with tools and returns tool calls.
-func sendAnthropicWithTools(ctx context.Context, p Provider, msgs []message, system string, tools []Tool, o *options) (string, []toolCall, Usage, error) {
+func sendAnthropicWithTools(ctx context.Context, p Provider, msgs []message, system string, tools []Tool, o *options) (turn, error) {
 	maxTokens := 4096
 	if o.maxTokens != nil {
 		maxTokens = *o.maxTokens
@@ -235,20 +515,28 @@ func sendAnthropicWithTools(ctx context.Context, p Provider, msgs []message, sys
 				Type:      "tool_result",
 				ToolUseID: m.toolResult.toolUseID,
 				Content:   m.toolResult.content,
+				IsError:   m.toolResult.isError,
 			}}
+		} else if len(m.parts) > 0 {
+			// Assistant message in the original block order
+			for _, part := range m.parts {
+				if part.call != nil {
+					msg.Content = append(msg.Content, anthropicToolUse(*part.call))
+				} else if part.text != "" {
+					msg.Content = append(msg.Content, anthropicContent{Type: "text", Text: part.text})
+				}
+			}
 		} else if len(m.toolCalls) > 0 {
 			// Assistant message with tool calls
+			if m.content != "" {
+				msg.Content = append(msg.Content, anthropicContent{Type: "text", Text: m.content})
+			}
 			for _, tc := range m.toolCalls {
-				msg.Content = append(msg.Content, anthropicContent{
-					Type:  "tool_use",
-					ID:    tc.id,
-					Name:  tc.name,
-					Input: tc.input,
-				})
+				msg.Content = append(msg.Content, anthropicToolUse(tc))
 			}
 		} else {
-			// Regular text message
-			msg.Content = []anthropicContent{{Type: "text", Text: m.content}}
+			// Regular message, with any attachments first
+			msg.Content = buildAnthropicContent(Request{User: m.content, Images: m.images, Files: m.files})
 		}
 		messages = append(messages, msg)
 	}
@@ -273,53 +561,46 @@ func sendAnthropicWithTools(ctx context.Context, p Provider, msgs []message, sys
 		TopP:          o.topP,
 		TopK:          o.topK,
 		StopSequences: o.stopSequences,
+		ServiceTier:   o.serviceTier,
 	}
 
-	headers := map[string]string{
-		"x-api-key":         p.APIKey,
-		"anthropic-version": "2023-06-01",
+	// Anthropic allows parallel tool use by default
+	if o.parallelToolCalls != nil && !*o.parallelToolCalls {
+		payload.ToolChoice = &anthropicToolChoice{Type: "auto", DisableParallelToolUse: true}
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return "", nil, Usage{}, err
-	}
+	headers := anthropicHeaders(p)
 
-	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, p.buildURL(anthropicChatPath), body, headers)
+	resp, err := postAnthropic(ctx, p, payload, headers, o)
 	if err != nil {
-		return "", nil, Usage{}, err
-	}
-
-	if statusCode >= 400 {
-		return "", nil, Usage{}, parseError(Anthropic, statusCode, respBody, nil)
-	}
-
-	var resp anthropicResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return "", nil, Usage{}, err
+		return turn{}, err
 	}
 
-	// Extract text and tool calls from response
-	var text string
-	var calls []toolCall
+	// Extract text and tool calls, keeping the block order for history
+	var t turn
+	var texts []string
 	for _, c := range resp.Content {
-		if c.Type == "text" {
-			text = c.Text
-		} else if c.Type == "tool_use" {
-			calls = append(calls, toolCall{
+		switch c.Type {
+		case "text":
+			texts = append(texts, c.Text)
+			t.parts = append(t.parts, messagePart{text: c.Text})
+		case "tool_use":
+			call := toolCall{
 				id:    c.ID,
 				name:  c.Name,
 				input: c.Input,
-			})
+			}
+			t.calls = append(t.calls, call)
+			t.parts = append(t.parts, messagePart{call: &call})
 		}
 	}
+	t.text = strings.Join(texts, "\n\n")
+	t.usage = resp.Usage.usage()
+	t.serviceTier = resp.Usage.ServiceTier
+	t.rateLimit = resp.rateLimit
+	t.finishReason = anthropicFinishReason(resp.StopReason)
 
-	usage := Usage{
-		Input:  resp.Usage.InputTokens,
-		Output: resp.Usage.OutputTokens,
-	}
-
-	return text, calls, usage, nil
+	return t, nil
 }
 
 const anthropicFilesPath = "/v1/files"
@@ -332,11 +613,8 @@ type anthropicFileResponse struct {
 
 // uploadAnthropic uploads a file to Anthropic's Files API (beta).
 func uploadAnthropic(ctx context.Context, p Provider, data []byte, name, mimeType string, o *options) (File, error) {
-	headers := map[string]string{
-		"x-api-key":         p.APIKey,
-		"anthropic-version": "2023-06-01",
-		"anthropic-beta":    "files-api-2025-04-14",
-	}
+	headers := anthropicHeaders(p)
+	headers["anthropic-beta"] = "files-api-2025-04-14"
 
 	respBody, statusCode, err := doMultipartPost(ctx, o.httpClient, p.buildURL(anthropicFilesPath),
 		"file", name, data, nil, headers)
@@ -359,3 +637,152 @@ func uploadAnthropic(ctx context.Context, p Provider, data []byte, name, mimeTyp
 		Name:     resp.Filename,
 	}, nil
 }
+
+// anthropicBatch is a message batch from the Message Batches API.
+type anthropicBatch struct {
+	ID               string    `json:"id"`
+	ProcessingStatus string    `json:"processing_status"`
+	CreatedAt        time.Time `json:"created_at"`
+	RequestCounts    struct {
+		Processing int `json:"processing"`
+		Succeeded  int `json:"succeeded"`
+		Errored    int `json:"errored"`
+		Canceled   int `json:"canceled"`
+		Expired    int `json:"expired"`
+	} `json:"request_counts"`
+}
+
+func (b anthropicBatch) batch() Batch {
+	c := b.RequestCounts
+	failed := c.Errored + c.Canceled + c.Expired
+	return Batch{
+		ID:        b.ID,
+		Provider:  Anthropic,
+		Status:    b.ProcessingStatus,
+		Done:      b.ProcessingStatus == "ended",
+		Total:     c.Processing + c.Succeeded + failed,
+		Succeeded: c.Succeeded,
+		Failed:    failed,
+		CreatedAt: b.CreatedAt,
+	}
+}
+
+// anthropicBatchResult is one line of a message batch results file.
+type anthropicBatchResult struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string            `json:"type"` // "succeeded", "errored", "canceled" or "expired"
+		Message anthropicResponse `json:"message"`
+		Error   json.RawMessage   `json:"error"`
+	} `json:"result"`
+}
+
+// createBatchAnthropic submits the requests as a message batch.
+func createBatchAnthropic(ctx context.Context, p Provider, reqs []BatchRequest, o *options) (Batch, error) {
+	type batchRequest struct {
+		CustomID string           `json:"custom_id"`
+		Params   anthropicRequest `json:"params"`
+	}
+	var payload struct {
+		Requests []batchRequest `json:"requests"`
+	}
+	var headers map[string]string
+	for _, r := range reqs {
+		params, h, err := anthropicPayload(p, r.Request, o)
+		if err != nil {
+			return Batch{}, err
+		}
+		// Beta headers apply to the whole batch
+		if headers == nil || h["anthropic-beta"] != "" {
+			headers = h
+		}
+		payload.Requests = append(payload.Requests, batchRequest{CustomID: r.CustomID, Params: params})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Batch{}, err
+	}
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, p.buildURL(anthropicBatchesPath), body, headers)
+	if err != nil {
+		return Batch{}, err
+	}
+	if statusCode >= 400 {
+		return Batch{}, parseError(Anthropic, statusCode, respBody, respHeaders)
+	}
+
+	var b anthropicBatch
+	if err := json.Unmarshal(respBody, &b); err != nil {
+		return Batch{}, err
+	}
+	return b.batch(), nil
+}
+
+func getBatchAnthropic(ctx context.Context, p Provider, id string, o *options) (Batch, error) {
+	respBody, statusCode, respHeaders, err := doGet(ctx, o.httpClient, p.buildURL(anthropicBatchesPath+"/"+id), anthropicHeaders(p))
+	if err != nil {
+		return Batch{}, err
+	}
+	if statusCode >= 400 {
+		return Batch{}, parseError(Anthropic, statusCode, respBody, respHeaders)
+	}
+
+	var b anthropicBatch
+	if err := json.Unmarshal(respBody, &b); err != nil {
+		return Batch{}, err
+	}
+	return b.batch(), nil
+}
+
+// listBatchResultsAnthropic downloads and decodes the results of an ended message batch.
+func listBatchResultsAnthropic(ctx context.Context, p Provider, id string, o *options) ([]BatchResult, error) {
+	b, err := getBatchAnthropic(ctx, p, id, o)
+	if err != nil {
+		return nil, err
+	}
+	if !b.Done {
+		return nil, &ValidationError{Field: "batch", Message: id + " is still " + b.Status}
+	}
+
+	data, statusCode, respHeaders, err := doGet(ctx, o.httpClient, p.buildURL(anthropicBatchesPath+"/"+id+"/results"), anthropicHeaders(p))
+	if err != nil {
+		return nil, err
+	}
+	if statusCode >= 400 {
+		return nil, parseError(Anthropic, statusCode, data, respHeaders)
+	}
+
+	var results []BatchResult
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var line anthropicBatchResult
+		if err := dec.Decode(&line); err != nil {
+			return nil, err
+		}
+		r := BatchResult{CustomID: line.CustomID}
+		switch line.Result.Type {
+		case "succeeded":
+			msg := line.Result.Message
+			r.Response = Response{Text: anthropicText(msg), Tokens: msg.Usage.usage(), ServiceTier: msg.Usage.ServiceTier}
+			model := msg.Model
+			if model == "" {
+				model = p.model()
+			}
+			r.Response.Cost = EstimateCost(r.Response.Tokens, model) * batchDiscount
+		case "errored":
+			r.Err = parseError(Anthropic, 0, line.Result.Error, nil)
+		default:
+			r.Err = &APIError{Provider: Anthropic, Type: line.Result.Type, Message: "request " + line.Result.Type}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// anthropicHeaders returns the auth and version headers.
+func anthropicHeaders(p Provider) map[string]string {
+	return p.withHeaders(map[string]string{
+		"x-api-key":         p.APIKey,
+		"anthropic-version": "2023-06-01",
+	})
+}
@@ -2,9 +2,14 @@ package llmkit
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"gopkg.in/dnaeon/go-vcr.v3/cassette"
 	"gopkg.in/dnaeon/go-vcr.v3/recorder"
@@ -189,6 +194,15 @@ func TestBuildAnthropicContent(t *testing.T) {
 			wantLen:   2,
 			wantTypes: []string{"document", "text"},
 		},
+		{
+			name: "inline pdf and text",
+			req: Request{
+				User:  "summarize this",
+				Files: []File{{MimeType: "application/pdf", Data: []byte("%PDF-1.4")}},
+			},
+			wantLen:   2,
+			wantTypes: []string{"document", "text"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -206,6 +220,27 @@ func TestBuildAnthropicContent(t *testing.T) {
 	}
 }
 
+func TestBuildAnthropicContent_InlineDocument(t *testing.T) {
+	req := Request{Files: []File{
+		{MimeType: "application/pdf", Data: []byte("%PDF-1.4")},
+		{MimeType: "text/plain", Data: []byte("plain text")},
+	}}
+
+	content := buildAnthropicContent(req)
+	if len(content) != 2 {
+		t.Fatalf("got %d parts, want 2", len(content))
+	}
+
+	pdf := content[0].Source
+	if pdf.Type != "base64" || pdf.MediaType != "application/pdf" || pdf.Data != "JVBERi0xLjQ=" {
+		t.Errorf("pdf source = %+v, want base64 application/pdf", pdf)
+	}
+	txt := content[1].Source
+	if txt.Type != "text" || txt.MediaType != "text/plain" || txt.Data != "plain text" {
+		t.Errorf("text source = %+v, want text/plain", txt)
+	}
+}
+
 func TestExtractBase64Data(t *testing.T) {
 	tests := []struct {
 		input string
@@ -223,3 +258,220 @@ func TestExtractBase64Data(t *testing.T) {
 		}
 	}
 }
+
+func TestSendAnthropicWithTools_DisableParallel(t *testing.T) {
+	var body map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"content":[{"type":"text","text":"done"}],"usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Anthropic, APIKey: "test-key", BaseURL: server.URL}
+	msgs := []message{{role: "user", content: "hi"}}
+
+	_, err := sendAnthropicWithTools(context.Background(), p, msgs, "", []Tool{testWeatherTool()},
+		applyOptions(WithParallelToolCalls(false)))
+	if err != nil {
+		t.Fatalf("sendAnthropicWithTools() error = %v", err)
+	}
+
+	choice, ok := body["tool_choice"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected tool_choice object, got %T", body["tool_choice"])
+	}
+	if choice["type"] != "auto" || choice["disable_parallel_tool_use"] != true {
+		t.Errorf("tool_choice = %v, want auto with disable_parallel_tool_use", choice)
+	}
+}
+
+// sseServer returns a test server that writes the given SSE events.
+func sseServer(t *testing.T, events ...string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, e := range events {
+			w.Write([]byte(e + "\n\n"))
+		}
+	}))
+}
+
+func TestPromptStream_Anthropic(t *testing.T) {
+	server := sseServer(t,
+		`event: message_start`+"\n"+`data: {"type":"message_start","message":{"usage":{"input_tokens":12,"output_tokens":1}}}`,
+		`event: content_block_start`+"\n"+`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`event: content_block_delta`+"\n"+`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`,
+		`event: ping`+"\n"+`data: {"type":"ping"}`,
+		`event: content_block_delta`+"\n"+`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" world"}}`,
+		`event: content_block_stop`+"\n"+`data: {"type":"content_block_stop","index":0}`,
+		`event: message_delta`+"\n"+`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":7}}`,
+		`event: message_stop`+"\n"+`data: {"type":"message_stop"}`,
+	)
+	defer server.Close()
+
+	p := Provider{Name: Anthropic, APIKey: "test-key", BaseURL: server.URL}
+	var deltas []string
+
+	resp, err := PromptStream(context.Background(), p, Request{User: "Hi"}, func(d string) {
+		deltas = append(deltas, d)
+	})
+	if err != nil {
+		t.Fatalf("PromptStream() error = %v", err)
+	}
+	if strings.Join(deltas, "|") != "Hello| world" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if resp.Text != "Hello world" {
+		t.Errorf("Text = %q, want Hello world", resp.Text)
+	}
+	if resp.Tokens.Input != 12 || resp.Tokens.Output != 7 {
+		t.Errorf("Tokens = %+v, want 12 in / 7 out", resp.Tokens)
+	}
+	if resp.FinishReason != FinishStop {
+		t.Errorf("FinishReason = %q, want stop", resp.FinishReason)
+	}
+}
+
+func TestPromptAnthropic_FinishReasonLength(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"content":[{"type":"text","text":"Once upon a"}],"stop_reason":"max_tokens","usage":{"input_tokens":5,"output_tokens":3}}`,
+	}}
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	resp, err := Prompt(context.Background(), p, Request{User: "Tell a story"},
+		WithMaxTokens(3), WithHTTPClient(&http.Client{Transport: mock}))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.FinishReason != FinishLength {
+		t.Errorf("FinishReason = %q, want length", resp.FinishReason)
+	}
+}
+
+func TestPromptStream_AnthropicErrorEvent(t *testing.T) {
+	server := sseServer(t,
+		`event: error`+"\n"+`data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+	)
+	defer server.Close()
+
+	p := Provider{Name: Anthropic, APIKey: "test-key", BaseURL: server.URL}
+
+	_, err := PromptStream(context.Background(), p, Request{User: "Hi"}, func(string) {})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if apiErr.Type != "overloaded_error" || !apiErr.Retryable {
+		t.Errorf("APIError = %+v, want retryable overloaded_error", apiErr)
+	}
+}
+
+func TestSendAnthropicWithTools_StreamToolUse(t *testing.T) {
+	server := sseServer(t,
+		`data: {"type":"message_start","message":{"usage":{"input_tokens":5}}}`,
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Checking."}}`,
+		`data: {"type":"content_block_stop","index":0}`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":" \"Paris\"}"}}`,
+		`data: {"type":"content_block_stop","index":1}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":20}}`,
+	)
+	defer server.Close()
+
+	p := Provider{Name: Anthropic, APIKey: "test-key", BaseURL: server.URL}
+	o := applyOptions()
+	var streamed string
+	o.stream = func(d string) { streamed += d }
+
+	tr, err := sendAnthropicWithTools(context.Background(), p, []message{{role: "user", content: "hi"}}, "", []Tool{testWeatherTool()}, o)
+	if err != nil {
+		t.Fatalf("sendAnthropicWithTools() error = %v", err)
+	}
+	if streamed != "Checking." || tr.text != "Checking." {
+		t.Errorf("streamed = %q, text = %q", streamed, tr.text)
+	}
+	if len(tr.calls) != 1 || tr.calls[0].input["city"] != "Paris" {
+		t.Fatalf("calls = %+v, want get_weather(Paris)", tr.calls)
+	}
+	if len(tr.parts) != 2 || tr.parts[0].text != "Checking." || tr.parts[1].call == nil {
+		t.Errorf("parts = %+v, want text then tool call", tr.parts)
+	}
+	if tr.usage.Input != 5 || tr.usage.Output != 20 {
+		t.Errorf("usage = %+v", tr.usage)
+	}
+}
+
+func TestPromptAnthropic_ServiceTierAndRateLimit(t *testing.T) {
+	var gotTier string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		gotTier = req.ServiceTier
+		w.Header().Set("anthropic-ratelimit-requests-limit", "50")
+		w.Header().Set("anthropic-ratelimit-requests-remaining", "49")
+		w.Header().Set("anthropic-ratelimit-tokens-reset", "2026-01-02T15:04:05Z")
+		w.Header().Set("anthropic-priority-input-tokens-remaining", "90000")
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":1,"output_tokens":1,"service_tier":"priority"}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Anthropic, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hi"}, WithServiceTier("auto"))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+
+	if gotTier != "auto" {
+		t.Errorf("service_tier = %q, want auto", gotTier)
+	}
+	if resp.ServiceTier != "priority" {
+		t.Errorf("ServiceTier = %q, want priority", resp.ServiceTier)
+	}
+	rl := resp.RateLimit
+	if rl == nil || rl.RequestsLimit != 50 || rl.RequestsRemaining != 49 || rl.PriorityInputTokensRemaining != 90000 {
+		t.Fatalf("RateLimit = %+v", rl)
+	}
+	if !rl.TokensReset.Equal(time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("TokensReset = %v", rl.TokensReset)
+	}
+}
+
+func TestWithServiceTier_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		tier     string
+	}{
+		{"unsupported provider", OpenAI, "auto"},
+		{"unknown tier", Anthropic, "priority"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOptions(Provider{Name: tt.provider}, applyOptions(WithServiceTier(tt.tier)))
+			var valErr *ValidationError
+			if !errors.As(err, &valErr) || valErr.Field != "service_tier" {
+				t.Errorf("expected service_tier ValidationError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPromptAnthropic_CacheUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":12,"output_tokens":3,"cache_read_input_tokens":2048,"cache_creation_input_tokens":512}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Anthropic, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hi"})
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+
+	want := Usage{Input: 12, Output: 3, CacheReadTokens: 2048, CacheWriteTokens: 512}
+	if resp.Tokens != want {
+		t.Errorf("Tokens = %+v, want %+v", resp.Tokens, want)
+	}
+}
@@ -0,0 +1,160 @@
+package llmkit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// audioChunk is a slice of a longer recording that plays on its own.
+type audioChunk struct {
+	data  []byte
+	start time.Duration // offset in the original recording
+}
+
+// splitAudio cuts WAV or MP3 audio into chunks of at most maxBytes, each
+// starting overlap before the previous one ends so no word is lost at a cut.
+func splitAudio(data []byte, maxBytes int, overlap time.Duration) ([]audioChunk, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("RIFF")) && len(data) >= 12 && string(data[8:12]) == "WAVE":
+		return splitWAV(data, maxBytes, overlap)
+	case bytes.HasPrefix(data, []byte("ID3")) || len(data) > 1 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		return splitMP3(data, maxBytes, overlap)
+	default:
+		return nil, &ValidationError{Field: "audio", Message: fmt.Sprintf("over %d MB; only WAV and MP3 can be split", maxBytes>>20)}
+	}
+}
+
+// splitWAV cuts PCM data on sample frame boundaries, repeating the fmt chunk
+// in every piece.
+func splitWAV(data []byte, maxBytes int, overlap time.Duration) ([]audioChunk, error) {
+	var format, pcm []byte
+	for off := 12; off+8 <= len(data); {
+		id, size := string(data[off:off+4]), int(binary.LittleEndian.Uint32(data[off+4:off+8]))
+		body := data[off+8 : min(off+8+size, len(data))]
+		switch id {
+		case "fmt ":
+			format = data[off : off+8+len(body)]
+		case "data":
+			pcm = body
+		}
+		off += 8 + size + size%2 // chunks are word aligned
+	}
+	if len(format) < 24 || pcm == nil {
+		return nil, &ValidationError{Field: "audio", Message: "malformed WAV file"}
+	}
+	rate := int(binary.LittleEndian.Uint32(format[12:16]))
+	align := int(binary.LittleEndian.Uint16(format[20:22]))
+	if rate == 0 || align == 0 {
+		return nil, &ValidationError{Field: "audio", Message: "malformed WAV file"}
+	}
+
+	bytesPerSec := rate * align
+	size := (maxBytes - 20 - len(format)) / align * align
+	step := size - int(overlap.Seconds()*float64(rate))*align
+	if step <= 0 {
+		return nil, &ValidationError{Field: "audio", Message: "chunk limit too small for the overlap"}
+	}
+
+	var chunks []audioChunk
+	for off := 0; off < len(pcm); off += step {
+		part := pcm[off:min(off+size, len(pcm))]
+		out := make([]byte, 0, 20+len(format)+len(part))
+		out = append(out, "RIFF"...)
+		out = binary.LittleEndian.AppendUint32(out, uint32(12+len(format)+len(part)))
+		out = append(out, "WAVE"...)
+		out = append(out, format...)
+		out = append(out, "data"...)
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(part)))
+		out = append(out, part...)
+		chunks = append(chunks, audioChunk{data: out, start: time.Duration(off) * time.Second / time.Duration(bytesPerSec)})
+		if off+size >= len(pcm) {
+			break
+		}
+	}
+	return chunks, nil
+}
+
+// mp3Frame is one MPEG audio frame's position in the file.
+type mp3Frame struct {
+	off, size int
+	start     time.Duration
+}
+
+// Layer III bitrates in kbit/s by bitrate index, for MPEG-1 and MPEG-2/2.5.
+var (
+	mp3Bitrates1 = [15]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3Bitrates2 = [15]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mp3Rates     = map[int][3]int{3: {44100, 48000, 32000}, 2: {22050, 24000, 16000}, 0: {11025, 12000, 8000}}
+)
+
+// mp3Frames lists the Layer III frames after any ID3v2 tag, stopping at the
+// first bytes that are not a frame (such as a trailing ID3v1 tag).
+func mp3Frames(data []byte) ([]mp3Frame, error) {
+	off := 0
+	if bytes.HasPrefix(data, []byte("ID3")) && len(data) >= 10 {
+		// The tag size is syncsafe: 7 bits per byte
+		off = 10 + (int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9]))
+	}
+
+	var frames []mp3Frame
+	var at time.Duration
+	for off+4 <= len(data) {
+		h := data[off : off+4]
+		version, layer := int(h[1]>>3)&3, int(h[1]>>1)&3
+		bitrateIdx, rateIdx, padding := int(h[2]>>4), int(h[2]>>2)&3, int(h[2]>>1)&1
+		if h[0] != 0xFF || h[1]&0xE0 != 0xE0 || version == 1 || bitrateIdx == 0 || bitrateIdx == 15 || rateIdx == 3 {
+			break
+		}
+		if layer != 1 {
+			return nil, &ValidationError{Field: "audio", Message: "only MPEG Layer III audio can be split"}
+		}
+
+		rate := mp3Rates[version][rateIdx]
+		size, samples := 144000*mp3Bitrates1[bitrateIdx]/rate+padding, 1152
+		if version != 3 {
+			size, samples = 72000*mp3Bitrates2[bitrateIdx]/rate+padding, 576
+		}
+		frames = append(frames, mp3Frame{off: off, size: min(size, len(data)-off), start: at})
+		at += time.Duration(samples) * time.Second / time.Duration(rate)
+		off += size
+	}
+	if len(frames) == 0 {
+		return nil, &ValidationError{Field: "audio", Message: "malformed MP3 file"}
+	}
+	return frames, nil
+}
+
+// splitMP3 cuts on frame boundaries; each piece is a playable MP3 stream.
+func splitMP3(data []byte, maxBytes int, overlap time.Duration) ([]audioChunk, error) {
+	frames, err := mp3Frames(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []audioChunk
+	for first := 0; first < len(frames); {
+		end, size := first, 0
+		for end < len(frames) && size+frames[end].size <= maxBytes {
+			size += frames[end].size
+			end++
+		}
+		if end == first {
+			return nil, &ValidationError{Field: "audio", Message: "chunk limit smaller than an MP3 frame"}
+		}
+		last := frames[end-1]
+		chunks = append(chunks, audioChunk{data: data[frames[first].off : last.off+last.size], start: frames[first].start})
+		if end == len(frames) {
+			break
+		}
+
+		// Start the next chunk overlap before this one ends
+		next := end
+		for next > first+1 && frames[end].start-frames[next].start < overlap {
+			next--
+		}
+		first = next
+	}
+	return chunks, nil
+}
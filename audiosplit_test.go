@@ -0,0 +1,86 @@
+package llmkit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSplitWAV(t *testing.T) {
+	pcm := make([]byte, 32000) // 2 s of 16-bit mono at 8 kHz
+	for i := range pcm {
+		pcm[i] = byte(i / 2)
+	}
+	chunks, err := splitAudio(wavFile(pcm, 8000), 44+16000, 250*time.Millisecond)
+	if err != nil {
+		t.Fatalf("splitAudio() error = %v", err)
+	}
+
+	// 1 s per chunk, stepping 0.75 s
+	wantStarts := []time.Duration{0, 750 * time.Millisecond, 1500 * time.Millisecond}
+	if len(chunks) != len(wantStarts) {
+		t.Fatalf("got %d chunks, want %d", len(chunks), len(wantStarts))
+	}
+	for i, c := range chunks {
+		if c.start != wantStarts[i] {
+			t.Errorf("chunk %d start = %v, want %v", i, c.start, wantStarts[i])
+		}
+		if len(c.data) > 44+16000 || string(c.data[:4]) != "RIFF" || string(c.data[36:40]) != "data" {
+			t.Fatalf("chunk %d header = %q", i, c.data[:44])
+		}
+		size := int(binary.LittleEndian.Uint32(c.data[40:44]))
+		off := int(c.start.Seconds() * 16000)
+		if size != len(c.data)-44 || !bytes.Equal(c.data[44:], pcm[off:off+size]) {
+			t.Errorf("chunk %d data does not match source at %d", i, off)
+		}
+	}
+}
+
+// mp3File builds an ID3-tagged MP3 of n silent MPEG-1 Layer III frames
+// (128 kbit/s, 44.1 kHz, 417 bytes and 1152 samples each).
+func mp3File(n int) []byte {
+	data := []byte("ID3\x04\x00\x00\x00\x00\x00\x05hello")
+	for range n {
+		frame := make([]byte, 417)
+		copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+		data = append(data, frame...)
+	}
+	return append(data, "TAG"...)
+}
+
+func TestSplitMP3(t *testing.T) {
+	data := mp3File(100) // about 2.6 s
+	chunks, err := splitAudio(data, 417*40, 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("splitAudio() error = %v", err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("got %d chunks, want 4", len(chunks))
+	}
+
+	frame := 1152 * time.Second / 44100
+	for i, c := range chunks {
+		if len(c.data)%417 != 0 || c.data[0] != 0xFF || len(c.data) > 417*40 {
+			t.Errorf("chunk %d: %d bytes starting %x", i, len(c.data), c.data[:2])
+		}
+		if i > 0 {
+			prevEnd := chunks[i-1].start + time.Duration(len(chunks[i-1].data)/417)*frame
+			if overlap := prevEnd - c.start; overlap < 500*time.Millisecond || overlap > 500*time.Millisecond+frame {
+				t.Errorf("chunk %d overlaps the previous by %v", i, overlap)
+			}
+		}
+	}
+	last := chunks[len(chunks)-1]
+	if end := last.start + time.Duration(len(last.data)/417)*frame; end != 100*frame {
+		t.Errorf("chunks end at %v, want %v", end, 100*frame)
+	}
+}
+
+func TestSplitAudio_Unsupported(t *testing.T) {
+	var ve *ValidationError
+	if _, err := splitAudio([]byte("OggS...."), 1<<20, time.Second); !errors.As(err, &ve) || ve.Field != "audio" {
+		t.Errorf("error = %v, want audio ValidationError", err)
+	}
+}
@@ -0,0 +1,167 @@
+package llmkit
+
+import (
+	"context"
+	"time"
+)
+
+// batchDiscount is the share of list price charged for batch requests.
+const batchDiscount = 0.5
+
+const defaultBatchPollInterval = 30 * time.Second
+
+// BatchRequest is one request of a batch job. CustomID matches it to its result.
+type BatchRequest struct {
+	CustomID string
+	Request  Request
+}
+
+// Batch is the state of an asynchronous batch job.
+type Batch struct {
+	ID        string
+	Provider  string
+	Status    string // provider status, e.g. "validating", "in_progress", "completed"
+	Done      bool   // the job has finished (successfully or not) and its results can be listed
+	Total     int
+	Succeeded int
+	Failed    int
+	CreatedAt time.Time
+}
+
+// BatchResult is the outcome of one BatchRequest. Err is set when that request failed.
+type BatchResult struct {
+	CustomID string
+	Response Response
+	Err      error
+}
+
+// CreateBatch submits reqs as an asynchronous batch job, which providers bill
+// at a discount and complete within 24 hours. Options such as WithModel and
+// WithTemperature apply to every request. Anthropic, OpenAI and Google only.
+func CreateBatch(ctx context.Context, p Provider, reqs []BatchRequest, opts ...Option) (Batch, error) {
+	o := applyOptions(opts...)
+	if o.model != "" {
+		p.Model = o.model
+	}
+	if err := validateBatchProvider(p); err != nil {
+		return Batch{}, err
+	}
+	if len(reqs) == 0 {
+		return Batch{}, &ValidationError{Field: "requests", Message: "required"}
+	}
+	seen := map[string]bool{}
+	for _, r := range reqs {
+		if r.CustomID == "" || seen[r.CustomID] {
+			return Batch{}, &ValidationError{Field: "custom_id", Message: "must be set and unique: " + r.CustomID}
+		}
+		seen[r.CustomID] = true
+		if err := validateRequest(r.Request); err != nil {
+			return Batch{}, err
+		}
+		if len(r.Request.Tools) > 0 {
+			return Batch{}, &ValidationError{Field: "tools", Message: "tool execution not supported in batches"}
+		}
+	}
+	if err := validateOptions(p, o); err != nil {
+		return Batch{}, err
+	}
+
+	switch p.Name {
+	case Anthropic:
+		return withPoolKey(ctx, p, o, func(p Provider) (Batch, error) {
+			return createBatchAnthropic(ctx, p, reqs, o)
+		})
+	case OpenAI:
+		return withPoolKey(ctx, p, o, func(p Provider) (Batch, error) {
+			return createBatchOpenAI(ctx, p, reqs, o)
+		})
+	case Google:
+		return withPoolKey(ctx, p, o, func(p Provider) (Batch, error) {
+			return createBatchGoogle(ctx, p, reqs, o)
+		})
+	default:
+		return Batch{}, &ValidationError{Field: "provider", Message: "batches not supported by " + p.Name}
+	}
+}
+
+// GetBatch returns the current state of a batch job.
+func GetBatch(ctx context.Context, p Provider, id string, opts ...Option) (Batch, error) {
+	o := applyOptions(opts...)
+	if err := validateBatchProvider(p); err != nil {
+		return Batch{}, err
+	}
+	switch p.Name {
+	case Anthropic:
+		return withPoolKey(ctx, p, o, func(p Provider) (Batch, error) {
+			return getBatchAnthropic(ctx, p, id, o)
+		})
+	case OpenAI:
+		return withPoolKey(ctx, p, o, func(p Provider) (Batch, error) {
+			b, err := getBatchOpenAI(ctx, p, id, o)
+			return b.batch(), err
+		})
+	case Google:
+		return withPoolKey(ctx, p, o, func(p Provider) (Batch, error) {
+			b, err := getBatchGoogle(ctx, p, id, o)
+			return b.batch(), err
+		})
+	default:
+		return Batch{}, &ValidationError{Field: "provider", Message: "batches not supported by " + p.Name}
+	}
+}
+
+// WaitBatch polls a batch job every interval (30s when 0) until it is done or ctx ends.
+func WaitBatch(ctx context.Context, p Provider, id string, interval time.Duration, opts ...Option) (Batch, error) {
+	o := applyOptions(opts...)
+	if interval <= 0 {
+		interval = defaultBatchPollInterval
+	}
+	for {
+		b, err := GetBatch(ctx, p, id, opts...)
+		if err != nil || b.Done {
+			return b, err
+		}
+		select {
+		case <-o.clock.After(interval):
+		case <-ctx.Done():
+			return b, ctx.Err()
+		}
+	}
+}
+
+// ListBatchResults downloads the results of a finished batch job. Response.Cost
+// reflects the batch discount.
+func ListBatchResults(ctx context.Context, p Provider, id string, opts ...Option) ([]BatchResult, error) {
+	o := applyOptions(opts...)
+	if err := validateBatchProvider(p); err != nil {
+		return nil, err
+	}
+	switch p.Name {
+	case Anthropic:
+		return withPoolKey(ctx, p, o, func(p Provider) ([]BatchResult, error) {
+			return listBatchResultsAnthropic(ctx, p, id, o)
+		})
+	case OpenAI:
+		return withPoolKey(ctx, p, o, func(p Provider) ([]BatchResult, error) {
+			return listBatchResultsOpenAI(ctx, p, id, o)
+		})
+	case Google:
+		return withPoolKey(ctx, p, o, func(p Provider) ([]BatchResult, error) {
+			return listBatchResultsGoogle(ctx, p, id, o)
+		})
+	default:
+		return nil, &ValidationError{Field: "provider", Message: "batches not supported by " + p.Name}
+	}
+}
+
+// validateBatchProvider checks p like validateProvider and rejects Vertex AI,
+// whose batch prediction jobs work differently.
+func validateBatchProvider(p Provider) error {
+	if err := validateProvider(p); err != nil {
+		return err
+	}
+	if p.Vertex != nil {
+		return &ValidationError{Field: "vertex", Message: "batches not supported on Vertex AI"}
+	}
+	return nil
+}
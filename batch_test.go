@@ -0,0 +1,258 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatch_OpenAI(t *testing.T) {
+	var uploaded, created string
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/files":
+			if r.FormValue("purpose") != "batch" {
+				t.Errorf("purpose = %q", r.FormValue("purpose"))
+			}
+			f, _, _ := r.FormFile("file")
+			data, _ := io.ReadAll(f)
+			uploaded = string(data)
+			w.Write([]byte(`{"id":"file_in","filename":"batch.jsonl"}`))
+		case r.Method == "POST" && r.URL.Path == "/v1/batches":
+			data, _ := io.ReadAll(r.Body)
+			created = string(data)
+			w.Write([]byte(`{"id":"batch_1","status":"validating","created_at":1700000000}`))
+		case r.URL.Path == "/v1/batches/batch_1":
+			polls++
+			if polls < 2 {
+				w.Write([]byte(`{"id":"batch_1","status":"in_progress"}`))
+				return
+			}
+			w.Write([]byte(`{"id":"batch_1","status":"completed","output_file_id":"file_out","error_file_id":"file_err",
+				"request_counts":{"total":3,"completed":2,"failed":1}}`))
+		case r.URL.Path == "/v1/files/file_out/content":
+			w.Write([]byte(`{"custom_id":"a","response":{"status_code":200,"body":{"model":"gpt-4o-mini","choices":[{"message":{"content":"yes"}}],"usage":{"prompt_tokens":1000,"completion_tokens":1000}}},"error":null}
+{"custom_id":"b","response":{"status_code":200,"body":{"choices":[{"message":{"content":"no"}}]}},"error":null}
+`))
+		case r.URL.Path == "/v1/files/file_err/content":
+			w.Write([]byte(`{"custom_id":"c","response":{"status_code":400,"body":{"error":{"message":"bad request","type":"invalid_request_error"}}},"error":null}` + "\n"))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	ctx := context.Background()
+	b, err := CreateBatch(ctx, p, []BatchRequest{
+		{CustomID: "a", Request: Request{User: "Is the sky blue?"}},
+		{CustomID: "b", Request: Request{User: "Is grass red?"}},
+		{CustomID: "c", Request: Request{User: "Bad"}},
+	}, WithModel("gpt-4o-mini"), WithTemperature(0))
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if b.ID != "batch_1" || b.Done || b.CreatedAt.Unix() != 1700000000 {
+		t.Errorf("batch = %+v", b)
+	}
+	lines := strings.Split(strings.TrimSpace(uploaded), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], `"custom_id":"a","method":"POST","url":"/v1/chat/completions"`) ||
+		!strings.Contains(lines[0], `"model":"gpt-4o-mini"`) || !strings.Contains(lines[0], `"temperature":0`) {
+		t.Errorf("input file = %s", uploaded)
+	}
+	if !strings.Contains(created, `"input_file_id":"file_in"`) || !strings.Contains(created, `"completion_window":"24h"`) {
+		t.Errorf("create body = %s", created)
+	}
+
+	if _, err := ListBatchResults(ctx, p, "batch_1"); err == nil {
+		t.Error("results of an unfinished batch were listed")
+	}
+
+	b, err = WaitBatch(ctx, p, "batch_1", time.Millisecond)
+	if err != nil || !b.Done || b.Succeeded != 2 || b.Failed != 1 {
+		t.Fatalf("WaitBatch() = %+v, %v", b, err)
+	}
+
+	results, err := ListBatchResults(ctx, p, "batch_1")
+	if err != nil {
+		t.Fatalf("ListBatchResults() error = %v", err)
+	}
+	if len(results) != 3 || results[0].Response.Text != "yes" || results[1].Response.Text != "no" {
+		t.Fatalf("results = %+v", results)
+	}
+	if want := (0.00015 + 0.0006) / 2; math.Abs(results[0].Response.Cost-want) > 1e-12 {
+		t.Errorf("Cost = %v, want %v", results[0].Response.Cost, want)
+	}
+	var apiErr *APIError
+	if !errors.As(results[2].Err, &apiErr) || apiErr.Message != "bad request" || results[2].CustomID != "c" {
+		t.Errorf("failed result = %+v", results[2])
+	}
+}
+
+func TestCreateBatch_Validation(t *testing.T) {
+	p := Provider{Name: OpenAI, APIKey: "test-key"}
+	tests := map[string][]BatchRequest{
+		"empty":        nil,
+		"missing id":   {{Request: Request{User: "a"}}},
+		"duplicate id": {{CustomID: "x", Request: Request{User: "a"}}, {CustomID: "x", Request: Request{User: "b"}}},
+		"bad request":  {{CustomID: "x"}},
+	}
+	for name, reqs := range tests {
+		var ve *ValidationError
+		if _, err := CreateBatch(context.Background(), p, reqs); !errors.As(err, &ve) {
+			t.Errorf("%s: error = %v, want ValidationError", name, err)
+		}
+	}
+
+	_, err := CreateBatch(context.Background(), Provider{Name: Grok, APIKey: "k"}, []BatchRequest{{CustomID: "x", Request: Request{User: "a"}}})
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Errorf("unsupported provider error = %v", err)
+	}
+}
+
+func TestBatch_Anthropic(t *testing.T) {
+	var created string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("%s missing API key", r.URL.Path)
+		}
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/messages/batches":
+			data, _ := io.ReadAll(r.Body)
+			created = string(data)
+			w.Write([]byte(`{"id":"msgbatch_1","processing_status":"in_progress","created_at":"2025-01-01T00:00:00Z",
+				"request_counts":{"processing":3}}`))
+		case r.URL.Path == "/v1/messages/batches/msgbatch_1":
+			w.Write([]byte(`{"id":"msgbatch_1","processing_status":"ended",
+				"request_counts":{"succeeded":1,"errored":1,"expired":1}}`))
+		case r.URL.Path == "/v1/messages/batches/msgbatch_1/results":
+			w.Write([]byte(`{"custom_id":"a","result":{"type":"succeeded","message":{"model":"claude-sonnet-4-5","content":[{"type":"text","text":"Score: 8"}],"usage":{"input_tokens":1000,"output_tokens":1000}}}}
+{"custom_id":"b","result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens too large"}}}}
+{"custom_id":"c","result":{"type":"expired"}}
+`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Anthropic, APIKey: "test-key", BaseURL: server.URL}
+	ctx := context.Background()
+	b, err := CreateBatch(ctx, p, []BatchRequest{
+		{CustomID: "a", Request: Request{System: "Score 1-10.", User: "Script A"}},
+		{CustomID: "b", Request: Request{User: "Script B"}},
+		{CustomID: "c", Request: Request{User: "Script C"}},
+	}, WithMaxTokens(256))
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if b.ID != "msgbatch_1" || b.Done || b.Total != 3 || b.CreatedAt.IsZero() {
+		t.Errorf("batch = %+v", b)
+	}
+	if !strings.Contains(created, `{"custom_id":"a","params":{"model":"claude-sonnet-4-5","max_tokens":256,"system":"Score 1-10."`) {
+		t.Errorf("create body = %s", created)
+	}
+
+	b, err = GetBatch(ctx, p, "msgbatch_1")
+	if err != nil || !b.Done || b.Succeeded != 1 || b.Failed != 2 {
+		t.Fatalf("GetBatch() = %+v, %v", b, err)
+	}
+
+	results, err := ListBatchResults(ctx, p, "msgbatch_1")
+	if err != nil || len(results) != 3 {
+		t.Fatalf("ListBatchResults() = %+v, %v", results, err)
+	}
+	if results[0].Response.Text != "Score: 8" || math.Abs(results[0].Response.Cost-(0.003+0.015)/2) > 1e-12 {
+		t.Errorf("succeeded result = %+v", results[0])
+	}
+	var apiErr *APIError
+	if !errors.As(results[1].Err, &apiErr) || apiErr.Type != "invalid_request_error" || apiErr.Message != "max_tokens too large" {
+		t.Errorf("errored result = %+v", results[1].Err)
+	}
+	if !errors.As(results[2].Err, &apiErr) || apiErr.Type != "expired" {
+		t.Errorf("expired result = %+v", results[2].Err)
+	}
+}
+
+func TestBatch_Google(t *testing.T) {
+	var uploaded, created string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "test-key" {
+			t.Errorf("key = %q", r.URL.Query().Get("key"))
+		}
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/upload/v1beta/files":
+			f, _, _ := r.FormFile("file")
+			data, _ := io.ReadAll(f)
+			uploaded = string(data)
+			w.Write([]byte(`{"file":{"name":"files/in1","displayName":"batch.jsonl"}}`))
+		case r.Method == "POST" && r.URL.Path == "/v1beta/models/gemini-2.5-flash:batchGenerateContent":
+			data, _ := io.ReadAll(r.Body)
+			created = string(data)
+			w.Write([]byte(`{"name":"batches/b1","metadata":{"state":"BATCH_STATE_PENDING","createTime":"2025-06-01T10:00:00Z"}}`))
+		case r.URL.Path == "/v1beta/batches/b1":
+			w.Write([]byte(`{"name":"batches/b1","metadata":{"state":"BATCH_STATE_SUCCEEDED",
+				"batchStats":{"requestCount":"2","successfulRequestCount":"1","failedRequestCount":"1"},
+				"output":{"responsesFile":"files/out1"}},"done":true}`))
+		case r.URL.Path == "/download/v1beta/files/out1:download":
+			if r.URL.Query().Get("alt") != "media" {
+				t.Errorf("alt = %q", r.URL.Query().Get("alt"))
+			}
+			w.Write([]byte(`{"key":"a","response":{"candidates":[{"content":{"parts":[{"text":"yes"}]}}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":2}}}
+{"key":"b","error":{"code":400,"message":"bad request","status":"INVALID_ARGUMENT"}}
+`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	ctx := context.Background()
+	b, err := CreateBatch(ctx, p, []BatchRequest{
+		{CustomID: "a", Request: Request{User: "Is the sky blue?"}},
+		{CustomID: "b", Request: Request{User: "Bad"}},
+	}, WithTemperature(0))
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if b.ID != "batches/b1" || b.Done || b.CreatedAt.IsZero() {
+		t.Errorf("batch = %+v", b)
+	}
+	lines := strings.Split(strings.TrimSpace(uploaded), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `{"key":"a","request":{"contents":[{"role":"user","parts":[{"text":"Is the sky blue?"}]}]`) ||
+		!strings.Contains(lines[0], `"temperature":0`) {
+		t.Errorf("input file = %s", uploaded)
+	}
+	if !strings.Contains(created, `"file_name":"files/in1"`) {
+		t.Errorf("create body = %s", created)
+	}
+
+	b, err = GetBatch(ctx, p, "b1")
+	if err != nil || !b.Done || b.Total != 2 || b.Succeeded != 1 || b.Failed != 1 {
+		t.Fatalf("GetBatch() = %+v, %v", b, err)
+	}
+
+	results, err := ListBatchResults(ctx, p, b.ID)
+	if err != nil {
+		t.Fatalf("ListBatchResults() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Response.Text != "yes" || results[0].Response.Tokens.Input != 10 {
+		t.Fatalf("results = %+v", results)
+	}
+	var apiErr *APIError
+	if !errors.As(results[1].Err, &apiErr) || apiErr.Type != "INVALID_ARGUMENT" || results[1].CustomID != "b" {
+		t.Errorf("failed result = %+v", results[1])
+	}
+}
@@ -0,0 +1,145 @@
+// Package chains provides ready-made LLM steps built on llmkit.Prompt:
+// long-text summarization, schema-driven extraction, translation and classification.
+// Steps share one signature so they can be run standalone or composed with Then.
+package chains
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aktagon/llmkit"
+)
+
+// Step transforms input text with one or more LLM calls.
+type Step func(ctx context.Context, input string) (llmkit.Response, error)
+
+// Then runs steps in order, feeding each step's text to the next.
+// The returned response has the last step's text and the summed token usage and cost.
+func Then(steps ...Step) Step {
+	return func(ctx context.Context, input string) (llmkit.Response, error) {
+		var resp llmkit.Response
+		var total llmkit.Usage
+		var cost float64
+		for i, step := range steps {
+			r, err := step(ctx, input)
+			if err != nil {
+				return llmkit.Response{}, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			total = total.Add(r.Tokens)
+			cost += r.Cost
+			resp, input = r, r.Text
+		}
+		resp.Tokens, resp.Cost = total, cost
+		return resp, nil
+	}
+}
+
+const (
+	summarizeSystem = "Summarize the text. Keep key facts, names, numbers and conclusions."
+	combineSystem   = "The following are summaries of consecutive parts of one document. " +
+		"Combine them into a single coherent summary."
+)
+
+// SummarizeLong summarizes text of any length. Text longer than chunkSize characters
+// (default 100,000 when 0) is split with llmkit.ChunkText, each chunk is summarized,
+// and the partial summaries are combined.
+func SummarizeLong(p llmkit.Provider, chunkSize int, opts ...llmkit.Option) Step {
+	return func(ctx context.Context, input string) (llmkit.Response, error) {
+		chunks := llmkit.ChunkText(input, chunkSize)
+		if len(chunks) == 1 {
+			return llmkit.Prompt(ctx, p, llmkit.Request{System: summarizeSystem, User: input}, opts...)
+		}
+
+		var total llmkit.Usage
+		var cost float64
+		var b strings.Builder
+		for i, chunk := range chunks {
+			r, err := llmkit.Prompt(ctx, p, llmkit.Request{System: summarizeSystem, User: chunk}, opts...)
+			if err != nil {
+				return llmkit.Response{}, fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+			}
+			total = total.Add(r.Tokens)
+			cost += r.Cost
+			fmt.Fprintf(&b, "Part %d:\n%s\n\n", i+1, r.Text)
+		}
+
+		resp, err := llmkit.Prompt(ctx, p, llmkit.Request{System: combineSystem, User: b.String()}, opts...)
+		if err != nil {
+			return llmkit.Response{}, err
+		}
+		resp.Tokens = total.Add(resp.Tokens)
+		resp.Cost += cost
+		return resp, nil
+	}
+}
+
+// ExtractEntities extracts structured data matching a JSON schema.
+// The response text is JSON that conforms to schema.
+func ExtractEntities(p llmkit.Provider, schema string, opts ...llmkit.Option) Step {
+	return func(ctx context.Context, input string) (llmkit.Response, error) {
+		return llmkit.Prompt(ctx, p, llmkit.Request{
+			System: "Extract the information described by the response schema from the text. " +
+				"Use only facts stated in the text.",
+			User:   input,
+			Schema: schema,
+		}, opts...)
+	}
+}
+
+// Translate translates text into the target language, e.g. "French" or "pt-BR".
+func Translate(p llmkit.Provider, language string, opts ...llmkit.Option) Step {
+	return func(ctx context.Context, input string) (llmkit.Response, error) {
+		return llmkit.Prompt(ctx, p, llmkit.Request{
+			System: "Translate the text into " + language + ". " +
+				"Preserve formatting and reply with the translation only.",
+			User: input,
+		}, opts...)
+	}
+}
+
+// Classify assigns exactly one of labels to the text.
+// The response text is the chosen label.
+func Classify(p llmkit.Provider, labels []string, opts ...llmkit.Option) Step {
+	return func(ctx context.Context, input string) (llmkit.Response, error) {
+		if len(labels) == 0 {
+			return llmkit.Response{}, &llmkit.ValidationError{Field: "labels", Message: "required"}
+		}
+
+		schema, err := json.Marshal(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"label": map[string]any{"type": "string", "enum": labels},
+			},
+			"required":             []string{"label"},
+			"additionalProperties": false,
+		})
+		if err != nil {
+			return llmkit.Response{}, err
+		}
+
+		resp, err := llmkit.Prompt(ctx, p, llmkit.Request{
+			System: "Classify the text into exactly one of these labels: " + strings.Join(labels, ", ") + ".",
+			User:   input,
+			Schema: string(schema),
+		}, opts...)
+		if err != nil {
+			return llmkit.Response{}, err
+		}
+
+		var out struct {
+			Label string `json:"label"`
+		}
+		if err := json.Unmarshal([]byte(resp.Text), &out); err != nil {
+			return llmkit.Response{}, fmt.Errorf("classify: %w", err)
+		}
+		for _, l := range labels {
+			if strings.EqualFold(l, out.Label) {
+				resp.Text = l
+				return resp, nil
+			}
+		}
+		return llmkit.Response{}, fmt.Errorf("classify: unknown label %q", out.Label)
+	}
+}
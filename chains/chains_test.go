@@ -0,0 +1,99 @@
+package chains
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aktagon/llmkit"
+)
+
+// fakeOpenAI answers chat completions with reply(system, user).
+func fakeOpenAI(t *testing.T, reply func(system, user string) string) llmkit.Provider {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var system, user string
+		for _, m := range req.Messages {
+			if m.Role == "system" {
+				system = m.Content[0].Text
+			} else {
+				user = m.Content[len(m.Content)-1].Text
+			}
+		}
+		content, _ := json.Marshal(reply(system, user))
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%s}}],"usage":{"prompt_tokens":2,"completion_tokens":1}}`, content)
+	}))
+	t.Cleanup(server.Close)
+	return llmkit.Provider{Name: llmkit.OpenAI, APIKey: "test-key", BaseURL: server.URL}
+}
+
+func TestSummarizeLong(t *testing.T) {
+	var calls atomic.Int32
+	p := fakeOpenAI(t, func(system, user string) string {
+		calls.Add(1)
+		if strings.HasPrefix(system, "The following are summaries") {
+			return "combined"
+		}
+		return "summary"
+	})
+
+	text := strings.Repeat("word ", 30) + "\n\n" + strings.Repeat("more ", 30)
+	resp, err := SummarizeLong(p, 160)(context.Background(), text)
+	if err != nil {
+		t.Fatalf("SummarizeLong() error = %v", err)
+	}
+	if resp.Text != "combined" || calls.Load() != 3 {
+		t.Errorf("Text = %q after %d calls, want combined after 3", resp.Text, calls.Load())
+	}
+	if resp.Tokens.Input != 6 {
+		t.Errorf("Tokens.Input = %d, want 6", resp.Tokens.Input)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	p := fakeOpenAI(t, func(system, user string) string {
+		return `{"label":"NEGATIVE"}`
+	})
+
+	resp, err := Classify(p, []string{"positive", "negative"})(context.Background(), "Terrible service")
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+	if resp.Text != "negative" {
+		t.Errorf("Text = %q, want negative", resp.Text)
+	}
+}
+
+func TestThen(t *testing.T) {
+	p := fakeOpenAI(t, func(system, user string) string {
+		if strings.Contains(system, "French") {
+			return "fr(" + user + ")"
+		}
+		return "sum(" + user + ")"
+	})
+
+	resp, err := Then(SummarizeLong(p, 0), Translate(p, "French"))(context.Background(), "text")
+	if err != nil {
+		t.Fatalf("Then() error = %v", err)
+	}
+	if resp.Text != "fr(sum(text))" {
+		t.Errorf("Text = %q, want fr(sum(text))", resp.Text)
+	}
+	if resp.Tokens.Input != 4 || resp.Tokens.Output != 2 {
+		t.Errorf("Tokens = %+v, want summed usage", resp.Tokens)
+	}
+}
@@ -0,0 +1,55 @@
+package llmkit
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Clock is the time source for time-dependent behavior such as hedging delays
+// and retry backoff.
+// Tests can pass a fake with WithClock to avoid real sleeps.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the default Clock backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithClock sets the time source for time-dependent behavior.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithRand sets the random source used for generated request IDs and retry
+// jitter, making them reproducible in tests. The source is guarded internally, so it
+// may be shared by concurrent calls.
+func WithRand(src rand.Source) Option {
+	return func(o *options) {
+		o.rand = &lockedRand{r: rand.New(src)}
+	}
+}
+
+// lockedRand serializes access to a *rand.Rand.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) uint64() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Uint64()
+}
+
+func (l *lockedRand) float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
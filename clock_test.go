@@ -0,0 +1,122 @@
+package llmkit
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock fires After channels only when advance is called.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	added   chan struct{}
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0), added: make(chan struct{}, 16)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	c.added <- struct{}{}
+	return ch
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if !w.at.After(c.now) {
+			w.ch <- c.now
+		} else {
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+}
+
+func TestWithClock_HedgeWaitsForClock(t *testing.T) {
+	clock := newFakeClock()
+	primaryStarted := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var hosts []string
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		hosts = append(hosts, req.URL.Host)
+		mu.Unlock()
+		if req.URL.Host == "primary.test" {
+			close(primaryStarted)
+			select {
+			case <-release:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"choices":[{"message":{"content":"` + req.URL.Host + `"}}]}`)),
+			Header:     make(http.Header),
+		}, nil
+	})
+	defer close(release)
+
+	primary := Provider{Name: OpenAI, APIKey: "k", BaseURL: "http://primary.test"}
+	backup := Provider{Name: OpenAI, APIKey: "k", BaseURL: "http://backup.test"}
+
+	done := make(chan Response)
+	go func() {
+		resp, _ := Prompt(context.Background(), primary, Request{User: "Hi"},
+			WithHTTPClient(&http.Client{Transport: transport}),
+			WithClock(clock), WithHedging(time.Hour, backup))
+		done <- resp
+	}()
+
+	<-primaryStarted
+	<-clock.added
+	select {
+	case <-done:
+		t.Fatal("returned before the hedge delay elapsed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clock.advance(time.Hour)
+	if resp := <-done; resp.Text != "backup.test" {
+		t.Errorf("Text = %q, want backup.test", resp.Text)
+	}
+}
+
+func TestWithRand_DeterministicRequestID(t *testing.T) {
+	id := func() string {
+		_, id := ensureRequestID(context.Background(), applyOptions(WithRand(rand.NewPCG(1, 2))))
+		return id
+	}
+	a, b := id(), id()
+	if a != b || !strings.HasPrefix(a, "req_") {
+		t.Errorf("request IDs %q and %q, want equal with req_ prefix", a, b)
+	}
+}
@@ -0,0 +1,82 @@
+// Command llmkit-gen generates Go structs and a schema constant from a JSON
+// schema or from sample structured responses, for use with go:generate:
+//
+//	//go:generate go run github.com/aktagon/llmkit/cmd/llmkit-gen -type Invoice -schema invoice.schema.json
+//	//go:generate go run github.com/aktagon/llmkit/cmd/llmkit-gen -type Review -samples testdata/reviews/*.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aktagon/llmkit"
+)
+
+func main() {
+	var typeName, schemaPath, samples, pkg, output, provider string
+
+	flag.StringVar(&typeName, "type", "", "Name of the generated Go type (required)")
+	flag.StringVar(&schemaPath, "schema", "", "JSON schema file")
+	flag.StringVar(&samples, "samples", "", "Glob of sample JSON responses to infer the schema from, instead of -schema")
+	flag.StringVar(&pkg, "package", os.Getenv("GOPACKAGE"), "Package name (default $GOPACKAGE, set by go generate)")
+	flag.StringVar(&output, "o", "", "Output file (default <type>_schema.go)")
+	flag.StringVar(&provider, "provider", "", "Also check that this provider's structured output accepts the schema")
+	flag.Parse()
+
+	if typeName == "" || (schemaPath == "") == (samples == "") {
+		fmt.Fprintln(os.Stderr, "Usage: llmkit-gen -type <Name> (-schema <file.json> | -samples <glob>) [-package <pkg>] [-o <file.go>] [-provider <name>]")
+		os.Exit(1)
+	}
+	if pkg == "" {
+		pkg = "main"
+	}
+	if output == "" {
+		output = strings.ToLower(typeName) + "_schema.go"
+	}
+
+	var schema string
+	if schemaPath != "" {
+		data, err := os.ReadFile(schemaPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		schema = string(data)
+	} else {
+		files, err := filepath.Glob(samples)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(files) == 0 {
+			log.Fatalf("no samples match %s", samples)
+		}
+		var docs []string
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				log.Fatal(err)
+			}
+			docs = append(docs, string(data))
+		}
+		if schema, err = llmkit.InferSchema(docs...); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if provider != "" {
+		if _, _, err := llmkit.TranslateSchema(provider, schema); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	src, err := llmkit.GenerateGo(pkg, typeName, schema)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
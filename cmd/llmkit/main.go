@@ -13,12 +13,14 @@ import (
 func main() {
 	var provider string
 	var model string
+	var baseURL string
 	var systemPrompt string
 	var userPrompt string
 	var jsonSchema string
 
-	flag.StringVar(&provider, "provider", "", "LLM provider (anthropic, openai, google, grok)")
+	flag.StringVar(&provider, "provider", "", "LLM provider (anthropic, openai, google, grok, ollama, openrouter, openai-compatible)")
 	flag.StringVar(&model, "model", "", "Model name (optional, uses provider default)")
+	flag.StringVar(&baseURL, "base-url", "", "API base URL (required for openai-compatible)")
 	flag.StringVar(&systemPrompt, "system", "", "System prompt")
 	flag.StringVar(&userPrompt, "user", "", "User prompt")
 	flag.StringVar(&jsonSchema, "schema", "", "JSON schema for structured output (optional)")
@@ -34,8 +36,17 @@ func main() {
 		}
 	}
 
+	var detected llmkit.Provider
 	if provider == "" {
-		fmt.Fprintln(os.Stderr, "Usage: llmkit -provider <anthropic|openai|google|grok> -system <system_prompt> -user <user_prompt> [-schema <json_schema>]")
+		// Fall back to whichever provider has a key in the environment
+		if p, err := llmkit.ProviderFromEnv(); err == nil {
+			detected = p
+			provider = p.Name
+		}
+	}
+
+	if provider == "" {
+		fmt.Fprintln(os.Stderr, "Usage: llmkit -provider <anthropic|openai|google|grok|ollama|openrouter> -system <system_prompt> -user <user_prompt> [-schema <json_schema>]")
 		fmt.Fprintln(os.Stderr, "   or: llmkit -provider <provider> <system_prompt> <user_prompt> [json_schema]")
 		os.Exit(1)
 	}
@@ -44,12 +55,16 @@ func main() {
 		log.Fatal("Both system prompt and user prompt are required")
 	}
 
-	apiKey := getAPIKey(provider)
+	apiKey := detected.APIKey
+	if apiKey == "" {
+		apiKey = getAPIKey(provider)
+	}
 
 	p := llmkit.Provider{
-		Name:   provider,
-		APIKey: apiKey,
-		Model:  model,
+		Name:    provider,
+		APIKey:  apiKey,
+		Model:   model,
+		BaseURL: baseURL,
 	}
 
 	req := llmkit.Request{
@@ -77,6 +92,12 @@ func getAPIKey(provider string) string {
 		envVar = "GOOGLE_API_KEY"
 	case llmkit.Grok:
 		envVar = "GROK_API_KEY"
+	case llmkit.OpenRouter:
+		envVar = "OPENROUTER_API_KEY"
+	case llmkit.Ollama:
+		return os.Getenv("OLLAMA_API_KEY") // optional
+	case llmkit.OpenAICompatible:
+		return os.Getenv("OPENAI_API_KEY") // optional
 	default:
 		log.Fatalf("Unsupported provider: %s", provider)
 	}
@@ -0,0 +1,186 @@
+package llmkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CodeBlock is a fenced code block found by ExtractCodeBlocks.
+type CodeBlock struct {
+	Language   string // e.g. "go", "python"; guessed when the fence has none, "" if unknown
+	Path       string // file name from the fence, as in ```go main.go or ```go:main.go
+	Code       string // contents without the fences
+	Start, End int    // byte offsets of the block, fences included, in the text
+}
+
+// languageAliases maps common fence labels to one name per language.
+var languageAliases = map[string]string{
+	"golang": "go", "py": "python", "python3": "python", "js": "javascript", "jsx": "javascript",
+	"ts": "typescript", "tsx": "typescript", "sh": "bash", "shell": "bash", "zsh": "bash",
+	"console": "bash", "yml": "yaml", "rb": "ruby", "rs": "rust", "c++": "cpp", "cs": "csharp",
+	"kt": "kotlin", "md": "markdown", "htm": "html", "postgres": "sql", "postgresql": "sql",
+}
+
+// languageExtensions maps languages to file extensions, both ways.
+var languageExtensions = map[string]string{
+	"go": ".go", "python": ".py", "javascript": ".js", "typescript": ".ts", "bash": ".sh",
+	"json": ".json", "yaml": ".yaml", "sql": ".sql", "html": ".html", "css": ".css",
+	"ruby": ".rb", "rust": ".rs", "java": ".java", "c": ".c", "cpp": ".cpp", "csharp": ".cs",
+	"kotlin": ".kt", "markdown": ".md", "toml": ".toml", "xml": ".xml", "dockerfile": ".dockerfile",
+}
+
+// ExtractCodeBlocks returns the fenced (``` or ~~~) code blocks in markdown
+// text in order. Languages are normalized ("golang" and "py" become "go" and
+// "python") and, for unlabeled blocks, guessed from the file name or code.
+func ExtractCodeBlocks(text string) []CodeBlock {
+	var blocks []CodeBlock
+	for _, m := range fence.FindAllStringSubmatchIndex(text, -1) {
+		info, code := fenceParts(text, m)
+		lang, path := parseFenceInfo(info)
+		if lang == "" {
+			lang = guessLanguage(path, code)
+		}
+		blocks = append(blocks, CodeBlock{Language: lang, Path: path, Code: code, Start: m[0], End: m[1]})
+	}
+	return blocks
+}
+
+// parseFenceInfo splits a fence info string such as "go main.go",
+// "go:main.go" or "main.go" into a language and a file name.
+func parseFenceInfo(info string) (lang, path string) {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	lang = fields[0]
+	if len(fields) > 1 {
+		path = fields[1]
+	} else if l, p, ok := strings.Cut(lang, ":"); ok {
+		lang, path = l, p
+	} else if strings.Contains(lang, ".") {
+		lang, path = "", lang
+	}
+	lang = strings.ToLower(lang)
+	if alias, ok := languageAliases[lang]; ok {
+		lang = alias
+	}
+	return lang, path
+}
+
+// guessLanguage tags an unlabeled block from its file extension or from
+// telltale first lines; "" when unsure.
+func guessLanguage(path, code string) string {
+	if ext := strings.ToLower(filepath.Ext(path)); ext != "" {
+		for lang, e := range languageExtensions {
+			if e == ext {
+				return lang
+			}
+		}
+	}
+
+	trimmed := strings.TrimSpace(code)
+	first, _, _ := strings.Cut(trimmed, "\n")
+	switch {
+	case strings.HasPrefix(first, "package "):
+		return "go"
+	case strings.HasPrefix(first, "#!") && strings.Contains(first, "python"):
+		return "python"
+	case strings.HasPrefix(first, "#!"):
+		return "bash"
+	case strings.HasPrefix(first, "<?xml"):
+		return "xml"
+	case strings.HasPrefix(strings.ToLower(first), "<!doctype html"), strings.HasPrefix(first, "<html"):
+		return "html"
+	case (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)):
+		return "json"
+	case strings.HasPrefix(first, "def ") || strings.HasPrefix(first, "from ") && strings.Contains(first, " import "):
+		return "python"
+	case strings.HasPrefix(first, "fn ") || strings.HasPrefix(first, "use "):
+		return "rust"
+	}
+	upper := strings.ToUpper(first)
+	for _, kw := range []string{"SELECT ", "INSERT ", "UPDATE ", "DELETE ", "CREATE ", "WITH "} {
+		if strings.HasPrefix(upper, kw) {
+			return "sql"
+		}
+	}
+	return ""
+}
+
+// WriteCodeBlocks writes each block to dir and returns the paths written.
+// Blocks are written to their Path, or to snippet-N with an extension for
+// their language when they have none. Paths that are absolute, leave dir
+// (such as "../x") or pass through a symbolic link are rejected before
+// anything is written.
+func WriteCodeBlocks(dir string, blocks []CodeBlock) ([]string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]string, len(blocks))
+	for i, b := range blocks {
+		name := b.Path
+		if name == "" {
+			name = fmt.Sprintf("snippet-%d%s", i+1, codeExtension(b.Language))
+		}
+		if !filepath.IsLocal(name) {
+			return nil, &ValidationError{Field: "path", Message: fmt.Sprintf("%q is outside the output directory", name)}
+		}
+		targets[i] = filepath.Join(root, name)
+		if err := checkNoSymlinks(root, targets[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var written []string
+	for i, b := range blocks {
+		if err := os.MkdirAll(filepath.Dir(targets[i]), 0o755); err != nil {
+			return written, err
+		}
+		code := b.Code
+		if code != "" && !strings.HasSuffix(code, "\n") {
+			code += "\n"
+		}
+		if err := os.WriteFile(targets[i], []byte(code), 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, targets[i])
+	}
+	return written, nil
+}
+
+// codeExtension returns the file extension for a language, ".txt" if unknown.
+func codeExtension(lang string) string {
+	if ext, ok := languageExtensions[lang]; ok {
+		return ext
+	}
+	return ".txt"
+}
+
+// checkNoSymlinks rejects a target when it, or a directory between root and
+// it, is a symbolic link that could redirect the write outside root.
+func checkNoSymlinks(root, target string) error {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return err
+	}
+	path := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		path = filepath.Join(path, part)
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return &ValidationError{Field: "path", Message: fmt.Sprintf("%q is a symbolic link", rel)}
+		}
+	}
+	return nil
+}
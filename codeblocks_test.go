@@ -0,0 +1,72 @@
+package llmkit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractCodeBlocks(t *testing.T) {
+	text := "Create two files.\n\n```golang cmd/main.go\npackage main\n```\n\n```py:tools/run.py\nprint(1)\n```\n" +
+		"Then run:\n```\n#!/bin/sh\nmake\n```\n~~~\n{\"debug\": true}\n~~~\n```\njust text\n```"
+	blocks := ExtractCodeBlocks(text)
+	want := []CodeBlock{
+		{Language: "go", Path: "cmd/main.go", Code: "package main"},
+		{Language: "python", Path: "tools/run.py", Code: "print(1)"},
+		{Language: "bash", Code: "#!/bin/sh\nmake"},
+		{Language: "json", Code: `{"debug": true}`},
+		{Code: "just text"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("ExtractCodeBlocks() = %d blocks, want %d: %#v", len(blocks), len(want), blocks)
+	}
+	for i, b := range blocks {
+		w := want[i]
+		if b.Language != w.Language || b.Path != w.Path || b.Code != w.Code {
+			t.Errorf("block %d = %+v, want %+v", i, b, w)
+		}
+		if text[b.Start:b.Start+3] != text[b.End-3:b.End] {
+			t.Errorf("block %d offsets [%d:%d] do not span its fences: %q", i, b.Start, b.End, text[b.Start:b.End])
+		}
+	}
+}
+
+func TestWriteCodeBlocks(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := WriteCodeBlocks(dir, []CodeBlock{
+		{Language: "go", Path: "cmd/main.go", Code: "package main"},
+		{Language: "sql", Code: "SELECT 1;\n"},
+	})
+	if err != nil {
+		t.Fatalf("WriteCodeBlocks() error = %v", err)
+	}
+	wantPaths := []string{filepath.Join(dir, "cmd", "main.go"), filepath.Join(dir, "snippet-2.sql")}
+	for i, p := range wantPaths {
+		if i >= len(paths) || paths[i] != p {
+			t.Fatalf("paths = %v, want %v", paths, wantPaths)
+		}
+	}
+	if data, _ := os.ReadFile(wantPaths[0]); string(data) != "package main\n" {
+		t.Errorf("main.go = %q", data)
+	}
+
+	// Unsafe paths fail before any file is written
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"../escape.go", "/etc/passwd", "link/x.go"} {
+		_, err := WriteCodeBlocks(dir, []CodeBlock{{Path: "ok.go", Code: "x"}, {Path: path, Code: "x"}})
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != "path" {
+			t.Errorf("WriteCodeBlocks(%q) error = %v, want path ValidationError", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ok.go")); !os.IsNotExist(err) {
+		t.Errorf("ok.go written despite a rejected block")
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("wrote through symlink: %v", entries)
+	}
+}
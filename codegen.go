@@ -0,0 +1,594 @@
+package llmkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// GenerateGo returns gofmt'ed Go source for package pkg declaring typeName,
+// its nested object types, and a typeName+"Schema" constant holding the
+// schema, so the struct used to decode answers and the schema sent in
+// Request.Schema come from one source. Fields carry the json, description and
+// enum tags SchemaFor reads. Properties keep the schema's order; optional ones
+// get omitempty, nullable ones become pointers. Local $refs become named types.
+// The cmd/llmkit-gen command wraps it for go:generate.
+func GenerateGo(pkg, typeName, schema string) ([]byte, error) {
+	root, err := decodeOrdered(schema)
+	if err != nil {
+		return nil, &ValidationError{Field: "schema", Message: "must be a JSON object: " + err.Error()}
+	}
+	obj, ok := root.(*jsonObject)
+	if !ok || obj.get("type") != "object" {
+		return nil, &ValidationError{Field: "schema", Message: `root must have "type": "object"`}
+	}
+	if !isGoIdent(typeName) {
+		return nil, &ValidationError{Field: "type", Message: fmt.Sprintf("%q is not a Go identifier", typeName)}
+	}
+
+	g := &goGen{root: obj, named: map[string]bool{}, building: map[string]bool{}, refs: map[string]string{}}
+	if _, err := g.structType(typeName, obj, "$"); err != nil {
+		return nil, &ValidationError{Field: "schema", Message: err.Error()}
+	}
+
+	compact, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString("// Code generated by llmkit-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if g.usesTime {
+		b.WriteString("import \"time\"\n\n")
+	}
+	fmt.Fprintf(&b, "// %sSchema is the JSON schema for %s, for llmkit.Request.Schema.\n", typeName, typeName)
+	// Backticks can only occur inside JSON strings, where \u0060 means the same
+	fmt.Fprintf(&b, "const %sSchema = `%s`\n", typeName, strings.ReplaceAll(string(compact), "`", `\u0060`))
+	for _, decl := range g.decls {
+		b.WriteString("\n" + decl)
+	}
+	return format.Source(b.Bytes())
+}
+
+// goGen collects the type declarations for one schema.
+type goGen struct {
+	root     *jsonObject
+	decls    []string
+	named    map[string]bool   // declared type names
+	building map[string]bool   // structs being declared, to break recursion
+	refs     map[string]string // $ref to type name
+	usesTime bool
+}
+
+// structType declares a struct for an object schema and returns its name.
+func (g *goGen) structType(name string, obj *jsonObject, path string) (string, error) {
+	name = g.unique(name)
+	g.named[name] = true
+	g.building[name] = true
+	defer delete(g.building, name)
+	i := len(g.decls)
+	g.decls = append(g.decls, "") // reserve the slot so parents precede children
+
+	required := map[string]bool{}
+	if list, ok := obj.get("required").([]any); ok {
+		for _, r := range list {
+			if s, ok := r.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	if desc, ok := obj.get("description").(string); ok && desc != "" {
+		fmt.Fprintf(&b, "// %s: %s\n", name, oneLine(desc))
+	}
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	props, _ := obj.get("properties").(*jsonObject)
+	fields := map[string]bool{}
+	if props != nil {
+		for _, key := range props.keys {
+			prop, ok := props.vals[key].(*jsonObject)
+			if !ok {
+				return "", fmt.Errorf("%s.%s: schema must be an object", path, key)
+			}
+			field := goIdent(key)
+			for fields[field] {
+				field += "_"
+			}
+			fields[field] = true
+
+			typ, err := g.goType(name+field, prop, path+"."+key)
+			if err != nil {
+				return "", err
+			}
+			tag := key
+			if !required[key] {
+				tag += ",omitempty"
+				// omitempty does not omit structs; a pointer tells absent from zero
+				if g.named[typ] || typ == "time.Time" {
+					typ = "*" + typ
+				}
+			}
+			tags := fmt.Sprintf("json:%q", tag)
+			if desc, ok := prop.get("description").(string); ok && desc != "" {
+				tags += fmt.Sprintf(" description:%q", oneLine(desc))
+			}
+			if enum, ok := prop.get("enum").([]any); ok && typ == "string" {
+				var values []string
+				for _, v := range enum {
+					values = append(values, fmt.Sprint(v))
+				}
+				tags += fmt.Sprintf(" enum:%q", strings.Join(values, ","))
+			}
+			fmt.Fprintf(&b, "\t%s %s `%s`\n", field, typ, tags)
+		}
+	}
+	b.WriteString("}\n")
+	g.decls[i] = b.String()
+	return name, nil
+}
+
+// goType returns the Go type for a property schema. name is used for nested object types.
+func (g *goGen) goType(name string, s *jsonObject, path string) (string, error) {
+	if ref, ok := s.get("$ref").(string); ok {
+		return g.refType(ref, path)
+	}
+
+	typ, nullable, err := schemaType(s, path)
+	if err != nil {
+		return "", err
+	}
+	if typ == "" {
+		// anyOf [X, {"type": "null"}] is a nullable X
+		if alts, ok := s.get("anyOf").([]any); ok && len(alts) == 2 {
+			for i, alt := range alts {
+				if a, ok := alt.(*jsonObject); ok && a.get("type") == "null" {
+					if other, ok := alts[1-i].(*jsonObject); ok {
+						t, err := g.goType(name, other, path)
+						return pointerTo(t), err
+					}
+				}
+			}
+		}
+		return "any", nil
+	}
+
+	var out string
+	switch typ {
+	case "string":
+		out = "string"
+		if s.get("format") == "date-time" {
+			out, g.usesTime = "time.Time", true
+		}
+	case "integer":
+		out = "int"
+	case "number":
+		out = "float64"
+	case "boolean":
+		out = "bool"
+	case "null":
+		out = "any" // only ever null in the samples
+	case "array":
+		items, ok := s.get("items").(*jsonObject)
+		if !ok {
+			out = "[]any"
+			break
+		}
+		elem, err := g.goType(singular(name), items, path+"[]")
+		if err != nil {
+			return "", err
+		}
+		out = "[]" + elem
+	case "object":
+		if _, ok := s.get("properties").(*jsonObject); ok {
+			if out, err = g.structType(name, s, path); err != nil {
+				return "", err
+			}
+			break
+		}
+		out = "map[string]any"
+		if values, ok := s.get("additionalProperties").(*jsonObject); ok {
+			elem, err := g.goType(name+"Value", values, path+"{}")
+			if err != nil {
+				return "", err
+			}
+			out = "map[string]" + elem
+		}
+	default:
+		return "", fmt.Errorf("%s: unsupported type %q", path, typ)
+	}
+	if nullable {
+		out = pointerTo(out)
+	}
+	return out, nil
+}
+
+// refType declares the type for a local $ref once and returns its name.
+func (g *goGen) refType(ref, path string) (string, error) {
+	key, ok := strings.CutPrefix(ref, "#/$defs/")
+	defs := "$defs"
+	if !ok {
+		key, ok = strings.CutPrefix(ref, "#/definitions/")
+		defs = "definitions"
+	}
+	if !ok {
+		return "", fmt.Errorf("%s: only local $refs are supported, got %q", path, ref)
+	}
+	if name, ok := g.refs[ref]; ok {
+		if g.building[name] {
+			return "*" + name, nil // recursive: a struct cannot contain itself
+		}
+		return name, nil
+	}
+	all, _ := g.root.get(defs).(*jsonObject)
+	def, ok := all.get(key).(*jsonObject)
+	if !ok {
+		return "", fmt.Errorf("%s: $ref %q not found", path, ref)
+	}
+	if def.get("type") != "object" {
+		return g.goType(goIdent(key), def, ref)
+	}
+	name := g.unique(goIdent(key))
+	g.refs[ref] = name
+	return g.structType(name, def, ref)
+}
+
+// unique returns name, suffixed with "_" until no declared type has it.
+func (g *goGen) unique(name string) string {
+	for g.named[name] {
+		name += "_"
+	}
+	return name
+}
+
+// schemaType returns the type keyword, allowing ["T", "null"].
+func schemaType(s *jsonObject, path string) (typ string, nullable bool, err error) {
+	switch t := s.get("type").(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return t, false, nil
+	case []any:
+		for _, v := range t {
+			switch {
+			case v == "null":
+				nullable = true
+			case typ == "":
+				typ, _ = v.(string)
+			default:
+				return "", false, fmt.Errorf("%s: union types are not supported", path)
+			}
+		}
+		return typ, nullable, nil
+	}
+	return "", false, fmt.Errorf("%s: invalid type", path)
+}
+
+// InferSchema derives a JSON schema from sample structured responses, e.g.
+// answers collected while prototyping a prompt. Every sample must be a JSON
+// object; properties present and non-null in all samples are required, and
+// numbers are integers only if every sample has an integral value. Pass the
+// result to GenerateGo.
+func InferSchema(samples ...string) (string, error) {
+	if len(samples) == 0 {
+		return "", &ValidationError{Field: "samples", Message: "required"}
+	}
+	var values []any
+	for i, s := range samples {
+		v, err := decodeOrdered(s)
+		if err != nil {
+			return "", &ValidationError{Field: "samples", Message: fmt.Sprintf("sample %d: %v", i+1, err)}
+		}
+		if _, ok := v.(*jsonObject); !ok {
+			return "", &ValidationError{Field: "samples", Message: fmt.Sprintf("sample %d: must be a JSON object", i+1)}
+		}
+		values = append(values, v)
+	}
+	schema, err := inferSchema(values, "$")
+	if err != nil {
+		return "", &ValidationError{Field: "samples", Message: err.Error()}
+	}
+	data, err := json.Marshal(schema)
+	return string(data), err
+}
+
+// inferSchema returns a schema matching every value in values.
+func inferSchema(values []any, path string) (*jsonObject, error) {
+	kind, nullable := "", false
+	for _, v := range values {
+		k := jsonKind(v)
+		switch {
+		case k == "null":
+			nullable = true
+		case kind == "" || kind == k:
+			kind = k
+		case kind == "integer" && k == "number", kind == "number" && k == "integer":
+			kind = "number"
+		default:
+			return nil, fmt.Errorf("%s: conflicting types %s and %s", path, kind, k)
+		}
+	}
+
+	out := &jsonObject{}
+	switch {
+	case kind == "" && nullable:
+		out.set("type", "null")
+		return out, nil
+	case nullable:
+		out.set("type", []any{kind, "null"})
+	default:
+		out.set("type", kind)
+	}
+
+	switch kind {
+	case "array":
+		var items []any
+		for _, v := range values {
+			if a, ok := v.([]any); ok {
+				items = append(items, a...)
+			}
+		}
+		if len(items) > 0 {
+			schema, err := inferSchema(items, path+"[]")
+			if err != nil {
+				return nil, err
+			}
+			out.set("items", schema)
+		}
+	case "object":
+		props := &jsonObject{}
+		var keys []string
+		seen := map[string][]any{}
+		present := map[string]int{}
+		objects := 0
+		for _, v := range values {
+			obj, ok := v.(*jsonObject)
+			if !ok {
+				continue
+			}
+			objects++
+			for _, key := range obj.keys {
+				if _, ok := seen[key]; !ok {
+					keys = append(keys, key)
+				}
+				seen[key] = append(seen[key], obj.vals[key])
+				if obj.vals[key] != nil {
+					present[key]++
+				}
+			}
+		}
+		required := []any{}
+		for _, key := range keys {
+			vals := seen[key]
+			optional := present[key] < objects
+			if optional {
+				// absent or null somewhere: omit nulls, the field is optional instead
+				vals = nonNull(vals)
+			}
+			if len(vals) == 0 {
+				vals = []any{nil}
+			}
+			schema, err := inferSchema(vals, path+"."+key)
+			if err != nil {
+				return nil, err
+			}
+			props.set(key, schema)
+			if !optional {
+				required = append(required, key)
+			}
+		}
+		out.set("properties", props)
+		out.set("required", required)
+		out.set("additionalProperties", false)
+	}
+	return out, nil
+}
+
+// jsonKind names the schema type of a decoded value.
+func jsonKind(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func nonNull(values []any) []any {
+	var out []any
+	for _, v := range values {
+		if v != nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// jsonObject is a decoded JSON object that keeps its key order.
+type jsonObject struct {
+	keys []string
+	vals map[string]any
+}
+
+func (o *jsonObject) get(key string) any {
+	if o == nil {
+		return nil
+	}
+	return o.vals[key]
+}
+
+func (o *jsonObject) set(key string, v any) {
+	if o.vals == nil {
+		o.vals = map[string]any{}
+	}
+	if _, ok := o.vals[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.vals[key] = v
+}
+
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.vals[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// decodeOrdered decodes JSON with objects as *jsonObject, arrays as []any and
+// numbers as json.Number.
+func decodeOrdered(s string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := &jsonObject{vals: map[string]any{}}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key.(string), v)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
+
+// goInitialisms are kept upper case in field names, as in golint.
+var goInitialisms = map[string]bool{
+	"API": true, "CSS": true, "DNS": true, "HTML": true, "HTTP": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goIdent converts a JSON property name such as "first_name" or "userId" to
+// an exported Go identifier ("FirstName", "UserID").
+func goIdent(key string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if up := strings.ToUpper(w); goInitialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		r := []rune(w)
+		b.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+	id := b.String()
+	if id == "" || unicode.IsDigit([]rune(id)[0]) {
+		id = "F" + id
+	}
+	return id
+}
+
+func isGoIdent(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// singular derives an element type name from a slice field's type name.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ss"):
+		return name + "Item"
+	case strings.HasSuffix(name, "s") && len(name) > 1:
+		return strings.TrimSuffix(name, "s")
+	}
+	return name + "Item"
+}
+
+func pointerTo(t string) string {
+	if strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") || t == "any" || strings.HasPrefix(t, "*") {
+		return t
+	}
+	return "*" + t
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
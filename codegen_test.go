@@ -0,0 +1,126 @@
+package llmkit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	schema := `{"type":"object","description":"A support ticket.","properties":{
+		"ticket_id":{"type":"string","description":"Ticket number"},
+		"priority":{"type":"string","enum":["low","high"]},
+		"opened_at":{"type":"string","format":"date-time"},
+		"assignee":{"$ref":"#/$defs/person"},
+		"comments":{"type":"array","items":{"type":"object","properties":{"body":{"type":"string"},"votes":{"type":"integer"}},"required":["body"]}},
+		"score":{"type":["number","null"]}
+	},"required":["ticket_id","priority","comments"],
+	"$defs":{"person":{"type":"object","properties":{"name":{"type":"string"},"manager":{"$ref":"#/$defs/person"}},"required":["name"]}}}`
+
+	src, err := GenerateGo("support", "Ticket", schema)
+	if err != nil {
+		t.Fatalf("GenerateGo() error = %v", err)
+	}
+
+	want := "// Code generated by llmkit-gen. DO NOT EDIT.\n\npackage support\n\nimport \"time\"\n\n" +
+		"// TicketSchema is the JSON schema for Ticket, for llmkit.Request.Schema.\n" +
+		"const TicketSchema = `" + `{"type":"object","description":"A support ticket.","properties":{"ticket_id":{"type":"string","description":"Ticket number"},"priority":{"type":"string","enum":["low","high"]},"opened_at":{"type":"string","format":"date-time"},"assignee":{"$ref":"#/$defs/person"},"comments":{"type":"array","items":{"type":"object","properties":{"body":{"type":"string"},"votes":{"type":"integer"}},"required":["body"]}},"score":{"type":["number","null"]}},"required":["ticket_id","priority","comments"],"$defs":{"person":{"type":"object","properties":{"name":{"type":"string"},"manager":{"$ref":"#/$defs/person"}},"required":["name"]}}}` + "`\n" + `
+// Ticket: A support ticket.
+type Ticket struct {
+	TicketID string          ` + "`" + `json:"ticket_id" description:"Ticket number"` + "`" + `
+	Priority string          ` + "`" + `json:"priority" enum:"low,high"` + "`" + `
+	OpenedAt *time.Time      ` + "`" + `json:"opened_at,omitempty"` + "`" + `
+	Assignee *Person         ` + "`" + `json:"assignee,omitempty"` + "`" + `
+	Comments []TicketComment ` + "`" + `json:"comments"` + "`" + `
+	Score    *float64        ` + "`" + `json:"score,omitempty"` + "`" + `
+}
+
+type Person struct {
+	Name    string  ` + "`" + `json:"name"` + "`" + `
+	Manager *Person ` + "`" + `json:"manager,omitempty"` + "`" + `
+}
+
+type TicketComment struct {
+	Body  string ` + "`" + `json:"body"` + "`" + `
+	Votes int    ` + "`" + `json:"votes,omitempty"` + "`" + `
+}
+`
+	if string(src) != want {
+		t.Errorf("GenerateGo() =\n%s\nwant\n%s", src, want)
+	}
+}
+
+func TestGenerateGo_Errors(t *testing.T) {
+	tests := []struct {
+		name, typeName, schema, field string
+	}{
+		{"not json", "T", `{`, "schema"},
+		{"not an object", "T", `{"type":"string"}`, "schema"},
+		{"bad type name", "my-type", `{"type":"object"}`, "type"},
+		{"remote ref", "T", `{"type":"object","properties":{"a":{"$ref":"https://example.com/a.json"}}}`, "schema"},
+		{"union", "T", `{"type":"object","properties":{"a":{"type":["string","integer"]}}}`, "schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateGo("p", tt.typeName, tt.schema)
+			var ve *ValidationError
+			if !errors.As(err, &ve) || ve.Field != tt.field {
+				t.Errorf("error = %v, want %s ValidationError", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	schema, err := InferSchema(
+		`{"title":"Great","stars":5,"pros":["fast"],"author":{"name":"A"},"reply":null}`,
+		`{"title":"Meh","stars":2.5,"pros":[],"author":{"name":"B","verified":true},"reply":"Thanks"}`,
+	)
+	if err != nil {
+		t.Fatalf("InferSchema() error = %v", err)
+	}
+	want := `{"type":"object","properties":{` +
+		`"title":{"type":"string"},"stars":{"type":"number"},"pros":{"type":"array","items":{"type":"string"}},` +
+		`"author":{"type":"object","properties":{"name":{"type":"string"},"verified":{"type":"boolean"}},"required":["name"],"additionalProperties":false},` +
+		`"reply":{"type":"string"}},"required":["title","stars","pros","author"],"additionalProperties":false}`
+	if schema != want {
+		t.Errorf("InferSchema() =\n%s\nwant\n%s", schema, want)
+	}
+
+	src, err := GenerateGo("reviews", "Review", schema)
+	if err != nil {
+		t.Fatalf("GenerateGo() error = %v", err)
+	}
+	if !strings.Contains(string(src), "Author ReviewAuthor `json:\"author\"`") {
+		t.Errorf("GenerateGo() =\n%s", src)
+	}
+}
+
+func TestInferSchema_Errors(t *testing.T) {
+	for _, samples := range [][]string{
+		nil,
+		{`[1, 2]`},
+		{`{"a": 1}`, `{"a": "one"}`},
+	} {
+		_, err := InferSchema(samples...)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != "samples" {
+			t.Errorf("InferSchema(%q) error = %v, want samples ValidationError", samples, err)
+		}
+	}
+}
+
+func TestGoIdent(t *testing.T) {
+	tests := map[string]string{
+		"first_name": "FirstName",
+		"userId":     "UserID",
+		"api-url":    "APIURL",
+		"2fa":        "F2fa",
+		"HTMLBody":   "HTMLBody",
+	}
+	for in, want := range tests {
+		if got := goIdent(in); got != want {
+			t.Errorf("goIdent(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
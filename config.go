@@ -3,8 +3,45 @@ package llmkit
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
+// providerEnvKeys lists the API key variables checked for each provider, in order.
+var providerEnvKeys = map[string][]string{
+	Anthropic:  {"ANTHROPIC_API_KEY"},
+	OpenAI:     {"OPENAI_API_KEY"},
+	Google:     {"GEMINI_API_KEY", "GOOGLE_API_KEY"},
+	Grok:       {"XAI_API_KEY", "GROK_API_KEY"},
+	OpenRouter: {"OPENROUTER_API_KEY"},
+}
+
+// defaultEnvPreference is the order ProviderFromEnv tries when none is given.
+var defaultEnvPreference = []string{Anthropic, OpenAI, Google, Grok, OpenRouter}
+
+// ProviderFromEnv returns a Provider for the first provider in preference whose
+// API key is set in the environment, using that provider's default model.
+// With no preference, Anthropic, OpenAI, Google, Grok and OpenRouter are tried in that order.
+func ProviderFromEnv(preference ...string) (Provider, error) {
+	if len(preference) == 0 {
+		preference = defaultEnvPreference
+	}
+
+	var tried []string
+	for _, name := range preference {
+		keys, ok := providerEnvKeys[name]
+		if !ok {
+			return Provider{}, &ValidationError{Field: "provider", Message: "no API key variable known for " + name}
+		}
+		for _, key := range keys {
+			if v := os.Getenv(key); v != "" {
+				return Provider{Name: name, APIKey: v}, nil
+			}
+		}
+		tried = append(tried, keys...)
+	}
+	return Provider{}, &ValidationError{Field: "api_key", Message: "none of " + strings.Join(tried, ", ") + " is set"}
+}
+
 // defaults holds environment-configured defaults for generation parameters.
 var defaults struct {
 	temperature      *float64
@@ -28,6 +65,22 @@ func init() {
 	defaults.presencePenalty = parseFloat("LLMKIT_PRESENCE_PENALTY")
 	defaults.thinkingBudget = parseInt("LLMKIT_THINKING_BUDGET")
 	defaults.reasoningEffort = parseString("LLMKIT_REASONING_EFFORT")
+	loadModelEnv()
+}
+
+// loadModelEnv applies LLMKIT_<PROVIDER>_MODEL variables with SetDefaultModel.
+func loadModelEnv() {
+	for name := range defaultModels {
+		if model := parseString(modelEnvKey(name)); model != "" {
+			SetDefaultModel(name, model)
+		}
+	}
+}
+
+// modelEnvKey returns the variable that overrides a provider's default model,
+// e.g. LLMKIT_ANTHROPIC_MODEL.
+func modelEnvKey(provider string) string {
+	return "LLMKIT_" + strings.ToUpper(provider) + "_MODEL"
 }
 
 func parseFloat(key string) *float64 {
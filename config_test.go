@@ -1,6 +1,7 @@
 package llmkit
 
 import (
+	"errors"
 	"os"
 	"testing"
 )
@@ -146,3 +147,65 @@ func TestParseString(t *testing.T) {
 func ptr(v float64) *float64 { return &v }
 func intPtr(v int) *int      { return &v }
 func int64Ptr(v int64) *int64 { return &v }
+
+func TestProviderFromEnv(t *testing.T) {
+	for _, keys := range providerEnvKeys {
+		for _, key := range keys {
+			t.Setenv(key, "")
+		}
+	}
+
+	if _, err := ProviderFromEnv(); err == nil {
+		t.Fatal("expected error with no keys set")
+	}
+
+	t.Setenv("GOOGLE_API_KEY", "g-key")
+	t.Setenv("XAI_API_KEY", "x-key")
+
+	p, err := ProviderFromEnv()
+	if err != nil {
+		t.Fatalf("ProviderFromEnv() error = %v", err)
+	}
+	if p.Name != Google || p.APIKey != "g-key" {
+		t.Errorf("got %s/%s, want google/g-key", p.Name, p.APIKey)
+	}
+	if p.model() != defaultModels[Google] {
+		t.Errorf("model = %q, want default", p.model())
+	}
+
+	p, err = ProviderFromEnv(Grok, Google)
+	if err != nil || p.Name != Grok || p.APIKey != "x-key" {
+		t.Errorf("preference: got %+v, %v", p, err)
+	}
+
+	var ve *ValidationError
+	if _, err := ProviderFromEnv("unknown"); !errors.As(err, &ve) {
+		t.Errorf("unknown provider: got %v, want ValidationError", err)
+	}
+}
+
+func TestDefaultModel(t *testing.T) {
+	t.Cleanup(func() {
+		SetDefaultModel(Anthropic, "")
+		SetDefaultModel(OpenAI, "")
+	})
+
+	t.Setenv("LLMKIT_ANTHROPIC_MODEL", "claude-next")
+	loadModelEnv()
+	if got := (Provider{Name: Anthropic}).model(); got != "claude-next" {
+		t.Errorf("model from env = %q, want claude-next", got)
+	}
+
+	SetDefaultModel(OpenAI, "gpt-next")
+	if got := DefaultModel(OpenAI); got != "gpt-next" {
+		t.Errorf("DefaultModel() = %q, want gpt-next", got)
+	}
+	if got := (Provider{Name: OpenAI, Model: "gpt-4o-mini"}).model(); got != "gpt-4o-mini" {
+		t.Errorf("explicit model = %q, want gpt-4o-mini", got)
+	}
+
+	SetDefaultModel(Anthropic, "")
+	if got := DefaultModel(Anthropic); got != defaultModels[Anthropic] {
+		t.Errorf("DefaultModel() after reset = %q, want built-in", got)
+	}
+}
@@ -0,0 +1,227 @@
+package llmkit
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// defaultConcurrency is the number of rows RunDataset processes in parallel.
+const defaultConcurrency = 4
+
+// RowFunc produces a response for one dataset row.
+// CSV rows map header names to string values; JSONL rows are decoded objects.
+type RowFunc func(ctx context.Context, row map[string]any) (Response, error)
+
+// DatasetResult is one line of RunDataset output.
+type DatasetResult struct {
+	Index        int            `json:"index"`
+	Input        map[string]any `json:"input"`
+	Output       string         `json:"output,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	InputTokens  int            `json:"input_tokens"`
+	OutputTokens int            `json:"output_tokens"`
+	RequestID    string         `json:"request_id,omitempty"`
+}
+
+// DatasetStats summarizes a RunDataset run.
+type DatasetStats struct {
+	Total     int // rows in the input
+	Skipped   int // rows already completed in a previous run
+	Succeeded int
+	Failed    int
+	Tokens    Usage
+}
+
+// RunDataset calls fn for every row of a CSV or JSONL file and appends one JSON
+// result per row to output, including failures and token usage. Rows are processed
+// concurrently (see WithConcurrency). Rows that already succeeded in output are
+// skipped, so an interrupted run can be resumed by calling RunDataset again.
+func RunDataset(ctx context.Context, input, output string, fn RowFunc, opts ...Option) (DatasetStats, error) {
+	rows, err := readDataset(input)
+	if err != nil {
+		return DatasetStats{}, err
+	}
+
+	done, partial, err := completedRows(output)
+	if err != nil {
+		return DatasetStats{}, err
+	}
+
+	out, err := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return DatasetStats{}, err
+	}
+	defer out.Close()
+
+	// Terminate a line cut short by an interrupt so new results start on their own line
+	if partial {
+		if _, err := out.Write([]byte("\n")); err != nil {
+			return DatasetStats{}, err
+		}
+	}
+
+	o := applyOptions(opts...)
+	stats := DatasetStats{Total: len(rows)}
+
+	var mu sync.Mutex
+	var writeErr error
+	record := func(r DatasetResult) {
+		line, err := json.Marshal(r)
+		mu.Lock()
+		defer mu.Unlock()
+		if err == nil {
+			_, err = out.Write(append(line, '\n'))
+		}
+		if err != nil && writeErr == nil {
+			writeErr = err
+		}
+		if r.Error != "" {
+			stats.Failed++
+		} else {
+			stats.Succeeded++
+		}
+		stats.Tokens.Input += r.InputTokens
+		stats.Tokens.Output += r.OutputTokens
+	}
+
+	sem := make(chan struct{}, max(o.concurrency, 1))
+	var wg sync.WaitGroup
+	for i, row := range rows {
+		if done[i] {
+			stats.Skipped++
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, row map[string]any) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp, err := fn(ctx, row)
+			r := DatasetResult{
+				Index:        i,
+				Input:        row,
+				Output:       resp.Text,
+				InputTokens:  resp.Tokens.Input,
+				OutputTokens: resp.Tokens.Output,
+				RequestID:    resp.RequestID,
+			}
+			if err != nil {
+				r.Error = err.Error()
+			}
+			record(r)
+		}(i, row)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+	return stats, writeErr
+}
+
+// readDataset loads rows from a .csv or .jsonl file.
+func readDataset(path string) ([]map[string]any, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCSVRows(f)
+	case ".jsonl", ".ndjson":
+		return readJSONLRows(f)
+	default:
+		return nil, &ValidationError{Field: "input", Message: "unsupported dataset format: " + filepath.Ext(path)}
+	}
+}
+
+// readCSVRows reads a CSV file with a header row.
+func readCSVRows(r io.Reader) ([]map[string]any, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var rows []map[string]any
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(header))
+		for i, name := range header {
+			row[name] = rec[i]
+		}
+		rows = append(rows, row)
+	}
+}
+
+// readJSONLRows reads one JSON object per line, skipping blank lines.
+func readJSONLRows(r io.Reader) ([]map[string]any, error) {
+	var rows []map[string]any
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var row map[string]any
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// completedRows returns the indexes of rows that succeeded in an existing output file,
+// and whether the file ends without a newline. A missing file means nothing has been
+// completed yet.
+func completedRows(path string) (map[int]bool, bool, error) {
+	done := map[int]bool{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return done, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		var r DatasetResult
+		// A line cut short by an interrupt is ignored and its row rerun
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			continue
+		}
+		done[r.Index] = r.Error == ""
+	}
+	partial := len(data) > 0 && data[len(data)-1] != '\n'
+	return done, partial, nil
+}
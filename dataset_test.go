@@ -0,0 +1,117 @@
+package llmkit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func readResults(t *testing.T, path string) []DatasetResult {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var results []DatasetResult
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r DatasetResult
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("invalid result line %q: %v", scanner.Text(), err)
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+	return results
+}
+
+func TestRunDataset_CSV(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "reviews.csv")
+	output := filepath.Join(dir, "out.jsonl")
+	os.WriteFile(input, []byte("id,text\n1,great\n2,bad\n3,fine\n"), 0o644)
+
+	fn := func(ctx context.Context, row map[string]any) (Response, error) {
+		if row["text"] == "bad" {
+			return Response{}, errors.New("boom")
+		}
+		return Response{Text: strings.ToUpper(row["text"].(string)), Tokens: Usage{Input: 3, Output: 1}}, nil
+	}
+
+	stats, err := RunDataset(context.Background(), input, output, fn, WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("RunDataset() error = %v", err)
+	}
+	if stats.Total != 3 || stats.Succeeded != 2 || stats.Failed != 1 || stats.Tokens.Input != 6 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	results := readResults(t, output)
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if results[0].Output != "GREAT" || results[0].Input["id"] != "1" || results[0].InputTokens != 3 {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].Error != "boom" {
+		t.Errorf("results[1].Error = %q, want boom", results[1].Error)
+	}
+}
+
+func TestRunDataset_Resume(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "rows.jsonl")
+	output := filepath.Join(dir, "out.jsonl")
+	os.WriteFile(input, []byte(`{"n":1}`+"\n"+`{"n":2}`+"\n\n"+`{"n":3}`+"\n"), 0o644)
+
+	// Row 0 succeeded, row 1 failed, and the write for row 2 was cut short
+	os.WriteFile(output, []byte(`{"index":0,"input":{"n":1},"output":"ok"}`+"\n"+
+		`{"index":1,"input":{"n":2},"error":"timeout"}`+"\n"+`{"index":2,"inp`), 0o644)
+
+	var calls atomic.Int32
+	fn := func(ctx context.Context, row map[string]any) (Response, error) {
+		calls.Add(1)
+		if row["n"] == float64(1) {
+			t.Error("completed row was rerun")
+		}
+		return Response{Text: "ok"}, nil
+	}
+
+	stats, err := RunDataset(context.Background(), input, output, fn)
+	if err != nil {
+		t.Fatalf("RunDataset() error = %v", err)
+	}
+	if calls.Load() != 2 || stats.Skipped != 1 || stats.Succeeded != 2 {
+		t.Errorf("calls = %d, stats = %+v", calls.Load(), stats)
+	}
+
+	// A second run finds every row completed
+	stats, err = RunDataset(context.Background(), input, output, fn)
+	if err != nil {
+		t.Fatalf("RunDataset() error = %v", err)
+	}
+	if calls.Load() != 2 || stats.Skipped != 3 {
+		t.Errorf("calls = %d, stats = %+v after second run", calls.Load(), stats)
+	}
+}
+
+func TestRunDataset_UnsupportedFormat(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "rows.txt")
+	os.WriteFile(input, []byte("x"), 0o644)
+
+	_, err := RunDataset(context.Background(), input, input+".out", nil)
+
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "input" {
+		t.Errorf("expected input ValidationError, got %v", err)
+	}
+}
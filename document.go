@@ -0,0 +1,157 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultChunkSize is the number of characters per chunk (~25k tokens).
+const defaultChunkSize = 100_000
+
+// inlineFileLimit is the largest file AskDocument sends inline instead of uploading.
+const inlineFileLimit = 4 << 20
+
+const (
+	askChunkSystem = "Answer the question using only the document excerpt below. " +
+		"If the excerpt does not contain the answer, reply with exactly NONE."
+	askCombineSystem = "You are given partial answers to a question, each based on a different part of one document. " +
+		"Combine them into a single complete answer. Ignore partial answers that are NONE."
+)
+
+// AskDocument answers a question about a local file.
+// Text files are inlined and split into chunks when they exceed the chunk size
+// (see WithChunkSize); per-chunk answers are combined into one. Images and small
+// documents are inlined, larger files are uploaded with UploadFile.
+// Token usage is summed across calls.
+func AskDocument(ctx context.Context, p Provider, path, question string, opts ...Option) (Response, error) {
+	if question == "" {
+		return Response{}, &ValidationError{Field: "question", Message: "required"}
+	}
+	if err := validateProvider(p); err != nil {
+		return Response{}, err
+	}
+
+	mimeType := detectMimeType(path)
+	switch {
+	case isTextMimeType(mimeType):
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Response{}, err
+		}
+		return askText(ctx, p, string(data), question, opts...)
+	case strings.HasPrefix(mimeType, "image/"):
+		img, err := ImageFromFile(path)
+		if err != nil {
+			return Response{}, err
+		}
+		return Prompt(ctx, p, Request{User: question, Images: []Image{img}}, opts...)
+	default:
+		f, err := documentFile(ctx, p, path, opts...)
+		if err != nil {
+			return Response{}, err
+		}
+		return Prompt(ctx, p, Request{User: question, Files: []File{f}}, opts...)
+	}
+}
+
+// askText answers a question over text, map-reducing across chunks when needed.
+func askText(ctx context.Context, p Provider, text, question string, opts ...Option) (Response, error) {
+	o := applyOptions(opts...)
+	chunks := ChunkText(text, o.chunkSize)
+
+	if len(chunks) == 1 {
+		return Prompt(ctx, p, Request{User: documentPrompt(chunks[0], question)}, opts...)
+	}
+
+	var total Usage
+	var answers []string
+	for i, chunk := range chunks {
+		resp, err := Prompt(ctx, p, Request{System: askChunkSystem, User: documentPrompt(chunk, question)}, opts...)
+		if err != nil {
+			return Response{}, fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		total = total.Add(resp.Tokens)
+		if strings.TrimSpace(resp.Text) != "NONE" {
+			answers = append(answers, resp.Text)
+		}
+	}
+
+	if len(answers) == 0 {
+		answers = []string{"NONE"}
+	}
+
+	var b strings.Builder
+	for i, a := range answers {
+		fmt.Fprintf(&b, "Partial answer %d:\n%s\n\n", i+1, a)
+	}
+	fmt.Fprintf(&b, "Question: %s", question)
+
+	resp, err := Prompt(ctx, p, Request{System: askCombineSystem, User: b.String()}, opts...)
+	if err != nil {
+		return Response{}, err
+	}
+	resp.Tokens = resp.Tokens.Add(total)
+	return resp, nil
+}
+
+// documentFile inlines small files and uploads the rest.
+// Grok does not accept inline documents, so files are always uploaded there.
+func documentFile(ctx context.Context, p Provider, path string, opts ...Option) (File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return File{}, err
+	}
+	if info.Size() <= inlineFileLimit && p.Name != Grok {
+		return InlineFile(path)
+	}
+	return UploadFile(ctx, p, path, opts...)
+}
+
+// documentPrompt wraps document text and a question into one user message.
+func documentPrompt(doc, question string) string {
+	return "<document>\n" + doc + "\n</document>\n\nQuestion: " + question
+}
+
+// ChunkText splits text into chunks of at most size characters (default 100,000 when
+// size <= 0), preferring to cut at paragraph and line boundaries.
+func ChunkText(text string, size int) []string {
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	var chunks []string
+	for len(text) > size {
+		cut := strings.LastIndex(text[:size], "\n\n")
+		if cut < size/2 {
+			cut = strings.LastIndex(text[:size], "\n")
+		}
+		if cut < size/2 {
+			cut = size
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				_, cut = utf8.DecodeRuneInString(text)
+			}
+		}
+		chunks = append(chunks, text[:cut])
+		text = strings.TrimLeft(text[cut:], "\n")
+	}
+	if text != "" || len(chunks) == 0 {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// isTextMimeType reports whether the MIME type can be inlined as text.
+func isTextMimeType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "text/") || mimeType == "application/json"
+}
+
+// dataURI encodes data as a base64 data URI.
+func dataURI(mimeType string, data []byte) string {
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
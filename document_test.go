@@ -0,0 +1,82 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestChunkText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		size int
+		want []string
+	}{
+		{"fits", "hello", 10, []string{"hello"}},
+		{"empty", "", 10, []string{""}},
+		{"paragraph boundary", "aaaa\n\nbbbb", 8, []string{"aaaa", "bbbb"}},
+		{"line boundary", "aaaa\nbbbb", 8, []string{"aaaa", "bbbb"}},
+		{"hard cut", "aaaaaaaaaa", 4, []string{"aaaa", "aaaa", "aa"}},
+		{"rune boundary", "ééé", 3, []string{"é", "é", "é"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChunkText(tt.text, tt.size)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("ChunkText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAskDocument_MissingQuestion(t *testing.T) {
+	p := Provider{Name: OpenAI, APIKey: "test-key"}
+
+	_, err := AskDocument(context.Background(), p, "doc.txt", "")
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "question" {
+		t.Fatalf("expected question ValidationError, got %v", err)
+	}
+}
+
+func TestAskDocument_Chunked(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"choices":[{"message":{"content":"42"}}],"usage":{"prompt_tokens":10,"completion_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	doc := strings.Repeat("line of text\n", 30)
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+
+	resp, err := AskDocument(context.Background(), p, path, "What is the answer?", WithChunkSize(200))
+	if err != nil {
+		t.Fatalf("AskDocument() error = %v", err)
+	}
+
+	chunks := len(ChunkText(doc, 200))
+	if got := int(calls.Load()); got != chunks+1 {
+		t.Errorf("calls = %d, want %d (chunks + combine)", got, chunks+1)
+	}
+	if resp.Text != "42" {
+		t.Errorf("Text = %q, want 42", resp.Text)
+	}
+	if resp.Tokens.Input != 10*(chunks+1) {
+		t.Errorf("Tokens.Input = %d, want %d", resp.Tokens.Input, 10*(chunks+1))
+	}
+}
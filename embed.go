@@ -0,0 +1,74 @@
+package llmkit
+
+import (
+	"context"
+	"fmt"
+)
+
+// Default embedding models per provider
+var defaultEmbeddingModels = map[string]string{
+	OpenAI: "text-embedding-3-small",
+	Google: "gemini-embedding-001",
+}
+
+// embedBatchLimits is the maximum number of inputs per embedding request.
+var embedBatchLimits = map[string]int{
+	OpenAI:           2048,
+	Google:           100,
+	OpenAICompatible: 2048,
+}
+
+// EmbedBatch returns one embedding vector per input text, in input order.
+// Inputs are split into as many requests as the provider's batch limit requires.
+// OpenAI, Google and OpenAI-compatible servers only; the model is set with
+// WithEmbeddingModel (Provider.Model for OpenAI-compatible servers) and the
+// vector size with WithDimensions.
+func EmbedBatch(ctx context.Context, p Provider, texts []string, opts ...Option) ([][]float32, error) {
+	if err := validateProvider(p); err != nil {
+		return nil, err
+	}
+	if len(texts) == 0 {
+		return nil, &ValidationError{Field: "texts", Message: "required"}
+	}
+
+	limit, ok := embedBatchLimits[p.Name]
+	if !ok {
+		return nil, &ValidationError{Field: "provider", Message: "embeddings not supported by " + p.Name}
+	}
+
+	o := applyOptions(opts...)
+	if o.dimensions != nil && *o.dimensions <= 0 {
+		return nil, &ValidationError{Field: "dimensions", Message: "must be positive"}
+	}
+	if o.taskType != "" && p.Name != Google {
+		return nil, &ValidationError{Field: "task_type", Message: "not supported by " + p.Name}
+	}
+	model := o.embeddingModel
+	if model == "" {
+		model = defaultEmbeddingModels[p.Name]
+	}
+	if model == "" {
+		model = p.Model
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += limit {
+		end := min(start+limit, len(texts))
+		batch := texts[start:end]
+
+		got, err := withPoolKey(ctx, p, o, func(p Provider) ([][]float32, error) {
+			if p.Name == Google {
+				return embedGoogle(ctx, p, model, batch, o)
+			}
+			return embedOpenAI(ctx, p, model, batch, o)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(got) != len(batch) {
+			return nil, fmt.Errorf("%s: got %d embeddings for %d inputs", p.Name, len(got), len(batch))
+		}
+		vectors = append(vectors, got...)
+	}
+	return vectors, nil
+}
@@ -0,0 +1,150 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEmbedBatch_OpenAI(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req openaiEmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "text-embedding-3-large" {
+			t.Errorf("model = %q", req.Model)
+		}
+		// Reply out of order to check results are placed by index
+		var data []string
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, fmt.Sprintf(`{"index":%d,"embedding":[%d]}`, i, len(req.Input[i])))
+		}
+		fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(data, ","))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	vectors, err := EmbedBatch(context.Background(), p, []string{"a", "bb", "ccc"},
+		WithEmbeddingModel("text-embedding-3-large"))
+	if err != nil {
+		t.Fatalf("EmbedBatch() error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+	for i, v := range vectors {
+		if len(v) != 1 || v[0] != float32(i+1) {
+			t.Errorf("vectors[%d] = %v, want [%d]", i, v, i+1)
+		}
+	}
+}
+
+func TestEmbedBatch_GoogleSplitsBatches(t *testing.T) {
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/models/gemini-embedding-001:batchEmbedContents") {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		var req googleEmbedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sizes = append(sizes, len(req.Requests))
+		var embeddings []string
+		for _, r := range req.Requests {
+			embeddings = append(embeddings, `{"values":[`+r.Content.Parts[0].Text+`]}`)
+		}
+		fmt.Fprintf(w, `{"embeddings":[%s]}`, strings.Join(embeddings, ","))
+	}))
+	defer server.Close()
+
+	texts := make([]string, 250)
+	for i := range texts {
+		texts[i] = fmt.Sprint(i)
+	}
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	vectors, err := EmbedBatch(context.Background(), p, texts)
+	if err != nil {
+		t.Fatalf("EmbedBatch() error = %v", err)
+	}
+	if fmt.Sprint(sizes) != "[100 100 50]" {
+		t.Errorf("batch sizes = %v, want [100 100 50]", sizes)
+	}
+	if len(vectors) != 250 || vectors[249][0] != 249 {
+		t.Errorf("got %d vectors, last = %v", len(vectors), vectors[len(vectors)-1])
+	}
+}
+
+func TestEmbedBatch_Unsupported(t *testing.T) {
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	_, err := EmbedBatch(context.Background(), p, []string{"a"})
+
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "provider" {
+		t.Errorf("expected provider ValidationError, got %v", err)
+	}
+}
+
+func TestEmbedBatch_Dimensions(t *testing.T) {
+	var gotOpenAI, gotGoogle string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]any
+		json.NewDecoder(r.Body).Decode(&raw)
+		if strings.Contains(r.URL.Path, "batchEmbedContents") {
+			gotGoogle = fmt.Sprint(raw["requests"].([]any)[0].(map[string]any)["outputDimensionality"])
+			w.Write([]byte(`{"embeddings":[{"values":[1]}]}`))
+			return
+		}
+		gotOpenAI = fmt.Sprint(raw["dimensions"])
+		w.Write([]byte(`{"data":[{"index":0,"embedding":[1]}]}`))
+	}))
+	defer server.Close()
+
+	for _, name := range []string{OpenAI, Google} {
+		p := Provider{Name: name, APIKey: "test-key", BaseURL: server.URL}
+		if _, err := EmbedBatch(context.Background(), p, []string{"a"}, WithDimensions(256)); err != nil {
+			t.Fatalf("%s: EmbedBatch() error = %v", name, err)
+		}
+	}
+	if gotOpenAI != "256" || gotGoogle != "256" {
+		t.Errorf("dimensions openai = %s, google = %s, want 256", gotOpenAI, gotGoogle)
+	}
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	_, err := EmbedBatch(context.Background(), p, []string{"a"}, WithDimensions(0))
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "dimensions" {
+		t.Errorf("expected dimensions ValidationError, got %v", err)
+	}
+}
+
+func TestEmbedBatch_TaskType(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req googleEmbedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		got = req.Requests[0].TaskType
+		w.Write([]byte(`{"embeddings":[{"values":[1]}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	if _, err := EmbedBatch(context.Background(), p, []string{"a"}, WithTaskType("RETRIEVAL_QUERY")); err != nil {
+		t.Fatalf("EmbedBatch() error = %v", err)
+	}
+	if got != "RETRIEVAL_QUERY" {
+		t.Errorf("taskType = %q, want RETRIEVAL_QUERY", got)
+	}
+
+	p = Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	_, err := EmbedBatch(context.Background(), p, []string{"a"}, WithTaskType("RETRIEVAL_QUERY"))
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "task_type" {
+		t.Errorf("expected task_type ValidationError, got %v", err)
+	}
+}
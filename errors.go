@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,7 @@ type APIError struct {
 	Message    string
 	Retryable  bool
 	RetryAfter time.Duration
+	RequestID  string // client-side correlation ID
 }
 
 func (e *APIError) Error() string {
@@ -32,6 +34,20 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation: %s - %s", e.Field, e.Message)
 }
 
+// ContentBlockedError indicates the provider withheld output due to a safety or policy filter.
+type ContentBlockedError struct {
+	Provider   string
+	Reason     string   // provider reason, e.g. "SAFETY", "RECITATION"
+	Categories []string // flagged categories, e.g. "HARM_CATEGORY_DANGEROUS_CONTENT"
+}
+
+func (e *ContentBlockedError) Error() string {
+	if len(e.Categories) == 0 {
+		return fmt.Sprintf("%s: content blocked (%s)", e.Provider, e.Reason)
+	}
+	return fmt.Sprintf("%s: content blocked (%s: %s)", e.Provider, e.Reason, strings.Join(e.Categories, ", "))
+}
+
 // parseError parses provider-specific error responses into APIError.
 func parseError(provider string, statusCode int, body []byte, headers http.Header) *APIError {
 	apiErr := &APIError{
@@ -54,7 +70,7 @@ func parseError(provider string, statusCode int, body []byte, headers http.Heade
 			apiErr.Message = resp.Error.Message
 		}
 
-	case OpenAI, Grok:
+	case OpenAI, Grok, Ollama, OpenRouter, OpenAICompatible:
 		var resp struct {
 			Error struct {
 				Message string `json:"message"`
@@ -164,3 +164,17 @@ func TestExtractRetryAfter(t *testing.T) {
 		})
 	}
 }
+
+func TestContentBlockedError_Error(t *testing.T) {
+	err := &ContentBlockedError{Provider: Google, Reason: "SAFETY", Categories: []string{"HARM_CATEGORY_HARASSMENT"}}
+	want := "google: content blocked (SAFETY: HARM_CATEGORY_HARASSMENT)"
+	if got := err.Error(); got != want {
+		t.Errorf("ContentBlockedError.Error() = %q, want %q", got, want)
+	}
+
+	err = &ContentBlockedError{Provider: Google, Reason: "NO_CANDIDATES"}
+	want = "google: content blocked (NO_CANDIDATES)"
+	if got := err.Error(); got != want {
+		t.Errorf("ContentBlockedError.Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package llmkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// googleFileTTL is how long the Gemini Files API keeps an upload.
+const googleFileTTL = 48 * time.Hour
+
+// FileCache remembers uploaded files by content hash so UploadFile can reuse
+// an existing File reference instead of uploading identical content again.
+// Entries are kept per provider endpoint and API key. Pass it with WithFileCache.
+type FileCache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]fileCacheEntry
+}
+
+type fileCacheEntry struct {
+	File     File      `json:"file"`
+	Uploaded time.Time `json:"uploaded"`
+}
+
+// NewFileCache returns a cache persisted as JSON at path, loading any entries
+// already saved there. An empty path keeps the cache in memory only.
+func NewFileCache(path string) (*FileCache, error) {
+	c := &FileCache{path: path, entries: map[string]fileCacheEntry{}}
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// WithFileCache makes UploadFile (and AskDocument) reuse files recorded in c.
+func WithFileCache(c *FileCache) Option {
+	return func(o *options) {
+		o.fileCache = c
+	}
+}
+
+// fileCacheKey identifies content uploaded to one provider account.
+func fileCacheKey(p Provider, data []byte) string {
+	account := sha256.Sum256([]byte(p.APIKey))
+	content := sha256.Sum256(data)
+	return p.Name + "|" + p.BaseURL + "|" + hex.EncodeToString(account[:8]) + "|" + hex.EncodeToString(content[:])
+}
+
+// lookup returns the cached file for data, skipping Google uploads that have expired.
+func (c *FileCache) lookup(p Provider, data []byte, now time.Time) (File, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[fileCacheKey(p, data)]
+	if !ok || (p.Name == Google && now.Sub(e.Uploaded) >= googleFileTTL) {
+		return File{}, false
+	}
+	return e.File, true
+}
+
+// store records an upload and persists the cache when it has a path.
+func (c *FileCache) store(p Provider, data []byte, f File, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f.Data = nil
+	c.entries[fileCacheKey(p, data)] = fileCacheEntry{File: f, Uploaded: now}
+	if c.path == "" {
+		return nil
+	}
+	out, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, out, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
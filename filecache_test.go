@@ -0,0 +1,106 @@
+package llmkit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCache_ReusesUploads(t *testing.T) {
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		fmt.Fprintf(w, `{"id":"file-%d","filename":"report.txt"}`, uploads)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "report.txt")
+	copyDoc := filepath.Join(dir, "copy.txt")
+	os.WriteFile(doc, []byte("quarterly numbers"), 0o644)
+	os.WriteFile(copyDoc, []byte("quarterly numbers"), 0o644)
+
+	cachePath := filepath.Join(dir, "files.json")
+	cache, err := NewFileCache(cachePath)
+	if err != nil {
+		t.Fatalf("NewFileCache() error = %v", err)
+	}
+
+	p := Provider{Name: OpenAI, APIKey: "key-a", BaseURL: server.URL}
+	ctx := context.Background()
+
+	first, err := UploadFile(ctx, p, doc, WithFileCache(cache))
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	second, err := UploadFile(ctx, p, copyDoc, WithFileCache(cache))
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	if uploads != 1 || second.ID != first.ID {
+		t.Errorf("uploads = %d, IDs %q/%q; want one upload reused", uploads, first.ID, second.ID)
+	}
+
+	// A reloaded cache still knows the file; another account does not
+	reloaded, err := NewFileCache(cachePath)
+	if err != nil {
+		t.Fatalf("NewFileCache() reload error = %v", err)
+	}
+	if f, _ := UploadFile(ctx, p, doc, WithFileCache(reloaded)); f.ID != "file-1" || uploads != 1 {
+		t.Errorf("reloaded cache: ID %q after %d uploads", f.ID, uploads)
+	}
+	other := Provider{Name: OpenAI, APIKey: "key-b", BaseURL: server.URL}
+	if f, _ := UploadFile(ctx, other, doc, WithFileCache(reloaded)); f.ID != "file-2" {
+		t.Errorf("other account: ID %q, want a fresh upload", f.ID)
+	}
+}
+
+func TestFileCache_KeyPool(t *testing.T) {
+	var uploaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploaders = append(uploaders, r.Header.Get("Authorization"))
+		fmt.Fprintf(w, `{"id":"file-%d","filename":"report.txt"}`, len(uploaders))
+	}))
+	defer server.Close()
+
+	doc := filepath.Join(t.TempDir(), "report.txt")
+	os.WriteFile(doc, []byte("quarterly numbers"), 0o644)
+	cache, _ := NewFileCache("")
+
+	// Keys are used in turn; each key gets its own upload, then reuses it
+	p := Provider{Name: OpenAI, Keys: NewKeyPool("key-a", "key-b"), BaseURL: server.URL}
+	var ids []string
+	for range 4 {
+		f, err := UploadFile(context.Background(), p, doc, WithFileCache(cache))
+		if err != nil {
+			t.Fatalf("UploadFile() error = %v", err)
+		}
+		ids = append(ids, f.ID)
+	}
+	if fmt.Sprint(ids) != "[file-1 file-2 file-1 file-2]" {
+		t.Errorf("IDs = %v, want one upload per key", ids)
+	}
+	if fmt.Sprint(uploaders) != "[Bearer key-a Bearer key-b]" {
+		t.Errorf("uploaded with %v", uploaders)
+	}
+}
+
+func TestFileCache_GoogleExpiry(t *testing.T) {
+	cache, _ := NewFileCache("")
+	p := Provider{Name: Google, APIKey: "key"}
+	data := []byte("pdf bytes")
+	uploaded := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cache.store(p, data, File{URI: "https://files/abc"}, uploaded)
+	if _, ok := cache.lookup(p, data, uploaded.Add(47*time.Hour)); !ok {
+		t.Error("expected hit before expiry")
+	}
+	if _, ok := cache.lookup(p, data, uploaded.Add(48*time.Hour)); ok {
+		t.Error("expected miss once the Gemini file has expired")
+	}
+}
@@ -0,0 +1,120 @@
+package llmkit
+
+import (
+	"net/http"
+	"strings"
+)
+
+// LiteLLM returns a Provider for a LiteLLM proxy at baseURL, e.g.
+// "http://localhost:4000". The proxy speaks the OpenAI API; key is a LiteLLM
+// virtual key and model one of the proxy's model names.
+func LiteLLM(baseURL, key, model string) Provider {
+	return Provider{Name: OpenAICompatible, BaseURL: baseURL, APIKey: key, Model: model}
+}
+
+// LiteLLMTags returns the header that tags a request for LiteLLM spend
+// tracking and tag-based routing. Pass it to WithHeaders.
+func LiteLLMTags(tags ...string) map[string]string {
+	return map[string]string{"x-litellm-tags": strings.Join(tags, ",")}
+}
+
+// Helicone endpoints with a dedicated proxy. Other providers go through the
+// generic gateway, which forwards to Helicone-Target-URL.
+var heliconeBaseURLs = map[string]string{
+	OpenAI:     "https://oai.helicone.ai",
+	Anthropic:  "https://anthropic.helicone.ai",
+	OpenRouter: "https://openrouter.helicone.ai/api",
+}
+
+const heliconeGatewayURL = "https://gateway.helicone.ai"
+
+// Helicone returns p routed through the Helicone observability proxy, keeping
+// p's own API key. heliconeKey authenticates with Helicone. Vertex AI is not
+// supported.
+func Helicone(p Provider, heliconeKey string) Provider {
+	headers := map[string]string{"Helicone-Auth": "Bearer " + heliconeKey}
+	if base, ok := heliconeBaseURLs[p.Name]; ok && p.BaseURL == "" {
+		p.BaseURL = base
+	} else {
+		target := p.BaseURL
+		if target == "" {
+			target = defaultBaseURLs[p.Name]
+		}
+		headers["Helicone-Target-URL"] = target
+		p.BaseURL = heliconeGatewayURL
+	}
+	p.Headers = mergeHeaders(p.Headers, headers)
+	return p
+}
+
+// HeliconeMetadata tags requests in Helicone. Pass Headers() to WithHeaders.
+type HeliconeMetadata struct {
+	UserID      string
+	SessionID   string // groups requests into a session trace
+	SessionName string
+	SessionPath string            // position in the trace, e.g. "/plan/search"
+	Properties  map[string]string // custom properties, sent as Helicone-Property-<name>
+	Cache       bool              // serve repeated requests from Helicone's cache
+}
+
+// Headers returns the Helicone request headers for m.
+func (m HeliconeMetadata) Headers() map[string]string {
+	h := map[string]string{}
+	set := func(k, v string) {
+		if v != "" {
+			h[k] = v
+		}
+	}
+	set("Helicone-User-Id", m.UserID)
+	set("Helicone-Session-Id", m.SessionID)
+	set("Helicone-Session-Name", m.SessionName)
+	set("Helicone-Session-Path", m.SessionPath)
+	for name, v := range m.Properties {
+		set("Helicone-Property-"+name, v)
+	}
+	if m.Cache {
+		h["Helicone-Cache-Enabled"] = "true"
+	}
+	return h
+}
+
+// WithHeaders adds headers to every HTTP request of the call, after the
+// provider's own and Provider.Headers, so it can override them.
+func WithHeaders(headers map[string]string) Option {
+	return WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return headerTransport{next: next, headers: headers}
+	})
+}
+
+type headerTransport struct {
+	next    http.RoundTripper
+	headers map[string]string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// withHeaders adds p.Headers to a request's headers.
+func (p Provider) withHeaders(headers map[string]string) map[string]string {
+	for k, v := range p.Headers {
+		headers[k] = v
+	}
+	return headers
+}
+
+// mergeHeaders returns a new map with the headers of a and then b.
+func mergeHeaders(a, b map[string]string) map[string]string {
+	out := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package llmkit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// captureClient records each request and answers with body.
+func captureClient(seen *[]*http.Request, body string) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		*seen = append(*seen, r)
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+}
+
+const openaiHello = `{"choices":[{"message":{"content":"hi"},"finish_reason":"stop"}]}`
+
+func TestHelicone_OpenAI(t *testing.T) {
+	var seen []*http.Request
+	p := Helicone(Provider{Name: OpenAI, APIKey: "sk-openai"}, "sk-helicone")
+
+	meta := HeliconeMetadata{UserID: "u1", SessionID: "s1", Properties: map[string]string{"Tenant": "acme"}, Cache: true}
+	_, err := Prompt(context.Background(), p, Request{User: "hello"},
+		WithHTTPClient(captureClient(&seen, openaiHello)), WithHeaders(meta.Headers()))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+
+	r := seen[0]
+	if got := r.URL.String(); got != "https://oai.helicone.ai/v1/chat/completions" {
+		t.Errorf("URL = %s", got)
+	}
+	want := map[string]string{
+		"Authorization":            "Bearer sk-openai",
+		"Helicone-Auth":            "Bearer sk-helicone",
+		"Helicone-User-Id":         "u1",
+		"Helicone-Session-Id":      "s1",
+		"Helicone-Property-Tenant": "acme",
+		"Helicone-Cache-Enabled":   "true",
+	}
+	for k, v := range want {
+		if got := r.Header.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if r.Header.Get("Helicone-Target-URL") != "" {
+		t.Error("dedicated proxy must not get Helicone-Target-URL")
+	}
+}
+
+func TestHelicone_Gateway(t *testing.T) {
+	tests := []struct {
+		name       string
+		p          Provider
+		url        string
+		target     string
+		authHeader string
+	}{
+		{"anthropic", Provider{Name: Anthropic, APIKey: "k"}, "https://anthropic.helicone.ai/v1/messages", "", "x-api-key"},
+		{"google", Provider{Name: Google, APIKey: "k"}, "https://gateway.helicone.ai/v1beta/models/", "https://generativelanguage.googleapis.com", ""},
+		{"custom base url", Provider{Name: OpenAI, APIKey: "k", BaseURL: "https://eu.api.openai.com"}, "https://gateway.helicone.ai/v1/chat/completions", "https://eu.api.openai.com", "Authorization"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Helicone(tt.p, "sk-helicone")
+			if !strings.HasPrefix(p.BaseURL, "https://") {
+				t.Fatalf("BaseURL = %q", p.BaseURL)
+			}
+			var seen []*http.Request
+			Prompt(context.Background(), p, Request{User: "hello"}, WithHTTPClient(captureClient(&seen, "{}")))
+			if len(seen) == 0 {
+				t.Fatal("no request sent")
+			}
+			r := seen[0]
+			if !strings.HasPrefix(r.URL.String(), tt.url) {
+				t.Errorf("URL = %s, want prefix %s", r.URL, tt.url)
+			}
+			if got := r.Header.Get("Helicone-Target-URL"); got != tt.target {
+				t.Errorf("Helicone-Target-URL = %q, want %q", got, tt.target)
+			}
+			if r.Header.Get("Helicone-Auth") != "Bearer sk-helicone" {
+				t.Errorf("Helicone-Auth = %q", r.Header.Get("Helicone-Auth"))
+			}
+			if tt.authHeader != "" && r.Header.Get(tt.authHeader) == "" {
+				t.Errorf("provider auth header %s missing", tt.authHeader)
+			}
+		})
+	}
+}
+
+func TestLiteLLM(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(openaiHello))
+	}))
+	defer server.Close()
+
+	p := LiteLLM(server.URL, "sk-litellm-virtual", "claude-sonnet")
+	resp, err := Prompt(context.Background(), p, Request{User: "hello"}, WithHeaders(LiteLLMTags("team-a", "checkout")))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.Text != "hi" {
+		t.Errorf("Text = %q", resp.Text)
+	}
+	if got.URL.Path != "/v1/chat/completions" || got.Header.Get("Authorization") != "Bearer sk-litellm-virtual" {
+		t.Errorf("request = %s %v", got.URL.Path, got.Header)
+	}
+	if tags := got.Header.Get("x-litellm-tags"); tags != "team-a,checkout" {
+		t.Errorf("x-litellm-tags = %q", tags)
+	}
+}
+
+func TestProviderHeaders(t *testing.T) {
+	var seen []*http.Request
+	p := Provider{Name: Google, APIKey: "k", Headers: map[string]string{"X-Gateway": "a", "X-Override": "provider"}}
+	_, err := Prompt(context.Background(), p, Request{User: "hello"},
+		WithHTTPClient(captureClient(&seen, `{"candidates":[{"content":{"parts":[{"text":"hi"}]}}]}`)),
+		WithHeaders(map[string]string{"X-Override": "request"}))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if h := seen[0].Header; h.Get("X-Gateway") != "a" || h.Get("X-Override") != "request" {
+		t.Errorf("headers = %v", h)
+	}
+}
@@ -0,0 +1,164 @@
+package llmkit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Glossary is terminology a response must follow. Matching ignores case and
+// only counts whole words.
+type Glossary struct {
+	Terms  map[string]string // source term -> the translation or wording to use for it
+	Banned []string          // terms that must not appear in the response
+}
+
+// GlossaryViolation is one way a response broke a Glossary.
+type GlossaryViolation struct {
+	Term string // the banned term, or the source term that was rendered wrongly
+	Want string // the required wording; "" for banned terms
+}
+
+func (v GlossaryViolation) String() string {
+	if v.Want == "" {
+		return fmt.Sprintf("used banned term %q", v.Term)
+	}
+	return fmt.Sprintf("did not render %q as %q", v.Term, v.Want)
+}
+
+// Check returns the violations in output. A required wording is only checked
+// when its source term occurs in input, the text that was sent to the model.
+func (g Glossary) Check(input, output string) []GlossaryViolation {
+	var out []GlossaryViolation
+	for _, term := range g.Banned {
+		if containsTerm(output, term) {
+			out = append(out, GlossaryViolation{Term: term})
+		}
+	}
+	for _, term := range sortedKeys(g.Terms) {
+		want := g.Terms[term]
+		if containsTerm(input, term) && !containsTerm(output, want) {
+			out = append(out, GlossaryViolation{Term: term, Want: want})
+		}
+	}
+	return out
+}
+
+// instruction describes g for the system prompt.
+func (g Glossary) instruction() string {
+	var b strings.Builder
+	if len(g.Terms) > 0 {
+		b.WriteString("Use this terminology:")
+		for _, term := range sortedKeys(g.Terms) {
+			fmt.Fprintf(&b, "\n- %q: %q", term, g.Terms[term])
+		}
+	}
+	if len(g.Banned) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		quoted := make([]string, len(g.Banned))
+		for i, term := range g.Banned {
+			quoted[i] = fmt.Sprintf("%q", term)
+		}
+		b.WriteString("Never use these terms: " + strings.Join(quoted, ", ") + ".")
+	}
+	return b.String()
+}
+
+// GlossaryError is returned when a response still breaks the glossary after a re-prompt.
+type GlossaryError struct {
+	Violations []GlossaryViolation
+	Response   Response // the last answer, for callers that accept it anyway
+}
+
+func (e *GlossaryError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return "glossary: " + strings.Join(msgs, "; ")
+}
+
+// WithGlossary enforces g: its terms are added to the system prompt, and a
+// response that breaks it is regenerated once with the violations pointed out.
+// A second failure returns *GlossaryError. Streamed responses are instructed
+// but not regenerated, since their text was already delivered.
+func WithGlossary(g Glossary) Option {
+	return func(o *options) {
+		o.glossary = &g
+	}
+}
+
+// enforceGlossary regenerates resp once when it breaks o.glossary.
+// req already carries the glossary instruction.
+func enforceGlossary(ctx context.Context, p Provider, req Request, o *options, resp Response) (Response, error) {
+	input := requestText(req)
+	violations := o.glossary.Check(input, resp.Text)
+	if len(violations) == 0 {
+		return resp, nil
+	}
+
+	msgs := make([]string, len(violations))
+	for i, v := range violations {
+		msgs[i] = v.String()
+	}
+	req.System = appendInstruction(req.System, "Your previous answer "+strings.Join(msgs, "; ")+". Follow the terminology exactly.")
+	retry, err := reprompt(ctx, p, req, o, resp)
+	if err != nil {
+		return Response{}, err
+	}
+	if violations := o.glossary.Check(input, retry.Text); len(violations) > 0 {
+		return Response{}, &GlossaryError{Violations: violations, Response: retry}
+	}
+	return retry, nil
+}
+
+// requestText returns the user-visible text of req, for glossary source terms.
+func requestText(req Request) string {
+	if len(req.Messages) == 0 {
+		return req.User
+	}
+	parts := make([]string, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		if m.Role == "user" {
+			parts = append(parts, m.Content)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// containsTerm reports whether term occurs in text as whole words, ignoring case.
+func containsTerm(text, term string) bool {
+	text, term = strings.ToLower(text), strings.ToLower(term)
+	if term == "" {
+		return false
+	}
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for start := 0; ; {
+		i := strings.Index(text[start:], term)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWord(before) && !isWord(after) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,73 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGlossary_Check(t *testing.T) {
+	g := Glossary{
+		Terms:  map[string]string{"dashboard": "kojelauta", "sign in": "kirjaudu"},
+		Banned: []string{"näyttö", "AI"},
+	}
+	got := g.Check("Open the Dashboard and sign in.", "Avaa näyttö ja kirjaudu sisään. Said the AI.")
+	want := []GlossaryViolation{{Term: "näyttö"}, {Term: "AI"}, {Term: "dashboard", Want: "kojelauta"}}
+	if len(got) != len(want) {
+		t.Fatalf("Check() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Check()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// Whole words only, and required terms only when the source term was used
+	if v := g.Check("Hello", "Email the chair about the näyttöpääte."); len(v) != 0 {
+		t.Errorf("Check() = %v, want no violations", v)
+	}
+}
+
+func TestGlossary_Instruction(t *testing.T) {
+	g := Glossary{Terms: map[string]string{"dashboard": "kojelauta"}, Banned: []string{"screen"}}
+	want := "Use this terminology:\n- \"dashboard\": \"kojelauta\"\nNever use these terms: \"screen\"."
+	if got := g.instruction(); got != want {
+		t.Errorf("instruction() = %q, want %q", got, want)
+	}
+}
+
+func TestWithGlossary_Regenerates(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"content":[{"type":"text","text":"Avaa näyttö."}],"usage":{"input_tokens":10,"output_tokens":3}}`,
+		`{"content":[{"type":"text","text":"Avaa kojelauta."}],"usage":{"input_tokens":14,"output_tokens":3}}`,
+	}}
+	g := Glossary{Terms: map[string]string{"dashboard": "kojelauta"}, Banned: []string{"näyttö"}}
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	resp, err := Prompt(context.Background(), p, Request{User: "Translate: Open the dashboard."},
+		WithGlossary(g), WithHTTPClient(&http.Client{Transport: mock}))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.Text != "Avaa kojelauta." || resp.Tokens.Input != 24 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(mock.bodies) != 2 || !strings.Contains(mock.bodies[0], "Use this terminology") ||
+		!strings.Contains(mock.bodies[1], `used banned term \"näyttö\"`) {
+		t.Errorf("bodies = %q", mock.bodies)
+	}
+}
+
+func TestWithGlossary_StillBroken(t *testing.T) {
+	bad := `{"content":[{"type":"text","text":"Avaa näyttö."}],"usage":{"input_tokens":10,"output_tokens":3}}`
+	mock := &scriptedTransport{responses: []string{bad, bad}}
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	_, err := Prompt(context.Background(), p, Request{User: "Open it"},
+		WithGlossary(Glossary{Banned: []string{"näyttö"}}), WithHTTPClient(&http.Client{Transport: mock}))
+
+	var ge *GlossaryError
+	if !errors.As(err, &ge) || len(ge.Violations) != 1 || ge.Response.Text != "Avaa näyttö." {
+		t.Errorf("error = %v, want GlossaryError", err)
+	}
+}
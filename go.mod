@@ -2,7 +2,6 @@ module github.com/aktagon/llmkit
 
 go 1.23
 
-require (
-	gopkg.in/dnaeon/go-vcr.v3 v3.2.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
-)
+require gopkg.in/dnaeon/go-vcr.v3 v3.2.0
+
+require gopkg.in/yaml.v3 v3.0.1 // indirect
@@ -1,13 +1,25 @@
 package llmkit
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"time"
 )
 
-const googleChatPathFmt = "/v1beta/models/%s:generateContent"
+const (
+	googleChatPathFmt        = "/v1beta/models/%s:generateContent"
+	googleStreamPathFmt      = "/v1beta/models/%s:streamGenerateContent?alt=sse"
+	googleEmbedPathFmt       = "/v1beta/models/%s:batchEmbedContents"
+	googlePredictPathFmt     = "/v1beta/models/%s:predict"
+	googleCountTokensPathFmt = "/v1beta/models/%s:countTokens"
+	googleBatchPathFmt       = "/v1beta/models/%s:batchGenerateContent"
+)
 
 type googleRequest struct {
 	Contents         []googleContent       `json:"contents"`
@@ -60,14 +72,17 @@ type googleInlineData struct {
 }
 
 type googleGenerationConf struct {
-	ResponseMimeType string               `json:"responseMimeType,omitempty"`
-	ResponseSchema   any                  `json:"responseSchema,omitempty"`
-	Temperature      *float64             `json:"temperature,omitempty"`
-	TopP             *float64             `json:"topP,omitempty"`
-	TopK             *int                 `json:"topK,omitempty"`
-	MaxOutputTokens  *int                 `json:"maxOutputTokens,omitempty"`
-	StopSequences    []string             `json:"stopSequences,omitempty"`
-	ThinkingConfig   *googleThinkingConf  `json:"thinkingConfig,omitempty"`
+	ResponseMimeType   string              `json:"responseMimeType,omitempty"`
+	ResponseSchema     any                 `json:"responseSchema,omitempty"`
+	Temperature        *float64            `json:"temperature,omitempty"`
+	TopP               *float64            `json:"topP,omitempty"`
+	TopK               *int                `json:"topK,omitempty"`
+	MaxOutputTokens    *int                `json:"maxOutputTokens,omitempty"`
+	StopSequences      []string            `json:"stopSequences,omitempty"`
+	Seed               *int64              `json:"seed,omitempty"`
+	ThinkingConfig     *googleThinkingConf `json:"thinkingConfig,omitempty"`
+	ResponseModalities []string            `json:"responseModalities,omitempty"`
+	SpeechConfig       *googleSpeechConf   `json:"speechConfig,omitempty"`
 }
 
 type googleThinkingConf struct {
@@ -76,47 +91,120 @@ type googleThinkingConf struct {
 }
 
 type googleResponse struct {
-	Candidates []struct {
-		Content struct {
-			Parts []struct {
-				Text         string              `json:"text,omitempty"`
-				FunctionCall *googleFunctionCall `json:"functionCall,omitempty"`
-			} `json:"parts"`
-		} `json:"content"`
-	} `json:"candidates"`
-	UsageMetadata struct {
-		PromptTokenCount     int `json:"promptTokenCount"`
-		CandidatesTokenCount int `json:"candidatesTokenCount"`
-	} `json:"usageMetadata"`
+	Candidates     []googleCandidate     `json:"candidates"`
+	PromptFeedback *googlePromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  *googleUsageMetadata  `json:"usageMetadata,omitempty"`
+	ModelVersion   string                `json:"modelVersion,omitempty"`
 }
 
-func promptGoogle(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
-	// Build contents array
-	var contents []googleContent
-	if len(req.Messages) > 0 {
-		for _, m := range req.Messages {
-			role := m.Role
-			if role == "assistant" {
-				role = "model" // Google uses "model" instead of "assistant"
+type googleCandidate struct {
+	Content struct {
+		Parts []googleResponsePart `json:"parts"`
+	} `json:"content"`
+	FinishReason  string               `json:"finishReason,omitempty"`
+	SafetyRatings []googleSafetyRating `json:"safetyRatings,omitempty"`
+}
+
+type googleResponsePart struct {
+	Text         string              `json:"text,omitempty"`
+	FunctionCall *googleFunctionCall `json:"functionCall,omitempty"`
+	InlineData   *googleResponseBlob `json:"inlineData,omitempty"`
+}
+
+// googleResponseBlob is inline media in a response, e.g. TTS audio.
+type googleResponseBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type googlePromptFeedback struct {
+	BlockReason   string               `json:"blockReason,omitempty"`
+	SafetyRatings []googleSafetyRating `json:"safetyRatings,omitempty"`
+}
+
+type googleUsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+}
+
+// usage returns token counts, or zero when metadata is absent.
+func (r googleResponse) usage() Usage {
+	if r.UsageMetadata == nil {
+		return Usage{}
+	}
+	return Usage{
+		Input:           r.UsageMetadata.PromptTokenCount,
+		Output:          r.UsageMetadata.CandidatesTokenCount,
+		CacheReadTokens: r.UsageMetadata.CachedContentTokenCount,
+		ReasoningTokens: r.UsageMetadata.ThoughtsTokenCount,
+	}
+}
+
+// text returns the first part of the first candidate.
+func (r googleResponse) text() string {
+	if len(r.Candidates) > 0 && len(r.Candidates[0].Content.Parts) > 0 {
+		return r.Candidates[0].Content.Parts[0].Text
+	}
+	return ""
+}
+
+type googleSafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked,omitempty"`
+}
+
+// googleBlockReasons are finish reasons that mean the output was withheld.
+var googleBlockReasons = map[string]bool{
+	"SAFETY":             true,
+	"RECITATION":         true,
+	"BLOCKLIST":          true,
+	"PROHIBITED_CONTENT": true,
+	"IMAGE_SAFETY":       true,
+	"SPII":               true,
+}
+
+// checkGoogleBlocked returns a ContentBlockedError when the prompt was blocked,
+// no candidates were returned, or the first candidate was stopped by a safety filter.
+func checkGoogleBlocked(resp googleResponse) error {
+	if len(resp.Candidates) == 0 {
+		blockErr := &ContentBlockedError{Provider: Google, Reason: "NO_CANDIDATES"}
+		if fb := resp.PromptFeedback; fb != nil {
+			if fb.BlockReason != "" {
+				blockErr.Reason = fb.BlockReason
 			}
-			contents = append(contents, googleContent{
-				Role:  role,
-				Parts: []googlePart{{Text: m.Content}},
-			})
+			blockErr.Categories = googleFlaggedCategories(fb.SafetyRatings)
 		}
-	} else {
-		contents = []googleContent{{Role: "user", Parts: buildGoogleParts(req)}}
+		return blockErr
 	}
 
-	payload := googleRequest{
-		Contents: contents,
+	c := resp.Candidates[0]
+	if googleBlockReasons[c.FinishReason] {
+		return &ContentBlockedError{
+			Provider:   Google,
+			Reason:     c.FinishReason,
+			Categories: googleFlaggedCategories(c.SafetyRatings),
+		}
 	}
+	return nil
+}
 
-	if req.System != "" {
-		payload.SystemInstruct = &googleContent{
-			Parts: []googlePart{{Text: req.System}},
+// googleFlaggedCategories returns categories that were blocked or rated above LOW.
+func googleFlaggedCategories(ratings []googleSafetyRating) []string {
+	var cats []string
+	for _, r := range ratings {
+		if r.Blocked || r.Probability == "MEDIUM" || r.Probability == "HIGH" {
+			cats = append(cats, r.Category)
 		}
 	}
+	return cats
+}
+
+// googlePayload builds the generateContent request for req.
+func googlePayload(req Request, o *options) (googleRequest, error) {
+	payload := googleContents(req)
 
 	// Build generation config
 	genConfig := &googleGenerationConf{
@@ -125,6 +213,7 @@ func promptGoogle(ctx context.Context, p Provider, req Request, o *options) (Res
 		TopK:            o.topK,
 		MaxOutputTokens: o.maxTokens,
 		StopSequences:   o.stopSequences,
+		Seed:            o.seed,
 	}
 
 	// Add thinking config if specified
@@ -139,52 +228,226 @@ func promptGoogle(ctx context.Context, p Provider, req Request, o *options) (Res
 	}
 
 	if req.Schema != "" {
-		var schema map[string]any
-		if err := json.Unmarshal([]byte(req.Schema), &schema); err != nil {
-			return Response{}, err
+		schema, _, err := TranslateSchema(Google, req.Schema)
+		if err != nil {
+			return googleRequest{}, err
 		}
-		// Google doesn't support additionalProperties
-		delete(schema, "additionalProperties")
 		genConfig.ResponseMimeType = "application/json"
 		genConfig.ResponseSchema = schema
 	}
 
 	payload.GenerationConfig = genConfig
+	return payload, nil
+}
 
-	body, err := json.Marshal(payload)
+func promptGoogle(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
+	payload, err := googlePayload(req, o)
 	if err != nil {
 		return Response{}, err
 	}
 
+	resp, err := postGoogle(ctx, p, payload, o)
+	if err != nil {
+		return Response{}, err
+	}
+	if err := checkGoogleBlocked(resp); err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		Text:              resp.text(),
+		Tokens:            resp.usage(),
+		FinishReason:      resp.finishReason(),
+		SystemFingerprint: resp.ModelVersion,
+	}, nil
+}
+
+// finishReason normalizes the first candidate's finishReason. Gemini reports
+// STOP for function calls, so a candidate with calls maps to FinishToolUse.
+func (r googleResponse) finishReason() FinishReason {
+	if len(r.Candidates) == 0 {
+		return ""
+	}
+	c := r.Candidates[0]
+	for _, part := range c.Content.Parts {
+		if part.FunctionCall != nil {
+			return FinishToolUse
+		}
+	}
+	switch c.FinishReason {
+	case "":
+		return ""
+	case "STOP":
+		return FinishStop
+	case "MAX_TOKENS":
+		return FinishLength
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return FinishContentFilter
+	default:
+		return FinishOther
+	}
+}
+
+// googleContents builds the contents and system instruction of a request.
+func googleContents(req Request) googleRequest {
+	var payload googleRequest
+	if len(req.Messages) > 0 {
+		for _, m := range req.Messages {
+			role := m.Role
+			if role == "assistant" {
+				role = "model" // Google uses "model" instead of "assistant"
+			}
+			payload.Contents = append(payload.Contents, googleContent{
+				Role:  role,
+				Parts: buildGoogleParts(Request{User: m.Content, Images: m.Images, Files: m.Files}),
+			})
+		}
+	} else {
+		payload.Contents = []googleContent{{Role: "user", Parts: buildGoogleParts(req)}}
+	}
+
+	if req.System != "" {
+		payload.SystemInstruct = &googleContent{
+			Parts: []googlePart{{Text: req.System}},
+		}
+	}
+	return payload
+}
+
+// countTokensGoogle asks the countTokens endpoint for the input tokens of req.
+func countTokensGoogle(ctx context.Context, p Provider, req Request, o *options) (int, error) {
+	type generateContentRequest struct {
+		Model string `json:"model"`
+		googleRequest
+	}
+	body, err := json.Marshal(struct {
+		Request generateContentRequest `json:"generateContentRequest"`
+	}{generateContentRequest{Model: "models/" + p.model(), googleRequest: googleContents(req)}})
+	if err != nil {
+		return 0, err
+	}
+
+	path := fmt.Sprintf(googleCountTokensPathFmt, p.model())
+	url := p.buildURL(path) + "?key=" + p.APIKey
+
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, url, body, googleHeaders(p))
+	if err != nil {
+		return 0, err
+	}
+	if statusCode >= 400 {
+		return 0, parseError(Google, statusCode, respBody, respHeaders)
+	}
+
+	var resp struct {
+		TotalTokens int `json:"totalTokens"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return 0, err
+	}
+	return resp.TotalTokens, nil
+}
+
+// postGoogle sends a generateContent request and decodes the response.
+// When o.stream is set the response is streamed instead (see streamGoogle).
+func postGoogle(ctx context.Context, p Provider, payload googleRequest, o *options) (googleResponse, error) {
+	if o.stream != nil {
+		return streamGoogle(ctx, p, payload, o)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return googleResponse{}, err
+	}
+
 	path := fmt.Sprintf(googleChatPathFmt, p.model())
 	url := p.buildURL(path) + "?key=" + p.APIKey
 
-	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, url, body, nil)
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, url, body, googleHeaders(p))
 	if err != nil {
-		return Response{}, err
+		return googleResponse{}, err
 	}
 
 	if statusCode >= 400 {
-		return Response{}, parseError(Google, statusCode, respBody, nil)
+		return googleResponse{}, parseError(Google, statusCode, respBody, respHeaders)
 	}
 
 	var resp googleResponse
 	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return Response{}, err
+		return googleResponse{}, err
 	}
+	return resp, nil
+}
 
-	text := ""
-	if len(resp.Candidates) > 0 && len(resp.Candidates[0].Content.Parts) > 0 {
-		text = resp.Candidates[0].Content.Parts[0].Text
+// streamGoogle sends a streamGenerateContent request, forwards text deltas to o.stream,
+// and merges the partial candidates into one response. Usage metadata is taken from
+// the last chunk that carries it.
+func streamGoogle(ctx context.Context, p Provider, payload googleRequest, o *options) (googleResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return googleResponse{}, err
 	}
 
-	return Response{
-		Text: text,
-		Tokens: Usage{
-			Input:  resp.UsageMetadata.PromptTokenCount,
-			Output: resp.UsageMetadata.CandidatesTokenCount,
-		},
-	}, nil
+	path := fmt.Sprintf(googleStreamPathFmt, p.model())
+	url := p.buildURL(path) + "&key=" + p.APIKey
+
+	httpResp, errBody, err := doPostStream(ctx, o.httpClient, url, body, googleHeaders(p))
+	if err != nil {
+		return googleResponse{}, err
+	}
+	if httpResp.StatusCode >= 400 {
+		return googleResponse{}, parseError(Google, httpResp.StatusCode, errBody, httpResp.Header)
+	}
+	defer httpResp.Body.Close()
+
+	var resp googleResponse
+	var merged googleCandidate
+	seen := false
+	err = readStream(ctx, httpResp.Body, func(event, data string) error {
+		var chunk googleResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return err
+		}
+		if chunk.PromptFeedback != nil {
+			resp.PromptFeedback = chunk.PromptFeedback
+		}
+		if chunk.UsageMetadata != nil {
+			resp.UsageMetadata = chunk.UsageMetadata
+			o.reportUsage(resp.usage())
+		}
+		if len(chunk.Candidates) == 0 {
+			return nil
+		}
+
+		seen = true
+		c := chunk.Candidates[0]
+		for _, part := range c.Content.Parts {
+			if part.Text != "" {
+				o.stream(part.Text)
+			}
+			// Consecutive text fragments are merged into a single part
+			parts := merged.Content.Parts
+			if part.FunctionCall == nil && len(parts) > 0 && parts[len(parts)-1].FunctionCall == nil {
+				parts[len(parts)-1].Text += part.Text
+				continue
+			}
+			merged.Content.Parts = append(parts, part)
+		}
+		if c.FinishReason != "" {
+			merged.FinishReason = c.FinishReason
+		}
+		if len(c.SafetyRatings) > 0 {
+			merged.SafetyRatings = c.SafetyRatings
+		}
+		return nil
+	})
+	if err != nil {
+		return googleResponse{}, err
+	}
+
+	if seen {
+		resp.Candidates = []googleCandidate{merged}
+	}
+	return resp, nil
 }
 
 // buildGoogleParts creates parts array from request.
@@ -193,6 +456,15 @@ func buildGoogleParts(req Request) []googlePart {
 
 	// Add files first
 	for _, f := range req.Files {
+		if f.inline() {
+			parts = append(parts, googlePart{
+				InlineData: &googleInlineData{
+					MimeType: f.MimeType,
+					Data:     base64.StdEncoding.EncodeToString(f.Data),
+				},
+			})
+			continue
+		}
 		parts = append(parts, googlePart{
 			FileData: &googleFileData{
 				FileURI:  f.URI,
@@ -226,7 +498,7 @@ func buildGoogleParts(req Request) []googlePart {
 }
 
 // sendGoogleWithTools sends a request with tools and returns tool calls.
-func sendGoogleWithTools(ctx context.Context, p Provider, msgs []message, system string, tools []Tool, o *options) (string, []toolCall, Usage, error) {
+func sendGoogleWithTools(ctx context.Context, p Provider, msgs []message, system string, tools []Tool, o *options) (turn, error) {
 	// Build contents
 	var contents []googleContent
 	for _, m := range msgs {
@@ -249,6 +521,9 @@ func sendGoogleWithTools(ctx context.Context, p Provider, msgs []message, system
 		} else if len(m.toolCalls) > 0 {
 			// Model message with function calls
 			var parts []googlePart
+			if m.content != "" {
+				parts = append(parts, googlePart{Text: m.content})
+			}
 			for _, tc := range m.toolCalls {
 				parts = append(parts, googlePart{
 					FunctionCall: &googleFunctionCall{
@@ -259,10 +534,10 @@ func sendGoogleWithTools(ctx context.Context, p Provider, msgs []message, system
 			}
 			contents = append(contents, googleContent{Role: "model", Parts: parts})
 		} else {
-			// Regular text message
+			// Regular message, with any attachments first
 			contents = append(contents, googleContent{
 				Role:  role,
-				Parts: []googlePart{{Text: m.content}},
+				Parts: buildGoogleParts(Request{User: m.content, Images: m.images, Files: m.files}),
 			})
 		}
 	}
@@ -279,7 +554,9 @@ func sendGoogleWithTools(ctx context.Context, p Provider, msgs []message, system
 
 	payload := googleRequest{
 		Contents: contents,
-		Tools:    []googleTool{{FunctionDeclarations: decls}},
+	}
+	if len(decls) > 0 {
+		payload.Tools = []googleTool{{FunctionDeclarations: decls}}
 	}
 
 	if system != "" {
@@ -295,41 +572,27 @@ func sendGoogleWithTools(ctx context.Context, p Provider, msgs []message, system
 		TopK:            o.topK,
 		MaxOutputTokens: o.maxTokens,
 		StopSequences:   o.stopSequences,
+		Seed:            o.seed,
 	}
 	payload.GenerationConfig = genConfig
 
-	body, err := json.Marshal(payload)
+	resp, err := postGoogle(ctx, p, payload, o)
 	if err != nil {
-		return "", nil, Usage{}, err
+		return turn{}, err
 	}
-
-	path := fmt.Sprintf(googleChatPathFmt, p.model())
-	url := p.buildURL(path) + "?key=" + p.APIKey
-
-	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, url, body, nil)
-	if err != nil {
-		return "", nil, Usage{}, err
-	}
-
-	if statusCode >= 400 {
-		return "", nil, Usage{}, parseError(Google, statusCode, respBody, nil)
-	}
-
-	var resp googleResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return "", nil, Usage{}, err
+	if err := checkGoogleBlocked(resp); err != nil {
+		return turn{}, err
 	}
 
 	// Extract text and function calls
-	var text string
-	var calls []toolCall
+	var t turn
 	if len(resp.Candidates) > 0 {
 		for _, part := range resp.Candidates[0].Content.Parts {
 			if part.Text != "" {
-				text = part.Text
+				t.text = part.Text
 			}
 			if part.FunctionCall != nil {
-				calls = append(calls, toolCall{
+				t.calls = append(t.calls, toolCall{
 					id:    part.FunctionCall.Name, // Google uses name as ID
 					name:  part.FunctionCall.Name,
 					input: part.FunctionCall.Args,
@@ -337,13 +600,10 @@ func sendGoogleWithTools(ctx context.Context, p Provider, msgs []message, system
 			}
 		}
 	}
+	t.usage = resp.usage()
+	t.finishReason = resp.finishReason()
 
-	usage := Usage{
-		Input:  resp.UsageMetadata.PromptTokenCount,
-		Output: resp.UsageMetadata.CandidatesTokenCount,
-	}
-
-	return text, calls, usage, nil
+	return t, nil
 }
 
 const googleUploadPath = "/upload/v1beta/files"
@@ -360,9 +620,8 @@ type googleFileResponse struct {
 // uploadGoogle uploads a file to Google's Files API.
 func uploadGoogle(ctx context.Context, p Provider, data []byte, name, mimeType string, o *options) (File, error) {
 	url := p.buildURL(googleUploadPath) + "?key=" + p.APIKey
-	headers := map[string]string{
-		"X-Goog-Upload-Protocol": "multipart",
-	}
+	headers := googleHeaders(p)
+	headers["X-Goog-Upload-Protocol"] = "multipart"
 
 	// Google requires JSON metadata as a separate field
 	metadata := fmt.Sprintf(`{"file":{"display_name":"%s"}}`, name)
@@ -392,3 +651,490 @@ func uploadGoogle(ctx context.Context, p Provider, data []byte, name, mimeType s
 		Name:     resp.File.DisplayName,
 	}, nil
 }
+
+type googleEmbedRequest struct {
+	Requests []googleEmbedContentRequest `json:"requests"`
+}
+
+type googleEmbedContentRequest struct {
+	Model                string        `json:"model"`
+	Content              googleContent `json:"content"`
+	OutputDimensionality *int          `json:"outputDimensionality,omitempty"`
+	TaskType             string        `json:"taskType,omitempty"`
+}
+
+type googleEmbedResponse struct {
+	Embeddings []struct {
+		Values []float32 `json:"values"`
+	} `json:"embeddings"`
+}
+
+func embedGoogle(ctx context.Context, p Provider, model string, texts []string, o *options) ([][]float32, error) {
+	payload := googleEmbedRequest{}
+	for _, text := range texts {
+		payload.Requests = append(payload.Requests, googleEmbedContentRequest{
+			Model:                "models/" + model,
+			Content:              googleContent{Parts: []googlePart{{Text: text}}},
+			OutputDimensionality: o.dimensions,
+			TaskType:             o.taskType,
+		})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf(googleEmbedPathFmt, model)
+	url := p.buildURL(path) + "?key=" + p.APIKey
+
+	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, url, body, googleHeaders(p))
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode >= 400 {
+		return nil, parseError(Google, statusCode, respBody, nil)
+	}
+
+	var resp googleEmbedResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(resp.Embeddings))
+	for i, e := range resp.Embeddings {
+		vectors[i] = e.Values
+	}
+	return vectors, nil
+}
+
+type googleImagenRequest struct {
+	Instances  []googleImagenInstance `json:"instances"`
+	Parameters googleImagenParams     `json:"parameters"`
+}
+
+type googleImagenInstance struct {
+	Prompt string `json:"prompt"`
+}
+
+type googleImagenParams struct {
+	SampleCount      int    `json:"sampleCount,omitempty"`
+	AspectRatio      string `json:"aspectRatio,omitempty"`
+	PersonGeneration string `json:"personGeneration,omitempty"`
+	SafetySetting    string `json:"safetySetting,omitempty"`
+}
+
+type googleImagenResponse struct {
+	Predictions []struct {
+		BytesBase64Encoded string `json:"bytesBase64Encoded"`
+		MimeType           string `json:"mimeType"`
+		RAIFilteredReason  string `json:"raiFilteredReason,omitempty"`
+	} `json:"predictions"`
+}
+
+func generateImageGoogle(ctx context.Context, p Provider, req ImageRequest, o *options) (ImageResponse, error) {
+	payload := googleImagenRequest{
+		Instances: []googleImagenInstance{{Prompt: req.Prompt}},
+		Parameters: googleImagenParams{
+			SampleCount:      req.N,
+			AspectRatio:      req.AspectRatio,
+			PersonGeneration: req.PersonGeneration,
+			SafetySetting:    req.SafetySetting,
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return ImageResponse{}, err
+	}
+
+	path := fmt.Sprintf(googlePredictPathFmt, req.Model)
+	url := p.buildURL(path) + "?key=" + p.APIKey
+
+	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, url, body, googleHeaders(p))
+	if err != nil {
+		return ImageResponse{}, err
+	}
+
+	if statusCode >= 400 {
+		return ImageResponse{}, parseError(Google, statusCode, respBody, nil)
+	}
+
+	var resp googleImagenResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return ImageResponse{}, err
+	}
+
+	var out ImageResponse
+	var filtered string
+	for _, pred := range resp.Predictions {
+		if pred.BytesBase64Encoded == "" {
+			if pred.RAIFilteredReason != "" {
+				filtered = pred.RAIFilteredReason
+			}
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(pred.BytesBase64Encoded)
+		if err != nil {
+			return ImageResponse{}, fmt.Errorf("google: decode image: %w", err)
+		}
+		out.Images = append(out.Images, GeneratedImage{Data: data, MimeType: pred.MimeType})
+	}
+
+	// Imagen omits images rejected by its safety filters
+	if len(out.Images) == 0 {
+		reason := "NO_IMAGES"
+		if filtered != "" {
+			reason = filtered
+		}
+		return ImageResponse{}, &ContentBlockedError{Provider: Google, Reason: reason}
+	}
+	return out, nil
+}
+
+type googleSpeechConf struct {
+	VoiceConfig             *googleVoiceConf        `json:"voiceConfig,omitempty"`
+	MultiSpeakerVoiceConfig *googleMultiSpeakerConf `json:"multiSpeakerVoiceConfig,omitempty"`
+}
+
+type googleMultiSpeakerConf struct {
+	SpeakerVoiceConfigs []googleSpeakerVoiceConf `json:"speakerVoiceConfigs"`
+}
+
+type googleSpeakerVoiceConf struct {
+	Speaker     string          `json:"speaker"`
+	VoiceConfig googleVoiceConf `json:"voiceConfig"`
+}
+
+type googleVoiceConf struct {
+	PrebuiltVoiceConfig struct {
+		VoiceName string `json:"voiceName"`
+	} `json:"prebuiltVoiceConfig"`
+}
+
+// speakGoogle synthesizes speech with a Gemini TTS model. Gemini returns raw
+// 16-bit PCM, optionally wrapped in a WAV header.
+func speakGoogle(ctx context.Context, p Provider, req SpeechRequest, o *options) (SpeechResponse, error) {
+	body, err := json.Marshal(googleSpeechPayload(req))
+	if err != nil {
+		return SpeechResponse{}, err
+	}
+
+	path := fmt.Sprintf(googleChatPathFmt, req.Model)
+	url := p.buildURL(path) + "?key=" + p.APIKey
+
+	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, url, body, googleHeaders(p))
+	if err != nil {
+		return SpeechResponse{}, err
+	}
+
+	if statusCode >= 400 {
+		return SpeechResponse{}, parseError(Google, statusCode, respBody, nil)
+	}
+
+	var resp googleResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return SpeechResponse{}, err
+	}
+	if err := checkGoogleBlocked(resp); err != nil {
+		return SpeechResponse{}, err
+	}
+
+	var pcm []byte
+	rate := 24000
+	for _, c := range resp.Candidates {
+		for _, part := range c.Content.Parts {
+			if part.InlineData == nil {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
+			if err != nil {
+				return SpeechResponse{}, fmt.Errorf("google: decode audio: %w", err)
+			}
+			pcm = append(pcm, data...)
+			rate = pcmRate(part.InlineData.MimeType, rate)
+		}
+	}
+	if len(pcm) == 0 {
+		return SpeechResponse{}, fmt.Errorf("google: response contained no audio")
+	}
+
+	out := SpeechResponse{Audio: pcm, MimeType: "audio/pcm;rate=" + strconv.Itoa(rate), Tokens: resp.usage()}
+	if req.Format == "wav" {
+		out.Audio, out.MimeType = wavFile(pcm, rate), "audio/wav"
+	}
+	return out, nil
+}
+
+// googleSpeechPayload builds the generateContent request for Gemini TTS.
+func googleSpeechPayload(req SpeechRequest) googleRequest {
+	text := req.Text
+	// Gemini TTS takes the speaking style as part of the prompt
+	if req.Instructions != "" {
+		text = req.Instructions + ": " + text
+	}
+	return googleRequest{
+		Contents: []googleContent{{Role: "user", Parts: []googlePart{{Text: text}}}},
+		GenerationConfig: &googleGenerationConf{
+			ResponseModalities: []string{"AUDIO"},
+			SpeechConfig:       googleSpeech(req),
+		},
+	}
+}
+
+// speakGoogleStream streams Gemini TTS through streamGenerateContent, writing
+// each chunk's PCM to the returned stream as it arrives.
+func speakGoogleStream(ctx context.Context, p Provider, req SpeechRequest, o *options) (*SpeechStream, error) {
+	body, err := json.Marshal(googleSpeechPayload(req))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	path := fmt.Sprintf(googleStreamPathFmt, req.Model)
+	url := p.buildURL(path) + "&key=" + p.APIKey
+	httpResp, errBody, err := doPostStream(ctx, o.httpClient, url, body, googleHeaders(p))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if httpResp.StatusCode >= 400 {
+		cancel()
+		return nil, parseError(Google, httpResp.StatusCode, errBody, httpResp.Header)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer httpResp.Body.Close()
+		wrote := false
+		err := readStream(ctx, httpResp.Body, func(event, data string) error {
+			var chunk googleResponse
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				return err
+			}
+			if len(chunk.Candidates) > 0 || chunk.PromptFeedback != nil {
+				if err := checkGoogleBlocked(chunk); err != nil {
+					return err
+				}
+			}
+			for _, c := range chunk.Candidates {
+				for _, part := range c.Content.Parts {
+					if part.InlineData == nil {
+						continue
+					}
+					pcm, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
+					if err != nil {
+						return fmt.Errorf("google: decode audio: %w", err)
+					}
+					// The sample rate is known once the first chunk arrives
+					if !wrote && req.Format == "wav" {
+						pcm = append(wavHeader(-1, pcmRate(part.InlineData.MimeType, 24000)), pcm...)
+					}
+					wrote = true
+					if _, err := pw.Write(pcm); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err == nil && !wrote {
+			err = fmt.Errorf("google: response contained no audio")
+		}
+		pw.CloseWithError(err)
+	}()
+
+	mimeType := "audio/pcm;rate=24000"
+	if req.Format == "wav" {
+		mimeType = "audio/wav"
+	}
+	return &SpeechStream{ReadCloser: cancelReader{pr, cancel}, MimeType: mimeType}, nil
+}
+
+// googleSpeech builds the voice config for one voice or several speakers.
+func googleSpeech(req SpeechRequest) *googleSpeechConf {
+	voice := func(name string) googleVoiceConf {
+		var v googleVoiceConf
+		v.PrebuiltVoiceConfig.VoiceName = name
+		return v
+	}
+	if len(req.Speakers) == 0 {
+		v := voice(req.Voice)
+		return &googleSpeechConf{VoiceConfig: &v}
+	}
+	multi := &googleMultiSpeakerConf{}
+	for _, s := range req.Speakers {
+		multi.SpeakerVoiceConfigs = append(multi.SpeakerVoiceConfigs, googleSpeakerVoiceConf{Speaker: s.Name, VoiceConfig: voice(s.Voice)})
+	}
+	return &googleSpeechConf{MultiSpeakerVoiceConfig: multi}
+}
+
+// googleHeaders returns the extra headers for Google requests, which
+// authenticate with the key query parameter instead.
+func googleHeaders(p Provider) map[string]string {
+	return p.withHeaders(map[string]string{})
+}
+
+// googleInt64 decodes an int64 that proto3 JSON may encode as a string.
+type googleInt64 int
+
+func (n *googleInt64) UnmarshalJSON(data []byte) error {
+	v, err := strconv.Atoi(strings.Trim(string(data), `"`))
+	*n = googleInt64(v)
+	return err
+}
+
+// googleBatch is a batch operation from the Gemini Batch API.
+type googleBatch struct {
+	Name     string `json:"name"`
+	Metadata struct {
+		State      string    `json:"state"`
+		CreateTime time.Time `json:"createTime"`
+		BatchStats struct {
+			RequestCount           googleInt64 `json:"requestCount"`
+			SuccessfulRequestCount googleInt64 `json:"successfulRequestCount"`
+			FailedRequestCount     googleInt64 `json:"failedRequestCount"`
+		} `json:"batchStats"`
+		Output struct {
+			ResponsesFile string `json:"responsesFile"`
+		} `json:"output"`
+	} `json:"metadata"`
+}
+
+func (b googleBatch) batch() Batch {
+	m := b.Metadata
+	done := false
+	switch m.State {
+	case "BATCH_STATE_SUCCEEDED", "BATCH_STATE_FAILED", "BATCH_STATE_CANCELLED", "BATCH_STATE_EXPIRED":
+		done = true
+	}
+	return Batch{
+		ID:        b.Name,
+		Provider:  Google,
+		Status:    m.State,
+		Done:      done,
+		Total:     int(m.BatchStats.RequestCount),
+		Succeeded: int(m.BatchStats.SuccessfulRequestCount),
+		Failed:    int(m.BatchStats.FailedRequestCount),
+		CreatedAt: m.CreateTime,
+	}
+}
+
+// googleBatchLine is one line of a batch input or output file.
+type googleBatchLine struct {
+	Key      string          `json:"key"`
+	Request  *googleRequest  `json:"request,omitempty"`
+	Response *googleResponse `json:"response,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+}
+
+// createBatchGoogle uploads the requests as a JSONL file and starts a batch job.
+func createBatchGoogle(ctx context.Context, p Provider, reqs []BatchRequest, o *options) (Batch, error) {
+	var input bytes.Buffer
+	enc := json.NewEncoder(&input)
+	for _, r := range reqs {
+		payload, err := googlePayload(r.Request, o)
+		if err != nil {
+			return Batch{}, err
+		}
+		if err := enc.Encode(googleBatchLine{Key: r.CustomID, Request: &payload}); err != nil {
+			return Batch{}, err
+		}
+	}
+
+	file, err := uploadGoogle(ctx, p, input.Bytes(), "batch.jsonl", "application/jsonl", o)
+	if err != nil {
+		return Batch{}, err
+	}
+
+	body, err := json.Marshal(map[string]any{"batch": map[string]any{
+		"display_name": "llmkit-batch",
+		"input_config": map[string]any{"file_name": file.ID},
+	}})
+	if err != nil {
+		return Batch{}, err
+	}
+	url := p.buildURL(fmt.Sprintf(googleBatchPathFmt, p.model())) + "?key=" + p.APIKey
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, url, body, googleHeaders(p))
+	if err != nil {
+		return Batch{}, err
+	}
+	if statusCode >= 400 {
+		return Batch{}, parseError(Google, statusCode, respBody, respHeaders)
+	}
+
+	var b googleBatch
+	if err := json.Unmarshal(respBody, &b); err != nil {
+		return Batch{}, err
+	}
+	return b.batch(), nil
+}
+
+func getBatchGoogle(ctx context.Context, p Provider, id string, o *options) (googleBatch, error) {
+	if !strings.HasPrefix(id, "batches/") {
+		id = "batches/" + id
+	}
+	respBody, statusCode, respHeaders, err := doGet(ctx, o.httpClient, p.buildURL("/v1beta/"+id)+"?key="+p.APIKey, googleHeaders(p))
+	if err != nil {
+		return googleBatch{}, err
+	}
+	if statusCode >= 400 {
+		return googleBatch{}, parseError(Google, statusCode, respBody, respHeaders)
+	}
+
+	var b googleBatch
+	if err := json.Unmarshal(respBody, &b); err != nil {
+		return googleBatch{}, err
+	}
+	return b, nil
+}
+
+// listBatchResultsGoogle downloads and decodes the responses file of a finished batch.
+func listBatchResultsGoogle(ctx context.Context, p Provider, id string, o *options) ([]BatchResult, error) {
+	b, err := getBatchGoogle(ctx, p, id, o)
+	if err != nil {
+		return nil, err
+	}
+	if !b.batch().Done {
+		return nil, &ValidationError{Field: "batch", Message: id + " is still " + b.Metadata.State}
+	}
+	file := b.Metadata.Output.ResponsesFile
+	if file == "" {
+		return nil, &APIError{Provider: Google, Message: "batch " + id + " has no responses file (" + b.Metadata.State + ")"}
+	}
+
+	url := p.buildURL("/download/v1beta/"+file+":download") + "?alt=media&key=" + p.APIKey
+	data, statusCode, respHeaders, err := doGet(ctx, o.httpClient, url, googleHeaders(p))
+	if err != nil {
+		return nil, err
+	}
+	if statusCode >= 400 {
+		return nil, parseError(Google, statusCode, data, respHeaders)
+	}
+
+	var results []BatchResult
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var line googleBatchLine
+		if err := dec.Decode(&line); err != nil {
+			return nil, err
+		}
+		r := BatchResult{CustomID: line.Key}
+		switch {
+		case line.Error != nil:
+			r.Err = parseError(Google, 0, []byte(`{"error":`+string(line.Error)+`}`), nil)
+		case line.Response == nil:
+			r.Err = &APIError{Provider: Google, Message: "batch result has no response"}
+		default:
+			if err := checkGoogleBlocked(*line.Response); err != nil {
+				r.Err = err
+				break
+			}
+			r.Response = Response{Text: line.Response.text(), Tokens: line.Response.usage()}
+			r.Response.Cost = EstimateCost(r.Response.Tokens, p.model()) * batchDiscount
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
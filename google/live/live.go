@@ -0,0 +1,326 @@
+// Package live implements Gemini's Live API: a bidirectional WebSocket session
+// that streams text or audio in and text or audio out, for real-time voice agents.
+// Tool calls requested by the model are run by the session and answered automatically.
+package live
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aktagon/llmkit"
+)
+
+const (
+	defaultModel   = "gemini-live-2.5-flash-preview"
+	defaultBaseURL = "https://generativelanguage.googleapis.com"
+	livePath       = "/ws/google.ai.generativelanguage.v1beta.GenerativeService.BidiGenerateContent"
+
+	// InputAudioMIMEType is the format Live expects for SendAudio: 16-bit PCM, 16 kHz, mono.
+	InputAudioMIMEType = "audio/pcm;rate=16000"
+)
+
+// Response modalities for Config.Modalities.
+const (
+	Text  = "TEXT"
+	Audio = "AUDIO"
+)
+
+// Config configures a Live session.
+type Config struct {
+	System     string        // system instruction (optional)
+	Modalities []string      // Text or Audio (default Text); Live allows one per session
+	Voice      string        // prebuilt voice for audio output, e.g. "Kore" (optional)
+	Tools      []llmkit.Tool // functions the model may call (optional)
+	Transcribe bool          // also report transcripts of input and output audio
+}
+
+// ToolCall is a function call the session ran on the model's behalf.
+type ToolCall struct {
+	ID     string
+	Name   string
+	Args   map[string]any
+	Result string // sent back to the model; "error: ..." when the tool failed
+}
+
+// Event is one server message. Only the fields the message carried are set.
+type Event struct {
+	Text             string // model text for the current turn
+	Audio            []byte // model audio chunk, PCM in AudioMIMEType
+	AudioMIMEType    string // e.g. "audio/pcm;rate=24000"
+	InputTranscript  string
+	OutputTranscript string
+	ToolCalls        []ToolCall
+	TurnComplete     bool // the model finished its turn
+	Interrupted      bool // user audio interrupted the model; drop queued audio
+	Usage            *llmkit.Usage
+}
+
+// Session is an open Live API connection. Send methods may be called
+// concurrently with Receive; Receive itself must not be called concurrently.
+type Session struct {
+	ws    *wsConn
+	tools []llmkit.Tool
+}
+
+// Connect opens a Live session with p, which must be a Google provider.
+// p.Model defaults to a Live-capable Gemini model; p.BaseURL overrides the host.
+func Connect(ctx context.Context, p llmkit.Provider, cfg Config) (*Session, error) {
+	if p.Name != llmkit.Google {
+		return nil, &llmkit.ValidationError{Field: "provider", Message: "live sessions not supported by " + p.Name}
+	}
+	if p.KeyProvider != nil {
+		key, err := p.KeyProvider.Key(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.APIKey = key
+	}
+	if p.APIKey == "" {
+		return nil, &llmkit.ValidationError{Field: "api_key", Message: "required"}
+	}
+	if len(cfg.Modalities) > 1 {
+		return nil, &llmkit.ValidationError{Field: "modalities", Message: "Live supports one response modality per session"}
+	}
+
+	ws, err := dialWS(ctx, liveURL(p), http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{ws: ws, tools: cfg.Tools}
+
+	// Abort the setup exchange if ctx ends first
+	stop := context.AfterFunc(ctx, func() { ws.conn.Close() })
+	defer stop()
+
+	if err := s.send(map[string]any{"setup": setupMessage(p, cfg)}); err != nil {
+		ws.close()
+		return nil, err
+	}
+	var ack struct {
+		SetupComplete *struct{} `json:"setupComplete"`
+	}
+	if err := s.read(&ack); err != nil {
+		ws.close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if ack.SetupComplete == nil {
+		ws.close()
+		return nil, fmt.Errorf("live: expected setupComplete")
+	}
+	return s, nil
+}
+
+// liveURL returns the WebSocket endpoint for p, authenticated with its API key.
+func liveURL(p llmkit.Provider) string {
+	base := strings.TrimSuffix(p.BaseURL, "/")
+	if base == "" {
+		base = defaultBaseURL
+	}
+	if rest, ok := strings.CutPrefix(base, "https://"); ok {
+		base = "wss://" + rest
+	} else if rest, ok := strings.CutPrefix(base, "http://"); ok {
+		base = "ws://" + rest
+	}
+	return base + livePath + "?key=" + url.QueryEscape(p.APIKey)
+}
+
+// setupMessage builds the session setup from p and cfg.
+func setupMessage(p llmkit.Provider, cfg Config) map[string]any {
+	model := p.Model
+	if model == "" {
+		model = defaultModel
+	}
+	modalities := cfg.Modalities
+	if len(modalities) == 0 {
+		modalities = []string{Text}
+	}
+
+	gen := map[string]any{"responseModalities": modalities}
+	if cfg.Voice != "" {
+		gen["speechConfig"] = map[string]any{
+			"voiceConfig": map[string]any{"prebuiltVoiceConfig": map[string]any{"voiceName": cfg.Voice}},
+		}
+	}
+	setup := map[string]any{
+		"model":            "models/" + strings.TrimPrefix(model, "models/"),
+		"generationConfig": gen,
+	}
+	if cfg.System != "" {
+		setup["systemInstruction"] = map[string]any{"parts": []map[string]any{{"text": cfg.System}}}
+	}
+	if len(cfg.Tools) > 0 {
+		decls := make([]map[string]any, len(cfg.Tools))
+		for i, t := range cfg.Tools {
+			decls[i] = map[string]any{"name": t.Name, "description": t.Description, "parameters": t.Schema}
+		}
+		setup["tools"] = []map[string]any{{"functionDeclarations": decls}}
+	}
+	if cfg.Transcribe {
+		setup["inputAudioTranscription"] = map[string]any{}
+		setup["outputAudioTranscription"] = map[string]any{}
+	}
+	return setup
+}
+
+// SendText adds a user turn. With turnComplete the model starts answering;
+// without it the text is queued as context for the next turn.
+func (s *Session) SendText(text string, turnComplete bool) error {
+	return s.send(map[string]any{"clientContent": map[string]any{
+		"turns":        []map[string]any{{"role": "user", "parts": []map[string]any{{"text": text}}}},
+		"turnComplete": turnComplete,
+	}})
+}
+
+// SendAudio streams a chunk of user audio (see InputAudioMIMEType). The server
+// detects the end of speech and starts the model's turn itself.
+func (s *Session) SendAudio(pcm []byte) error {
+	return s.send(map[string]any{"realtimeInput": map[string]any{
+		"audio": map[string]any{"data": base64.StdEncoding.EncodeToString(pcm), "mimeType": InputAudioMIMEType},
+	}})
+}
+
+// EndAudio tells the server the audio stream has paused, flushing buffered input.
+func (s *Session) EndAudio() error {
+	return s.send(map[string]any{"realtimeInput": map[string]any{"audioStreamEnd": true}})
+}
+
+// serverMessage is a Live API server message.
+type serverMessage struct {
+	ServerContent *struct {
+		ModelTurn *struct {
+			Parts []struct {
+				Text       string `json:"text"`
+				InlineData *struct {
+					MimeType string `json:"mimeType"`
+					Data     string `json:"data"`
+				} `json:"inlineData"`
+			} `json:"parts"`
+		} `json:"modelTurn"`
+		TurnComplete       bool `json:"turnComplete"`
+		Interrupted        bool `json:"interrupted"`
+		InputTranscription *struct {
+			Text string `json:"text"`
+		} `json:"inputTranscription"`
+		OutputTranscription *struct {
+			Text string `json:"text"`
+		} `json:"outputTranscription"`
+	} `json:"serverContent"`
+	ToolCall *struct {
+		FunctionCalls []struct {
+			ID   string         `json:"id"`
+			Name string         `json:"name"`
+			Args map[string]any `json:"args"`
+		} `json:"functionCalls"`
+	} `json:"toolCall"`
+	UsageMetadata *struct {
+		PromptTokenCount   int `json:"promptTokenCount"`
+		ResponseTokenCount int `json:"responseTokenCount"`
+		ThoughtsTokenCount int `json:"thoughtsTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// Receive returns the next event. Tool calls are run and answered before the
+// event reporting them is returned. A server close is reported as *CloseError.
+func (s *Session) Receive() (Event, error) {
+	for {
+		var msg serverMessage
+		if err := s.read(&msg); err != nil {
+			return Event{}, err
+		}
+
+		var ev Event
+		empty := true
+		if c := msg.ServerContent; c != nil {
+			if c.ModelTurn != nil {
+				for _, part := range c.ModelTurn.Parts {
+					ev.Text += part.Text
+					if d := part.InlineData; d != nil {
+						data, err := base64.StdEncoding.DecodeString(d.Data)
+						if err != nil {
+							return Event{}, fmt.Errorf("live: audio data: %w", err)
+						}
+						ev.Audio = append(ev.Audio, data...)
+						ev.AudioMIMEType = d.MimeType
+					}
+				}
+			}
+			if c.InputTranscription != nil {
+				ev.InputTranscript = c.InputTranscription.Text
+			}
+			if c.OutputTranscription != nil {
+				ev.OutputTranscript = c.OutputTranscription.Text
+			}
+			ev.TurnComplete = c.TurnComplete
+			ev.Interrupted = c.Interrupted
+			empty = false
+		}
+		if msg.ToolCall != nil {
+			for _, fc := range msg.ToolCall.FunctionCalls {
+				ev.ToolCalls = append(ev.ToolCalls, ToolCall{ID: fc.ID, Name: fc.Name, Args: fc.Args, Result: s.runTool(fc.Name, fc.Args)})
+			}
+			if err := s.sendToolResponses(ev.ToolCalls); err != nil {
+				return Event{}, err
+			}
+			empty = false
+		}
+		if u := msg.UsageMetadata; u != nil {
+			ev.Usage = &llmkit.Usage{Input: u.PromptTokenCount, Output: u.ResponseTokenCount, ReasoningTokens: u.ThoughtsTokenCount}
+			empty = false
+		}
+		if !empty {
+			return ev, nil
+		}
+	}
+}
+
+// runTool runs the named tool; failures are reported to the model as the result.
+func (s *Session) runTool(name string, args map[string]any) string {
+	for _, t := range s.tools {
+		if t.Name == name {
+			result, err := t.Run(args)
+			if err != nil {
+				return fmt.Sprintf("error: %v", err)
+			}
+			return result
+		}
+	}
+	return "error: unknown tool: " + name
+}
+
+// sendToolResponses answers the model's function calls.
+func (s *Session) sendToolResponses(calls []ToolCall) error {
+	responses := make([]map[string]any, len(calls))
+	for i, c := range calls {
+		responses[i] = map[string]any{"id": c.ID, "name": c.Name, "response": map[string]any{"result": c.Result}}
+	}
+	return s.send(map[string]any{"toolResponse": map[string]any{"functionResponses": responses}})
+}
+
+// Close ends the session.
+func (s *Session) Close() error {
+	return s.ws.close()
+}
+
+func (s *Session) send(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.ws.writeFrame(opText, data)
+}
+
+func (s *Session) read(v any) error {
+	data, err := s.ws.readMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,154 @@
+package live
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aktagon/llmkit"
+)
+
+// readJSON reads the next client message into a generic map.
+func readJSON(t *testing.T, c *wsConn) map[string]any {
+	t.Helper()
+	data, err := c.readMessage()
+	if err != nil {
+		t.Fatalf("server read: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("server decode %s: %v", data, err)
+	}
+	return m
+}
+
+func weatherTool() llmkit.Tool {
+	return llmkit.Tool{
+		Name:        "get_weather",
+		Description: "Current weather for a city",
+		Schema:      map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+		Run: func(args map[string]any) (string, error) {
+			return "sunny in " + args["city"].(string), nil
+		},
+	}
+}
+
+func TestSession_TextWithToolCall(t *testing.T) {
+	server := wsServer(t, func(c *wsConn, r *http.Request) {
+		if r.URL.Path != livePath || r.URL.Query().Get("key") != "test-key" {
+			t.Errorf("request = %s", r.URL)
+		}
+		setup := readJSON(t, c)["setup"].(map[string]any)
+		if setup["model"] != "models/gemini-live-2.5-flash-preview" {
+			t.Errorf("model = %v", setup["model"])
+		}
+		if setup["systemInstruction"] == nil || setup["tools"] == nil {
+			t.Errorf("setup = %v", setup)
+		}
+		c.writeFrame(opBinary, []byte(`{"setupComplete":{}}`))
+
+		content := readJSON(t, c)["clientContent"].(map[string]any)
+		if content["turnComplete"] != true {
+			t.Errorf("clientContent = %v", content)
+		}
+		c.writeFrame(opText, []byte(`{"toolCall":{"functionCalls":[{"id":"fc_1","name":"get_weather","args":{"city":"Oslo"}}]}}`))
+
+		resp := readJSON(t, c)["toolResponse"].(map[string]any)["functionResponses"].([]any)[0].(map[string]any)
+		if resp["id"] != "fc_1" || resp["response"].(map[string]any)["result"] != "sunny in Oslo" {
+			t.Errorf("toolResponse = %v", resp)
+		}
+		c.writeFrame(opText, []byte(`{"serverContent":{"modelTurn":{"parts":[{"text":"It is sunny in Oslo."}]}}}`))
+		c.writeFrame(opText, []byte(`{"serverContent":{"turnComplete":true},"usageMetadata":{"promptTokenCount":40,"responseTokenCount":9}}`))
+		c.readFrame() // close
+	})
+	defer server.Close()
+
+	p := llmkit.Provider{Name: llmkit.Google, APIKey: "test-key", BaseURL: server.URL}
+	s, err := Connect(context.Background(), p, Config{System: "Be brief.", Tools: []llmkit.Tool{weatherTool()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.SendText("Weather in Oslo?", true); err != nil {
+		t.Fatal(err)
+	}
+
+	ev, err := s.Receive()
+	if err != nil || len(ev.ToolCalls) != 1 || ev.ToolCalls[0].Result != "sunny in Oslo" {
+		t.Fatalf("tool event = %+v, %v", ev, err)
+	}
+	ev, err = s.Receive()
+	if err != nil || ev.Text != "It is sunny in Oslo." || ev.TurnComplete {
+		t.Fatalf("text event = %+v, %v", ev, err)
+	}
+	ev, err = s.Receive()
+	if err != nil || !ev.TurnComplete || ev.Usage == nil || ev.Usage.Input != 40 || ev.Usage.Output != 9 {
+		t.Fatalf("final event = %+v, %v", ev, err)
+	}
+}
+
+func TestSession_Audio(t *testing.T) {
+	pcm := []byte{1, 2, 3, 4}
+	server := wsServer(t, func(c *wsConn, _ *http.Request) {
+		gen := readJSON(t, c)["setup"].(map[string]any)["generationConfig"].(map[string]any)
+		if gen["responseModalities"].([]any)[0] != Audio || gen["speechConfig"] == nil {
+			t.Errorf("generationConfig = %v", gen)
+		}
+		c.writeFrame(opText, []byte(`{"setupComplete":{}}`))
+
+		audio := readJSON(t, c)["realtimeInput"].(map[string]any)["audio"].(map[string]any)
+		if audio["mimeType"] != InputAudioMIMEType || audio["data"] != base64.StdEncoding.EncodeToString(pcm) {
+			t.Errorf("audio = %v", audio)
+		}
+		c.writeFrame(opText, []byte(`{"serverContent":{"modelTurn":{"parts":[{"inlineData":{"mimeType":"audio/pcm;rate=24000","data":"BQYH"}}]},`+
+			`"outputTranscription":{"text":"Hi"}}}`))
+		c.writeFrame(opClose, []byte{0x03, 0xE8})
+		c.readFrame()
+	})
+	defer server.Close()
+
+	p := llmkit.Provider{Name: llmkit.Google, APIKey: "test-key", BaseURL: server.URL}
+	s, err := Connect(context.Background(), p, Config{Modalities: []string{Audio}, Voice: "Kore", Transcribe: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.SendAudio(pcm); err != nil {
+		t.Fatal(err)
+	}
+	ev, err := s.Receive()
+	if err != nil || string(ev.Audio) != "\x05\x06\x07" || ev.AudioMIMEType != "audio/pcm;rate=24000" || ev.OutputTranscript != "Hi" {
+		t.Fatalf("audio event = %+v, %v", ev, err)
+	}
+	_, err = s.Receive()
+	var ce *CloseError
+	if !errors.As(err, &ce) || ce.Code != 1000 {
+		t.Errorf("error = %v, want close 1000", err)
+	}
+}
+
+func TestConnect_Validation(t *testing.T) {
+	ctx := context.Background()
+	_, err := Connect(ctx, llmkit.Provider{Name: llmkit.OpenAI, APIKey: "k"}, Config{})
+	var ve *llmkit.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "provider" {
+		t.Errorf("error = %v", err)
+	}
+	_, err = Connect(ctx, llmkit.Provider{Name: llmkit.Google, APIKey: "k"}, Config{Modalities: []string{Text, Audio}})
+	if !errors.As(err, &ve) || ve.Field != "modalities" {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestLiveURL(t *testing.T) {
+	got := liveURL(llmkit.Provider{Name: llmkit.Google, APIKey: "a b"})
+	if !strings.HasPrefix(got, "wss://generativelanguage.googleapis.com/ws/") || !strings.HasSuffix(got, "?key=a+b") {
+		t.Errorf("liveURL() = %s", got)
+	}
+}
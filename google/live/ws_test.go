@@ -0,0 +1,107 @@
+package live
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// wsServer starts a WebSocket server that hands each accepted connection to serve.
+func wsServer(t *testing.T, serve func(c *wsConn, r *http.Request)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "expected websocket upgrade", http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		rw.Flush()
+		serve(&wsConn{conn: conn, r: bufio.NewReader(rw)}, r)
+	}))
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestWS_FragmentsAndPing(t *testing.T) {
+	long := strings.Repeat("x", 70000) // 64-bit length header
+	server := wsServer(t, func(c *wsConn, _ *http.Request) {
+		msg, err := c.readMessage()
+		if err != nil || string(msg) != long {
+			t.Errorf("server got %d bytes, %v", len(msg), err)
+			return
+		}
+		// Reply in two fragments with a ping in between
+		c.conn.Write([]byte{opText, 6})
+		c.conn.Write([]byte("hello "))
+		c.writeFrame(opPing, []byte("hb"))
+		c.conn.Write([]byte{0x80 | opContinuation, 5})
+		c.conn.Write([]byte("world"))
+		fin, op, payload, err := c.readFrame()
+		if err != nil || !fin || op != opPong || string(payload) != "hb" {
+			t.Errorf("pong = %v %#x %q %v", fin, op, payload, err)
+		}
+	})
+	defer server.Close()
+
+	c, err := dialWS(context.Background(), wsURL(server), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.close()
+
+	if err := c.writeFrame(opText, []byte(long)); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := c.readMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg) != "hello world" {
+		t.Errorf("message = %q, want %q", msg, "hello world")
+	}
+}
+
+func TestWS_Close(t *testing.T) {
+	server := wsServer(t, func(c *wsConn, _ *http.Request) {
+		c.writeFrame(opClose, append([]byte{0x03, 0xF0}, "quota exceeded"...)) // 1008
+		c.readFrame()
+	})
+	defer server.Close()
+
+	c, err := dialWS(context.Background(), wsURL(server), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.close()
+
+	_, err = c.readMessage()
+	var ce *CloseError
+	if !errors.As(err, &ce) || ce.Code != 1008 || ce.Reason != "quota exceeded" {
+		t.Errorf("error = %v, want close 1008", err)
+	}
+}
+
+func TestWS_HandshakeRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "API key not valid", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, err := dialWS(context.Background(), wsURL(server), nil)
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "API key not valid") {
+		t.Errorf("error = %v", err)
+	}
+}
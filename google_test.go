@@ -2,8 +2,12 @@ package llmkit
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -112,3 +116,150 @@ func TestBuildGoogleParts(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildGoogleParts_InlineFile(t *testing.T) {
+	req := Request{Files: []File{{MimeType: "application/pdf", Data: []byte("%PDF-1.4")}}}
+
+	parts := buildGoogleParts(req)
+	if len(parts) != 1 || parts[0].InlineData == nil {
+		t.Fatalf("expected one inline_data part, got %+v", parts)
+	}
+	if parts[0].InlineData.MimeType != "application/pdf" || parts[0].InlineData.Data != "JVBERi0xLjQ=" {
+		t.Errorf("InlineData = %+v", parts[0].InlineData)
+	}
+	if parts[0].FileData != nil {
+		t.Error("expected no file_data for inline file")
+	}
+}
+
+func TestPromptGoogle_ContentBlocked(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantReason string
+		wantCats   []string
+	}{
+		{
+			name:       "prompt blocked",
+			body:       `{"promptFeedback":{"blockReason":"SAFETY","safetyRatings":[{"category":"HARM_CATEGORY_DANGEROUS_CONTENT","probability":"HIGH"},{"category":"HARM_CATEGORY_HARASSMENT","probability":"NEGLIGIBLE"}]}}`,
+			wantReason: "SAFETY",
+			wantCats:   []string{"HARM_CATEGORY_DANGEROUS_CONTENT"},
+		},
+		{
+			name:       "no candidates",
+			body:       `{"candidates":[]}`,
+			wantReason: "NO_CANDIDATES",
+		},
+		{
+			name:       "recitation finish reason",
+			body:       `{"candidates":[{"content":{"parts":[]},"finishReason":"RECITATION"}]}`,
+			wantReason: "RECITATION",
+		},
+		{
+			name:       "image safety finish reason",
+			body:       `{"candidates":[{"content":{"parts":[{"text":""}]},"finishReason":"IMAGE_SAFETY"}]}`,
+			wantReason: "IMAGE_SAFETY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+			_, err := Prompt(context.Background(), p, Request{User: "Hello"})
+
+			var blocked *ContentBlockedError
+			if !errors.As(err, &blocked) {
+				t.Fatalf("expected ContentBlockedError, got %v", err)
+			}
+			if blocked.Reason != tt.wantReason {
+				t.Errorf("Reason = %q, want %q", blocked.Reason, tt.wantReason)
+			}
+			if strings.Join(blocked.Categories, ",") != strings.Join(tt.wantCats, ",") {
+				t.Errorf("Categories = %v, want %v", blocked.Categories, tt.wantCats)
+			}
+		})
+	}
+}
+
+func TestPromptStream_Google(t *testing.T) {
+	var gotPath, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}]}` + "\n\n"))
+		w.Write([]byte(`data: {"candidates":[{"content":{"role":"model","parts":[{"text":" world"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":2}}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", Model: "gemini-test", BaseURL: server.URL}
+	var deltas []string
+
+	resp, err := PromptStream(context.Background(), p, Request{User: "Hi"}, func(d string) {
+		deltas = append(deltas, d)
+	})
+	if err != nil {
+		t.Fatalf("PromptStream() error = %v", err)
+	}
+	if gotPath != "/v1beta/models/gemini-test:streamGenerateContent" || gotQuery != "alt=sse&key=test-key" {
+		t.Errorf("request = %s?%s", gotPath, gotQuery)
+	}
+	if strings.Join(deltas, "|") != "Hello| world" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if resp.Text != "Hello world" {
+		t.Errorf("Text = %q, want Hello world", resp.Text)
+	}
+	if resp.Tokens.Input != 4 || resp.Tokens.Output != 2 {
+		t.Errorf("Tokens = %+v, want 4 in / 2 out", resp.Tokens)
+	}
+	if resp.FinishReason != FinishStop {
+		t.Errorf("FinishReason = %q, want stop", resp.FinishReason)
+	}
+}
+
+func TestStreamGoogle_FunctionCall(t *testing.T) {
+	server := sseServer(t,
+		`data: {"candidates":[{"content":{"parts":[{"text":"Checking."}]}}]}`,
+		`data: {"candidates":[{"content":{"parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":8,"candidatesTokenCount":3}}`,
+	)
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	o := applyOptions()
+	o.stream = func(string) {}
+
+	got, err := sendGoogleWithTools(context.Background(), p, []message{{role: "user", content: "Weather?"}}, "", []Tool{testWeatherTool()}, o)
+	if err != nil {
+		t.Fatalf("sendGoogleWithTools() error = %v", err)
+	}
+	if got.text != "Checking." {
+		t.Errorf("text = %q", got.text)
+	}
+	if len(got.calls) != 1 || got.calls[0].input["city"] != "Paris" {
+		t.Errorf("calls = %+v", got.calls)
+	}
+	if got.usage.Input != 8 {
+		t.Errorf("usage = %+v", got.usage)
+	}
+	if got.finishReason != FinishToolUse {
+		t.Errorf("finishReason = %q, want tool_use", got.finishReason)
+	}
+}
+
+func TestGoogleResponse_UsageDetails(t *testing.T) {
+	var resp googleResponse
+	data := `{"usageMetadata":{"promptTokenCount":20,"candidatesTokenCount":8,"cachedContentTokenCount":16,"thoughtsTokenCount":120}}`
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Usage{Input: 20, Output: 8, CacheReadTokens: 16, ReasoningTokens: 120}
+	if got := resp.usage(); got != want {
+		t.Errorf("usage() = %+v, want %+v", got, want)
+	}
+}
@@ -20,6 +20,7 @@ type grokResponsesRequest struct {
 	ResponseFormat *grokResponseFormat  `json:"response_format,omitempty"`
 	Temperature    *float64             `json:"temperature,omitempty"`
 	MaxTokens      *int                 `json:"max_output_tokens,omitempty"`
+	Seed           *int64               `json:"seed,omitempty"`
 }
 
 type grokResponseFormat struct {
@@ -52,9 +53,19 @@ type grokResponsesResponse struct {
 			Text string `json:"text"`
 		} `json:"content"`
 	} `json:"output"`
+	Status            string `json:"status"` // "completed" or "incomplete"
+	IncompleteDetails *struct {
+		Reason string `json:"reason"`
+	} `json:"incomplete_details"`
 	Usage struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
+		InputTokens        int `json:"input_tokens"`
+		OutputTokens       int `json:"output_tokens"`
+		InputTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"input_tokens_details"`
+		OutputTokensDetails struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"output_tokens_details"`
 	} `json:"usage"`
 }
 
@@ -81,6 +92,9 @@ func promptGrok(ctx context.Context, p Provider, req Request, o *options) (Respo
 			// Mixed content with files
 			var parts []grokContentPart
 			for _, f := range req.Files {
+				if f.inline() {
+					return Response{}, &ValidationError{Field: "files", Message: "inline data not supported by " + Grok + ", use UploadFile"}
+				}
 				parts = append(parts, grokContentPart{
 					Type:   "file",
 					FileID: f.ID,
@@ -104,11 +118,12 @@ func promptGrok(ctx context.Context, p Provider, req Request, o *options) (Respo
 		Input:       input,
 		Temperature: o.temperature,
 		MaxTokens:   o.maxTokens,
+		Seed:        o.seed,
 	}
 
 	if req.Schema != "" {
-		var schema any
-		if err := json.Unmarshal([]byte(req.Schema), &schema); err != nil {
+		schema, _, err := TranslateSchema(Grok, req.Schema)
+		if err != nil {
 			return Response{}, err
 		}
 		payload.ResponseFormat = &grokResponseFormat{
@@ -126,17 +141,17 @@ func promptGrok(ctx context.Context, p Provider, req Request, o *options) (Respo
 		return Response{}, err
 	}
 
-	headers := map[string]string{
+	headers := p.withHeaders(map[string]string{
 		"Authorization": "Bearer " + p.APIKey,
-	}
+	})
 
-	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, p.buildURL(grokResponsesPath), body, headers)
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, p.buildURL(grokResponsesPath), body, headers)
 	if err != nil {
 		return Response{}, err
 	}
 
 	if statusCode >= 400 {
-		return Response{}, parseError(Grok, statusCode, respBody, nil)
+		return Response{}, parseError(Grok, statusCode, respBody, respHeaders)
 	}
 
 	var resp grokResponsesResponse
@@ -153,12 +168,22 @@ func promptGrok(ctx context.Context, p Provider, req Request, o *options) (Respo
 	return Response{
 		Text: text,
 		Tokens: Usage{
-			Input:  resp.Usage.InputTokens,
-			Output: resp.Usage.OutputTokens,
+			Input:           resp.Usage.InputTokens,
+			Output:          resp.Usage.OutputTokens,
+			CacheReadTokens: resp.Usage.InputTokensDetails.CachedTokens,
+			ReasoningTokens: resp.Usage.OutputTokensDetails.ReasoningTokens,
 		},
+		FinishReason: resp.finishReason(),
 	}, nil
 }
 
+func (r grokResponsesResponse) finishReason() FinishReason {
+	if r.IncompleteDetails != nil {
+		return responsesFinishReason(r.Status, r.IncompleteDetails.Reason)
+	}
+	return responsesFinishReason(r.Status, "")
+}
+
 type grokFileResponse struct {
 	ID       string `json:"id"`
 	Filename string `json:"filename"`
@@ -166,9 +191,9 @@ type grokFileResponse struct {
 
 // uploadGrok uploads a file to Grok's Files API.
 func uploadGrok(ctx context.Context, p Provider, data []byte, name string, o *options) (File, error) {
-	headers := map[string]string{
+	headers := p.withHeaders(map[string]string{
 		"Authorization": "Bearer " + p.APIKey,
-	}
+	})
 	fields := map[string]string{
 		"purpose": "assistants",
 	}
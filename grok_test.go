@@ -3,6 +3,7 @@ package llmkit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -107,6 +108,7 @@ func TestPromptGrok_ResponsesAPI_TextOnly(t *testing.T) {
 		w.Write([]byte(`{
 			"id": "resp_456",
 			"output": [{"type": "message", "content": [{"type": "output_text", "text": "Hello!"}]}],
+			"status": "incomplete", "incomplete_details": {"reason": "max_output_tokens"},
 			"usage": {"input_tokens": 10, "output_tokens": 5}
 		}`))
 	}))
@@ -123,10 +125,13 @@ func TestPromptGrok_ResponsesAPI_TextOnly(t *testing.T) {
 		User:   "Say hello",
 	}
 
-	_, err := Prompt(context.Background(), p, req)
+	resp, err := Prompt(context.Background(), p, req)
 	if err != nil {
 		t.Fatalf("Prompt() error = %v", err)
 	}
+	if resp.FinishReason != FinishLength {
+		t.Errorf("FinishReason = %q, want length", resp.FinishReason)
+	}
 
 	// Always use Responses API (xAI's preferred endpoint)
 	if capturedPath != "/v1/responses" {
@@ -164,3 +169,44 @@ func TestPromptGrok_ResponsesAPI_TextOnly(t *testing.T) {
 		t.Errorf("user content = %q, want 'Say hello'", userMsg["content"])
 	}
 }
+
+func TestPromptGrok_Seed(t *testing.T) {
+	var capturedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedBody, _ = io.ReadAll(r.Body)
+		w.Write([]byte(`{"output":[{"type":"message","content":[{"type":"output_text","text":"hi"}]}],"usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Grok, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := Prompt(context.Background(), p, Request{User: "Say hello"}, WithSeed(42))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	var body struct {
+		Seed *int64 `json:"seed"`
+	}
+	if err := json.Unmarshal(capturedBody, &body); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	if body.Seed == nil || *body.Seed != 42 {
+		t.Errorf("request seed = %v, want 42: %s", body.Seed, capturedBody)
+	}
+	if resp.Seed == nil || *resp.Seed != 42 {
+		t.Errorf("Response.Seed = %v, want 42", resp.Seed)
+	}
+}
+
+func TestPromptGrok_InlineFileRejected(t *testing.T) {
+	p := Provider{Name: Grok, APIKey: "test-key", BaseURL: "http://127.0.0.1:0"}
+	req := Request{
+		User:  "Summarize this PDF",
+		Files: []File{{MimeType: "application/pdf", Data: []byte("%PDF-1.4")}},
+	}
+
+	_, err := Prompt(context.Background(), p, req)
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Field != "files" {
+		t.Fatalf("expected files ValidationError, got %v", err)
+	}
+}
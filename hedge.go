@@ -0,0 +1,79 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithHedging sends a backup request to the next provider when no response has
+// arrived within delay, and returns the first successful response; the slower
+// requests are cancelled. A failed attempt starts the next backup immediately.
+// Backups use the same request and options, except WithModel, which applies
+// to the primary provider only. Not supported by PromptStream.
+func WithHedging(delay time.Duration, backups ...Provider) Option {
+	return func(o *options) {
+		o.hedgeDelay = delay
+		o.hedgeProviders = backups
+	}
+}
+
+type hedgeResult struct {
+	resp Response
+	err  error
+}
+
+// promptHedged races p against the hedge providers.
+func promptHedged(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
+	if o.stream != nil {
+		return Response{}, &ValidationError{Field: "hedging", Message: "not supported with streaming"}
+	}
+
+	attempts := append([]Provider{p}, o.hedgeProviders...)
+	single := *o
+	single.hedgeProviders = nil
+	single.model = "" // the override applies to p only; backups keep their own models
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // stops the losers
+
+	results := make(chan hedgeResult, len(attempts))
+	launch := func(p Provider) {
+		go func() {
+			resp, err := prompt(ctx, p, req, &single)
+			results <- hedgeResult{resp, err}
+		}()
+	}
+
+	launch(attempts[0])
+	next, running := 1, 1
+	timeout := o.clock.After(o.hedgeDelay)
+
+	var errs []error
+	for running > 0 {
+		select {
+		case r := <-results:
+			running--
+			if r.err == nil {
+				return r.resp, nil
+			}
+			errs = append(errs, r.err)
+			if next < len(attempts) {
+				launch(attempts[next])
+				next++
+				running++
+				timeout = o.clock.After(o.hedgeDelay)
+			}
+		case <-timeout:
+			if next < len(attempts) {
+				launch(attempts[next])
+				next++
+				running++
+				timeout = o.clock.After(o.hedgeDelay)
+			}
+		case <-ctx.Done():
+			return Response{}, ctx.Err()
+		}
+	}
+	return Response{}, errors.Join(errs...)
+}
@@ -0,0 +1,120 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithHedging_BackupWins(t *testing.T) {
+	cancelled := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body) // the server notices a closed connection only after the body is read
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"backup"}}]}`))
+	}))
+	defer fast.Close()
+
+	primary := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: slow.URL}
+	backup := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: fast.URL}
+
+	start := time.Now()
+	resp, err := Prompt(context.Background(), primary, Request{User: "Hi"}, WithHedging(20*time.Millisecond, backup))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.Text != "backup" {
+		t.Errorf("Text = %q, want backup", resp.Text)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("hedged request waited for the slow provider")
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("slow request was not cancelled")
+	}
+}
+
+func TestWithHedging_FailureStartsBackupImmediately(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":{"message":"down"}}`))
+	}))
+	defer failing.Close()
+
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer ok.Close()
+
+	primary := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: failing.URL}
+	backup := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: ok.URL}
+
+	start := time.Now()
+	resp, err := Prompt(context.Background(), primary, Request{User: "Hi"}, WithHedging(time.Hour, backup))
+	if err != nil || resp.Text != "ok" {
+		t.Fatalf("Prompt() = %q, %v", resp.Text, err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("backup waited for the hedge delay after a failure")
+	}
+
+	_, err = Prompt(context.Background(), primary, Request{User: "Hi"}, WithHedging(time.Millisecond, primary))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("expected APIError when every attempt fails, got %v", err)
+	}
+}
+
+func TestWithHedging_SharesRequestID(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":{"message":"down"}}`))
+	}))
+	defer failing.Close()
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer ok.Close()
+
+	var mu sync.Mutex
+	var ids []string
+	record := WithBeforeRequest(func(ctx context.Context, req *Request) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, RequestIDFromContext(ctx))
+		return nil
+	})
+	primary := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: failing.URL}
+	backup := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: ok.URL}
+
+	resp, err := Prompt(context.Background(), primary, Request{User: "Hi"}, WithHedging(time.Hour, backup), record)
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] || resp.RequestID != ids[0] {
+		t.Errorf("attempt IDs = %v, Response.RequestID = %q, want one shared ID", ids, resp.RequestID)
+	}
+
+	ids = nil
+	_, err = Prompt(context.Background(), primary, Request{User: "Hi"}, WithHedging(time.Hour, primary), record)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || len(ids) != 2 || ids[0] != ids[1] || apiErr.RequestID != ids[0] {
+		t.Errorf("attempt IDs = %v, error = %v", ids, err)
+	}
+}
@@ -0,0 +1,270 @@
+package llmkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"unicode/utf8"
+)
+
+// redacted replaces text removed by Agent.Redact.
+const redacted = "[REDACTED]"
+
+// Redact replaces every match of pattern in the stored conversation with "[REDACTED]",
+// including tool call arguments and tool results. Messages are edited in place,
+// so tool calls stay paired with their results. Returns the number of messages changed.
+func (a *Agent) Redact(pattern *regexp.Regexp) int {
+	changed := 0
+	for i := range a.history {
+		if redactMessage(&a.history[i], pattern) {
+			changed++
+		}
+	}
+	return changed
+}
+
+// TrimTo drops the oldest turns until the estimated size of the conversation fits
+// within tokenBudget. History is only cut before a user message, never between a
+// tool call and its result, and the latest turn is always kept. Returns the number
+// of messages removed.
+func (a *Agent) TrimTo(tokenBudget int) int {
+	total := 0
+	for _, m := range a.history {
+		total += messageTokens(m)
+	}
+
+	cut, dropped := -1, 0
+	for i, m := range a.history {
+		if isTurnStart(m) {
+			cut = i
+			if total-dropped <= tokenBudget {
+				break
+			}
+		}
+		dropped += messageTokens(m)
+	}
+	if cut <= 0 {
+		return 0
+	}
+
+	a.history = append([]message(nil), a.history[cut:]...)
+	return cut
+}
+
+// isTurnStart reports whether m is a user message rather than a tool result.
+func isTurnStart(m message) bool {
+	return m.role == "user" && m.toolResult == nil
+}
+
+// messageTokens estimates the tokens a stored message occupies (~4 characters per token).
+func messageTokens(m message) int {
+	n := utf8.RuneCountInString(m.content)
+	for _, tc := range m.toolCalls {
+		args, _ := json.Marshal(tc.input)
+		n += len(tc.name) + len(args)
+	}
+	if m.toolResult != nil {
+		n += utf8.RuneCountInString(m.toolResult.content)
+	}
+	return (n + 3) / 4
+}
+
+// redactMessage redacts a message in place and reports whether it changed.
+func redactMessage(m *message, pattern *regexp.Regexp) bool {
+	changed := false
+	redact := func(s string) string {
+		out := pattern.ReplaceAllString(s, redacted)
+		if out != s {
+			changed = true
+		}
+		return out
+	}
+
+	m.content = redact(m.content)
+	for i := range m.toolCalls {
+		m.toolCalls[i].input = redactArgs(m.toolCalls[i].input, redact)
+	}
+	for i, part := range m.parts {
+		m.parts[i].text = redact(part.text)
+		if part.call != nil {
+			call := *part.call
+			call.input = redactArgs(call.input, redact)
+			m.parts[i].call = &call
+		}
+	}
+	if m.toolResult != nil {
+		result := *m.toolResult
+		result.content = redact(result.content)
+		m.toolResult = &result
+	}
+	return changed
+}
+
+// redactArgs returns a copy of tool call arguments with string values redacted.
+func redactArgs(args map[string]any, redact func(string) string) map[string]any {
+	if args == nil {
+		return nil
+	}
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		out[k] = redactValue(v, redact)
+	}
+	return out
+}
+
+func redactValue(v any, redact func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return redact(v)
+	case map[string]any:
+		return redactArgs(v, redact)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = redactValue(item, redact)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// historyVersion is the SaveHistory file format version.
+const historyVersion = 1
+
+// historyDocument is the JSON form of an agent's conversation.
+type historyDocument struct {
+	Version  int              `json:"version"`
+	Messages []historyMessage `json:"messages"`
+}
+
+type historyMessage struct {
+	Role       string             `json:"role"`
+	Content    string             `json:"content,omitempty"`
+	Images     []historyImage     `json:"images,omitempty"`
+	Files      []historyFile      `json:"files,omitempty"`
+	ToolCalls  []historyToolCall  `json:"tool_calls,omitempty"`
+	ToolResult *historyToolResult `json:"tool_result,omitempty"`
+	Parts      []historyPart      `json:"parts,omitempty"`
+}
+
+type historyImage struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type historyFile struct {
+	ID       string `json:"id,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Data     []byte `json:"data,omitempty"` // base64
+}
+
+type historyToolCall struct {
+	ID    string         `json:"id"`
+	Name  string         `json:"name"`
+	Input map[string]any `json:"input,omitempty"`
+}
+
+type historyToolResult struct {
+	ToolUseID string `json:"tool_use_id"`
+	Content   string `json:"content"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+type historyPart struct {
+	Text string           `json:"text,omitempty"`
+	Call *historyToolCall `json:"call,omitempty"`
+}
+
+// SaveHistory writes the conversation, including tool calls and their results,
+// to path as JSON so it can be restored with LoadHistory after a restart. The
+// system prompt and tools are not saved; they come from the code that creates
+// the agent. The file is replaced atomically and readable only by its owner.
+func (a *Agent) SaveHistory(path string) error {
+	doc := historyDocument{Version: historyVersion, Messages: make([]historyMessage, len(a.history))}
+	for i, m := range a.history {
+		doc.Messages[i] = encodeMessage(m)
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, out, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadHistory replaces the conversation with one saved by SaveHistory.
+func (a *Agent) LoadHistory(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc historyDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("history %s: %w", path, err)
+	}
+	if doc.Version != historyVersion {
+		return fmt.Errorf("history %s: unsupported version %d", path, doc.Version)
+	}
+	history := make([]message, len(doc.Messages))
+	for i, m := range doc.Messages {
+		history[i] = decodeMessage(m)
+	}
+	a.history = history
+	return nil
+}
+
+func encodeMessage(m message) historyMessage {
+	out := historyMessage{Role: m.role, Content: m.content}
+	for _, img := range m.images {
+		out.Images = append(out.Images, historyImage{URL: img.URL, MimeType: img.MimeType, Detail: img.Detail})
+	}
+	for _, f := range m.files {
+		out.Files = append(out.Files, historyFile{ID: f.ID, URI: f.URI, MimeType: f.MimeType, Name: f.Name, Data: f.Data})
+	}
+	for _, tc := range m.toolCalls {
+		out.ToolCalls = append(out.ToolCalls, historyToolCall{ID: tc.id, Name: tc.name, Input: tc.input})
+	}
+	if r := m.toolResult; r != nil {
+		out.ToolResult = &historyToolResult{ToolUseID: r.toolUseID, Content: r.content, IsError: r.isError}
+	}
+	for _, part := range m.parts {
+		p := historyPart{Text: part.text}
+		if c := part.call; c != nil {
+			p.Call = &historyToolCall{ID: c.id, Name: c.name, Input: c.input}
+		}
+		out.Parts = append(out.Parts, p)
+	}
+	return out
+}
+
+func decodeMessage(m historyMessage) message {
+	out := message{role: m.Role, content: m.Content}
+	for _, img := range m.Images {
+		out.images = append(out.images, Image{URL: img.URL, MimeType: img.MimeType, Detail: img.Detail})
+	}
+	for _, f := range m.Files {
+		out.files = append(out.files, File{ID: f.ID, URI: f.URI, MimeType: f.MimeType, Name: f.Name, Data: f.Data})
+	}
+	for _, tc := range m.ToolCalls {
+		out.toolCalls = append(out.toolCalls, toolCall{id: tc.ID, name: tc.Name, input: tc.Input})
+	}
+	if r := m.ToolResult; r != nil {
+		out.toolResult = &toolResult{toolUseID: r.ToolUseID, content: r.Content, isError: r.IsError}
+	}
+	for _, part := range m.Parts {
+		p := messagePart{text: part.Text}
+		if c := part.Call; c != nil {
+			p.call = &toolCall{id: c.ID, name: c.Name, input: c.Input}
+		}
+		out.parts = append(out.parts, p)
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package llmkit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func testHistory() []message {
+	call := toolCall{id: "call_1", name: "lookup", input: map[string]any{"email": "ann@example.com"}}
+	return []message{
+		{role: "user", content: "My email is ann@example.com"},
+		{role: "assistant", content: "Looking up", toolCalls: []toolCall{call}, parts: []messagePart{{text: "Looking up"}, {call: &call}}},
+		{role: "user", toolResult: &toolResult{toolUseID: "call_1", content: "found ann@example.com"}},
+		{role: "assistant", content: "Found you."},
+		{role: "user", content: strings.Repeat("x", 400)},
+		{role: "assistant", content: "ok"},
+	}
+}
+
+func TestAgent_Redact(t *testing.T) {
+	agent := NewAgent(Provider{Name: OpenAI, APIKey: "test-key"})
+	agent.history = testHistory()
+
+	n := agent.Redact(regexp.MustCompile(`[\w.]+@[\w.]+`))
+	if n != 3 {
+		t.Errorf("Redact() = %d, want 3", n)
+	}
+
+	h := agent.history
+	if h[0].content != "My email is [REDACTED]" {
+		t.Errorf("user content = %q", h[0].content)
+	}
+	if h[1].toolCalls[0].input["email"] != redacted || h[1].parts[1].call.input["email"] != redacted {
+		t.Errorf("tool call input not redacted: %+v", h[1])
+	}
+	if h[1].toolCalls[0].id != "call_1" || h[2].toolResult.toolUseID != "call_1" {
+		t.Error("tool call pairing changed")
+	}
+	if h[2].toolResult.content != "found [REDACTED]" {
+		t.Errorf("tool result = %q", h[2].toolResult.content)
+	}
+}
+
+func TestAgent_TrimTo(t *testing.T) {
+	tests := []struct {
+		name        string
+		budget      int
+		wantRemoved int
+	}{
+		{"fits", 1000, 0},
+		{"drops first turn with its tool exchange", 110, 4},
+		{"keeps latest turn over budget", 10, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := NewAgent(Provider{Name: OpenAI, APIKey: "test-key"})
+			agent.history = testHistory()
+
+			if got := agent.TrimTo(tt.budget); got != tt.wantRemoved {
+				t.Errorf("TrimTo() = %d, want %d", got, tt.wantRemoved)
+			}
+			if first := agent.history[0]; !isTurnStart(first) {
+				t.Errorf("history starts with %+v", first)
+			}
+		})
+	}
+}
+
+func TestAgent_SaveLoadHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	saved := NewAgent(Provider{Name: OpenAI, APIKey: "test-key"})
+	saved.history = testHistory()
+	saved.history[0].images = []Image{{URL: "https://example.com/a.png", Detail: "low"}}
+	saved.history[0].files = []File{{MimeType: "audio/wav", Name: "call.wav", Data: []byte("RIFF")}}
+	if err := saved.SaveHistory(path); err != nil {
+		t.Fatalf("SaveHistory() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{`"url": "https://example.com/a.png"`, `"mime_type": "audio/wav"`, `"data": "UklGRg=="`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("history file lacks %s:\n%s", want, data)
+		}
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("history file mode = %v, %v", info.Mode(), err)
+	}
+
+	loaded := NewAgent(Provider{Name: OpenAI, APIKey: "test-key"})
+	if err := loaded.LoadHistory(path); err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.history, saved.history) {
+		t.Errorf("history = %+v\nwant %+v", loaded.history, saved.history)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version": 9, "messages": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.LoadHistory(path); err == nil || !strings.Contains(err.Error(), "version 9") {
+		t.Errorf("LoadHistory(v9) error = %v", err)
+	}
+	if len(loaded.history) != len(saved.history) {
+		t.Error("failed load replaced the history")
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"net/textproto"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // doPost sends a POST request and returns the response body.
@@ -31,9 +33,15 @@ func doPost(ctx context.Context, client *http.Client, url string, body []byte, h
 
 // doPostRaw sends a POST request and returns status code and body without error handling.
 func doPostRaw(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) ([]byte, int, error) {
+	data, statusCode, _, err := doPostWithHeaders(ctx, client, url, body, headers)
+	return data, statusCode, err
+}
+
+// doPostWithHeaders is doPostRaw that also returns the response headers.
+func doPostWithHeaders(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) ([]byte, int, http.Header, error) {
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -43,22 +51,91 @@ func doPostRaw(ctx context.Context, client *http.Client, url string, body []byte
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, nil, err
 	}
 	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, resp.StatusCode, err
+		return nil, resp.StatusCode, resp.Header, err
+	}
+
+	return data, resp.StatusCode, resp.Header, nil
+}
+
+// doGet sends a GET request and returns the status code, headers and body.
+func doGet(ctx context.Context, client *http.Client, url string, headers map[string]string) ([]byte, int, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, nil, err
 	}
+	defer resp.Body.Close()
 
-	return data, resp.StatusCode, nil
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, resp.Header, err
+	}
+	return data, resp.StatusCode, resp.Header, nil
+}
+
+// doPostStream sends a POST request and returns the open response for streaming.
+// For status codes >= 400 the body is read, closed and returned as data.
+// Otherwise the caller must close the response body when done.
+func doPostStream(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		return resp, data, nil
+	}
+
+	return resp, nil, nil
+}
+
+// multipartFile is one file part of a multipart request.
+type multipartFile struct {
+	field    string
+	filename string
+	data     []byte
 }
 
 // doMultipartPost sends a multipart POST request for file uploads.
 // Sets Content-Type based on filename extension.
 func doMultipartPost(ctx context.Context, client *http.Client, url string,
 	fieldName, filename string, data []byte, fields map[string]string, headers map[string]string) ([]byte, int, error) {
+	files := []multipartFile{{field: fieldName, filename: filename, data: data}}
+	return doMultipartFiles(ctx, client, url, files, fields, headers)
+}
+
+// doMultipartFiles sends a multipart POST request with several file parts.
+func doMultipartFiles(ctx context.Context, client *http.Client, url string,
+	files []multipartFile, fields map[string]string, headers map[string]string) ([]byte, int, error) {
 
 	var buf bytes.Buffer
 	w := multipart.NewWriter(&buf)
@@ -70,17 +147,18 @@ func doMultipartPost(ctx context.Context, client *http.Client, url string,
 		}
 	}
 
-	// Add file with proper MIME type from filename
-	mimeType := detectMimeType(filename)
-	h := make(textproto.MIMEHeader)
-	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, fieldName, filename))
-	h.Set("Content-Type", mimeType)
-	fw, err := w.CreatePart(h)
-	if err != nil {
-		return nil, 0, err
-	}
-	if _, err := fw.Write(data); err != nil {
-		return nil, 0, err
+	// Add files with proper MIME type from filename
+	for _, f := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, f.field, f.filename))
+		h.Set("Content-Type", detectMimeType(f.filename))
+		fw, err := w.CreatePart(h)
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return nil, 0, err
+		}
 	}
 
 	if err := w.Close(); err != nil {
@@ -111,6 +189,18 @@ func doMultipartPost(ctx context.Context, client *http.Client, url string,
 	return respData, resp.StatusCode, nil
 }
 
+// headerInt parses an integer header, returning 0 when absent or invalid.
+func headerInt(h http.Header, key string) int {
+	n, _ := strconv.Atoi(h.Get(key))
+	return n
+}
+
+// headerTime parses an RFC 3339 timestamp header, returning the zero time when absent or invalid.
+func headerTime(h http.Header, key string) time.Time {
+	t, _ := time.Parse(time.RFC3339, h.Get(key))
+	return t
+}
+
 // detectMimeType returns MIME type based on file extension.
 func detectMimeType(path string) string {
 	ext := strings.ToLower(filepath.Ext(path))
@@ -133,6 +223,18 @@ func detectMimeType(path string) string {
 		return "image/gif"
 	case ".webp":
 		return "image/webp"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".wav":
+		return "audio/wav"
+	case ".m4a":
+		return "audio/mp4"
+	case ".ogg":
+		return "audio/ogg"
+	case ".flac":
+		return "audio/flac"
+	case ".webm":
+		return "audio/webm"
 	default:
 		return "application/octet-stream"
 	}
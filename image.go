@@ -0,0 +1,127 @@
+package llmkit
+
+import (
+	"context"
+	"strings"
+)
+
+// Default image models per provider, used when ImageRequest.Model is empty.
+var defaultImageModels = map[string]string{
+	OpenAI: "gpt-image-1",
+	Google: "imagen-4.0-generate-001",
+}
+
+// ImageRequest describes an image to generate or edit.
+type ImageRequest struct {
+	Prompt  string
+	Model   string // optional, uses the provider's default image model
+	Size    string // OpenAI: "1024x1024" (default), "1536x1024", "1024x1536", "auto"
+	Quality string // OpenAI: "low", "medium", "high", "auto" (dall-e-3: "standard", "hd")
+	Format  string // OpenAI gpt-image models: "png" (default), "jpeg", "webp"
+	N       int    // number of images, default 1
+	Images  []File // source images for EditImage (see InlineFile)
+	Mask    *File  // optional EditImage mask; transparent areas are edited
+
+	// Google Imagen parameters
+	AspectRatio      string // "1:1" (default), "3:4", "4:3", "9:16", "16:9"
+	PersonGeneration string // "dont_allow", "allow_adult", "allow_all"
+	SafetySetting    string // e.g. "block_medium_and_above", "block_low_and_above"
+}
+
+// ImageResponse contains generated images.
+type ImageResponse struct {
+	Images []GeneratedImage
+	Tokens Usage
+}
+
+// GeneratedImage is one decoded output image.
+type GeneratedImage struct {
+	Data          []byte
+	MimeType      string
+	RevisedPrompt string // dall-e-3 only
+}
+
+// GenerateImage creates images from a text prompt. OpenAI and Google (Imagen) only.
+func GenerateImage(ctx context.Context, p Provider, req ImageRequest, opts ...Option) (ImageResponse, error) {
+	if err := validateImageRequest(p, &req); err != nil {
+		return ImageResponse{}, err
+	}
+	o := applyOptions(opts...)
+	return withPoolKey(ctx, p, o, func(p Provider) (ImageResponse, error) {
+		if p.Name == Google {
+			return generateImageGoogle(ctx, p, req, o)
+		}
+		return generateImageOpenAI(ctx, p, req, o)
+	})
+}
+
+// EditImage changes or extends req.Images according to the prompt. OpenAI only.
+// Pass a Mask to limit the edit to its transparent areas.
+func EditImage(ctx context.Context, p Provider, req ImageRequest, opts ...Option) (ImageResponse, error) {
+	if err := validateImageRequest(p, &req); err != nil {
+		return ImageResponse{}, err
+	}
+	if p.Name != OpenAI {
+		return ImageResponse{}, &ValidationError{Field: "provider", Message: "image editing not supported by " + p.Name}
+	}
+	if len(req.Images) == 0 {
+		return ImageResponse{}, &ValidationError{Field: "images", Message: "required"}
+	}
+	for _, f := range req.Images {
+		if len(f.Data) == 0 {
+			return ImageResponse{}, &ValidationError{Field: "images", Message: "must contain inline data"}
+		}
+	}
+	if req.Mask != nil && len(req.Mask.Data) == 0 {
+		return ImageResponse{}, &ValidationError{Field: "mask", Message: "must contain inline data"}
+	}
+	o := applyOptions(opts...)
+	return withPoolKey(ctx, p, o, func(p Provider) (ImageResponse, error) {
+		return editImageOpenAI(ctx, p, req, o)
+	})
+}
+
+// validateImageRequest checks common fields and fills in defaults.
+func validateImageRequest(p Provider, req *ImageRequest) error {
+	if err := validateProvider(p); err != nil {
+		return err
+	}
+	if _, ok := defaultImageModels[p.Name]; !ok {
+		return &ValidationError{Field: "provider", Message: "image generation not supported by " + p.Name}
+	}
+	if req.Prompt == "" {
+		return &ValidationError{Field: "prompt", Message: "required"}
+	}
+	if req.N < 0 {
+		return &ValidationError{Field: "n", Message: "must be positive"}
+	}
+	switch req.Format {
+	case "", "png", "jpeg", "webp":
+	default:
+		return &ValidationError{Field: "format", Message: "must be png, jpeg or webp"}
+	}
+	if req.Model == "" {
+		req.Model = defaultImageModels[p.Name]
+	}
+	if req.N == 0 {
+		req.N = 1
+	}
+	return nil
+}
+
+// imageMimeType maps an output format to its MIME type.
+func imageMimeType(format string) string {
+	switch format {
+	case "jpeg":
+		return "image/jpeg"
+	case "webp":
+		return "image/webp"
+	default:
+		return "image/png"
+	}
+}
+
+// isDallE reports whether the model is a DALL-E model, which uses a different parameter set.
+func isDallE(model string) bool {
+	return strings.HasPrefix(model, "dall-e")
+}
@@ -0,0 +1,160 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerateImage_OpenAI(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/images/generations" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		// "aGVsbG8=" is base64 for "hello"
+		w.Write([]byte(`{"data":[{"b64_json":"aGVsbG8="}],"usage":{"input_tokens":10,"output_tokens":200}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := GenerateImage(context.Background(), p, ImageRequest{
+		Prompt:  "a red fox",
+		Size:    "1024x1024",
+		Quality: "high",
+		Format:  "webp",
+	})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+
+	if got["model"] != "gpt-image-1" || got["output_format"] != "webp" || got["quality"] != "high" {
+		t.Errorf("request = %v", got)
+	}
+	if _, ok := got["response_format"]; ok {
+		t.Error("response_format must not be sent to gpt-image models")
+	}
+	if len(resp.Images) != 1 || string(resp.Images[0].Data) != "hello" || resp.Images[0].MimeType != "image/webp" {
+		t.Errorf("Images = %+v", resp.Images)
+	}
+	if resp.Tokens.Output != 200 {
+		t.Errorf("Tokens = %+v", resp.Tokens)
+	}
+}
+
+func TestEditImage_OpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/images/edits" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		if r.FormValue("prompt") != "add a hat" || r.FormValue("n") != "1" {
+			t.Errorf("fields = %v", r.MultipartForm.Value)
+		}
+		if len(r.MultipartForm.File["image[]"]) != 2 || len(r.MultipartForm.File["mask"]) != 1 {
+			t.Errorf("files = %v", r.MultipartForm.File)
+		}
+		w.Write([]byte(`{"data":[{"b64_json":"aGVsbG8="}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	img := File{MimeType: "image/png", Data: []byte("png")}
+	resp, err := EditImage(context.Background(), p, ImageRequest{
+		Prompt: "add a hat",
+		Images: []File{img, img},
+		Mask:   &img,
+	})
+	if err != nil {
+		t.Fatalf("EditImage() error = %v", err)
+	}
+	if len(resp.Images) != 1 || resp.Images[0].MimeType != "image/png" {
+		t.Errorf("Images = %+v", resp.Images)
+	}
+}
+
+func TestImageRequest_Validation(t *testing.T) {
+	openai := Provider{Name: OpenAI, APIKey: "test-key"}
+	tests := []struct {
+		name  string
+		p     Provider
+		req   ImageRequest
+		edit  bool
+		field string
+	}{
+		{"unsupported provider", Provider{Name: Anthropic, APIKey: "test-key"}, ImageRequest{Prompt: "x"}, false, "provider"},
+		{"missing prompt", openai, ImageRequest{}, false, "prompt"},
+		{"bad format", openai, ImageRequest{Prompt: "x", Format: "gif"}, false, "format"},
+		{"edit on google", Provider{Name: Google, APIKey: "test-key"}, ImageRequest{Prompt: "x"}, true, "provider"},
+		{"edit without images", openai, ImageRequest{Prompt: "x"}, true, "images"},
+		{"edit with uploaded file", openai, ImageRequest{Prompt: "x", Images: []File{{ID: "file-1"}}}, true, "images"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.edit {
+				_, err = EditImage(context.Background(), tt.p, tt.req)
+			} else {
+				_, err = GenerateImage(context.Background(), tt.p, tt.req)
+			}
+			var valErr *ValidationError
+			if !errors.As(err, &valErr) || valErr.Field != tt.field {
+				t.Errorf("expected %s ValidationError, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestGenerateImage_Google(t *testing.T) {
+	var gotPath string
+	var got googleImagenRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"predictions":[{"bytesBase64Encoded":"aGVsbG8=","mimeType":"image/png"},{"raiFilteredReason":"filtered"}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := GenerateImage(context.Background(), p, ImageRequest{
+		Prompt:        "a red fox",
+		N:             2,
+		AspectRatio:   "16:9",
+		SafetySetting: "block_low_and_above",
+	})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+
+	if gotPath != "/v1beta/models/imagen-4.0-generate-001:predict" {
+		t.Errorf("path = %s", gotPath)
+	}
+	if got.Instances[0].Prompt != "a red fox" || got.Parameters.SampleCount != 2 ||
+		got.Parameters.AspectRatio != "16:9" || got.Parameters.SafetySetting != "block_low_and_above" {
+		t.Errorf("request = %+v", got)
+	}
+	if len(resp.Images) != 1 || string(resp.Images[0].Data) != "hello" {
+		t.Errorf("Images = %+v", resp.Images)
+	}
+}
+
+func TestGenerateImage_GoogleAllFiltered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"predictions":[{"raiFilteredReason":"unsafe content"}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	_, err := GenerateImage(context.Background(), p, ImageRequest{Prompt: "x"})
+
+	var blocked *ContentBlockedError
+	if !errors.As(err, &blocked) || blocked.Reason != "unsafe content" {
+		t.Errorf("expected ContentBlockedError, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// defaultKeyCooldown is how long a rate-limited key is skipped when the
+// provider does not send Retry-After.
+const defaultKeyCooldown = time.Minute
+
+// KeyPool rotates requests round-robin across several API keys. A key that is
+// rate limited (HTTP 429) is skipped until its Retry-After has passed and the
+// request is retried with the next key. Set it as Provider.Keys.
+type KeyPool struct {
+	mu       sync.Mutex
+	keys     []string
+	next     int
+	coolDown map[string]time.Time
+}
+
+// NewKeyPool returns a pool over keys; empty keys are ignored.
+func NewKeyPool(keys ...string) *KeyPool {
+	k := &KeyPool{coolDown: map[string]time.Time{}}
+	for _, key := range keys {
+		if key != "" {
+			k.keys = append(k.keys, key)
+		}
+	}
+	return k
+}
+
+// Len returns the number of keys in the pool.
+func (k *KeyPool) Len() int {
+	return len(k.keys)
+}
+
+// pick returns the next key not cooling down at now. When every key is cooling
+// down, the one that becomes available first is returned.
+func (k *KeyPool) pick(now time.Time) string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	best := ""
+	for i := range k.keys {
+		key := k.keys[(k.next+i)%len(k.keys)]
+		until, cooling := k.coolDown[key]
+		if !cooling || !now.Before(until) {
+			k.next = (k.next + i + 1) % len(k.keys)
+			return key
+		}
+		if best == "" || until.Before(k.coolDown[best]) {
+			best = key
+		}
+	}
+	return best
+}
+
+// rateLimited marks key as unavailable until now+wait.
+func (k *KeyPool) rateLimited(key string, now time.Time, wait time.Duration) {
+	if wait <= 0 {
+		wait = defaultKeyCooldown
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.coolDown[key] = now.Add(wait)
+}
+
+// withPoolKey runs call with the API key for this request: from p.KeyProvider
+// when set, or from p.Keys, moving to the next key when the provider answers 429.
+// Otherwise call runs once with p as is.
+func withPoolKey[T any](ctx context.Context, p Provider, o *options, call func(Provider) (T, error)) (T, error) {
+	if p.KeyProvider != nil {
+		return withProvidedKey(ctx, p, call)
+	}
+	if p.Keys == nil || p.Keys.Len() == 0 {
+		return call(p)
+	}
+
+	var res T
+	var err error
+	for range p.Keys.Len() {
+		p.APIKey = p.Keys.pick(o.clock.Now())
+		res, err = call(p)
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != 429 {
+			return res, err
+		}
+		p.Keys.rateLimited(p.APIKey, o.clock.Now(), apiErr.RetryAfter)
+	}
+	return res, err
+}
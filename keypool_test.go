@@ -0,0 +1,63 @@
+package llmkit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyPool_RoundRobin(t *testing.T) {
+	pool := NewKeyPool("a", "", "b", "c")
+	now := time.Now()
+
+	var got []string
+	for range 4 {
+		got = append(got, pool.pick(now))
+	}
+	if strings.Join(got, ",") != "a,b,c,a" {
+		t.Errorf("picked %v, want a,b,c,a", got)
+	}
+}
+
+func TestKeyPool_RotatesOn429(t *testing.T) {
+	clock := newFakeClock()
+	var keys []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		key := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		keys = append(keys, key)
+		if key == "limited" {
+			h := make(http.Header)
+			h.Set("Retry-After", "30")
+			return &http.Response{
+				StatusCode: 429,
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"rate limited"}}`)),
+				Header:     h,
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"choices":[{"message":{"content":"ok"}}]}`)),
+			Header:     make(http.Header),
+		}, nil
+	})
+
+	p := Provider{Name: OpenAI, Keys: NewKeyPool("limited", "spare")}
+	opts := []Option{WithHTTPClient(&http.Client{Transport: transport}), WithClock(clock)}
+	ctx := context.Background()
+
+	if _, err := Prompt(ctx, p, Request{User: "Hi"}, opts...); err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	// The limited key is skipped while cooling down, then used again
+	Prompt(ctx, p, Request{User: "Hi"}, opts...)
+	clock.advance(31 * time.Second)
+	Prompt(ctx, p, Request{User: "Hi"}, opts...)
+
+	want := "limited,spare,spare,limited,spare"
+	if strings.Join(keys, ",") != want {
+		t.Errorf("keys used = %v, want %s", keys, want)
+	}
+}
@@ -0,0 +1,142 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeyProvider supplies the API key for each request, so keys can differ per
+// tenant or rotate without rebuilding the Provider. Set it as Provider.KeyProvider.
+type KeyProvider interface {
+	Key(ctx context.Context) (string, error)
+}
+
+// KeyFunc adapts a function to KeyProvider.
+type KeyFunc func(ctx context.Context) (string, error)
+
+// Key calls f.
+func (f KeyFunc) Key(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticKey returns a KeyProvider that always supplies key.
+func StaticKey(key string) KeyProvider {
+	return KeyFunc(func(context.Context) (string, error) { return key, nil })
+}
+
+// EnvKey returns a KeyProvider that reads the environment variable name on
+// every request, so a changed value takes effect without a restart.
+func EnvKey(name string) KeyProvider {
+	return KeyFunc(func(context.Context) (string, error) {
+		if key := os.Getenv(name); key != "" {
+			return key, nil
+		}
+		return "", &ValidationError{Field: "api_key", Message: name + " not set"}
+	})
+}
+
+// tenantKey is the context key for the tenant of a request.
+type tenantKey struct{}
+
+// ContextWithTenant returns a context carrying the tenant a request bills to.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant stored in ctx, or "" if none.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// TenantKeys returns a KeyProvider that looks up the key of the tenant set with
+// ContextWithTenant. Requests without a tenant fail with a ValidationError.
+// Wrap it in NewKeyCache when lookup is slow.
+func TenantKeys(lookup func(ctx context.Context, tenant string) (string, error)) KeyProvider {
+	return KeyFunc(func(ctx context.Context) (string, error) {
+		tenant := TenantFromContext(ctx)
+		if tenant == "" {
+			return "", &ValidationError{Field: "tenant", Message: "required by TenantKeys; use ContextWithTenant"}
+		}
+		return lookup(ctx, tenant)
+	})
+}
+
+// KeyCache caches the keys of a slower KeyProvider, such as a secret manager,
+// per tenant for a TTL. When a provider rejects a cached key with 401 (it was
+// rotated), the entry is dropped and the request retried once with a fresh key.
+type KeyCache struct {
+	src   KeyProvider
+	ttl   time.Duration
+	clock Clock
+
+	mu      sync.Mutex
+	entries map[string]cachedKey
+}
+
+type cachedKey struct {
+	key     string
+	expires time.Time
+}
+
+// NewKeyCache returns a cache over src holding keys for ttl. It honors
+// WithClock.
+func NewKeyCache(src KeyProvider, ttl time.Duration, opts ...Option) *KeyCache {
+	return &KeyCache{src: src, ttl: ttl, clock: applyOptions(opts...).clock, entries: map[string]cachedKey{}}
+}
+
+// Key returns the cached key for ctx's tenant, fetching it from the source when
+// missing or expired.
+func (c *KeyCache) Key(ctx context.Context) (string, error) {
+	tenant := TenantFromContext(ctx)
+	c.mu.Lock()
+	e, ok := c.entries[tenant]
+	c.mu.Unlock()
+	if ok && c.clock.Now().Before(e.expires) {
+		return e.key, nil
+	}
+
+	key, err := c.src.Key(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.entries[tenant] = cachedKey{key: key, expires: c.clock.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return key, nil
+}
+
+// Invalidate drops the cached key for ctx's tenant.
+func (c *KeyCache) Invalidate(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, TenantFromContext(ctx))
+}
+
+// withProvidedKey runs call with the key from p.KeyProvider. A 401 from a
+// provider whose keys can be invalidated is retried once with a refetched key.
+func withProvidedKey[T any](ctx context.Context, p Provider, call func(Provider) (T, error)) (T, error) {
+	var zero T
+	key, err := p.KeyProvider.Key(ctx)
+	if err != nil {
+		return zero, err
+	}
+	p.APIKey = key
+	res, err := call(p)
+
+	inv, ok := p.KeyProvider.(interface{ Invalidate(context.Context) })
+	var apiErr *APIError
+	if !ok || !errors.As(err, &apiErr) || apiErr.StatusCode != 401 {
+		return res, err
+	}
+	inv.Invalidate(ctx)
+	fresh, keyErr := p.KeyProvider.Key(ctx)
+	if keyErr != nil || fresh == p.APIKey {
+		return res, err
+	}
+	p.APIKey = fresh
+	return call(p)
+}
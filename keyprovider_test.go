@@ -0,0 +1,128 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// keyServer answers OpenAI chat requests, rejecting keys not in valid with 401.
+func keyServer(t *testing.T, valid map[string]bool, seen *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Authorization")
+		*seen = append(*seen, key)
+		if !valid[key] {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"Incorrect API key provided"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"hi"}}]}`))
+	}))
+}
+
+func TestTenantKeys(t *testing.T) {
+	var seen []string
+	server := keyServer(t, map[string]bool{"Bearer key-acme": true, "Bearer key-globex": true}, &seen)
+	defer server.Close()
+
+	keys := map[string]string{"acme": "key-acme", "globex": "key-globex"}
+	p := Provider{Name: OpenAI, BaseURL: server.URL, KeyProvider: TenantKeys(func(_ context.Context, tenant string) (string, error) {
+		return keys[tenant], nil
+	})}
+
+	for _, tenant := range []string{"acme", "globex"} {
+		if _, err := Prompt(ContextWithTenant(context.Background(), tenant), p, Request{User: "Hi"}); err != nil {
+			t.Fatalf("Prompt(%s) error = %v", tenant, err)
+		}
+	}
+	if len(seen) != 2 || seen[0] != "Bearer key-acme" || seen[1] != "Bearer key-globex" {
+		t.Errorf("keys sent = %q", seen)
+	}
+
+	_, err := Prompt(context.Background(), p, Request{User: "Hi"})
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field != "tenant" {
+		t.Errorf("error = %v, want tenant ValidationError", err)
+	}
+}
+
+func TestKeyCache(t *testing.T) {
+	fetches := 0
+	src := KeyFunc(func(context.Context) (string, error) {
+		fetches++
+		return "key-1", nil
+	})
+	clock := newFakeClock()
+	c := NewKeyCache(src, time.Minute, WithClock(clock))
+
+	ctx := context.Background()
+	c.Key(ctx)
+	c.Key(ctx)
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1 within TTL", fetches)
+	}
+	clock.advance(2 * time.Minute)
+	c.Key(ctx)
+	if fetches != 2 {
+		t.Errorf("fetches = %d, want 2 after TTL", fetches)
+	}
+	c.Key(ContextWithTenant(ctx, "acme"))
+	if fetches != 3 {
+		t.Errorf("fetches = %d, want 3 for a new tenant", fetches)
+	}
+}
+
+func TestKeyCache_RotatedKeyRetried(t *testing.T) {
+	var seen []string
+	server := keyServer(t, map[string]bool{"Bearer new": true}, &seen)
+	defer server.Close()
+
+	current := "old"
+	cache := NewKeyCache(KeyFunc(func(context.Context) (string, error) { return current, nil }), time.Hour)
+	p := Provider{Name: OpenAI, BaseURL: server.URL, KeyProvider: cache}
+
+	cache.Key(context.Background()) // caches "old"
+	current = "new"                 // rotated in the secret manager
+	if _, err := Prompt(context.Background(), p, Request{User: "Hi"}); err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if len(seen) != 2 || seen[1] != "Bearer new" {
+		t.Errorf("keys sent = %q", seen)
+	}
+
+	// A key that is still wrong after refetching is reported, not retried forever
+	current = "revoked"
+	cache.Invalidate(context.Background())
+	seen = nil
+	_, err := Prompt(context.Background(), p, Request{User: "Hi"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 401 || len(seen) != 1 {
+		t.Errorf("error = %v after %d requests, want one 401", err, len(seen))
+	}
+}
+
+func TestEnvKey(t *testing.T) {
+	t.Setenv("LLMKIT_TEST_KEY", "from-env")
+	key, err := EnvKey("LLMKIT_TEST_KEY").Key(context.Background())
+	if err != nil || key != "from-env" {
+		t.Errorf("Key() = %q, %v", key, err)
+	}
+	if _, err := EnvKey("LLMKIT_TEST_UNSET").Key(context.Background()); err == nil {
+		t.Error("Key() error = nil for unset variable")
+	}
+}
+
+func TestKeyProvider_Validation(t *testing.T) {
+	p := Provider{Name: OpenAI, KeyProvider: StaticKey("k"), Keys: NewKeyPool("a", "b")}
+	var ve *ValidationError
+	if err := validateProvider(p); !errors.As(err, &ve) || ve.Field != "keys" {
+		t.Errorf("validateProvider() = %v, want keys ValidationError", err)
+	}
+	if err := validateProvider(Provider{Name: OpenAI, KeyProvider: StaticKey("k")}); err != nil {
+		t.Errorf("validateProvider() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,190 @@
+package llmkit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// WithLanguage requires answers in lang, an ISO 639-1 code such as "fi" or
+// "pt-BR". An instruction is added to the system prompt, and if DetectLanguage
+// finds the answer in another language the request is sent once more with a
+// firmer instruction. A second miss returns *LanguageError. Streamed responses
+// are instructed but not re-prompted, since their text was already delivered.
+func WithLanguage(lang string) Option {
+	return func(o *options) {
+		o.language = lang
+	}
+}
+
+// LanguageError is returned when a response stays in the wrong language after a re-prompt.
+type LanguageError struct {
+	Want, Got string   // ISO 639-1 codes
+	Response  Response // the last answer, for callers that accept it anyway
+}
+
+func (e *LanguageError) Error() string {
+	return fmt.Sprintf("response language %q, want %q", e.Got, e.Want)
+}
+
+// languageNames names the languages DetectLanguage recognizes, for instructions.
+var languageNames = map[string]string{
+	"ar": "Arabic", "da": "Danish", "de": "German", "el": "Greek", "en": "English",
+	"es": "Spanish", "fi": "Finnish", "fr": "French", "he": "Hebrew", "hi": "Hindi",
+	"it": "Italian", "ja": "Japanese", "ko": "Korean", "nl": "Dutch", "pl": "Polish",
+	"pt": "Portuguese", "ru": "Russian", "sv": "Swedish", "th": "Thai", "zh": "Chinese",
+}
+
+// stopwords are frequent function words that tell Latin-script languages apart.
+var stopwords = map[string][]string{
+	"da": {"og", "det", "er", "ikke", "jeg", "af", "til", "med", "har", "som", "en", "på"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "ich", "mit", "ein", "eine", "zu", "auf", "sie"},
+	"en": {"the", "and", "is", "of", "to", "in", "that", "it", "you", "for", "with", "are", "this"},
+	"es": {"el", "la", "de", "que", "y", "en", "los", "es", "por", "una", "para", "con", "las"},
+	"fi": {"ja", "on", "ei", "että", "se", "hän", "oli", "ovat", "mutta", "tämä", "kun", "myös"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "que", "pas", "pour", "dans", "vous", "du"},
+	"it": {"il", "di", "che", "è", "e", "la", "per", "non", "una", "sono", "gli", "con", "del"},
+	"nl": {"de", "het", "een", "en", "is", "van", "niet", "dat", "ik", "zijn", "op", "voor", "met"},
+	"pl": {"i", "w", "nie", "się", "na", "że", "jest", "to", "z", "do", "jak", "ale", "jego"},
+	"pt": {"o", "a", "de", "que", "e", "do", "da", "em", "não", "um", "uma", "para", "com", "os"},
+	"sv": {"och", "att", "det", "är", "som", "en", "på", "inte", "jag", "med", "för", "har", "av"},
+}
+
+// DetectLanguage guesses the ISO 639-1 code of text from its script and, for
+// Latin script, its most frequent function words. It returns "" when the text
+// is too short or ambiguous to tell.
+func DetectLanguage(text string) string {
+	if lang := detectScript(text); lang != "" {
+		return lang
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) < 5 {
+		return ""
+	}
+	scores := map[string]int{}
+	for _, w := range words {
+		for lang, list := range stopwords {
+			for _, s := range list {
+				if w == s {
+					scores[lang]++
+					break
+				}
+			}
+		}
+	}
+
+	best, bestScore, second := "", 0, 0
+	for lang, n := range scores {
+		switch {
+		case n > bestScore:
+			best, bestScore, second = lang, n, bestScore
+		case n > second:
+			second = n
+		}
+	}
+	// Require a few hits and a clear lead over the runner-up
+	if bestScore < 3 || bestScore < second*3/2 {
+		return ""
+	}
+	return best
+}
+
+// detectScript returns the language of text's dominant non-Latin script, or "".
+func detectScript(text string) string {
+	counts := map[string]int{}
+	letters, kana := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+			counts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		}
+	}
+	// Japanese mixes kanji with kana
+	if kana > 0 {
+		counts["ja"] += counts["zh"]
+		delete(counts, "zh")
+	}
+	for lang, n := range counts {
+		if n*2 > letters {
+			return lang
+		}
+	}
+	return ""
+}
+
+// languageInstruction appends the answer-language instruction to system.
+func languageInstruction(system, lang string, firm bool) string {
+	name := languageName(lang)
+	line := "Respond in " + name + "."
+	if firm {
+		line = "Your answer must be written entirely in " + name + ", even if the input or sources are in another language."
+	}
+	return appendInstruction(system, line)
+}
+
+// languageName returns the English name of a language tag, or the tag itself.
+func languageName(lang string) string {
+	if name := languageNames[baseLanguage(lang)]; name != "" {
+		return name
+	}
+	return lang
+}
+
+// appendInstruction adds a paragraph to a system prompt.
+func appendInstruction(system, line string) string {
+	if system == "" || line == "" {
+		return system + line
+	}
+	return system + "\n\n" + line
+}
+
+// baseLanguage returns the primary subtag of a language tag, e.g. "pt" for "pt-BR".
+func baseLanguage(lang string) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	return base
+}
+
+// enforceLanguage re-prompts once when resp is detectably not in o.language.
+// req already carries the plain instruction.
+func enforceLanguage(ctx context.Context, p Provider, req Request, o *options, resp Response) (Response, error) {
+	want := baseLanguage(o.language)
+	got := DetectLanguage(resp.Text)
+	if got == "" || got == want {
+		return resp, nil
+	}
+
+	req.System = languageInstruction(req.System, o.language, true)
+	retry, err := reprompt(ctx, p, req, o, resp)
+	if err != nil {
+		return Response{}, err
+	}
+	if got := DetectLanguage(retry.Text); got != "" && got != want {
+		return Response{}, &LanguageError{Want: want, Got: got, Response: retry}
+	}
+	return retry, nil
+}
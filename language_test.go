@@ -0,0 +1,79 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"The weather is nice today and it is warm in the city.", "en"},
+		{"Das Wetter ist heute schön und es ist nicht kalt in der Stadt.", "de"},
+		{"Le temps est beau aujourd'hui et il ne fait pas froid dans la ville.", "fr"},
+		{"El tiempo es bueno hoy y no hace frío en la ciudad, por la tarde.", "es"},
+		{"Sää on tänään kaunis ja kaupungissa on lämmin, mutta se ei ole kuuma.", "fi"},
+		{"Vädret är fint idag och det är inte kallt i staden, som jag har sagt.", "sv"},
+		{"今日はとても良い天気ですね。", "ja"},
+		{"今天天气很好。", "zh"},
+		{"Сегодня хорошая погода.", "ru"},
+		{"Hello there", ""}, // too short
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWithLanguage_Reprompts(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"content":[{"type":"text","text":"The weather is nice today and it is warm in the city."}],"usage":{"input_tokens":10,"output_tokens":5}}`,
+		`{"content":[{"type":"text","text":"Sää on tänään kaunis ja kaupungissa on lämmin, mutta se ei ole kuuma."}],"usage":{"input_tokens":12,"output_tokens":6}}`,
+	}}
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	resp, err := Prompt(context.Background(), p, Request{User: "How is the weather?"},
+		WithLanguage("fi"), WithHTTPClient(&http.Client{Transport: mock}))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if !strings.HasPrefix(resp.Text, "Sää") || resp.Tokens.Input != 22 || resp.Tokens.Output != 11 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(mock.bodies) != 2 || !strings.Contains(mock.bodies[0], "Respond in Finnish.") ||
+		!strings.Contains(mock.bodies[1], "must be written entirely in Finnish") {
+		t.Errorf("bodies = %q", mock.bodies)
+	}
+}
+
+func TestWithLanguage_StillWrong(t *testing.T) {
+	english := `{"content":[{"type":"text","text":"The weather is nice today and it is warm in the city."}],"usage":{"input_tokens":10,"output_tokens":5}}`
+	mock := &scriptedTransport{responses: []string{english, english}}
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	_, err := Prompt(context.Background(), p, Request{User: "How is the weather?"},
+		WithLanguage("de"), WithHTTPClient(&http.Client{Transport: mock}))
+
+	var le *LanguageError
+	if !errors.As(err, &le) || le.Want != "de" || le.Got != "en" || le.Response.Text == "" {
+		t.Errorf("error = %v, want LanguageError", err)
+	}
+}
+
+func TestWithLanguage_Matching(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"content":[{"type":"text","text":"Das Wetter ist heute schön und es ist nicht kalt in der Stadt."}],"usage":{"input_tokens":10,"output_tokens":5}}`,
+	}}
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	if _, err := Prompt(context.Background(), p, Request{User: "Wetter?"},
+		WithLanguage("de-AT"), WithHTTPClient(&http.Client{Transport: mock})); err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if len(mock.bodies) != 1 {
+		t.Errorf("requests = %d, want 1", len(mock.bodies))
+	}
+}
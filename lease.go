@@ -0,0 +1,235 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrLeaseLost is returned by Leaser methods when a task's lease expired and
+// the task may have been handed to another worker.
+var ErrLeaseLost = errors.New("llmkit: task lease lost")
+
+// Leaser is an Inbox that leases tasks: a received task is redelivered, to
+// this or another worker process, unless it is completed before its lease
+// expires. Several workers can then share one backlog, and a task held by a
+// crashed worker is picked up again. RunWorker renews the lease of each
+// running task (see WithHeartbeat) and cancels a task whose lease was lost.
+// Queue adapters map Renew to, for example, SQS ChangeMessageVisibility or a
+// Redis key expiry.
+type Leaser interface {
+	Inbox
+	Renew(ctx context.Context, t Task) error
+}
+
+// defaultHeartbeat is how often RunWorker renews leases by default.
+const defaultHeartbeat = 10 * time.Second
+
+// WithHeartbeat sets how often RunWorker renews the leases of running tasks
+// from a Leaser. Keep it well below the lease duration. Default is 10s.
+func WithHeartbeat(d time.Duration) Option {
+	return func(o *options) {
+		o.heartbeat = d
+	}
+}
+
+// heartbeat renews t's lease every interval until ctx ends, calling lost
+// when the lease can no longer be held.
+func heartbeat(ctx context.Context, l Leaser, t Task, o *options, lost func()) {
+	interval := o.heartbeat
+	if interval <= 0 {
+		interval = defaultHeartbeat
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.clock.After(interval):
+		}
+		err := l.Renew(ctx, t)
+		switch {
+		case errors.Is(err, ErrLeaseLost):
+			o.log(ctx, slog.LevelWarn, "llmkit worker lease lost", "task", t.ID)
+			lost()
+			return
+		case err != nil && ctx.Err() == nil:
+			// Transient failure: keep working and retry at the next beat
+			o.log(ctx, slog.LevelWarn, "llmkit worker lease renewal failed", "task", t.ID, "error", err)
+		}
+	}
+}
+
+// LeaseQueue is an in-process Leaser. Tasks added with Add are leased to one
+// receiver at a time for the lease duration; a task that is neither renewed
+// nor completed in time is delivered again. It is safe for concurrent use by
+// any number of workers. Each delivery is stamped with a lease token in
+// Task.Meta["lease"], so a worker whose lease expired cannot renew or
+// complete the task on behalf of the worker it was redelivered to.
+type LeaseQueue struct {
+	ttl   time.Duration
+	clock Clock
+
+	mu      sync.Mutex
+	pending []Task
+	leased  map[string]leasedTask // by task ID
+	leases  int                   // lease tokens handed out
+	closed  bool
+	changed chan struct{} // closed and replaced whenever the queue changes
+}
+
+type leasedTask struct {
+	task    Task // as added, without the lease token
+	token   string
+	expires time.Time
+}
+
+// NewLeaseQueue returns an empty queue that leases tasks for ttl. It honors
+// WithClock.
+func NewLeaseQueue(ttl time.Duration, opts ...Option) *LeaseQueue {
+	return &LeaseQueue{
+		ttl:     ttl,
+		clock:   applyOptions(opts...).clock,
+		leased:  map[string]leasedTask{},
+		changed: make(chan struct{}),
+	}
+}
+
+// Add queues tasks. Task IDs must be unique among queued and leased tasks.
+func (q *LeaseQueue) Add(tasks ...Task) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, tasks...)
+	q.notify()
+}
+
+// Close marks the queue as complete: once every task has been completed,
+// Receive returns io.EOF.
+func (q *LeaseQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.notify()
+}
+
+// Len returns the number of tasks waiting and leased.
+func (q *LeaseQueue) Len() (pending, leased int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire(q.clock.Now())
+	return len(q.pending), len(q.leased)
+}
+
+// Receive leases the next task, waiting for one to be added or for a lease
+// to expire.
+func (q *LeaseQueue) Receive(ctx context.Context) (Task, error) {
+	for {
+		q.mu.Lock()
+		now := q.clock.Now()
+		q.expire(now)
+		if len(q.pending) > 0 {
+			t := q.pending[0]
+			q.pending = q.pending[1:]
+			q.leases++
+			token := strconv.Itoa(q.leases)
+			q.leased[t.ID] = leasedTask{task: t, token: token, expires: now.Add(q.ttl)}
+			q.mu.Unlock()
+			return withLeaseToken(t, token), nil
+		}
+		if q.closed && len(q.leased) == 0 {
+			q.mu.Unlock()
+			return Task{}, io.EOF
+		}
+
+		// Wait for a change or for the earliest lease to expire
+		changed := q.changed
+		var expiry <-chan time.Time
+		if next, ok := q.nextExpiry(); ok {
+			expiry = q.clock.After(next.Sub(now))
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-expiry:
+		case <-ctx.Done():
+			return Task{}, ctx.Err()
+		}
+	}
+}
+
+// Renew extends t's lease by the lease duration. t must be the task as
+// returned by Receive.
+func (q *LeaseQueue) Renew(ctx context.Context, t Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.clock.Now()
+	q.expire(now)
+	l, ok := q.leased[t.ID]
+	if !ok || l.token != t.Meta["lease"] {
+		return ErrLeaseLost
+	}
+	l.expires = now.Add(q.ttl)
+	q.leased[t.ID] = l
+	return nil
+}
+
+// Complete removes the task from the queue, whether it succeeded or not. It
+// returns ErrLeaseLost if the lease had expired, when the task may have run
+// twice, and leaves a redelivered task leased to its new worker.
+func (q *LeaseQueue) Complete(ctx context.Context, r TaskResult) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire(q.clock.Now())
+	if l, ok := q.leased[r.Task.ID]; !ok || l.token != r.Task.Meta["lease"] {
+		return ErrLeaseLost
+	}
+	delete(q.leased, r.Task.ID)
+	q.notify()
+	return nil
+}
+
+// expire returns tasks whose lease ended to the front of the queue.
+func (q *LeaseQueue) expire(now time.Time) {
+	var expired []Task
+	for id, l := range q.leased {
+		if !now.Before(l.expires) {
+			expired = append(expired, l.task)
+			delete(q.leased, id)
+		}
+	}
+	if len(expired) > 0 {
+		q.pending = append(expired, q.pending...)
+	}
+}
+
+func (q *LeaseQueue) nextExpiry() (time.Time, bool) {
+	var next time.Time
+	for _, l := range q.leased {
+		if next.IsZero() || l.expires.Before(next) {
+			next = l.expires
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// withLeaseToken returns a copy of t with the lease token in its Meta,
+// leaving the caller's map untouched.
+func withLeaseToken(t Task, token string) Task {
+	meta := make(map[string]string, len(t.Meta)+1)
+	for k, v := range t.Meta {
+		meta[k] = v
+	}
+	meta["lease"] = token
+	t.Meta = meta
+	return t
+}
+
+// notify wakes waiting receivers.
+func (q *LeaseQueue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
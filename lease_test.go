@@ -0,0 +1,165 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLeaseQueue_RenewAndExpiry(t *testing.T) {
+	clock := newFakeClock()
+	q := NewLeaseQueue(time.Minute, WithClock(clock))
+	q.Add(Task{ID: "a"}, Task{ID: "b"})
+	ctx := context.Background()
+
+	a, err := q.Receive(ctx)
+	if err != nil || a.ID != "a" {
+		t.Fatalf("Receive() = %+v, %v", a, err)
+	}
+	clock.advance(30 * time.Second)
+	if err := q.Renew(ctx, a); err != nil {
+		t.Fatalf("Renew() error = %v", err)
+	}
+	clock.advance(45 * time.Second) // 75s: a renewed until 90s
+	b, _ := q.Receive(ctx)
+	if b.ID != "b" {
+		t.Fatalf("Receive() = %+v, want b", b)
+	}
+	clock.advance(20 * time.Second) // 95s: a expired
+
+	if err := q.Renew(ctx, a); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("Renew() after expiry error = %v, want ErrLeaseLost", err)
+	}
+	if err := q.Complete(ctx, TaskResult{Task: a}); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("Complete() after expiry error = %v, want ErrLeaseLost", err)
+	}
+	if pending, leased := q.Len(); pending != 1 || leased != 1 {
+		t.Errorf("Len() = %d, %d, want 1, 1", pending, leased)
+	}
+	again, _ := q.Receive(ctx)
+	if again.ID != "a" {
+		t.Fatalf("Receive() = %+v, want a redelivered", again)
+	}
+	for _, task := range []Task{again, b} {
+		if err := q.Complete(ctx, TaskResult{Task: task}); err != nil {
+			t.Errorf("Complete(%s) error = %v", task.ID, err)
+		}
+	}
+	q.Close()
+	if _, err := q.Receive(ctx); err != io.EOF {
+		t.Errorf("Receive() after Close error = %v, want io.EOF", err)
+	}
+}
+
+func TestLeaseQueue_StaleWorker(t *testing.T) {
+	clock := newFakeClock()
+	q := NewLeaseQueue(time.Minute, WithClock(clock))
+	q.Add(Task{ID: "a", Meta: map[string]string{"source": "test"}})
+	ctx := context.Background()
+
+	first, _ := q.Receive(ctx)
+	clock.advance(2 * time.Minute)
+	second, _ := q.Receive(ctx)
+	if second.ID != "a" || second.Meta["source"] != "test" || second.Meta["lease"] == first.Meta["lease"] {
+		t.Fatalf("redelivered task = %+v, first = %+v", second, first)
+	}
+
+	// The first worker's late heartbeat and completion must not touch the
+	// second worker's lease
+	if err := q.Renew(ctx, first); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("stale Renew() error = %v, want ErrLeaseLost", err)
+	}
+	if err := q.Complete(ctx, TaskResult{Task: first}); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("stale Complete() error = %v, want ErrLeaseLost", err)
+	}
+	if _, leased := q.Len(); leased != 1 {
+		t.Fatalf("leased = %d after stale Complete, want 1", leased)
+	}
+	if err := q.Renew(ctx, second); err != nil {
+		t.Errorf("Renew() error = %v", err)
+	}
+	if err := q.Complete(ctx, TaskResult{Task: second}); err != nil {
+		t.Errorf("Complete() error = %v", err)
+	}
+}
+
+func TestLeaseQueue_ReceiveWaitsForExpiry(t *testing.T) {
+	clock := newFakeClock()
+	q := NewLeaseQueue(time.Minute, WithClock(clock))
+	q.Add(Task{ID: "a"})
+	q.Close()
+	ctx := context.Background()
+	if _, err := q.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(chan Task)
+	go func() {
+		task, _ := q.Receive(ctx)
+		got <- task
+	}()
+	<-clock.added // waiting on the lease
+	clock.advance(time.Minute)
+	if task := <-got; task.ID != "a" {
+		t.Errorf("Receive() = %+v, want a after its lease expired", task)
+	}
+}
+
+func TestRunWorker_LeaseHeartbeat(t *testing.T) {
+	q := NewLeaseQueue(50 * time.Millisecond)
+	q.Add(Task{ID: "1"}, Task{ID: "2"}, Task{ID: "3"})
+	q.Close()
+
+	var mu sync.Mutex
+	runs := map[string]int{}
+	fn := func(ctx context.Context, task Task) (Response, error) {
+		mu.Lock()
+		runs[task.ID]++
+		mu.Unlock()
+		time.Sleep(150 * time.Millisecond) // longer than the lease
+		return Response{}, ctx.Err()
+	}
+
+	// Two workers share the queue; heartbeats keep each lease alive
+	var wg sync.WaitGroup
+	stats := make([]WorkerStats, 2)
+	for i := range stats {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats[i], _ = RunWorker(context.Background(), q, fn, WithHeartbeat(10*time.Millisecond))
+		}()
+	}
+	wg.Wait()
+
+	if len(runs) != 3 || runs["1"] != 1 || runs["2"] != 1 || runs["3"] != 1 {
+		t.Errorf("runs = %v, want each task once", runs)
+	}
+	if n := stats[0].Succeeded + stats[1].Succeeded; n != 3 {
+		t.Errorf("succeeded = %d, want 3", n)
+	}
+}
+
+// lostLeaser reports every lease as lost.
+type lostLeaser struct{ flakyInbox }
+
+func (l *lostLeaser) Renew(ctx context.Context, t Task) error { return ErrLeaseLost }
+
+func TestRunWorker_LeaseLostCancelsTask(t *testing.T) {
+	inbox := &lostLeaser{flakyInbox{tasks: []Task{{ID: "x"}}}}
+	fn := func(ctx context.Context, task Task) (Response, error) {
+		<-ctx.Done()
+		return Response{}, ctx.Err()
+	}
+
+	stats, err := RunWorker(context.Background(), inbox, fn, WithClock(&instantClock{}))
+	if err != nil {
+		t.Fatalf("RunWorker() error = %v", err)
+	}
+	if stats.Failed != 1 || len(inbox.completed) != 1 || !errors.Is(inbox.completed[0].Err, context.Canceled) {
+		t.Errorf("stats = %+v, completed = %+v", stats, inbox.completed)
+	}
+}
@@ -2,8 +2,12 @@ package llmkit
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // optionSupport defines which options each provider supports.
@@ -19,31 +23,74 @@ type optionSupport struct {
 	presencePenalty  bool
 	thinkingBudget   bool
 	reasoningEffort  bool
+	serviceTier      bool
+	streaming        bool
+	fileSearch       bool
 }
 
 // support maps providers to their supported options.
 var support = map[string]optionSupport{
 	Anthropic: {
 		temperature: true, topP: true, topK: true, maxTokens: true,
-		stopSequences: true, thinkingBudget: true,
+		stopSequences: true, thinkingBudget: true, serviceTier: true, streaming: true,
 	},
 	OpenAI: {
 		temperature: true, topP: true, maxTokens: true, stopSequences: true,
 		seed: true, frequencyPenalty: true, presencePenalty: true, reasoningEffort: true,
+		streaming: true, fileSearch: true,
 	},
 	Google: {
 		temperature: true, topP: true, topK: true, maxTokens: true,
 		stopSequences: true, seed: true, thinkingBudget: true, reasoningEffort: true,
+		streaming: true,
 	},
 	Grok: {
 		temperature: true, topP: true, topK: true, maxTokens: true,
 		stopSequences: true, seed: true, frequencyPenalty: true, presencePenalty: true,
 	},
+	Ollama: {
+		temperature: true, topP: true, maxTokens: true, stopSequences: true,
+		seed: true, frequencyPenalty: true, presencePenalty: true, streaming: true,
+	},
+	OpenRouter: {
+		temperature: true, topP: true, maxTokens: true, stopSequences: true,
+		seed: true, frequencyPenalty: true, presencePenalty: true, streaming: true,
+	},
+	OpenAICompatible: {
+		temperature: true, topP: true, maxTokens: true, stopSequences: true,
+		seed: true, frequencyPenalty: true, presencePenalty: true, streaming: true,
+	},
 }
 
 // Prompt sends a one-shot request to an LLM provider.
 func Prompt(ctx context.Context, p Provider, req Request, opts ...Option) (Response, error) {
+	return prompt(ctx, p, req, applyOptions(opts...))
+}
+
+// PromptStream sends a one-shot request and streams response text to fn as it is generated.
+// The returned Response holds the complete text and usage. Providers without
+// streaming support deliver the full text in a single call to fn.
+func PromptStream(ctx context.Context, p Provider, req Request, fn StreamFunc, opts ...Option) (Response, error) {
 	o := applyOptions(opts...)
+	o.stream = fn
+	return prompt(ctx, p, req, o)
+}
+
+// prompt validates the request and routes it to the provider.
+func prompt(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
+	if o.model != "" {
+		p.Model = o.model
+	}
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	// One correlation ID per call, shared by every hedged attempt
+	ctx, requestID := ensureRequestID(ctx, o)
+	if len(o.hedgeProviders) > 0 {
+		return promptHedged(ctx, p, req, o)
+	}
 
 	// Before hook
 	if o.beforeRequest != nil {
@@ -63,34 +110,120 @@ func Prompt(ctx context.Context, p Provider, req Request, opts ...Option) (Respo
 		return Response{}, err
 	}
 
-	// Route to provider
-	var resp Response
-	var err error
+	// A stream budget cancels the request once the streamed text exceeds it
+	var guard *streamGuard
+	if o.stream != nil && o.streamBudget.set() {
+		ctx, o, guard = guardStream(ctx, o)
+		defer guard.stop()
+	}
+
+	// Tool calls run in an agent loop, which logs, streams and reports usage per turn
+	if len(req.Tools) > 0 {
+		resp, err := promptWithTools(ctx, p, req, o)
+		resp, err = guard.result(resp, err)
+		if err == nil {
+			resp = o.postProcessResponse(resp)
+		}
+		if o.afterResponse != nil {
+			o.afterResponse(ctx, &resp, err)
+		}
+		return resp, err
+	}
+
+	if o.language != "" {
+		req.System = languageInstruction(req.System, o.language, false)
+	}
+	if o.glossary != nil {
+		req.System = appendInstruction(req.System, o.glossary.instruction())
+	}
+
+	start := o.logRequest(ctx, p)
+	resp, err := withPoolKey(ctx, p, o, func(p Provider) (Response, error) {
+		return route(ctx, p, req, o)
+	})
+	resp, err = guard.result(resp, err)
+	if err == nil && o.language != "" && o.stream == nil {
+		resp, err = enforceLanguage(ctx, p, req, o, resp)
+	}
+	if err == nil && o.glossary != nil && o.stream == nil {
+		resp, err = enforceGlossary(ctx, p, req, o, resp)
+	}
+	o.logResponse(ctx, p, start, resp.Tokens, err)
+	resp.RequestID = requestID
+	err = stampRequestID(err, requestID)
+	if err == nil || guard.tripped() {
+		resp.Cost = EstimateCost(resp.Tokens, p.model())
+		resp.Seed = o.seed
+		o.reportUsage(resp.Tokens)
+	}
+
+	// Providers without native streaming deliver the whole text at once
+	if err == nil && o.stream != nil && !support[p.Name].streaming && resp.Text != "" {
+		o.stream(resp.Text)
+	}
+	if err == nil {
+		resp = o.postProcessResponse(resp)
+	}
+
+	// After hook
+	if o.afterResponse != nil {
+		o.afterResponse(ctx, &resp, err)
+	}
+
+	return resp, err
+}
+
+// route dispatches a validated request to the provider.
+func route(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
 	switch p.Name {
 	case Anthropic:
-		resp, err = promptAnthropic(ctx, p, req, o)
-	case OpenAI:
-		resp, err = promptOpenAI(ctx, p, req, o)
+		return promptAnthropic(ctx, p, req, o)
+	case OpenAI, Ollama, OpenRouter, OpenAICompatible:
+		return promptOpenAI(ctx, p, req, o)
 	case Google:
-		resp, err = promptGoogle(ctx, p, req, o)
+		return promptGoogle(ctx, p, req, o)
 	case Grok:
-		resp, err = promptGrok(ctx, p, req, o)
+		return promptGrok(ctx, p, req, o)
 	default:
 		return Response{}, &ValidationError{Field: "provider", Message: "unknown: " + p.Name}
 	}
+}
 
-	// After hook
-	if o.afterResponse != nil {
-		o.afterResponse(ctx, &resp, err)
+// reprompt sends req again after prev was rejected, counting prev's tokens in the result.
+func reprompt(ctx context.Context, p Provider, req Request, o *options, prev Response) (Response, error) {
+	resp, err := withPoolKey(ctx, p, o, func(p Provider) (Response, error) {
+		return route(ctx, p, req, o)
+	})
+	if err != nil {
+		return Response{}, err
 	}
-
-	return resp, err
+	resp.Tokens = resp.Tokens.Add(prev.Tokens)
+	return resp, nil
 }
 
 // validateProvider checks that provider is properly configured.
+// Ollama runs locally and needs no API key; OpenAI-compatible servers may not
+// either, but have no default endpoint or model.
 func validateProvider(p Provider) error {
-	if p.APIKey == "" {
-		return &ValidationError{Field: "api_key", Message: "required"}
+	if p.Vertex != nil {
+		return validateVertex(p)
+	}
+	switch p.Name {
+	case Ollama:
+	case OpenAICompatible:
+		if p.BaseURL == "" {
+			return &ValidationError{Field: "base_url", Message: "required for " + p.Name}
+		}
+		if p.Model == "" {
+			return &ValidationError{Field: "model", Message: "required for " + p.Name}
+		}
+	default:
+		if p.APIKey == "" && (p.Keys == nil || p.Keys.Len() == 0) && p.KeyProvider == nil {
+			return &ValidationError{Field: "api_key", Message: "required"}
+		}
+	}
+	if p.KeyProvider != nil && p.Keys != nil {
+		return &ValidationError{Field: "keys", Message: "cannot be combined with KeyProvider"}
 	}
 	return nil
 }
@@ -100,6 +233,9 @@ func validateRequest(req Request) error {
 	if req.User == "" && len(req.Messages) == 0 {
 		return &ValidationError{Field: "user", Message: "required"}
 	}
+	if len(req.Tools) > 0 && (len(req.Files) > 0 || len(req.Images) > 0 || req.Schema != "") {
+		return &ValidationError{Field: "tools", Message: "cannot be combined with files, images or schema"}
+	}
 	return nil
 }
 
@@ -125,18 +261,168 @@ func validateOptions(p Provider, o *options) error {
 	if o.reasoningEffort != "" && !s.reasoningEffort {
 		return &ValidationError{Field: "reasoning_effort", Message: "not supported by " + p.Name}
 	}
+	if o.serviceTier != "" && !s.serviceTier {
+		return &ValidationError{Field: "service_tier", Message: "not supported by " + p.Name}
+	}
+	if o.serviceTier != "" && o.serviceTier != "auto" && o.serviceTier != "standard_only" {
+		return &ValidationError{Field: "service_tier", Message: "must be 'auto' or 'standard_only'"}
+	}
 
 	// Google only accepts "low" and "high" for reasoning_effort
 	if o.reasoningEffort != "" && p.Name == Google {
-		if o.reasoningEffort != "low" && o.reasoningEffort != "high" {
+		if o.reasoningEffort != EffortLow && o.reasoningEffort != EffortHigh {
 			return &ValidationError{Field: "reasoning_effort", Message: "Google only supports 'low' and 'high'"}
 		}
 	}
+	if o.reasoningEffort != "" && p.Name == OpenAI {
+		switch o.reasoningEffort {
+		case EffortMinimal, EffortLow, EffortMedium, EffortHigh:
+		default:
+			return &ValidationError{Field: "reasoning_effort", Message: "OpenAI supports 'minimal', 'low', 'medium' and 'high'"}
+		}
+	}
 
+	if len(o.fileSearch) > 0 {
+		if !s.fileSearch {
+			return &ValidationError{Field: "file_search", Message: "not supported by " + p.Name}
+		}
+		if o.seed != nil || len(o.stopSequences) > 0 || o.frequencyPenalty != nil || o.presencePenalty != nil {
+			return &ValidationError{Field: "file_search", Message: "cannot be combined with seed, stop sequences or penalties"}
+		}
+	}
+
+	if o.streamBudget.set() && !s.streaming {
+		return &ValidationError{Field: "stream_budget", Message: "not supported by " + p.Name}
+	}
+	if o.streamBudget.tokens < 0 || o.streamBudget.bytes < 0 {
+		return &ValidationError{Field: "stream_budget", Message: "must be positive"}
+	}
+
+	if o.rateLimit < 0 {
+		return &ValidationError{Field: "rate_limit", Message: "must be positive"}
+	}
+
+	return validateRanges(p, o)
+}
+
+// maxTemperature is the highest sampling temperature each provider accepts.
+var maxTemperature = map[string]float64{
+	Anthropic:        1,
+	OpenAI:           2,
+	Google:           2,
+	Grok:             2,
+	Ollama:           2,
+	OpenRouter:       2,
+	OpenAICompatible: 2,
+}
+
+// maxStopSequences limits stop sequences for providers that cap them.
+var maxStopSequences = map[string]int{
+	OpenAI: 4,
+	Google: 5,
+}
+
+// validateRanges checks numeric options against the ranges the provider accepts.
+func validateRanges(p Provider, o *options) error {
+	if o.temperature != nil {
+		if hi, ok := maxTemperature[p.Name]; ok {
+			if err := checkRange("temperature", *o.temperature, 0, hi, p.Name); err != nil {
+				return err
+			}
+		}
+	}
+	if o.topP != nil {
+		if err := checkRange("top_p", *o.topP, 0, 1, p.Name); err != nil {
+			return err
+		}
+	}
+	if o.topK != nil && *o.topK < 1 {
+		return &ValidationError{Field: "top_k", Message: "must be at least 1"}
+	}
+	if o.maxTokens != nil && *o.maxTokens < 1 {
+		return &ValidationError{Field: "max_tokens", Message: "must be at least 1"}
+	}
+	if o.frequencyPenalty != nil {
+		if err := checkRange("frequency_penalty", *o.frequencyPenalty, -2, 2, p.Name); err != nil {
+			return err
+		}
+	}
+	if o.presencePenalty != nil {
+		if err := checkRange("presence_penalty", *o.presencePenalty, -2, 2, p.Name); err != nil {
+			return err
+		}
+	}
+	if n, ok := maxStopSequences[p.Name]; ok && len(o.stopSequences) > n {
+		return &ValidationError{Field: "stop_sequences", Message: fmt.Sprintf("at most %d supported by %s", n, p.Name)}
+	}
+	if o.thinkingBudget != nil {
+		switch {
+		case p.Name == Anthropic && *o.thinkingBudget < 1024:
+			return &ValidationError{Field: "thinking_budget", Message: "must be at least 1024 for anthropic"}
+		case p.Name == Google && *o.thinkingBudget < -1:
+			return &ValidationError{Field: "thinking_budget", Message: "must be -1 (dynamic) or greater for google"}
+		}
+	}
 	return nil
 }
 
+// checkRange returns a ValidationError when v is outside [lo, hi].
+func checkRange(field string, v, lo, hi float64, provider string) error {
+	if v < lo || v > hi {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("must be between %g and %g for %s", lo, hi, provider)}
+	}
+	return nil
+}
+
+// InlineFile reads a local file into a File that is sent inline with the request.
+// Use it for small documents to skip the upload round-trip; large files should use UploadFile.
+func InlineFile(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	return File{
+		MimeType: detectMimeType(path),
+		Name:     filepath.Base(path),
+		Data:     data,
+	}, nil
+}
+
+// ImageFromFile reads a local PNG, JPEG, GIF or WebP image into an Image with a
+// base64 data URI, ready for Request.Images.
+func ImageFromFile(path string) (Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Image{}, err
+	}
+	return imageFromBytes(data, detectMimeType(path))
+}
+
+// ImageFromReader reads an image from r like ImageFromFile, detecting the MIME
+// type from its content.
+func ImageFromReader(r io.Reader) (Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Image{}, err
+	}
+	return imageFromBytes(data, "")
+}
+
+// imageFromBytes sniffs the image format, falling back to the type implied by
+// the file name when the content is not recognized.
+func imageFromBytes(data []byte, fallback string) (Image, error) {
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = fallback
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return Image{}, &ValidationError{Field: "image", Message: "not a PNG, JPEG, GIF or WebP image"}
+	}
+	return Image{URL: dataURI(mimeType, data), MimeType: mimeType}, nil
+}
+
 // UploadFile uploads a file to a provider and returns a File reference.
+// With WithFileCache, content already uploaded to the same account is not sent again.
 func UploadFile(ctx context.Context, p Provider, path string, opts ...Option) (File, error) {
 	if err := validateProvider(p); err != nil {
 		return File{}, err
@@ -148,9 +434,31 @@ func UploadFile(ctx context.Context, p Provider, path string, opts ...Option) (F
 	}
 
 	o := applyOptions(opts...)
+
+	// The cache is keyed by account, so look up and store with the key the
+	// upload is sent with, whether it comes from Keys or a KeyProvider
+	return withPoolKey(ctx, p, o, func(p Provider) (File, error) {
+		if o.fileCache != nil {
+			if f, ok := o.fileCache.lookup(p, data, o.clock.Now()); ok {
+				return f, nil
+			}
+		}
+		f, err := upload(ctx, p, data, path, o)
+		if err != nil || o.fileCache == nil {
+			return f, err
+		}
+		return f, o.fileCache.store(p, data, f, o.clock.Now())
+	})
+}
+
+// upload routes file content to the provider's upload endpoint.
+func upload(ctx context.Context, p Provider, data []byte, path string, o *options) (File, error) {
 	mimeType := detectMimeType(path)
 	name := filepath.Base(path)
 
+	if p.Vertex != nil {
+		return File{}, &ValidationError{Field: "vertex", Message: "file upload not supported on Vertex AI"}
+	}
 	switch p.Name {
 	case Anthropic:
 		return uploadAnthropic(ctx, p, data, name, mimeType, o)
@@ -160,6 +468,8 @@ func UploadFile(ctx context.Context, p Provider, path string, opts ...Option) (F
 		return uploadGoogle(ctx, p, data, name, mimeType, o)
 	case Grok:
 		return uploadGrok(ctx, p, data, name, o)
+	case Ollama, OpenRouter, OpenAICompatible:
+		return File{}, &ValidationError{Field: "provider", Message: "file upload not supported by " + p.Name}
 	default:
 		return File{}, &ValidationError{Field: "provider", Message: "unknown: " + p.Name}
 	}
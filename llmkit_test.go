@@ -2,10 +2,13 @@ package llmkit
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -32,6 +35,12 @@ func TestValidateRequest(t *testing.T) {
 			wantErr: true,
 			field:   "user",
 		},
+		{
+			name:    "tools with schema",
+			req:     Request{User: "Hello", Schema: `{"type":"object"}`, Tools: []Tool{testWeatherTool()}},
+			wantErr: true,
+			field:   "tools",
+		},
 	}
 
 	for _, tt := range tests {
@@ -55,6 +64,48 @@ func TestValidateRequest(t *testing.T) {
 	}
 }
 
+func TestValidateOptions_Ranges(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		opt      Option
+		field    string // empty means valid
+	}{
+		{"anthropic temperature above 1", Anthropic, WithTemperature(1.5), "temperature"},
+		{"openai temperature 1.5", OpenAI, WithTemperature(1.5), ""},
+		{"negative temperature", Google, WithTemperature(-0.1), "temperature"},
+		{"top_p above 1", OpenAI, WithTopP(1.2), "top_p"},
+		{"top_k zero", Google, WithTopK(0), "top_k"},
+		{"max_tokens zero", OpenAI, WithMaxTokens(0), "max_tokens"},
+		{"frequency penalty out of range", OpenAI, WithFrequencyPenalty(3), "frequency_penalty"},
+		{"presence penalty in range", Grok, WithPresencePenalty(-2), ""},
+		{"too many stop sequences", OpenAI, WithStopSequences("a", "b", "c", "d", "e"), "stop_sequences"},
+		{"anthropic thinking budget too small", Anthropic, WithThinkingBudget(512), "thinking_budget"},
+		{"google dynamic thinking budget", Google, WithThinkingBudget(-1), ""},
+		{"openai unknown reasoning effort", OpenAI, WithReasoningEffort("max"), "reasoning_effort"},
+		{"openai minimal reasoning effort", OpenAI, WithReasoningEffort(EffortMinimal), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOptions(Provider{Name: tt.provider}, applyOptions(tt.opt))
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var valErr *ValidationError
+			if !errors.As(err, &valErr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if valErr.Field != tt.field {
+				t.Errorf("Field = %q, want %q", valErr.Field, tt.field)
+			}
+		})
+	}
+}
+
 func TestPrompt_UnknownProvider(t *testing.T) {
 	p := Provider{Name: "unknown", APIKey: "key"}
 	req := Request{User: "Hello"}
@@ -232,3 +283,113 @@ func TestPrompt_Structured(t *testing.T) {
 		})
 	}
 }
+
+func TestInlineFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := InlineFile(path)
+	if err != nil {
+		t.Fatalf("InlineFile() error = %v", err)
+	}
+	if f.Name != "report.pdf" || f.MimeType != "application/pdf" || string(f.Data) != "%PDF-1.4" {
+		t.Errorf("InlineFile() = %+v", f)
+	}
+	if !f.inline() {
+		t.Error("expected inline file")
+	}
+}
+
+func TestImageFromFile(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "chart.bin") // content wins over the extension
+	if err := os.WriteFile(path, png, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := ImageFromFile(path)
+	if err != nil {
+		t.Fatalf("ImageFromFile() error = %v", err)
+	}
+	want := "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+	if img.MimeType != "image/png" || img.URL != want {
+		t.Errorf("ImageFromFile() = %+v", img)
+	}
+
+	img, err = ImageFromReader(strings.NewReader("GIF89a..."))
+	if err != nil || img.MimeType != "image/gif" || !strings.HasPrefix(img.URL, "data:image/gif;base64,") {
+		t.Errorf("ImageFromReader() = %+v, %v", img, err)
+	}
+
+	_, err = ImageFromReader(strings.NewReader("plain text"))
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field != "image" {
+		t.Errorf("ImageFromReader(text) error = %v, want image ValidationError", err)
+	}
+}
+
+func TestPrompt_WithModel(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"choices":[{"message":{"content":"cheap"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`,
+		`{"choices":[{"message":{"content":"default"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`,
+	}}
+	p := Provider{Name: OpenAI, APIKey: "test-key", Model: "gpt-4o"}
+	client := WithHTTPClient(&http.Client{Transport: mock})
+
+	if _, err := Prompt(context.Background(), p, Request{User: "Hi"}, client, WithModel("gpt-4o-mini")); err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if _, err := Prompt(context.Background(), p, Request{User: "Hi"}, client); err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+
+	for i, want := range []string{"gpt-4o-mini", "gpt-4o"} {
+		var body openaiRequest
+		json.Unmarshal([]byte(mock.bodies[i]), &body)
+		if body.Model != want {
+			t.Errorf("request %d model = %q, want %q", i, body.Model, want)
+		}
+	}
+}
+
+func TestPrompt_Tools(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"choices":[{"message":{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]}}],"usage":{"prompt_tokens":10,"completion_tokens":5}}`,
+		`{"choices":[{"message":{"content":"72°F and sunny"}}],"usage":{"prompt_tokens":20,"completion_tokens":5}}`,
+	}}
+	p := Provider{Name: OpenAI, APIKey: "test-key"}
+
+	var after Response
+	resp, err := Prompt(context.Background(), p, Request{
+		System: "Be brief.",
+		User:   "Weather in Paris?",
+		Tools:  []Tool{testWeatherTool()},
+	}, WithHTTPClient(&http.Client{Transport: mock}), WithAfterResponse(func(_ context.Context, r *Response, _ error) { after = *r }))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.Text != "72°F and sunny" || resp.Tokens.Input != 30 || resp.RequestID == "" {
+		t.Errorf("response = %+v", resp)
+	}
+	if after.Text != resp.Text {
+		t.Errorf("after hook saw %+v", after)
+	}
+	if len(mock.bodies) != 2 || !strings.Contains(mock.bodies[1], `"role":"tool"`) {
+		t.Errorf("bodies = %q", mock.bodies)
+	}
+}
+
+func TestPrompt_ToolsMaxIterations(t *testing.T) {
+	call := `{"choices":[{"message":{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{}"}}]}}],"usage":{}}`
+	mock := &scriptedTransport{responses: []string{call, call}}
+	p := Provider{Name: OpenAI, APIKey: "test-key"}
+
+	_, err := Prompt(context.Background(), p, Request{User: "Weather?", Tools: []Tool{testWeatherTool()}},
+		WithHTTPClient(&http.Client{Transport: mock}), WithMaxToolIterations(2))
+	if err == nil || !strings.Contains(err.Error(), "exceeded max tool iterations (2)") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package llmkit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithLogger sends request, response, retry and tool events to l.
+// Requests, responses and tool calls log at Debug, retries and failed tool
+// calls at Warn, and failed requests at Error. Nothing is logged by default.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// log writes an event to the configured logger, if any.
+func (o *options) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if o.logger == nil || !o.logger.Enabled(ctx, level) {
+		return
+	}
+	o.logger.Log(ctx, level, msg, args...)
+}
+
+// logRequest logs the start of one provider call and returns its start time.
+func (o *options) logRequest(ctx context.Context, p Provider) time.Time {
+	o.log(ctx, slog.LevelDebug, "llmkit request",
+		"provider", p.Name, "model", p.model(), "request_id", RequestIDFromContext(ctx))
+	return o.clock.Now()
+}
+
+// logResponse logs the outcome of a provider call started at start.
+func (o *options) logResponse(ctx context.Context, p Provider, start time.Time, usage Usage, err error) {
+	args := []any{"provider", p.Name, "model", p.model(), "request_id", RequestIDFromContext(ctx),
+		"duration", o.clock.Now().Sub(start)}
+	if err != nil {
+		o.log(ctx, slog.LevelError, "llmkit request failed", append(args, "error", err)...)
+		return
+	}
+	o.log(ctx, slog.LevelDebug, "llmkit response",
+		append(args, "input_tokens", usage.Input, "output_tokens", usage.Output)...)
+}
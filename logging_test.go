@@ -0,0 +1,102 @@
+package llmkit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithLogger_Prompt(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		status = http.StatusOK
+		w.Write([]byte(`{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL, Model: "gpt-test"}
+
+	_, err := Prompt(context.Background(), p, Request{User: "Hello"}, WithLogger(logger),
+		WithRequestID("req_1"), WithRetry(RetryPolicy{BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`level=DEBUG msg="llmkit request" provider=openai model=gpt-test request_id=req_1`,
+		`level=WARN msg="llmkit retry"`, "status=503",
+		`level=DEBUG msg="llmkit response"`, "input_tokens=3 output_tokens=1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWithLogger_AgentTools(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"content": [
+			{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"city": "Paris"}},
+			{"type": "tool_use", "id": "toolu_2", "name": "broken", "input": {}}
+		], "usage": {"input_tokens": 10, "output_tokens": 5}}`,
+		`{"content": [{"type": "text", "text": "Sunny."}], "usage": {"input_tokens": 10, "output_tokens": 5}}`,
+	}}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	agent := NewAgent(Provider{Name: Anthropic, APIKey: "test-key"},
+		WithHTTPClient(&http.Client{Transport: mock}), WithLogger(logger))
+	agent.AddTool(testWeatherTool())
+	agent.AddTool(Tool{Name: "broken", Run: func(map[string]any) (string, error) {
+		return "", errors.New("service down")
+	}})
+
+	if _, err := agent.Chat(context.Background(), "Weather?"); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	out := buf.String()
+	// The provider has no Model, so its default is logged
+	if n := strings.Count(out, `msg="llmkit request" provider=anthropic model=`+DefaultModel(Anthropic)+" "); n != 2 {
+		t.Errorf("logged %d requests with the default model, want 2:\n%s", n, out)
+	}
+	for _, want := range []string{
+		`level=DEBUG msg="llmkit tool call" tool=get_weather`,
+		`level=WARN msg="llmkit tool failed" tool=broken`, `error="service down"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWithLogger_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"bad model"}}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+
+	Prompt(context.Background(), p, Request{User: "Hello"}, WithLogger(logger))
+	out := buf.String()
+	if !strings.Contains(out, `level=ERROR msg="llmkit request failed"`) || !strings.Contains(out, "bad model") {
+		t.Errorf("failure not logged:\n%s", out)
+	}
+	if strings.Contains(out, "DEBUG") {
+		t.Errorf("debug events logged at Info level:\n%s", out)
+	}
+}
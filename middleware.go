@@ -0,0 +1,30 @@
+package llmkit
+
+import "net/http"
+
+// Middleware wraps the transport that sends provider requests. It can add
+// headers, route through a proxy, record or replay traffic, and so on.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// WithMiddleware adds transport middleware. The first middleware given runs
+// first. Middleware sees every HTTP attempt: it sits below WithRetry and
+// WithRateLimit, so a retried request passes through it again.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, mw...)
+	}
+}
+
+// middlewareClient returns a copy of c whose transport is wrapped by mw.
+func middlewareClient(c *http.Client, mw []Middleware) *http.Client {
+	rt := c.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		rt = mw[i](rt)
+	}
+	wrapped := *c
+	wrapped.Transport = rt
+	return &wrapped
+}
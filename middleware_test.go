@@ -0,0 +1,51 @@
+package llmkit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var order []string
+	var attempts int
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+		order = append(order, "base:"+r.Header.Get("X-Proxy-Auth"))
+		status := http.StatusOK
+		if attempts == 1 {
+			status = http.StatusServiceUnavailable
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(`{"choices":[{"message":{"content":"hi"}}]}`)),
+			Header:     make(http.Header),
+		}, nil
+	})
+	tag := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				r = r.Clone(r.Context())
+				r.Header.Set("X-Proxy-Auth", "secret")
+				return next.RoundTrip(r)
+			})
+		}
+	}
+
+	p := Provider{Name: OpenAI, APIKey: "test-key"}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hello"},
+		WithHTTPClient(&http.Client{Transport: base}),
+		WithMiddleware(tag("outer"), tag("inner")),
+		WithRetry(RetryPolicy{BaseDelay: 1}))
+	if err != nil || resp.Text != "hi" {
+		t.Fatalf("Prompt() = %q, %v", resp.Text, err)
+	}
+
+	want := "outer inner base:secret outer inner base:secret"
+	if got := strings.Join(order, " "); got != want {
+		t.Errorf("order = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,59 @@
+package llmkit
+
+import (
+	"context"
+	"sort"
+)
+
+// defaultModerationModel classifies both text and images.
+const defaultModerationModel = "omni-moderation-latest"
+
+// ModerationRequest is content to classify with Moderate.
+type ModerationRequest struct {
+	Text   string
+	Images []Image // URLs or base64 data URIs
+}
+
+// Moderation is a safety classification of one ModerationRequest.
+type Moderation struct {
+	Flagged    bool               // any category was flagged
+	Categories map[string]bool    // e.g. "harassment", "self-harm/intent", "violence"
+	Scores     map[string]float64 // per-category confidence, 0 to 1
+	Model      string
+}
+
+// FlaggedCategories returns the flagged category names.
+func (m Moderation) FlaggedCategories() []string {
+	var out []string
+	for name, flagged := range m.Categories {
+		if flagged {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Moderate classifies text and images against the provider's content policy,
+// so unsafe input or output can be blocked before or after an LLM call.
+// OpenAI only; WithModel overrides the default omni-moderation-latest model.
+func Moderate(ctx context.Context, p Provider, req ModerationRequest, opts ...Option) (Moderation, error) {
+	if err := validateProvider(p); err != nil {
+		return Moderation{}, err
+	}
+	if p.Name != OpenAI {
+		return Moderation{}, &ValidationError{Field: "provider", Message: "moderation not supported by " + p.Name}
+	}
+	if req.Text == "" && len(req.Images) == 0 {
+		return Moderation{}, &ValidationError{Field: "input", Message: "text or images required"}
+	}
+
+	o := applyOptions(opts...)
+	model := o.model
+	if model == "" {
+		model = defaultModerationModel
+	}
+	return withPoolKey(ctx, p, o, func(p Provider) (Moderation, error) {
+		return moderateOpenAI(ctx, p, model, req, o)
+	})
+}
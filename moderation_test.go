@@ -0,0 +1,57 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestModerate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != openaiModerationsPath {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		var req openaiModerationRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != defaultModerationModel || len(req.Input) != 2 ||
+			req.Input[0].Text != "some text" || req.Input[1].ImageURL.URL != "https://example.com/a.png" {
+			t.Errorf("request = %+v", req)
+		}
+		w.Write([]byte(`{"model":"omni-moderation-2024-09-26","results":[{"flagged":true,
+			"categories":{"violence":true,"harassment":false,"self-harm/intent":true},
+			"category_scores":{"violence":0.91,"harassment":0.01,"self-harm/intent":0.7}}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	m, err := Moderate(context.Background(), p, ModerationRequest{
+		Text:   "some text",
+		Images: []Image{{URL: "https://example.com/a.png"}},
+	})
+	if err != nil {
+		t.Fatalf("Moderate() error = %v", err)
+	}
+	if !m.Flagged || m.Scores["violence"] != 0.91 || m.Model != "omni-moderation-2024-09-26" {
+		t.Errorf("Moderation = %+v", m)
+	}
+	if got := m.FlaggedCategories(); len(got) != 2 || got[0] != "self-harm/intent" || got[1] != "violence" {
+		t.Errorf("FlaggedCategories() = %v", got)
+	}
+}
+
+func TestModerate_Validation(t *testing.T) {
+	ctx := context.Background()
+	var ve *ValidationError
+
+	_, err := Moderate(ctx, Provider{Name: Anthropic, APIKey: "k"}, ModerationRequest{Text: "x"})
+	if !errors.As(err, &ve) || ve.Field != "provider" {
+		t.Errorf("error = %v, want provider ValidationError", err)
+	}
+	_, err = Moderate(ctx, Provider{Name: OpenAI, APIKey: "k"}, ModerationRequest{})
+	if !errors.As(err, &ve) || ve.Field != "input" {
+		t.Errorf("error = %v, want input ValidationError", err)
+	}
+}
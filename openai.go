@@ -1,28 +1,52 @@
 package llmkit
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
-	openaiChatPath  = "/v1/chat/completions"
-	openaiFilesPath = "/v1/files"
+	openaiChatPath         = "/v1/chat/completions"
+	openaiFilesPath        = "/v1/files"
+	openaiEmbeddingsPath   = "/v1/embeddings"
+	openaiImagesPath       = "/v1/images/generations"
+	openaiImageEditsPath   = "/v1/images/edits"
+	openaiBatchesPath      = "/v1/batches"
+	openaiResponsesPath    = "/v1/responses"
+	openaiVectorStoresPath = "/v1/vector_stores"
+	openaiModerationsPath  = "/v1/moderations"
+	openaiSpeechPath       = "/v1/audio/speech"
+	openaiTranscribePath   = "/v1/audio/transcriptions"
+	openaiTranslatePath    = "/v1/audio/translations"
 )
 
 type openaiRequest struct {
-	Model            string          `json:"model"`
-	Messages         []openaiMessage `json:"messages"`
-	Tools            []openaiTool    `json:"tools,omitempty"`
-	ResponseFormat   *responseFormat `json:"response_format,omitempty"`
-	Temperature      *float64        `json:"temperature,omitempty"`
-	TopP             *float64        `json:"top_p,omitempty"`
-	MaxTokens        *int            `json:"max_tokens,omitempty"`
-	Stop             []string        `json:"stop,omitempty"`
-	Seed             *int64          `json:"seed,omitempty"`
-	FrequencyPenalty *float64        `json:"frequency_penalty,omitempty"`
-	PresencePenalty  *float64        `json:"presence_penalty,omitempty"`
-	ReasoningEffort  string          `json:"reasoning_effort,omitempty"`
+	Model             string            `json:"model"`
+	Messages          []openaiMessage   `json:"messages"`
+	Tools             []openaiTool      `json:"tools,omitempty"`
+	ParallelToolCalls *bool             `json:"parallel_tool_calls,omitempty"`
+	ResponseFormat    *responseFormat   `json:"response_format,omitempty"`
+	Temperature       *float64          `json:"temperature,omitempty"`
+	TopP              *float64          `json:"top_p,omitempty"`
+	MaxTokens         *int              `json:"max_tokens,omitempty"`
+	Stop              []string          `json:"stop,omitempty"`
+	Seed              *int64            `json:"seed,omitempty"`
+	FrequencyPenalty  *float64          `json:"frequency_penalty,omitempty"`
+	PresencePenalty   *float64          `json:"presence_penalty,omitempty"`
+	ReasoningEffort   string            `json:"reasoning_effort,omitempty"`
+	Stream            bool              `json:"stream,omitempty"`
+	StreamOptions     *openaiStreamOpts `json:"stream_options,omitempty"`
+}
+
+type openaiStreamOpts struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type openaiTool struct {
@@ -48,30 +72,41 @@ type jsonSchema struct {
 }
 
 type openaiMessage struct {
-	Role       string              `json:"role"`
-	Content    any                 `json:"content,omitempty"`     // []openaiContent or string
-	ToolCalls  []openaiToolCall    `json:"tool_calls,omitempty"`  // for assistant
-	ToolCallID string              `json:"tool_call_id,omitempty"` // for tool role
+	Role       string           `json:"role"`
+	Content    any              `json:"content,omitempty"`      // []openaiContent or string
+	ToolCalls  []openaiToolCall `json:"tool_calls,omitempty"`   // for assistant
+	ToolCallID string           `json:"tool_call_id,omitempty"` // for tool role
 }
 
 type openaiToolCall struct {
-	ID       string `json:"id"`
-	Type     string `json:"type"`
-	Function struct {
-		Name      string `json:"name"`
-		Arguments string `json:"arguments"` // JSON string
-	} `json:"function"`
+	ID       string             `json:"id"`
+	Type     string             `json:"type"`
+	Function openaiFunctionCall `json:"function"`
+}
+
+type openaiFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON string
 }
 
 type openaiContent struct {
-	Type     string          `json:"type"`
-	Text     string          `json:"text,omitempty"`
-	ImageURL *openaiImageURL `json:"image_url,omitempty"`
-	File     *openaiFile     `json:"file,omitempty"`
+	Type       string            `json:"type"`
+	Text       string            `json:"text,omitempty"`
+	ImageURL   *openaiImageURL   `json:"image_url,omitempty"`
+	File       *openaiFile       `json:"file,omitempty"`
+	InputAudio *openaiInputAudio `json:"input_audio,omitempty"`
+}
+
+// openaiInputAudio is an inline audio clip for audio-capable chat models.
+type openaiInputAudio struct {
+	Data   string `json:"data"`   // base64, no data URI prefix
+	Format string `json:"format"` // "wav" or "mp3"
 }
 
 type openaiFile struct {
-	FileID string `json:"file_id"`
+	FileID   string `json:"file_id,omitempty"`
+	FileData string `json:"file_data,omitempty"` // base64 data URI for inline files
+	Filename string `json:"filename,omitempty"`  // required with file_data
 }
 
 type openaiImageURL struct {
@@ -80,20 +115,62 @@ type openaiImageURL struct {
 }
 
 type openaiResponse struct {
+	Choices           []openaiChoice `json:"choices"`
+	Usage             openaiUsage    `json:"usage"`
+	SystemFingerprint string         `json:"system_fingerprint,omitempty"`
+}
+
+type openaiChoice struct {
+	Message struct {
+		Content   string           `json:"content"`
+		ToolCalls []openaiToolCall `json:"tool_calls,omitempty"`
+	} `json:"message"`
+	FinishReason string `json:"finish_reason"`
+}
+
+type openaiUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+		AudioTokens  int `json:"audio_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+		AudioTokens     int `json:"audio_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+func (u openaiUsage) usage() Usage {
+	return Usage{
+		Input:           u.PromptTokens,
+		Output:          u.CompletionTokens,
+		CacheReadTokens: u.PromptTokensDetails.CachedTokens,
+		ReasoningTokens: u.CompletionTokensDetails.ReasoningTokens,
+		AudioTokens:     u.PromptTokensDetails.AudioTokens + u.CompletionTokensDetails.AudioTokens,
+	}
+}
+
+// openaiStreamChunk is one chat.completion.chunk event.
+type openaiStreamChunk struct {
 	Choices []struct {
-		Message struct {
-			Content   string           `json:"content"`
-			ToolCalls []openaiToolCall `json:"tool_calls,omitempty"`
-		} `json:"message"`
+		Index int `json:"index"`
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int                `json:"index"`
+				ID       string             `json:"id"`
+				Type     string             `json:"type"`
+				Function openaiFunctionCall `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-	} `json:"usage"`
+	Usage *openaiUsage `json:"usage"`
 }
 
-func promptOpenAI(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
+// openaiPayload builds the chat completions request for req.
+func openaiPayload(p Provider, req Request, o *options) (openaiRequest, error) {
 	var msgs []openaiMessage
 	if req.System != "" {
 		msgs = append(msgs, openaiMessage{
@@ -105,7 +182,7 @@ func promptOpenAI(ctx context.Context, p Provider, req Request, o *options) (Res
 		for _, m := range req.Messages {
 			msgs = append(msgs, openaiMessage{
 				Role:    m.Role,
-				Content: []openaiContent{{Type: "text", Text: m.Content}},
+				Content: buildOpenAIContent(Request{User: m.Content, Images: m.Images, Files: m.Files}),
 			})
 		}
 	} else {
@@ -126,9 +203,9 @@ func promptOpenAI(ctx context.Context, p Provider, req Request, o *options) (Res
 	}
 
 	if req.Schema != "" {
-		var schema any
-		if err := json.Unmarshal([]byte(req.Schema), &schema); err != nil {
-			return Response{}, err
+		schema, _, err := TranslateSchema(p.Name, req.Schema)
+		if err != nil {
+			return openaiRequest{}, err
 		}
 		payload.ResponseFormat = &responseFormat{
 			Type: "json_schema",
@@ -139,42 +216,208 @@ func promptOpenAI(ctx context.Context, p Provider, req Request, o *options) (Res
 			},
 		}
 	}
+	return payload, nil
+}
+
+func promptOpenAI(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
+	if len(o.fileSearch) > 0 {
+		return promptOpenAIResponses(ctx, p, req, o)
+	}
 
-	body, err := json.Marshal(payload)
+	payload, err := openaiPayload(p, req, o)
 	if err != nil {
 		return Response{}, err
 	}
 
-	headers := map[string]string{
-		"Authorization": "Bearer " + p.APIKey,
-	}
+	headers := openaiHeaders(p)
 
-	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, p.buildURL(openaiChatPath), body, headers)
+	resp, err := postOpenAI(ctx, p, payload, headers, o)
 	if err != nil {
 		return Response{}, err
 	}
 
+	text := ""
+	var finish FinishReason
+	if len(resp.Choices) > 0 {
+		text = resp.Choices[0].Message.Content
+		finish = openaiFinishReason(resp.Choices[0].FinishReason)
+	}
+
+	return Response{
+		Text:              text,
+		Tokens:            resp.Usage.usage(),
+		FinishReason:      finish,
+		SystemFingerprint: resp.SystemFingerprint,
+	}, nil
+}
+
+// openaiFinishReason normalizes a chat completions finish_reason.
+func openaiFinishReason(reason string) FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "stop":
+		return FinishStop
+	case "length":
+		return FinishLength
+	case "tool_calls", "function_call":
+		return FinishToolUse
+	case "content_filter":
+		return FinishContentFilter
+	default:
+		return FinishOther
+	}
+}
+
+// responsesFinishReason normalizes the status of a Responses API result,
+// which OpenAI and Grok share.
+func responsesFinishReason(status, incompleteReason string) FinishReason {
+	switch status {
+	case "":
+		return ""
+	case "completed":
+		return FinishStop
+	case "incomplete":
+		switch incompleteReason {
+		case "max_output_tokens":
+			return FinishLength
+		case "content_filter":
+			return FinishContentFilter
+		}
+	}
+	return FinishOther
+}
+
+// openaiHeaders returns auth headers, including organization and project when set.
+func openaiHeaders(p Provider) map[string]string {
+	headers := map[string]string{}
+	if p.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.APIKey
+	}
+	if p.Organization != "" {
+		headers["OpenAI-Organization"] = p.Organization
+	}
+	if p.Project != "" {
+		headers["OpenAI-Project"] = p.Project
+	}
+	if p.AppURL != "" {
+		headers["HTTP-Referer"] = p.AppURL
+	}
+	if p.AppName != "" {
+		headers["X-Title"] = p.AppName
+	}
+	return p.withHeaders(headers)
+}
+
+// postOpenAI sends a chat completions request and decodes the response.
+// When o.stream is set the response is streamed instead (see streamOpenAI).
+func postOpenAI(ctx context.Context, p Provider, payload openaiRequest, headers map[string]string, o *options) (openaiResponse, error) {
+	if o.stream != nil {
+		return streamOpenAI(ctx, p, payload, headers, o)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return openaiResponse{}, err
+	}
+
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, p.buildURL(openaiChatPath), body, headers)
+	if err != nil {
+		return openaiResponse{}, err
+	}
+
 	if statusCode >= 400 {
-		return Response{}, parseError(OpenAI, statusCode, respBody, nil)
+		return openaiResponse{}, parseError(p.Name, statusCode, respBody, respHeaders)
 	}
 
 	var resp openaiResponse
 	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return Response{}, err
+		return openaiResponse{}, err
 	}
+	return resp, nil
+}
 
-	text := ""
-	if len(resp.Choices) > 0 {
-		text = resp.Choices[0].Message.Content
+// streamOpenAI sends a streaming chat completions request, forwards content deltas
+// to o.stream, and reassembles the message, including tool call argument fragments.
+func streamOpenAI(ctx context.Context, p Provider, payload openaiRequest, headers map[string]string, o *options) (openaiResponse, error) {
+	payload.Stream = true
+	payload.StreamOptions = &openaiStreamOpts{IncludeUsage: true}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return openaiResponse{}, err
 	}
 
-	return Response{
-		Text: text,
-		Tokens: Usage{
-			Input:  resp.Usage.PromptTokens,
-			Output: resp.Usage.CompletionTokens,
-		},
-	}, nil
+	httpResp, errBody, err := doPostStream(ctx, o.httpClient, p.buildURL(openaiChatPath), body, headers)
+	if err != nil {
+		return openaiResponse{}, err
+	}
+	if httpResp.StatusCode >= 400 {
+		return openaiResponse{}, parseError(p.Name, httpResp.StatusCode, errBody, httpResp.Header)
+	}
+	defer httpResp.Body.Close()
+
+	var choice openaiChoice
+	var resp openaiResponse
+	err = readStream(ctx, httpResp.Body, func(event, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk openaiStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return err
+		}
+		if chunk.Usage != nil {
+			resp.Usage = *chunk.Usage
+			o.reportUsage(resp.Usage.usage())
+		}
+
+		for _, c := range chunk.Choices {
+			if c.Index != 0 {
+				continue
+			}
+			if c.Delta.Content != "" {
+				choice.Message.Content += c.Delta.Content
+				o.stream(c.Delta.Content)
+			}
+			// Tool calls arrive as fragments keyed by index; the first carries id and name
+			for _, tc := range c.Delta.ToolCalls {
+				for len(choice.Message.ToolCalls) <= tc.Index {
+					choice.Message.ToolCalls = append(choice.Message.ToolCalls, openaiToolCall{Type: "function"})
+				}
+				call := &choice.Message.ToolCalls[tc.Index]
+				if tc.ID != "" {
+					call.ID = tc.ID
+				}
+				if tc.Function.Name != "" {
+					call.Function.Name = tc.Function.Name
+				}
+				call.Function.Arguments += tc.Function.Arguments
+			}
+			if c.FinishReason != "" {
+				choice.FinishReason = c.FinishReason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return openaiResponse{}, err
+	}
+
+	resp.Choices = []openaiChoice{choice}
+	return resp, nil
+}
+
+// openaiAudioFormat returns the input_audio format for a MIME type, or "" when
+// chat models do not accept it as audio.
+func openaiAudioFormat(mimeType string) string {
+	switch mimeType {
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return "wav"
+	case "audio/mpeg", "audio/mp3":
+		return "mp3"
+	default:
+		return ""
+	}
 }
 
 // buildOpenAIContent creates content array from request.
@@ -183,9 +426,20 @@ func buildOpenAIContent(req Request) []openaiContent {
 
 	// Add files first
 	for _, f := range req.Files {
+		if format := openaiAudioFormat(f.MimeType); format != "" && f.inline() {
+			content = append(content, openaiContent{
+				Type:       "input_audio",
+				InputAudio: &openaiInputAudio{Data: base64.StdEncoding.EncodeToString(f.Data), Format: format},
+			})
+			continue
+		}
+		file := &openaiFile{FileID: f.ID}
+		if f.inline() {
+			file = &openaiFile{FileData: dataURI(f.MimeType, f.Data), Filename: f.Name}
+		}
 		content = append(content, openaiContent{
 			Type: "file",
-			File: &openaiFile{FileID: f.ID},
+			File: file,
 		})
 	}
 
@@ -213,7 +467,7 @@ func buildOpenAIContent(req Request) []openaiContent {
 }
 
 // sendOpenAIWithTools sends a request with tools and returns tool calls.
-func sendOpenAIWithTools(ctx context.Context, p Provider, msgs []message, system string, tools []Tool, o *options) (string, []toolCall, Usage, error) {
+func sendOpenAIWithTools(ctx context.Context, p Provider, msgs []message, system string, tools []Tool, o *options) (turn, error) {
 	// Build messages
 	var messages []openaiMessage
 	if system != "" {
@@ -239,18 +493,25 @@ func sendOpenAIWithTools(ctx context.Context, p Provider, msgs []message, system
 				oaiCalls = append(oaiCalls, openaiToolCall{
 					ID:   tc.id,
 					Type: "function",
-					Function: struct {
-						Name      string `json:"name"`
-						Arguments string `json:"arguments"`
-					}{
+					Function: openaiFunctionCall{
 						Name:      tc.name,
 						Arguments: string(argsJSON),
 					},
 				})
 			}
-			messages = append(messages, openaiMessage{
+			msg := openaiMessage{
 				Role:      "assistant",
 				ToolCalls: oaiCalls,
+			}
+			if m.content != "" {
+				msg.Content = m.content
+			}
+			messages = append(messages, msg)
+		} else if len(m.images) > 0 || len(m.files) > 0 {
+			// User message with attachments
+			messages = append(messages, openaiMessage{
+				Role:    m.role,
+				Content: buildOpenAIContent(Request{User: m.content, Images: m.images, Files: m.files}),
 			})
 		} else {
 			// Regular text message
@@ -287,51 +548,36 @@ func sendOpenAIWithTools(ctx context.Context, p Provider, msgs []message, system
 		PresencePenalty:  o.presencePenalty,
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return "", nil, Usage{}, err
+	// parallel_tool_calls is only valid when tools are present
+	if len(oaiTools) > 0 {
+		payload.ParallelToolCalls = o.parallelToolCalls
 	}
 
-	headers := map[string]string{
-		"Authorization": "Bearer " + p.APIKey,
-	}
+	headers := openaiHeaders(p)
 
-	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, p.buildURL(openaiChatPath), body, headers)
+	resp, err := postOpenAI(ctx, p, payload, headers, o)
 	if err != nil {
-		return "", nil, Usage{}, err
-	}
-
-	if statusCode >= 400 {
-		return "", nil, Usage{}, parseError(OpenAI, statusCode, respBody, nil)
-	}
-
-	var resp openaiResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return "", nil, Usage{}, err
+		return turn{}, err
 	}
 
 	// Extract text and tool calls
-	var text string
-	var calls []toolCall
+	var t turn
 	if len(resp.Choices) > 0 {
-		text = resp.Choices[0].Message.Content
+		t.text = resp.Choices[0].Message.Content
+		t.finishReason = openaiFinishReason(resp.Choices[0].FinishReason)
 		for _, tc := range resp.Choices[0].Message.ToolCalls {
 			var input map[string]any
 			json.Unmarshal([]byte(tc.Function.Arguments), &input)
-			calls = append(calls, toolCall{
+			t.calls = append(t.calls, toolCall{
 				id:    tc.ID,
 				name:  tc.Function.Name,
 				input: input,
 			})
 		}
 	}
+	t.usage = resp.Usage.usage()
 
-	usage := Usage{
-		Input:  resp.Usage.PromptTokens,
-		Output: resp.Usage.CompletionTokens,
-	}
-
-	return text, calls, usage, nil
+	return t, nil
 }
 
 type openaiFileResponse struct {
@@ -341,31 +587,922 @@ type openaiFileResponse struct {
 
 // uploadOpenAI uploads a file to OpenAI's Files API.
 func uploadOpenAI(ctx context.Context, p Provider, data []byte, name string, o *options) (File, error) {
-	headers := map[string]string{
-		"Authorization": "Bearer " + p.APIKey,
+	resp, err := uploadOpenAIFile(ctx, p, data, name, "assistants", o)
+	if err != nil {
+		return File{}, err
 	}
+
+	return File{
+		ID:       resp.ID,
+		MimeType: detectMimeType(name),
+		Name:     resp.Filename,
+	}, nil
+}
+
+// uploadOpenAIFile uploads data to the Files API for purpose ("assistants", "batch", ...).
+func uploadOpenAIFile(ctx context.Context, p Provider, data []byte, name, purpose string, o *options) (openaiFileResponse, error) {
 	fields := map[string]string{
-		"purpose": "assistants",
+		"purpose": purpose,
 	}
 
 	respBody, statusCode, err := doMultipartPost(ctx, o.httpClient, p.buildURL(openaiFilesPath),
-		"file", name, data, fields, headers)
+		"file", name, data, fields, openaiHeaders(p))
 	if err != nil {
-		return File{}, err
+		return openaiFileResponse{}, err
 	}
 
 	if statusCode >= 400 {
-		return File{}, parseError(OpenAI, statusCode, respBody, nil)
+		return openaiFileResponse{}, parseError(OpenAI, statusCode, respBody, nil)
 	}
 
 	var resp openaiFileResponse
 	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return File{}, err
+		return openaiFileResponse{}, err
 	}
+	return resp, nil
+}
 
-	return File{
-		ID:       resp.ID,
-		MimeType: detectMimeType(name),
-		Name:     resp.Filename,
+type openaiEmbeddingRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions *int     `json:"dimensions,omitempty"`
+}
+
+type openaiEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func embedOpenAI(ctx context.Context, p Provider, model string, texts []string, o *options) ([][]float32, error) {
+	body, err := json.Marshal(openaiEmbeddingRequest{Model: model, Input: texts, Dimensions: o.dimensions})
+	if err != nil {
+		return nil, err
+	}
+
+	headers := openaiHeaders(p)
+
+	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, p.buildURL(openaiEmbeddingsPath), body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode >= 400 {
+		return nil, parseError(OpenAI, statusCode, respBody, nil)
+	}
+
+	var resp openaiEmbeddingResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+
+	// Results carry their input index and are not guaranteed to be ordered
+	vectors := make([][]float32, len(resp.Data))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("openai: embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+type openaiModerationRequest struct {
+	Model string          `json:"model"`
+	Input []openaiContent `json:"input"`
+}
+
+type openaiModerationResponse struct {
+	Model   string `json:"model"`
+	Results []struct {
+		Flagged        bool               `json:"flagged"`
+		Categories     map[string]bool    `json:"categories"`
+		CategoryScores map[string]float64 `json:"category_scores"`
+	} `json:"results"`
+}
+
+// moderateOpenAI classifies req as one multimodal input.
+func moderateOpenAI(ctx context.Context, p Provider, model string, req ModerationRequest, o *options) (Moderation, error) {
+	var input []openaiContent
+	if req.Text != "" {
+		input = append(input, openaiContent{Type: "text", Text: req.Text})
+	}
+	for _, img := range req.Images {
+		input = append(input, openaiContent{Type: "image_url", ImageURL: &openaiImageURL{URL: img.URL}})
+	}
+	body, err := json.Marshal(openaiModerationRequest{Model: model, Input: input})
+	if err != nil {
+		return Moderation{}, err
+	}
+
+	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, p.buildURL(openaiModerationsPath), body, openaiHeaders(p))
+	if err != nil {
+		return Moderation{}, err
+	}
+	if statusCode >= 400 {
+		return Moderation{}, parseError(OpenAI, statusCode, respBody, nil)
+	}
+
+	var resp openaiModerationResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return Moderation{}, err
+	}
+	if len(resp.Results) == 0 {
+		return Moderation{}, fmt.Errorf("openai: moderation returned no results")
+	}
+	r := resp.Results[0]
+	return Moderation{Flagged: r.Flagged, Categories: r.Categories, Scores: r.CategoryScores, Model: resp.Model}, nil
+}
+
+type openaiSpeechRequest struct {
+	Model          string  `json:"model"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	ResponseFormat string  `json:"response_format,omitempty"`
+	Instructions   string  `json:"instructions,omitempty"`
+	Speed          float64 `json:"speed,omitempty"`
+}
+
+// speakOpenAI synthesizes speech. The response body is the audio itself.
+func speakOpenAI(ctx context.Context, p Provider, req SpeechRequest, o *options) (SpeechResponse, error) {
+	body, err := json.Marshal(openaiSpeechRequest{
+		Model:          req.Model,
+		Input:          req.Text,
+		Voice:          req.Voice,
+		ResponseFormat: req.Format,
+		Instructions:   req.Instructions,
+		Speed:          req.Speed,
+	})
+	if err != nil {
+		return SpeechResponse{}, err
+	}
+
+	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, p.buildURL(openaiSpeechPath), body, openaiHeaders(p))
+	if err != nil {
+		return SpeechResponse{}, err
+	}
+	if statusCode >= 400 {
+		return SpeechResponse{}, parseError(OpenAI, statusCode, respBody, nil)
+	}
+	return SpeechResponse{Audio: respBody, MimeType: audioMimeType(req.Format)}, nil
+}
+
+// speakOpenAIStream requests speech like speakOpenAI but hands the chunked
+// response body to the caller instead of reading it.
+func speakOpenAIStream(ctx context.Context, p Provider, req SpeechRequest, o *options) (*SpeechStream, error) {
+	body, err := json.Marshal(openaiSpeechRequest{
+		Model:          req.Model,
+		Input:          req.Text,
+		Voice:          req.Voice,
+		ResponseFormat: req.Format,
+		Instructions:   req.Instructions,
+		Speed:          req.Speed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	headers := openaiHeaders(p)
+	headers["Accept"] = audioMimeType(req.Format)
+	httpResp, errBody, err := doPostStream(ctx, o.httpClient, p.buildURL(openaiSpeechPath), body, headers)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode >= 400 {
+		return nil, parseError(OpenAI, httpResp.StatusCode, errBody, httpResp.Header)
+	}
+	return &SpeechStream{ReadCloser: httpResp.Body, MimeType: audioMimeType(req.Format)}, nil
+}
+
+type openaiTranscription struct {
+	Text     string  `json:"text"`
+	Language string  `json:"language,omitempty"` // verbose_json only, e.g. "english"
+	Duration float64 `json:"duration,omitempty"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments,omitempty"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// transcribeOpenAI uploads audio to the transcriptions endpoint, or with
+// translate the translations endpoint, which always answers in English. Only
+// whisper-1 returns timed segments (verbose_json); gpt-4o models return text.
+func transcribeOpenAI(ctx context.Context, p Provider, model string, audio File, translate bool, o *options) (Transcript, error) {
+	path := openaiTranscribePath
+	fields := map[string]string{
+		"model":           model,
+		"response_format": "json",
+	}
+	if model == "whisper-1" {
+		fields["response_format"] = "verbose_json"
+	}
+	if translate {
+		// Translations take neither a language nor timestamp granularities
+		path = openaiTranslatePath
+	} else {
+		if model == "whisper-1" {
+			fields["timestamp_granularities[]"] = "segment"
+		}
+		if o.language != "" {
+			fields["language"] = baseLanguage(o.language)
+		}
+	}
+	files := []multipartFile{{field: "file", filename: audioFilename(audio), data: audio.Data}}
+
+	respBody, statusCode, err := doMultipartFiles(ctx, o.httpClient, p.buildURL(path), files, fields, openaiHeaders(p))
+	if err != nil {
+		return Transcript{}, err
+	}
+	if statusCode >= 400 {
+		return Transcript{}, parseError(OpenAI, statusCode, respBody, nil)
+	}
+
+	var resp openaiTranscription
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return Transcript{}, err
+	}
+	t := Transcript{
+		Text:     resp.Text,
+		Duration: seconds(resp.Duration),
+		Tokens:   Usage{Input: resp.Usage.InputTokens, Output: resp.Usage.OutputTokens},
+	}
+	if translate {
+		t.Language = "en"
+	} else if resp.Language != "" {
+		t.Language = languageCode(resp.Language)
+	}
+	for _, s := range resp.Segments {
+		t.Segments = append(t.Segments, TranscriptSegment{Start: seconds(s.Start), End: seconds(s.End), Text: strings.TrimSpace(s.Text)})
+	}
+	return t, nil
+}
+
+// audioFilename names an upload; OpenAI detects the audio format from its extension.
+func audioFilename(f File) string {
+	if f.Name != "" {
+		return f.Name
+	}
+	ext := ".mp3"
+	switch f.MimeType {
+	case "audio/wav", "audio/x-wav":
+		ext = ".wav"
+	case "audio/mp4", "audio/m4a":
+		ext = ".m4a"
+	case "audio/ogg":
+		ext = ".ogg"
+	case "audio/flac":
+		ext = ".flac"
+	case "audio/webm":
+		ext = ".webm"
+	}
+	return "audio" + ext
+}
+
+type openaiImageRequest struct {
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n,omitempty"`
+	Size           string `json:"size,omitempty"`
+	Quality        string `json:"quality,omitempty"`
+	OutputFormat   string `json:"output_format,omitempty"`   // gpt-image models
+	ResponseFormat string `json:"response_format,omitempty"` // dall-e models
+}
+
+type openaiImageResponse struct {
+	Data []struct {
+		B64JSON       string `json:"b64_json"`
+		RevisedPrompt string `json:"revised_prompt,omitempty"`
+	} `json:"data"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func generateImageOpenAI(ctx context.Context, p Provider, req ImageRequest, o *options) (ImageResponse, error) {
+	payload := openaiImageRequest{
+		Model:   req.Model,
+		Prompt:  req.Prompt,
+		N:       req.N,
+		Size:    req.Size,
+		Quality: req.Quality,
+	}
+	// gpt-image models always return base64; DALL-E must be asked for it
+	if isDallE(req.Model) {
+		payload.ResponseFormat = "b64_json"
+	} else {
+		payload.OutputFormat = req.Format
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return ImageResponse{}, err
+	}
+
+	headers := openaiHeaders(p)
+
+	respBody, statusCode, err := doPostRaw(ctx, o.httpClient, p.buildURL(openaiImagesPath), body, headers)
+	if err != nil {
+		return ImageResponse{}, err
+	}
+
+	if statusCode >= 400 {
+		return ImageResponse{}, parseError(OpenAI, statusCode, respBody, nil)
+	}
+
+	return decodeOpenAIImages(respBody, req)
+}
+
+func editImageOpenAI(ctx context.Context, p Provider, req ImageRequest, o *options) (ImageResponse, error) {
+	fields := map[string]string{
+		"model":  req.Model,
+		"prompt": req.Prompt,
+		"n":      strconv.Itoa(req.N),
+	}
+	if req.Size != "" {
+		fields["size"] = req.Size
+	}
+	if req.Quality != "" {
+		fields["quality"] = req.Quality
+	}
+	if isDallE(req.Model) {
+		fields["response_format"] = "b64_json"
+	} else if req.Format != "" {
+		fields["output_format"] = req.Format
+	}
+
+	// gpt-image models accept several source images as image[]
+	field := "image[]"
+	if isDallE(req.Model) {
+		field = "image"
+	}
+	var files []multipartFile
+	for i, img := range req.Images {
+		files = append(files, multipartFile{field: field, filename: imageFilename(img, i), data: img.Data})
+	}
+	if req.Mask != nil {
+		files = append(files, multipartFile{field: "mask", filename: imageFilename(*req.Mask, 0), data: req.Mask.Data})
+	}
+
+	headers := openaiHeaders(p)
+
+	respBody, statusCode, err := doMultipartFiles(ctx, o.httpClient, p.buildURL(openaiImageEditsPath), files, fields, headers)
+	if err != nil {
+		return ImageResponse{}, err
+	}
+
+	if statusCode >= 400 {
+		return ImageResponse{}, parseError(OpenAI, statusCode, respBody, nil)
+	}
+
+	return decodeOpenAIImages(respBody, req)
+}
+
+// decodeOpenAIImages decodes base64 image data from an images response.
+func decodeOpenAIImages(respBody []byte, req ImageRequest) (ImageResponse, error) {
+	var resp openaiImageResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return ImageResponse{}, err
+	}
+
+	format := req.Format
+	if isDallE(req.Model) {
+		format = "png"
+	}
+
+	out := ImageResponse{
+		Tokens: Usage{Input: resp.Usage.InputTokens, Output: resp.Usage.OutputTokens},
+	}
+	for _, d := range resp.Data {
+		data, err := base64.StdEncoding.DecodeString(d.B64JSON)
+		if err != nil {
+			return ImageResponse{}, fmt.Errorf("openai: decode image: %w", err)
+		}
+		out.Images = append(out.Images, GeneratedImage{
+			Data:          data,
+			MimeType:      imageMimeType(format),
+			RevisedPrompt: d.RevisedPrompt,
+		})
+	}
+	return out, nil
+}
+
+// imageFilename returns a filename whose extension matches the image type,
+// so the multipart part gets the right Content-Type.
+func imageFilename(f File, i int) string {
+	if f.Name != "" {
+		return f.Name
+	}
+	ext := ".png"
+	switch f.MimeType {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/webp":
+		ext = ".webp"
+	}
+	return "image" + strconv.Itoa(i) + ext
+}
+
+// openaiBatch is a batch object from the Batch API.
+type openaiBatch struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	OutputFileID  string `json:"output_file_id"`
+	ErrorFileID   string `json:"error_file_id"`
+	CreatedAt     int64  `json:"created_at"`
+	RequestCounts struct {
+		Total     int `json:"total"`
+		Completed int `json:"completed"`
+		Failed    int `json:"failed"`
+	} `json:"request_counts"`
+}
+
+func (b openaiBatch) batch() Batch {
+	done := false
+	switch b.Status {
+	case "completed", "failed", "expired", "cancelled":
+		done = true
+	}
+	return Batch{
+		ID:        b.ID,
+		Provider:  OpenAI,
+		Status:    b.Status,
+		Done:      done,
+		Total:     b.RequestCounts.Total,
+		Succeeded: b.RequestCounts.Completed,
+		Failed:    b.RequestCounts.Failed,
+		CreatedAt: time.Unix(b.CreatedAt, 0),
+	}
+}
+
+// openaiBatchLine is one line of a batch input file.
+type openaiBatchLine struct {
+	CustomID string        `json:"custom_id"`
+	Method   string        `json:"method"`
+	URL      string        `json:"url"`
+	Body     openaiRequest `json:"body"`
+}
+
+// openaiBatchOutput is one line of a batch output or error file.
+type openaiBatchOutput struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// createBatchOpenAI uploads the requests as a JSONL file and starts a chat completions batch.
+func createBatchOpenAI(ctx context.Context, p Provider, reqs []BatchRequest, o *options) (Batch, error) {
+	var input bytes.Buffer
+	enc := json.NewEncoder(&input)
+	for _, r := range reqs {
+		payload, err := openaiPayload(p, r.Request, o)
+		if err != nil {
+			return Batch{}, err
+		}
+		line := openaiBatchLine{CustomID: r.CustomID, Method: "POST", URL: openaiChatPath, Body: payload}
+		if err := enc.Encode(line); err != nil {
+			return Batch{}, err
+		}
+	}
+
+	file, err := uploadOpenAIFile(ctx, p, input.Bytes(), "batch.jsonl", "batch", o)
+	if err != nil {
+		return Batch{}, err
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"input_file_id":     file.ID,
+		"endpoint":          openaiChatPath,
+		"completion_window": "24h",
+	})
+	if err != nil {
+		return Batch{}, err
+	}
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, p.buildURL(openaiBatchesPath), body, openaiHeaders(p))
+	if err != nil {
+		return Batch{}, err
+	}
+	if statusCode >= 400 {
+		return Batch{}, parseError(OpenAI, statusCode, respBody, respHeaders)
+	}
+
+	var b openaiBatch
+	if err := json.Unmarshal(respBody, &b); err != nil {
+		return Batch{}, err
+	}
+	return b.batch(), nil
+}
+
+func getBatchOpenAI(ctx context.Context, p Provider, id string, o *options) (openaiBatch, error) {
+	respBody, statusCode, respHeaders, err := doGet(ctx, o.httpClient, p.buildURL(openaiBatchesPath+"/"+id), openaiHeaders(p))
+	if err != nil {
+		return openaiBatch{}, err
+	}
+	if statusCode >= 400 {
+		return openaiBatch{}, parseError(OpenAI, statusCode, respBody, respHeaders)
+	}
+
+	var b openaiBatch
+	if err := json.Unmarshal(respBody, &b); err != nil {
+		return openaiBatch{}, err
+	}
+	return b, nil
+}
+
+// listBatchResultsOpenAI downloads and decodes a finished batch's output and error files.
+func listBatchResultsOpenAI(ctx context.Context, p Provider, id string, o *options) ([]BatchResult, error) {
+	b, err := getBatchOpenAI(ctx, p, id, o)
+	if err != nil {
+		return nil, err
+	}
+	if !b.batch().Done {
+		return nil, &ValidationError{Field: "batch", Message: id + " is still " + b.Status}
+	}
+
+	var results []BatchResult
+	for _, fileID := range []string{b.OutputFileID, b.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		data, statusCode, respHeaders, err := doGet(ctx, o.httpClient, p.buildURL(openaiFilesPath+"/"+fileID+"/content"), openaiHeaders(p))
+		if err != nil {
+			return nil, err
+		}
+		if statusCode >= 400 {
+			return nil, parseError(OpenAI, statusCode, data, respHeaders)
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for dec.More() {
+			var line openaiBatchOutput
+			if err := dec.Decode(&line); err != nil {
+				return nil, err
+			}
+			results = append(results, line.result(p))
+		}
+	}
+	return results, nil
+}
+
+// result converts an output line to a BatchResult, pricing it at the batch discount.
+func (l openaiBatchOutput) result(p Provider) BatchResult {
+	r := BatchResult{CustomID: l.CustomID}
+	switch {
+	case l.Error != nil:
+		r.Err = &APIError{Provider: OpenAI, Type: l.Error.Code, Message: l.Error.Message}
+	case l.Response == nil:
+		r.Err = &APIError{Provider: OpenAI, Message: "batch result has no response"}
+	case l.Response.StatusCode >= 400:
+		r.Err = parseError(OpenAI, l.Response.StatusCode, l.Response.Body, nil)
+	default:
+		var resp struct {
+			Model string `json:"model"`
+			openaiResponse
+		}
+		if err := json.Unmarshal(l.Response.Body, &resp); err != nil {
+			r.Err = err
+			break
+		}
+		if len(resp.Choices) > 0 {
+			r.Response.Text = resp.Choices[0].Message.Content
+		}
+		r.Response.Tokens = resp.Usage.usage()
+		model := resp.Model
+		if model == "" {
+			model = p.model()
+		}
+		r.Response.Cost = EstimateCost(r.Response.Tokens, model) * batchDiscount
+	}
+	return r
+}
+
+// openaiResponsesRequest is a Responses API request, used when built-in tools are enabled.
+type openaiResponsesRequest struct {
+	Model           string                 `json:"model"`
+	Instructions    string                 `json:"instructions,omitempty"`
+	Input           []openaiResponsesInput `json:"input"`
+	Tools           []openaiBuiltinTool    `json:"tools,omitempty"`
+	Text            *openaiResponsesText   `json:"text,omitempty"`
+	Temperature     *float64               `json:"temperature,omitempty"`
+	TopP            *float64               `json:"top_p,omitempty"`
+	MaxOutputTokens *int                   `json:"max_output_tokens,omitempty"`
+	Reasoning       *openaiReasoning       `json:"reasoning,omitempty"`
+}
+
+type openaiResponsesInput struct {
+	Role    string                `json:"role"`
+	Content []openaiResponsesPart `json:"content"`
+}
+
+type openaiResponsesPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	FileID   string `json:"file_id,omitempty"`
+	FileData string `json:"file_data,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type openaiBuiltinTool struct {
+	Type           string   `json:"type"`
+	VectorStoreIDs []string `json:"vector_store_ids,omitempty"`
+}
+
+type openaiResponsesText struct {
+	Format openaiResponsesFormat `json:"format"`
+}
+
+type openaiResponsesFormat struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Schema any    `json:"schema"`
+	Strict bool   `json:"strict"`
+}
+
+type openaiReasoning struct {
+	Effort string `json:"effort"`
+}
+
+type openaiResponsesResponse struct {
+	Output []struct {
+		Type    string `json:"type"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"output"`
+	Status            string `json:"status"` // "completed" or "incomplete"
+	IncompleteDetails *struct {
+		Reason string `json:"reason"`
+	} `json:"incomplete_details"`
+	Usage struct {
+		InputTokens        int `json:"input_tokens"`
+		OutputTokens       int `json:"output_tokens"`
+		InputTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"input_tokens_details"`
+		OutputTokensDetails struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"output_tokens_details"`
+	} `json:"usage"`
+}
+
+func (r openaiResponsesResponse) finishReason() FinishReason {
+	if r.IncompleteDetails != nil {
+		return responsesFinishReason(r.Status, r.IncompleteDetails.Reason)
+	}
+	return responsesFinishReason(r.Status, "")
+}
+
+// openaiResponsesFiles converts files to input_file parts.
+func openaiResponsesFiles(files []File) []openaiResponsesPart {
+	var parts []openaiResponsesPart
+	for _, f := range files {
+		part := openaiResponsesPart{Type: "input_file", FileID: f.ID}
+		if f.inline() {
+			part = openaiResponsesPart{Type: "input_file", FileData: dataURI(f.MimeType, f.Data), Filename: f.Name}
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// openaiResponsesImages converts images to input_image parts.
+func openaiResponsesImages(images []Image) []openaiResponsesPart {
+	var parts []openaiResponsesPart
+	for _, img := range images {
+		detail := img.Detail
+		if detail == "" {
+			detail = "auto"
+		}
+		parts = append(parts, openaiResponsesPart{Type: "input_image", ImageURL: img.URL, Detail: detail})
+	}
+	return parts
+}
+
+// openaiResponsesPayload builds a Responses API request for req with the built-in tools in o.
+func openaiResponsesPayload(p Provider, req Request, o *options) (openaiResponsesRequest, error) {
+	var input []openaiResponsesInput
+	if len(req.Messages) > 0 {
+		for _, m := range req.Messages {
+			partType := "input_text"
+			if m.Role == "assistant" {
+				partType = "output_text"
+			}
+			parts := append(openaiResponsesFiles(m.Files), openaiResponsesImages(m.Images)...)
+			input = append(input, openaiResponsesInput{
+				Role:    m.Role,
+				Content: append(parts, openaiResponsesPart{Type: partType, Text: m.Content}),
+			})
+		}
+	} else {
+		parts := append(openaiResponsesFiles(req.Files), openaiResponsesImages(req.Images)...)
+		if req.User != "" {
+			parts = append(parts, openaiResponsesPart{Type: "input_text", Text: req.User})
+		}
+		input = append(input, openaiResponsesInput{Role: "user", Content: parts})
+	}
+
+	payload := openaiResponsesRequest{
+		Model:           p.model(),
+		Instructions:    req.System,
+		Input:           input,
+		Tools:           []openaiBuiltinTool{{Type: "file_search", VectorStoreIDs: o.fileSearch}},
+		Temperature:     o.temperature,
+		TopP:            o.topP,
+		MaxOutputTokens: o.maxTokens,
+	}
+	if o.reasoningEffort != "" {
+		payload.Reasoning = &openaiReasoning{Effort: o.reasoningEffort}
+	}
+
+	if req.Schema != "" {
+		schema, _, err := TranslateSchema(p.Name, req.Schema)
+		if err != nil {
+			return openaiResponsesRequest{}, err
+		}
+		payload.Text = &openaiResponsesText{Format: openaiResponsesFormat{
+			Type:   "json_schema",
+			Name:   "response",
+			Schema: schema,
+			Strict: true,
+		}}
+	}
+	return payload, nil
+}
+
+// promptOpenAIResponses sends req to the Responses API, which runs built-in tools
+// such as file_search server-side. Streaming delivers the full text in one call.
+func promptOpenAIResponses(ctx context.Context, p Provider, req Request, o *options) (Response, error) {
+	payload, err := openaiResponsesPayload(p, req, o)
+	if err != nil {
+		return Response{}, err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Response{}, err
+	}
+
+	respBody, statusCode, respHeaders, err := doPostWithHeaders(ctx, o.httpClient, p.buildURL(openaiResponsesPath), body, openaiHeaders(p))
+	if err != nil {
+		return Response{}, err
+	}
+
+	if statusCode >= 400 {
+		return Response{}, parseError(p.Name, statusCode, respBody, respHeaders)
+	}
+
+	var resp openaiResponsesResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return Response{}, err
+	}
+
+	// Tool calls are separate output items; the answer is in the message items
+	text := ""
+	for _, item := range resp.Output {
+		if item.Type != "message" {
+			continue
+		}
+		for _, c := range item.Content {
+			if c.Type == "output_text" {
+				text += c.Text
+			}
+		}
+	}
+	if o.stream != nil && text != "" {
+		o.stream(text)
+	}
+
+	return Response{
+		Text: text,
+		Tokens: Usage{
+			Input:           resp.Usage.InputTokens,
+			Output:          resp.Usage.OutputTokens,
+			CacheReadTokens: resp.Usage.InputTokensDetails.CachedTokens,
+			ReasoningTokens: resp.Usage.OutputTokensDetails.ReasoningTokens,
+		},
+		FinishReason: resp.finishReason(),
 	}, nil
 }
+
+// openaiVectorStore is a vector store object from the Vector Stores API.
+type openaiVectorStore struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	FileCounts struct {
+		InProgress int `json:"in_progress"`
+		Completed  int `json:"completed"`
+		Failed     int `json:"failed"`
+		Total      int `json:"total"`
+	} `json:"file_counts"`
+}
+
+func (v openaiVectorStore) vectorStore() VectorStore {
+	return VectorStore{
+		ID:        v.ID,
+		Name:      v.Name,
+		Status:    v.Status,
+		Total:     v.FileCounts.Total,
+		Completed: v.FileCounts.Completed,
+		Failed:    v.FileCounts.Failed,
+	}
+}
+
+// vectorStoreRequest sends payload as JSON to a Vector Stores API path (GET when
+// payload is nil) and decodes the response into out.
+func vectorStoreRequest(ctx context.Context, p Provider, path string, payload, out any, o *options) error {
+	var respBody []byte
+	var statusCode int
+	var respHeaders http.Header
+	var err error
+	if payload == nil {
+		respBody, statusCode, respHeaders, err = doGet(ctx, o.httpClient, p.buildURL(path), openaiHeaders(p))
+	} else {
+		var body []byte
+		if body, err = json.Marshal(payload); err != nil {
+			return err
+		}
+		respBody, statusCode, respHeaders, err = doPostWithHeaders(ctx, o.httpClient, p.buildURL(path), body, openaiHeaders(p))
+	}
+	if err != nil {
+		return err
+	}
+	if statusCode >= 400 {
+		return parseError(OpenAI, statusCode, respBody, respHeaders)
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// createVectorStoreOpenAI creates a vector store and attaches the given uploaded files.
+func createVectorStoreOpenAI(ctx context.Context, p Provider, name string, fileIDs []string, o *options) (VectorStore, error) {
+	var v openaiVectorStore
+	err := vectorStoreRequest(ctx, p, openaiVectorStoresPath, map[string]any{"name": name, "file_ids": fileIDs}, &v, o)
+	return v.vectorStore(), err
+}
+
+// getVectorStoreOpenAI returns the vector store's ingestion state.
+func getVectorStoreOpenAI(ctx context.Context, p Provider, id string, o *options) (VectorStore, error) {
+	var v openaiVectorStore
+	err := vectorStoreRequest(ctx, p, openaiVectorStoresPath+"/"+id, nil, &v, o)
+	return v.vectorStore(), err
+}
+
+// addVectorStoreFilesOpenAI attaches uploaded files to a vector store as one file batch.
+func addVectorStoreFilesOpenAI(ctx context.Context, p Provider, id string, fileIDs []string, o *options) error {
+	var batch struct {
+		ID string `json:"id"`
+	}
+	return vectorStoreRequest(ctx, p, openaiVectorStoresPath+"/"+id+"/file_batches", map[string]any{"file_ids": fileIDs}, &batch, o)
+}
+
+// openaiVectorStoreSearch is a Vector Stores search response.
+type openaiVectorStoreSearch struct {
+	Data []struct {
+		FileID   string  `json:"file_id"`
+		Filename string  `json:"filename"`
+		Score    float64 `json:"score"`
+		Content  []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"data"`
+}
+
+// searchVectorStoreOpenAI returns up to limit chunks of the vector store most relevant to query.
+func searchVectorStoreOpenAI(ctx context.Context, p Provider, id, query string, limit int, o *options) ([]VectorStoreHit, error) {
+	payload := map[string]any{"query": query}
+	if limit > 0 {
+		payload["max_num_results"] = limit
+	}
+	var resp openaiVectorStoreSearch
+	if err := vectorStoreRequest(ctx, p, openaiVectorStoresPath+"/"+id+"/search", payload, &resp, o); err != nil {
+		return nil, err
+	}
+
+	hits := make([]VectorStoreHit, len(resp.Data))
+	for i, d := range resp.Data {
+		var text strings.Builder
+		for _, c := range d.Content {
+			if c.Type == "text" {
+				text.WriteString(c.Text)
+			}
+		}
+		hits[i] = VectorStoreHit{FileID: d.FileID, Filename: d.Filename, Score: d.Score, Text: text.String()}
+	}
+	return hits, nil
+}
@@ -2,8 +2,13 @@ package llmkit
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -110,6 +115,15 @@ func TestBuildOpenAIContent(t *testing.T) {
 			wantLen:   2,
 			wantTypes: []string{"file", "text"},
 		},
+		{
+			name: "inline file and text",
+			req: Request{
+				User:  "summarize this",
+				Files: []File{{Name: "doc.pdf", MimeType: "application/pdf", Data: []byte("%PDF-1.4")}},
+			},
+			wantLen:   2,
+			wantTypes: []string{"file", "text"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -126,3 +140,324 @@ func TestBuildOpenAIContent(t *testing.T) {
 		})
 	}
 }
+
+func TestSendOpenAIWithTools_ParallelToolCalls(t *testing.T) {
+	var body map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"choices":[{"message":{"content":"done"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	msgs := []message{{role: "user", content: "hi"}}
+
+	_, err := sendOpenAIWithTools(context.Background(), p, msgs, "", []Tool{testWeatherTool()},
+		applyOptions(WithParallelToolCalls(false)))
+	if err != nil {
+		t.Fatalf("sendOpenAIWithTools() error = %v", err)
+	}
+
+	if v, ok := body["parallel_tool_calls"]; !ok || v != false {
+		t.Errorf("parallel_tool_calls = %v, want false", v)
+	}
+}
+
+func TestBuildOpenAIContent_InlineFile(t *testing.T) {
+	req := Request{Files: []File{{Name: "doc.pdf", MimeType: "application/pdf", Data: []byte("%PDF-1.4")}}}
+
+	content := buildOpenAIContent(req)
+	if len(content) != 1 || content[0].File == nil {
+		t.Fatalf("expected one file part, got %+v", content)
+	}
+	f := content[0].File
+	if f.FileID != "" {
+		t.Errorf("FileID = %q, want empty", f.FileID)
+	}
+	if f.FileData != "data:application/pdf;base64,JVBERi0xLjQ=" {
+		t.Errorf("FileData = %q", f.FileData)
+	}
+	if f.Filename != "doc.pdf" {
+		t.Errorf("Filename = %q, want doc.pdf", f.Filename)
+	}
+}
+
+func TestPromptStream_OpenAI(t *testing.T) {
+	server := sseServer(t,
+		`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}`,
+		`data: {"choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`data: {"choices":[{"index":0,"delta":{"content":" world"}}]}`,
+		`data: {"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`data: {"choices":[],"usage":{"prompt_tokens":9,"completion_tokens":2}}`,
+		`data: [DONE]`,
+	)
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	var deltas []string
+
+	resp, err := PromptStream(context.Background(), p, Request{User: "Hi"}, func(d string) {
+		deltas = append(deltas, d)
+	})
+	if err != nil {
+		t.Fatalf("PromptStream() error = %v", err)
+	}
+	if strings.Join(deltas, "|") != "Hello| world" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if resp.Text != "Hello world" {
+		t.Errorf("Text = %q, want Hello world", resp.Text)
+	}
+	if resp.Tokens.Input != 9 || resp.Tokens.Output != 2 {
+		t.Errorf("Tokens = %+v, want 9 in / 2 out", resp.Tokens)
+	}
+	if resp.FinishReason != FinishStop {
+		t.Errorf("FinishReason = %q, want stop", resp.FinishReason)
+	}
+}
+
+func TestFinishReason_OpenAI(t *testing.T) {
+	tests := map[string]FinishReason{
+		"stop": FinishStop, "length": FinishLength, "tool_calls": FinishToolUse,
+		"content_filter": FinishContentFilter, "something_new": FinishOther, "": "",
+	}
+	for in, want := range tests {
+		if got := openaiFinishReason(in); got != want {
+			t.Errorf("openaiFinishReason(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := responsesFinishReason("incomplete", "max_output_tokens"); got != FinishLength {
+		t.Errorf("responsesFinishReason(incomplete) = %q, want length", got)
+	}
+	if got := responsesFinishReason("completed", ""); got != FinishStop {
+		t.Errorf("responsesFinishReason(completed) = %q, want stop", got)
+	}
+}
+
+func TestStreamOpenAI_ToolCallFragments(t *testing.T) {
+	server := sseServer(t,
+		`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Rome\"}"}}]}}]}`,
+		`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`data: {"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		`data: [DONE]`,
+	)
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	o := applyOptions()
+	o.stream = func(string) {}
+
+	got, err := sendOpenAIWithTools(context.Background(), p, []message{{role: "user", content: "Weather?"}}, "", []Tool{testWeatherTool()}, o)
+	if err != nil {
+		t.Fatalf("sendOpenAIWithTools() error = %v", err)
+	}
+	if len(got.calls) != 2 {
+		t.Fatalf("calls = %d, want 2", len(got.calls))
+	}
+	if got.calls[0].id != "call_1" || got.calls[0].input["city"] != "Paris" {
+		t.Errorf("calls[0] = %+v", got.calls[0])
+	}
+	if got.calls[1].id != "call_2" || got.calls[1].input["city"] != "Rome" {
+		t.Errorf("calls[1] = %+v", got.calls[1])
+	}
+}
+
+func TestPrompt_OllamaWithoutAPIKey(t *testing.T) {
+	var gotPath, gotModel string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		var req openaiRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		gotModel = req.Model
+		w.Write([]byte(`{"choices":[{"message":{"content":"local"}}],"usage":{"prompt_tokens":3,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Ollama, BaseURL: server.URL}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hi"})
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if gotPath != "/v1/chat/completions" || gotModel != "llama3.2" {
+		t.Errorf("request = %s model %q", gotPath, gotModel)
+	}
+	if resp.Text != "local" {
+		t.Errorf("Text = %q, want local", resp.Text)
+	}
+}
+
+func TestAgent_OllamaTools(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"choices":[{"message":{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Oslo\"}"}}]}}]}`,
+		`{"choices":[{"message":{"content":"Cold"}}]}`,
+	}}
+
+	agent := NewAgent(Provider{Name: Ollama}, WithHTTPClient(&http.Client{Transport: mock}))
+	agent.AddTool(testWeatherTool())
+
+	resp, err := agent.Chat(context.Background(), "Weather in Oslo?")
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Text != "Cold" {
+		t.Errorf("Text = %q, want Cold", resp.Text)
+	}
+}
+
+func TestOpenAI_OrganizationAndProjectHeaders(t *testing.T) {
+	var got []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+		if r.URL.Path == "/v1/files" {
+			w.Write([]byte(`{"id":"file-1","filename":"a.txt"}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL, Organization: "org-1", Project: "proj-1"}
+	if _, err := Prompt(context.Background(), p, Request{User: "Hi"}); err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(path, []byte("hello"), 0o644)
+	if _, err := UploadFile(context.Background(), p, path); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+
+	for i, h := range got {
+		if h.Get("OpenAI-Organization") != "org-1" || h.Get("OpenAI-Project") != "proj-1" {
+			t.Errorf("request %d headers = %v", i, h)
+		}
+	}
+
+	p.Organization, p.Project = "", ""
+	got = nil
+	Prompt(context.Background(), p, Request{User: "Hi"})
+	if _, ok := got[0]["Openai-Organization"]; ok {
+		t.Error("OpenAI-Organization sent without an organization")
+	}
+}
+
+func TestPromptOpenAI_UsageDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}],"usage":{"prompt_tokens":100,"completion_tokens":40,` +
+			`"prompt_tokens_details":{"cached_tokens":64,"audio_tokens":5},"completion_tokens_details":{"reasoning_tokens":30,"audio_tokens":2}}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hi"})
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+
+	want := Usage{Input: 100, Output: 40, CacheReadTokens: 64, ReasoningTokens: 30, AudioTokens: 7}
+	if resp.Tokens != want {
+		t.Errorf("Tokens = %+v, want %+v", resp.Tokens, want)
+	}
+}
+
+func TestPrompt_OpenRouterHeaders(t *testing.T) {
+	var gotPath, gotModel string
+	var gotHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeaders = r.Header
+		var req openaiRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		gotModel = req.Model
+		w.Write([]byte(`{"choices":[{"message":{"content":"routed"}}],"usage":{"prompt_tokens":3,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{
+		Name:    OpenRouter,
+		APIKey:  "or-key",
+		Model:   "anthropic/claude-3.5-sonnet",
+		BaseURL: server.URL,
+		AppURL:  "https://example.com",
+		AppName: "Example App",
+	}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hi"})
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.Text != "routed" {
+		t.Errorf("Text = %q", resp.Text)
+	}
+	if gotPath != "/v1/chat/completions" || gotModel != "anthropic/claude-3.5-sonnet" {
+		t.Errorf("request = %s model %q", gotPath, gotModel)
+	}
+	if gotHeaders.Get("Authorization") != "Bearer or-key" {
+		t.Errorf("Authorization = %q", gotHeaders.Get("Authorization"))
+	}
+	if gotHeaders.Get("HTTP-Referer") != "https://example.com" || gotHeaders.Get("X-Title") != "Example App" {
+		t.Errorf("attribution headers = %q, %q", gotHeaders.Get("HTTP-Referer"), gotHeaders.Get("X-Title"))
+	}
+}
+
+func TestPrompt_OpenAICompatible(t *testing.T) {
+	var gotPath, gotModel, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		var req openaiRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		gotModel = req.Model
+		w.Write([]byte(`{"choices":[{"message":{"content":"served"}}],"usage":{"prompt_tokens":3,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAICompatible, BaseURL: server.URL + "/v1", Model: "mistral-7b-instruct"}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hi"})
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.Text != "served" || gotPath != "/v1/chat/completions" || gotModel != "mistral-7b-instruct" {
+		t.Errorf("got %q via %s model %q", resp.Text, gotPath, gotModel)
+	}
+	if gotAuth != "" {
+		t.Errorf("Authorization = %q, want none without an API key", gotAuth)
+	}
+
+	var ve *ValidationError
+	for _, bad := range []Provider{
+		{Name: OpenAICompatible, Model: "m"},
+		{Name: OpenAICompatible, BaseURL: server.URL},
+	} {
+		if _, err := Prompt(context.Background(), bad, Request{User: "Hi"}); !errors.As(err, &ve) {
+			t.Errorf("Prompt(%+v) error = %v, want ValidationError", bad, err)
+		}
+	}
+}
+
+func TestPromptStream_OpenAIUsageCallback(t *testing.T) {
+	server := sseServer(t,
+		`data: {"choices":[{"index":0,"delta":{"content":"Hi"}}]}`,
+		`data: {"choices":[],"usage":{"prompt_tokens":9,"completion_tokens":2,"completion_tokens_details":{"reasoning_tokens":1}}}`,
+		`data: [DONE]`,
+	)
+	defer server.Close()
+
+	var snapshots []Usage
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := PromptStream(context.Background(), p, Request{User: "Hi"}, func(string) {},
+		WithUsageCallback(func(u Usage) { snapshots = append(snapshots, u) }))
+	if err != nil {
+		t.Fatalf("PromptStream() error = %v", err)
+	}
+
+	want := Usage{Input: 9, Output: 2, ReasoningTokens: 1}
+	if resp.Tokens != want {
+		t.Errorf("Tokens = %+v, want %+v", resp.Tokens, want)
+	}
+	if len(snapshots) == 0 || snapshots[len(snapshots)-1] != want {
+		t.Errorf("snapshots = %+v, want last %+v", snapshots, want)
+	}
+}
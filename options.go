@@ -2,7 +2,9 @@ package llmkit
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
+	"time"
 )
 
 // Option configures Prompt and Agent behavior.
@@ -10,10 +12,27 @@ type Option func(*options)
 
 type options struct {
 	httpClient    *http.Client
+	timeout       time.Duration
 	beforeRequest func(ctx context.Context, req *Request) error
 	afterResponse func(ctx context.Context, resp *Response, err error)
+	requestID     string
+	stream        StreamFunc // set internally by PromptStream and Agent.ChatStream
+	streamBudget  streamBudget
+	onUsage       func(Usage)
+	logger        *slog.Logger
+	clock         Clock
+	rand          *lockedRand // nil uses crypto/rand for IDs and the global source for jitter
+	retry         *RetryPolicy
+	rateLimit     float64 // requests per second; 0 disables
+	rateBurst     int
+	middleware    []Middleware
+
+	// Hedging parameters
+	hedgeDelay     time.Duration
+	hedgeProviders []Provider
 
 	// Generation parameters
+	model            string
 	temperature      *float64
 	topP             *float64
 	topK             *int
@@ -24,9 +43,38 @@ type options struct {
 	presencePenalty  *float64
 	thinkingBudget   *int
 	reasoningEffort  string
+	serviceTier      string
+	language         string    // required answer language; see WithLanguage
+	glossary         *Glossary // required terminology; see WithGlossary
 
 	// Agent parameters
 	maxToolIterations int
+	parallelToolCalls *bool
+	fileSearch        []string // vector store IDs for OpenAI's file_search tool
+
+	// Document parameters
+	chunkSize int
+	fileCache *FileCache
+
+	// Embedding parameters
+	embeddingModel string
+	dimensions     *int
+	taskType       string
+
+	// Dataset parameters
+	concurrency int
+
+	// Worker parameters
+	heartbeat time.Duration
+
+	// Agent parameters
+	injectionGuard *Provider
+
+	// Response parameters
+	postProcess []PostProcessor
+
+	// Token counting parameters
+	tokenizer Tokenizer
 }
 
 // WithHTTPClient sets a custom HTTP client.
@@ -36,6 +84,37 @@ func WithHTTPClient(c *http.Client) Option {
 	}
 }
 
+// WithTimeout bounds each Prompt call, or each model turn of an agent, to d
+// including retries. The call fails with context.DeadlineExceeded when it runs out.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithModel overrides Provider.Model for one call (or for every turn of an agent).
+func WithModel(model string) Option {
+	return func(o *options) {
+		o.model = model
+	}
+}
+
+// WithUsageCallback calls fn with a snapshot of token usage whenever it changes:
+// as streamed responses report it and once each response completes. For agents
+// the snapshot is the running total of the Chat call across tool turns.
+func WithUsageCallback(fn func(Usage)) Option {
+	return func(o *options) {
+		o.onUsage = fn
+	}
+}
+
+// reportUsage passes u to the usage callback, if any.
+func (o *options) reportUsage(u Usage) {
+	if o.onUsage != nil {
+		o.onUsage(u)
+	}
+}
+
 // WithBeforeRequest sets a hook called before each request.
 func WithBeforeRequest(fn func(ctx context.Context, req *Request) error) Option {
 	return func(o *options) {
@@ -50,6 +129,14 @@ func WithAfterResponse(fn func(ctx context.Context, resp *Response, err error))
 	}
 }
 
+// WithRequestID sets the client-side correlation ID instead of generating one.
+// The ID is available to hooks via RequestIDFromContext and is set on Response and APIError.
+func WithRequestID(id string) Option {
+	return func(o *options) {
+		o.requestID = id
+	}
+}
+
 // WithTemperature sets the sampling temperature (0.0-2.0).
 func WithTemperature(v float64) Option {
 	return func(o *options) {
@@ -114,6 +201,14 @@ func WithThinkingBudget(n int) Option {
 	}
 }
 
+// Reasoning effort levels for WithReasoningEffort.
+const (
+	EffortMinimal = "minimal" // OpenAI only
+	EffortLow     = "low"
+	EffortMedium  = "medium" // OpenAI only
+	EffortHigh    = "high"
+)
+
 // WithReasoningEffort controls reasoning intensity ("low", "medium", "high"). OpenAI o-series and Google Gemini 3 only.
 func WithReasoningEffort(v string) Option {
 	return func(o *options) {
@@ -121,7 +216,16 @@ func WithReasoningEffort(v string) Option {
 	}
 }
 
-// WithMaxToolIterations sets the maximum tool execution iterations for Agent.Chat().
+// WithServiceTier selects the capacity tier for the request. Anthropic only:
+// "auto" uses Priority Tier capacity when available, "standard_only" never does.
+func WithServiceTier(tier string) Option {
+	return func(o *options) {
+		o.serviceTier = tier
+	}
+}
+
+// WithMaxToolIterations sets the maximum tool execution iterations for Agent.Chat()
+// and for Prompt with Request.Tools.
 // Default is 10. Set to 0 for unlimited (use with caution).
 func WithMaxToolIterations(n int) Option {
 	return func(o *options) {
@@ -129,10 +233,68 @@ func WithMaxToolIterations(n int) Option {
 	}
 }
 
+// WithParallelToolCalls controls whether the model may request several tool calls in one turn.
+// Pass false to force sequential calls when tools have ordering dependencies.
+// Anthropic, OpenAI and Grok only; Google ignores this setting.
+func WithParallelToolCalls(enabled bool) Option {
+	return func(o *options) {
+		o.parallelToolCalls = &enabled
+	}
+}
+
+// WithFileSearch lets the model search the given vector stores (see CreateVectorStore)
+// with OpenAI's built-in file_search tool, which runs server-side. Such requests
+// use the Responses API; agents support it only without function tools. OpenAI only.
+func WithFileSearch(vectorStoreIDs ...string) Option {
+	return func(o *options) {
+		o.fileSearch = vectorStoreIDs
+	}
+}
+
+// WithChunkSize sets the maximum characters per chunk for AskDocument.
+// Default is 100,000 characters (~25k tokens).
+func WithChunkSize(n int) Option {
+	return func(o *options) {
+		o.chunkSize = n
+	}
+}
+
+// WithEmbeddingModel sets the model used by EmbedBatch instead of the provider default.
+func WithEmbeddingModel(model string) Option {
+	return func(o *options) {
+		o.embeddingModel = model
+	}
+}
+
+// WithDimensions sets the embedding vector size for models that support shortening
+// (OpenAI text-embedding-3, Google gemini-embedding).
+func WithDimensions(n int) Option {
+	return func(o *options) {
+		o.dimensions = &n
+	}
+}
+
+// WithTaskType sets the intended use of embeddings, e.g. "RETRIEVAL_QUERY",
+// "RETRIEVAL_DOCUMENT", "SEMANTIC_SIMILARITY" or "CLASSIFICATION". Google only.
+func WithTaskType(t string) Option {
+	return func(o *options) {
+		o.taskType = t
+	}
+}
+
+// WithConcurrency sets how many rows RunDataset, or tasks RunWorker, processes
+// in parallel. Default is 4.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
 // applyOptions creates options with defaults and applies all provided options.
 func applyOptions(opts ...Option) *options {
 	o := &options{
 		httpClient:        http.DefaultClient,
+		clock:             systemClock{},
 		temperature:       defaults.temperature,
 		topP:              defaults.topP,
 		topK:              defaults.topK,
@@ -143,9 +305,22 @@ func applyOptions(opts ...Option) *options {
 		thinkingBudget:    defaults.thinkingBudget,
 		reasoningEffort:   defaults.reasoningEffort,
 		maxToolIterations: 10,
+		chunkSize:         defaultChunkSize,
+		concurrency:       defaultConcurrency,
 	}
 	for _, opt := range opts {
 		opt(o)
 	}
+	// Middleware is closest to the wire; the rate limiter sits inside the
+	// retry loop so every attempt is paced
+	if len(o.middleware) > 0 {
+		o.httpClient = middlewareClient(o.httpClient, o.middleware)
+	}
+	if o.rateLimit > 0 {
+		o.httpClient = rateLimitClient(o.httpClient, o)
+	}
+	if o.retry != nil {
+		o.httpClient = retryClient(o.httpClient, *o.retry, o)
+	}
 	return o
 }
@@ -2,8 +2,11 @@ package llmkit
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestWithHTTPClient(t *testing.T) {
@@ -222,3 +225,52 @@ func TestWithReasoningEffort(t *testing.T) {
 		t.Errorf("reasoningEffort = %v, want high", opts.reasoningEffort)
 	}
 }
+
+func TestWithParallelToolCalls(t *testing.T) {
+	opt := WithParallelToolCalls(false)
+	opts := &options{}
+	opt(opts)
+
+	if opts.parallelToolCalls == nil {
+		t.Fatal("WithParallelToolCalls did not set parallelToolCalls")
+	}
+	if *opts.parallelToolCalls {
+		t.Errorf("parallelToolCalls = %v, want false", *opts.parallelToolCalls)
+	}
+}
+
+func TestWithChunkSize(t *testing.T) {
+	opts := applyOptions(WithChunkSize(500))
+
+	if opts.chunkSize != 500 {
+		t.Errorf("chunkSize = %d, want 500", opts.chunkSize)
+	}
+	if applyOptions().chunkSize != defaultChunkSize {
+		t.Errorf("default chunkSize = %d, want %d", applyOptions().chunkSize, defaultChunkSize)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release // never answers in time
+	}))
+	defer server.Close()
+	defer close(release)
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+
+	start := time.Now()
+	_, err := Prompt(context.Background(), p, Request{User: "Hi"}, WithTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Prompt() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Prompt() took %v", elapsed)
+	}
+
+	agent := NewAgent(p, WithTimeout(50*time.Millisecond))
+	agent.AddTool(testWeatherTool())
+	if _, err := agent.Chat(context.Background(), "Hi"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Chat() error = %v, want context.DeadlineExceeded", err)
+	}
+}
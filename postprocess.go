@@ -0,0 +1,113 @@
+package llmkit
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// PostProcessor rewrites a successful response before it is returned, such
+// as cleaning up Response.Text for a parser.
+type PostProcessor func(Response) Response
+
+// WithPostProcess applies post-processors, in order, to each successful
+// response of Prompt, PromptStream and Agent chats. Streamed deltas are
+// delivered unprocessed; the final Response is processed. Agents keep the
+// unprocessed text in their history.
+func WithPostProcess(fns ...PostProcessor) Option {
+	return func(o *options) {
+		o.postProcess = append(o.postProcess, fns...)
+	}
+}
+
+// postProcessResponse runs the configured post-processors on resp.
+func (o *options) postProcessResponse(resp Response) Response {
+	for _, fn := range o.postProcess {
+		resp = fn(resp)
+	}
+	return resp
+}
+
+// SegmentKind tells prose from code in a Segment.
+type SegmentKind string
+
+const (
+	SegmentText SegmentKind = "text"
+	SegmentCode SegmentKind = "code"
+)
+
+// Segment is a run of prose or one fenced code block of a response.
+type Segment struct {
+	Kind     SegmentKind
+	Language string // of a code block, as in CodeBlock
+	Text     string // code without its fences
+}
+
+// fence matches a fenced code block: an opening ``` or ~~~ line with an
+// optional info string (language, file name), the code, and a closing fence
+// of the same kind.
+var fence = regexp.MustCompile("(?ms)^[ \t]*(?:```([^\\n`]*)\\n(.*?)^[ \t]*```|~~~([^\\n]*)\\n(.*?)^[ \t]*~~~)[ \t]*$")
+
+// fenceParts returns the info string and code of a fence match.
+func fenceParts(text string, m []int) (info, code string) {
+	if m[4] < 0 {
+		m = m[4:] // the ~~~ alternative
+	}
+	return text[m[2]:m[3]], strings.TrimSuffix(text[m[4]:m[5]], "\n")
+}
+
+// ParseSegments splits markdown text into prose and fenced code blocks.
+// Prose between blocks is trimmed, and blank prose is dropped.
+func ParseSegments(text string) []Segment {
+	var out []Segment
+	addText := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, Segment{Kind: SegmentText, Text: s})
+		}
+	}
+	last := 0
+	for _, b := range ExtractCodeBlocks(text) {
+		addText(text[last:b.Start])
+		out = append(out, Segment{Kind: SegmentCode, Language: b.Language, Text: b.Code})
+		last = b.End
+	}
+	addText(text[last:])
+	return out
+}
+
+// StripFences removes a markdown fence around the whole text, as models
+// often add around JSON or code even when asked not to.
+func StripFences(resp Response) Response {
+	trimmed := strings.TrimSpace(resp.Text)
+	if m := fence.FindStringSubmatchIndex(trimmed); m != nil && m[0] == 0 && m[1] == len(trimmed) {
+		_, resp.Text = fenceParts(trimmed, m)
+	}
+	return resp
+}
+
+// UnescapeHTML decodes HTML entities such as &amp; and &#39; in the text.
+func UnescapeHTML(resp Response) Response {
+	resp.Text = html.UnescapeString(resp.Text)
+	return resp
+}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// NormalizeWhitespace converts line endings to \n, removes trailing spaces
+// on each line, collapses runs of blank lines into one and trims the text.
+// Indentation is kept.
+func NormalizeWhitespace(resp Response) Response {
+	lines := strings.Split(strings.ReplaceAll(resp.Text, "\r\n", "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t\r")
+	}
+	resp.Text = strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+	return resp
+}
+
+// ExtractCode sets Response.Segments from the text with ParseSegments,
+// leaving the text unchanged.
+func ExtractCode(resp Response) Response {
+	resp.Segments = ParseSegments(resp.Text)
+	return resp
+}
@@ -0,0 +1,81 @@
+package llmkit
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseSegments(t *testing.T) {
+	text := "Here is the fix:\n\n```go\nfunc add(a, b int) int {\n\treturn a + b\n}\n```\n\nAnd a test:\n~~~\nadd(1, 2)\n~~~\n"
+	want := []Segment{
+		{Kind: SegmentText, Text: "Here is the fix:"},
+		{Kind: SegmentCode, Language: "go", Text: "func add(a, b int) int {\n\treturn a + b\n}"},
+		{Kind: SegmentText, Text: "And a test:"},
+		{Kind: SegmentCode, Text: "add(1, 2)"},
+	}
+	if got := ParseSegments(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSegments() = %#v", got)
+	}
+	if got := ParseSegments("no code here"); len(got) != 1 || got[0].Kind != SegmentText {
+		t.Errorf("ParseSegments(prose) = %#v", got)
+	}
+}
+
+func TestPostProcessors(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   PostProcessor
+		in   string
+		want string
+	}{
+		{"fenced json", StripFences, "\n```json\n{\"total\": 42}\n```\n", `{"total": 42}`},
+		{"fence inside prose kept", StripFences, "Result:\n```\nx\n```", "Result:\n```\nx\n```"},
+		{"html entities", UnescapeHTML, "Fish &amp; chips &#39;n&#39; &lt;3", "Fish & chips 'n' <3"},
+		{"whitespace", NormalizeWhitespace, "  Title  \r\n\r\n\r\n\r\n    indented\t\n", "Title\n\n    indented"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(Response{Text: tt.in}).Text; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPostProcess(t *testing.T) {
+	reply := `{"choices":[{"message":{"content":"` + "```json\\n{\\\"ok\\\": true}\\n```" + `"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`
+	p := Provider{Name: OpenAI, APIKey: "test-key"}
+
+	mock := &scriptedTransport{responses: []string{reply}}
+	resp, err := Prompt(context.Background(), p, Request{User: "Status?"},
+		WithHTTPClient(&http.Client{Transport: mock}), WithPostProcess(ExtractCode, StripFences))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.Text != `{"ok": true}` || len(resp.Segments) != 1 || resp.Segments[0].Language != "json" {
+		t.Errorf("Prompt() = %q, segments %#v", resp.Text, resp.Segments)
+	}
+
+	// Agents return processed text but keep the original in history
+	mock = &scriptedTransport{responses: []string{reply}}
+	agent := NewAgent(p, WithHTTPClient(&http.Client{Transport: mock}), WithPostProcess(StripFences))
+	resp, err = agent.Chat(context.Background(), "Status?")
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Text != `{"ok": true}` || agent.history[1].content != "```json\n{\"ok\": true}\n```" {
+		t.Errorf("Chat() = %q, history %q", resp.Text, agent.history[1].content)
+	}
+
+	// Schema answers too, where a stray fence breaks the JSON parser
+	mock.responses = append(mock.responses, reply)
+	resp, err = agent.ChatWithSchema(context.Background(), "Status?", `{"type":"object"}`)
+	if err != nil {
+		t.Fatalf("ChatWithSchema() error = %v", err)
+	}
+	if resp.Text != `{"ok": true}` || agent.history[3].content != "```json\n{\"ok\": true}\n```" {
+		t.Errorf("ChatWithSchema() = %q, history %q", resp.Text, agent.history[3].content)
+	}
+}
@@ -0,0 +1,98 @@
+package llmkit
+
+import (
+	"strings"
+	"sync"
+)
+
+// Price is what a model costs in US dollars per 1,000 tokens.
+type Price struct {
+	Input      float64
+	Output     float64
+	CachedRead float64 // input served from the prompt cache; 0 bills it at Input
+	CacheWrite float64 // input written to the prompt cache (Anthropic); 0 bills it at Input
+}
+
+// prices maps model names (or name prefixes) to list prices.
+var prices = struct {
+	sync.RWMutex
+	m map[string]Price
+}{m: map[string]Price{
+	"claude-opus-4":     {Input: 0.015, Output: 0.075, CachedRead: 0.0015, CacheWrite: 0.01875},
+	"claude-sonnet-4":   {Input: 0.003, Output: 0.015, CachedRead: 0.0003, CacheWrite: 0.00375},
+	"claude-3-7-sonnet": {Input: 0.003, Output: 0.015, CachedRead: 0.0003, CacheWrite: 0.00375},
+	"claude-3-5-sonnet": {Input: 0.003, Output: 0.015, CachedRead: 0.0003, CacheWrite: 0.00375},
+	"claude-haiku-4":    {Input: 0.001, Output: 0.005, CachedRead: 0.0001, CacheWrite: 0.00125},
+	"claude-3-5-haiku":  {Input: 0.0008, Output: 0.004, CachedRead: 0.00008, CacheWrite: 0.001},
+
+	"gpt-4o":       {Input: 0.0025, Output: 0.01, CachedRead: 0.00125},
+	"gpt-4o-mini":  {Input: 0.00015, Output: 0.0006, CachedRead: 0.000075},
+	"gpt-4.1":      {Input: 0.002, Output: 0.008, CachedRead: 0.0005},
+	"gpt-4.1-mini": {Input: 0.0004, Output: 0.0016, CachedRead: 0.0001},
+	"gpt-4.1-nano": {Input: 0.0001, Output: 0.0004, CachedRead: 0.000025},
+	"o3":           {Input: 0.002, Output: 0.008, CachedRead: 0.0005},
+	"o3-mini":      {Input: 0.0011, Output: 0.0044, CachedRead: 0.00055},
+	"o4-mini":      {Input: 0.0011, Output: 0.0044, CachedRead: 0.000275},
+
+	"gemini-2.5-pro":        {Input: 0.00125, Output: 0.01, CachedRead: 0.00031},
+	"gemini-2.5-flash":      {Input: 0.0003, Output: 0.0025, CachedRead: 0.000075},
+	"gemini-2.5-flash-lite": {Input: 0.0001, Output: 0.0004, CachedRead: 0.000025},
+	"gemini-2.0-flash":      {Input: 0.0001, Output: 0.0004, CachedRead: 0.000025},
+
+	"grok-3":      {Input: 0.003, Output: 0.015, CachedRead: 0.00075},
+	"grok-3-fast": {Input: 0.005, Output: 0.025, CachedRead: 0.00125},
+	"grok-3-mini": {Input: 0.0003, Output: 0.0005, CachedRead: 0.000075},
+	"grok-4":      {Input: 0.003, Output: 0.015, CachedRead: 0.00075},
+}}
+
+// SetPrice adds or replaces the price of model. Dated versions such as
+// "gpt-4o-2024-08-06" use the price of the longest matching prefix.
+func SetPrice(model string, p Price) {
+	prices.Lock()
+	defer prices.Unlock()
+	prices.m[model] = p
+}
+
+// LookupPrice returns the price of model. An OpenRouter-style "vendor/" prefix is ignored.
+func LookupPrice(model string) (Price, bool) {
+	prices.RLock()
+	defer prices.RUnlock()
+	if p, ok := prices.m[model]; ok {
+		return p, true
+	}
+	if _, name, ok := strings.Cut(model, "/"); ok {
+		model = name
+	}
+	best, found := "", false
+	for name := range prices.m {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best, found = name, true
+		}
+	}
+	return prices.m[best], found
+}
+
+// EstimateCost returns the cost of usage on model in US dollars, or 0 when the
+// model has no price. Anthropic reports cache reads and writes separately from
+// Input; other providers count cached tokens as part of Input.
+func EstimateCost(u Usage, model string) float64 {
+	p, ok := LookupPrice(model)
+	if !ok {
+		return 0
+	}
+	cachedRead, cacheWrite := p.CachedRead, p.CacheWrite
+	if cachedRead == 0 {
+		cachedRead = p.Input
+	}
+	if cacheWrite == 0 {
+		cacheWrite = p.Input
+	}
+
+	input := float64(u.Input)
+	if !strings.HasPrefix(model, "claude") && !strings.HasPrefix(model, "anthropic/") {
+		input -= float64(u.CacheReadTokens)
+	}
+	cost := input*p.Input + float64(u.Output)*p.Output +
+		float64(u.CacheReadTokens)*cachedRead + float64(u.CacheWriteTokens)*cacheWrite
+	return cost / 1000
+}
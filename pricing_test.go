@@ -0,0 +1,74 @@
+package llmkit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLookupPrice(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string // key expected to match; "" for none
+	}{
+		{"gpt-4o-2024-08-06", "gpt-4o"},
+		{"gpt-4o-mini-2024-07-18", "gpt-4o-mini"},
+		{"openai/gpt-4o-mini", "gpt-4o-mini"},
+		{"claude-sonnet-4-5", "claude-sonnet-4"},
+		{"llama3.2", ""},
+	}
+	for _, tt := range tests {
+		got, ok := LookupPrice(tt.model)
+		if ok != (tt.want != "") {
+			t.Errorf("LookupPrice(%q) found = %v", tt.model, ok)
+			continue
+		}
+		if ok && got != prices.m[tt.want] {
+			t.Errorf("LookupPrice(%q) = %+v, want price of %s", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	SetPrice("test-model", Price{Input: 1, Output: 2, CachedRead: 0.5})
+	SetPrice("claude-test", Price{Input: 1, Output: 2, CachedRead: 0.5, CacheWrite: 1.5})
+	defer func() {
+		delete(prices.m, "test-model")
+		delete(prices.m, "claude-test")
+	}()
+
+	tests := []struct {
+		name  string
+		usage Usage
+		model string
+		want  float64
+	}{
+		{"plain", Usage{Input: 1000, Output: 500}, "test-model", 2},
+		{"cached share of input", Usage{Input: 1000, CacheReadTokens: 400}, "test-model", 0.6 + 0.2},
+		{"anthropic cache separate", Usage{Input: 1000, CacheReadTokens: 400, CacheWriteTokens: 200}, "claude-test", 1 + 0.2 + 0.3},
+		{"unknown model", Usage{Input: 1000}, "no-such-model", 0},
+	}
+	for _, tt := range tests {
+		if got := EstimateCost(tt.usage, tt.model); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: EstimateCost() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrompt_Cost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":1000,"completion_tokens":1000}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL, Model: "gpt-4o-mini"}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hello"})
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if want := 0.00015 + 0.0006; math.Abs(resp.Cost-want) > 1e-12 {
+		t.Errorf("Cost = %v, want %v", resp.Cost, want)
+	}
+}
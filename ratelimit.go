@@ -0,0 +1,94 @@
+package llmkit
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits HTTP requests to rps per second with bursts of up to
+// burst requests. Limits are per API host and shared by every call with the
+// same settings, including agent turns and retries. Waiting ends early with
+// the context's error.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(o *options) {
+		o.rateLimit = rps
+		o.rateBurst = max(burst, 1)
+	}
+}
+
+// limiters holds the shared token buckets, keyed by endpoint and settings.
+var limiters sync.Map
+
+// limiterFor returns the shared limiter for host with the given settings.
+func limiterFor(host string, rps float64, burst int) *rateLimiter {
+	key := fmt.Sprintf("%s|%g|%d", host, rps, burst)
+	l, _ := limiters.LoadOrStore(key, &rateLimiter{rps: rps, burst: float64(burst)})
+	return l.(*rateLimiter)
+}
+
+// rateLimiter is a token bucket.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.last.IsZero() {
+		l.tokens = l.burst
+	} else {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rps)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rps * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+// rateLimitClient returns a copy of c that paces requests per o's rate limit.
+func rateLimitClient(c *http.Client, o *options) *http.Client {
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *c
+	wrapped.Transport = &rateLimitTransport{base: base, rps: o.rateLimit, burst: o.rateBurst, clock: o.clock}
+	return &wrapped
+}
+
+// rateLimitTransport is an http.RoundTripper that paces requests per host.
+type rateLimitTransport struct {
+	base  http.RoundTripper
+	rps   float64
+	burst int
+	clock Clock
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := limiterFor(req.URL.Host, t.rps, t.burst)
+	if wait := l.reserve(t.clock.Now()); wait > 0 {
+		select {
+		case <-t.clock.After(wait):
+		case <-req.Context().Done():
+			l.cancel()
+			return nil, req.Context().Err()
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,58 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit_PacesAfterBurst(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	clock := &instantClock{}
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	for range 3 {
+		if _, err := Prompt(context.Background(), p, Request{User: "Hi"}, WithClock(clock), WithRateLimit(2, 2)); err != nil {
+			t.Fatalf("Prompt() error = %v", err)
+		}
+	}
+
+	// Two requests fit the burst; the third waits for about half a second at 2 rps
+	if len(clock.waits) != 1 || clock.waits[0] < 400*time.Millisecond || clock.waits[0] > 500*time.Millisecond {
+		t.Errorf("waits = %v, want one wait of ~500ms", clock.waits)
+	}
+}
+
+func TestWithRateLimit_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	opts := []Option{WithClock(newFakeClock()), WithRateLimit(0.001, 1)}
+	if _, err := Prompt(context.Background(), p, Request{User: "Hi"}, opts...); err != nil {
+		t.Fatalf("first Prompt() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := Prompt(ctx, p, Request{User: "Hi"}, opts...)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWithRateLimit_Validation(t *testing.T) {
+	p := Provider{Name: OpenAI, APIKey: "test-key"}
+	var ve *ValidationError
+	if _, err := Prompt(context.Background(), p, Request{User: "Hi"}, WithRateLimit(-1, 1)); !errors.As(err, &ve) {
+		t.Errorf("error = %v, want ValidationError", err)
+	}
+}
@@ -0,0 +1,258 @@
+package llmkit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RegressionCase is a recorded request and the output it produced before a change.
+type RegressionCase struct {
+	Name     string  `json:"name"`
+	Request  Request `json:"request"`
+	Baseline string  `json:"baseline"`
+}
+
+// FieldChange is one difference between two JSON outputs.
+// Path uses dots for object keys and [i] for array indexes; a nil Old or New
+// means the field was added or removed.
+type FieldChange struct {
+	Path string
+	Old  any
+	New  any
+}
+
+// RegressionResult compares a case's baseline with the candidate output.
+type RegressionResult struct {
+	Name       string
+	Baseline   string
+	Candidate  string
+	Similarity float64       // 1 means unchanged
+	Changes    []FieldChange // set when both outputs are JSON
+	Err        error
+}
+
+// RegressionReport holds results in case order.
+type RegressionReport struct {
+	Results []RegressionResult
+}
+
+// LoadRegressionCases reads cases from a JSONL file, one RegressionCase per line.
+func LoadRegressionCases(path string) ([]RegressionCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []RegressionCase
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var c RegressionCase
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		cases = append(cases, c)
+	}
+	return cases, scanner.Err()
+}
+
+// RunRegression replays each case against p (typically a new model or prompt
+// version) and compares the output with the recorded baseline. JSON outputs are
+// compared field by field; other text gets a word-overlap similarity score.
+// Cases run concurrently (see WithConcurrency); failures are recorded per result.
+func RunRegression(ctx context.Context, p Provider, cases []RegressionCase, opts ...Option) RegressionReport {
+	o := applyOptions(opts...)
+	results := make([]RegressionResult, len(cases))
+
+	sem := make(chan struct{}, max(o.concurrency, 1))
+	var wg sync.WaitGroup
+	for i, c := range cases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, c RegressionCase) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp, err := Prompt(ctx, p, c.Request, opts...)
+			if err != nil {
+				results[i] = RegressionResult{Name: c.Name, Baseline: c.Baseline, Err: err}
+				return
+			}
+			results[i] = CompareOutputs(c.Baseline, resp.Text)
+			results[i].Name = c.Name
+		}(i, c)
+	}
+	wg.Wait()
+
+	return RegressionReport{Results: results}
+}
+
+// CompareOutputs diffs two model outputs the way RunRegression does.
+func CompareOutputs(baseline, candidate string) RegressionResult {
+	r := RegressionResult{Baseline: baseline, Candidate: candidate}
+
+	var old, cur any
+	if json.Unmarshal([]byte(baseline), &old) == nil && json.Unmarshal([]byte(candidate), &cur) == nil {
+		diffJSON("", old, cur, &r.Changes)
+		leaves := max(countLeaves(old), countLeaves(cur), 1)
+		r.Similarity = max(0, 1-float64(len(r.Changes))/float64(leaves))
+		return r
+	}
+
+	r.Similarity = textSimilarity(baseline, candidate)
+	return r
+}
+
+// Changed returns results that failed or scored below minSimilarity.
+func (r RegressionReport) Changed(minSimilarity float64) []RegressionResult {
+	var out []RegressionResult
+	for _, res := range r.Results {
+		if res.Err != nil || res.Similarity < minSimilarity {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// Write prints a plain-text report with one block per case.
+func (r RegressionReport) Write(w io.Writer) error {
+	for _, res := range r.Results {
+		var err error
+		switch {
+		case res.Err != nil:
+			_, err = fmt.Fprintf(w, "%s: ERROR %v\n", res.Name, res.Err)
+		case res.Similarity == 1:
+			_, err = fmt.Fprintf(w, "%s: unchanged\n", res.Name)
+		default:
+			_, err = fmt.Fprintf(w, "%s: similarity %.2f\n", res.Name, res.Similarity)
+			for _, c := range res.Changes {
+				if err != nil {
+					break
+				}
+				_, err = fmt.Fprintf(w, "  %s: %s -> %s\n", c.Path, jsonString(c.Old), jsonString(c.New))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffJSON appends the differences between two decoded JSON values.
+func diffJSON(path string, old, cur any, changes *[]FieldChange) {
+	switch o := old.(type) {
+	case map[string]any:
+		c, ok := cur.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := map[string]bool{}
+		for k := range o {
+			keys[k] = true
+		}
+		for k := range c {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			diffJSON(joinPath(path, k), o[k], c[k], changes)
+		}
+		return
+	case []any:
+		c, ok := cur.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(o), len(c)); i++ {
+			var ov, cv any
+			if i < len(o) {
+				ov = o[i]
+			}
+			if i < len(c) {
+				cv = c[i]
+			}
+			diffJSON(fmt.Sprintf("%s[%d]", path, i), ov, cv, changes)
+		}
+		return
+	}
+	if !reflect.DeepEqual(old, cur) {
+		*changes = append(*changes, FieldChange{Path: path, Old: old, New: cur})
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// countLeaves counts scalar values in a decoded JSON value.
+func countLeaves(v any) int {
+	switch v := v.(type) {
+	case map[string]any:
+		n := 0
+		for _, item := range v {
+			n += countLeaves(item)
+		}
+		return n
+	case []any:
+		n := 0
+		for _, item := range v {
+			n += countLeaves(item)
+		}
+		return n
+	default:
+		return 1
+	}
+}
+
+// textSimilarity returns 2*LCS/(len(a)+len(b)) over words, from 0 to 1.
+func textSimilarity(a, b string) float64 {
+	wa, wb := strings.Fields(a), strings.Fields(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(wb)+1)
+	cur := make([]int, len(wb)+1)
+	for i := 1; i <= len(wa); i++ {
+		for j := 1; j <= len(wb); j++ {
+			if wa[i-1] == wb[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return 2 * float64(prev[len(wb)]) / float64(len(wa)+len(wb))
+}
+
+func jsonString(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
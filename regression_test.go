@@ -0,0 +1,76 @@
+package llmkit
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareOutputs_JSON(t *testing.T) {
+	r := CompareOutputs(
+		`{"name":"Ann","tags":["a","b"],"age":30}`,
+		`{"name":"Ann","tags":["a","c"],"city":"Oslo","age":30}`,
+	)
+
+	var paths []string
+	for _, c := range r.Changes {
+		paths = append(paths, c.Path)
+	}
+	if strings.Join(paths, ",") != "city,tags[1]" {
+		t.Errorf("changed paths = %v, want [city tags[1]]", paths)
+	}
+	if r.Changes[0].Old != nil || r.Changes[0].New != "Oslo" {
+		t.Errorf("city change = %+v", r.Changes[0])
+	}
+	if math.Abs(r.Similarity-0.6) > 1e-9 {
+		t.Errorf("Similarity = %v, want 0.6", r.Similarity)
+	}
+}
+
+func TestCompareOutputs_Text(t *testing.T) {
+	if r := CompareOutputs("the cat sat", "the cat sat"); r.Similarity != 1 {
+		t.Errorf("identical Similarity = %v", r.Similarity)
+	}
+	// LCS "the cat" = 2 words of 3+3
+	if r := CompareOutputs("the cat sat", "the cat ran"); math.Abs(r.Similarity-2.0/3) > 1e-9 {
+		t.Errorf("Similarity = %v, want 0.67", r.Similarity)
+	}
+}
+
+func TestRunRegression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"sentiment\":\"negative\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cases.jsonl")
+	os.WriteFile(path, []byte(
+		`{"name":"same","request":{"User":"bad"},"baseline":"{\"sentiment\":\"negative\"}"}`+"\n"+
+			`{"name":"flipped","request":{"User":"good"},"baseline":"{\"sentiment\":\"positive\"}"}`+"\n"), 0o644)
+
+	cases, err := LoadRegressionCases(path)
+	if err != nil {
+		t.Fatalf("LoadRegressionCases() error = %v", err)
+	}
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	report := RunRegression(context.Background(), p, cases)
+
+	changed := report.Changed(1)
+	if len(changed) != 1 || changed[0].Name != "flipped" {
+		t.Fatalf("Changed() = %+v", changed)
+	}
+
+	var buf bytes.Buffer
+	report.Write(&buf)
+	want := "same: unchanged\nflipped: similarity 0.00\n  sentiment: \"positive\" -> \"negative\"\n"
+	if buf.String() != want {
+		t.Errorf("report =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,104 @@
+package llmkit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// ReproducibilityReport compares repeated runs of one request.
+type ReproducibilityReport struct {
+	Seed         *int64
+	Runs         []Response
+	Results      []RegressionResult // run i+1 compared with the first run
+	Identical    int                // runs whose text matches the first exactly, including it
+	Fingerprints []string           // distinct SystemFingerprints, in order seen
+}
+
+// Reproducible reports whether every run produced the same text.
+func (r ReproducibilityReport) Reproducible() bool {
+	return r.Identical == len(r.Runs)
+}
+
+// MinSimilarity is the lowest similarity of any run to the first (1 when all match).
+func (r ReproducibilityReport) MinSimilarity() float64 {
+	min := 1.0
+	for _, res := range r.Results {
+		if res.Similarity < min {
+			min = res.Similarity
+		}
+	}
+	return min
+}
+
+// Write prints a plain-text summary followed by the runs that drifted.
+func (r ReproducibilityReport) Write(w io.Writer) error {
+	seed := "none"
+	if r.Seed != nil {
+		seed = fmt.Sprint(*r.Seed)
+	}
+	if _, err := fmt.Fprintf(w, "seed %s: %d/%d runs identical, min similarity %.2f, fingerprints %v\n",
+		seed, r.Identical, len(r.Runs), r.MinSimilarity(), r.Fingerprints); err != nil {
+		return err
+	}
+	for _, res := range r.Results {
+		if res.Similarity == 1 {
+			continue
+		}
+		if err := (RegressionReport{Results: []RegressionResult{res}}).Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckReproducibility sends req runs times with the same options (usually
+// including WithSeed) and reports how far the outputs drift from the first
+// run. Differing SystemFingerprints mean the provider changed backends between
+// runs, which breaks seeded determinism. Runs are concurrent (see
+// WithConcurrency); any failed run fails the check.
+func CheckReproducibility(ctx context.Context, p Provider, req Request, runs int, opts ...Option) (ReproducibilityReport, error) {
+	if runs < 2 {
+		return ReproducibilityReport{}, &ValidationError{Field: "runs", Message: "must be at least 2"}
+	}
+	o := applyOptions(opts...)
+	responses := make([]Response, runs)
+	errs := make([]error, runs)
+
+	sem := make(chan struct{}, max(o.concurrency, 1))
+	var wg sync.WaitGroup
+	for i := range runs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			responses[i], errs[i] = Prompt(ctx, p, req, opts...)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return ReproducibilityReport{}, fmt.Errorf("run %d: %w", i+1, err)
+		}
+	}
+
+	report := ReproducibilityReport{Seed: o.seed, Runs: responses, Identical: 1}
+	for i, resp := range responses {
+		if fp := resp.SystemFingerprint; fp != "" && !slices.Contains(report.Fingerprints, fp) {
+			report.Fingerprints = append(report.Fingerprints, fp)
+		}
+		if i == 0 {
+			continue
+		}
+		res := CompareOutputs(responses[0].Text, resp.Text)
+		res.Name = fmt.Sprintf("run %d", i+1)
+		report.Results = append(report.Results, res)
+		if resp.Text == responses[0].Text {
+			report.Identical++
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,96 @@
+package llmkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCheckReproducibility(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openaiRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Seed == nil || *req.Seed != 42 {
+			t.Errorf("seed = %v, want 42", req.Seed)
+		}
+		text, fp := "The answer is 4.", "fp_a"
+		if calls.Add(1) == 3 {
+			text, fp = "The answer is four.", "fp_b"
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"system_fingerprint": fp,
+			"choices":            []any{map[string]any{"message": map[string]any{"content": text}, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "k", BaseURL: server.URL}
+	report, err := CheckReproducibility(context.Background(), p, Request{User: "2+2?"}, 3, WithSeed(42), WithConcurrency(1))
+	if err != nil {
+		t.Fatalf("CheckReproducibility() error = %v", err)
+	}
+	if report.Reproducible() || report.Identical != 2 || len(report.Results) != 2 {
+		t.Errorf("report = %+v", report)
+	}
+	if got := report.Fingerprints; len(got) != 2 || got[0] != "fp_a" || got[1] != "fp_b" {
+		t.Errorf("Fingerprints = %v", got)
+	}
+	if s := report.MinSimilarity(); s >= 1 || s < 0.5 {
+		t.Errorf("MinSimilarity() = %v", s)
+	}
+	if r := report.Runs[0]; r.Seed == nil || *r.Seed != 42 || r.SystemFingerprint != "fp_a" {
+		t.Errorf("Runs[0] = %+v", r)
+	}
+
+	var buf bytes.Buffer
+	report.Write(&buf)
+	if out := buf.String(); !strings.HasPrefix(out, "seed 42: 2/3 runs identical") || !strings.Contains(out, "run 3: similarity") {
+		t.Errorf("Write() =\n%s", out)
+	}
+}
+
+func TestCheckReproducibility_Errors(t *testing.T) {
+	var ve *ValidationError
+	_, err := CheckReproducibility(context.Background(), Provider{Name: OpenAI, APIKey: "k"}, Request{User: "hi"}, 1)
+	if !errors.As(err, &ve) || ve.Field != "runs" {
+		t.Errorf("error = %v, want runs ValidationError", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"bad"}}`))
+	}))
+	defer server.Close()
+	_, err = CheckReproducibility(context.Background(), Provider{Name: OpenAI, APIKey: "k", BaseURL: server.URL}, Request{User: "hi"}, 2)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("error = %v, want APIError", err)
+	}
+}
+
+func TestGoogle_SeedAndModelVersion(t *testing.T) {
+	var got googleRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"hi"}]},"finishReason":"STOP"}],"modelVersion":"gemini-2.5-flash-001"}`))
+	}))
+	defer server.Close()
+
+	resp, err := Prompt(context.Background(), Provider{Name: Google, APIKey: "k", BaseURL: server.URL}, Request{User: "hi"}, WithSeed(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := got.GenerationConfig.Seed; s == nil || *s != 7 {
+		t.Errorf("seed = %v, want 7", s)
+	}
+	if resp.SystemFingerprint != "gemini-2.5-flash-001" {
+		t.Errorf("SystemFingerprint = %q", resp.SystemFingerprint)
+	}
+}
@@ -0,0 +1,62 @@
+package llmkit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+)
+
+// requestIDKey is the context key for the client-side correlation ID.
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context carrying the given correlation ID.
+// Prompt and Agent calls made with this context reuse the ID instead of generating one.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the correlation ID stored in ctx, or "" if none.
+// Hooks and HTTP transports can use it to tag logs and metrics.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ensureRequestID resolves the correlation ID (option, then context, then a new one)
+// and returns a context carrying it.
+func ensureRequestID(ctx context.Context, o *options) (context.Context, string) {
+	id := o.requestID
+	if id == "" {
+		id = RequestIDFromContext(ctx)
+	}
+	if id == "" {
+		id = newRequestID(o.rand)
+	}
+	if RequestIDFromContext(ctx) != id {
+		ctx = ContextWithRequestID(ctx, id)
+	}
+	return ctx, id
+}
+
+// newRequestID generates a random correlation ID, from r when set.
+func newRequestID(r *lockedRand) string {
+	b := make([]byte, 16)
+	if r != nil {
+		binary.BigEndian.PutUint64(b[:8], r.uint64())
+		binary.BigEndian.PutUint64(b[8:], r.uint64())
+	} else {
+		rand.Read(b)
+	}
+	return "req_" + hex.EncodeToString(b)
+}
+
+// stampRequestID attaches the correlation ID to API errors.
+func stampRequestID(err error, id string) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RequestID == "" {
+		apiErr.RequestID = id
+	}
+	return err
+}
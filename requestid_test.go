@@ -0,0 +1,120 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestPrompt_GeneratesRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"hi"}}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	var hookID string
+
+	resp, err := Prompt(context.Background(), p, Request{User: "Hello"},
+		WithBeforeRequest(func(ctx context.Context, req *Request) error {
+			hookID = RequestIDFromContext(ctx)
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if !strings.HasPrefix(resp.RequestID, "req_") {
+		t.Errorf("RequestID = %q, want req_ prefix", resp.RequestID)
+	}
+	if hookID != resp.RequestID {
+		t.Errorf("hook saw %q, response has %q", hookID, resp.RequestID)
+	}
+}
+
+func TestPrompt_RequestIDPrecedence(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"hi"}}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	ctx := ContextWithRequestID(context.Background(), "from-ctx")
+
+	resp, err := Prompt(ctx, p, Request{User: "Hello"})
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.RequestID != "from-ctx" {
+		t.Errorf("RequestID = %q, want from-ctx", resp.RequestID)
+	}
+
+	resp, err = Prompt(ctx, p, Request{User: "Hello"}, WithRequestID("from-option"))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.RequestID != "from-option" {
+		t.Errorf("RequestID = %q, want from-option", resp.RequestID)
+	}
+}
+
+func TestPrompt_APIErrorRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":{"message":"boom","type":"server_error"}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+
+	_, err := Prompt(context.Background(), p, Request{User: "Hello"}, WithRequestID("abc"))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if apiErr.RequestID != "abc" {
+		t.Errorf("RequestID = %q, want abc", apiErr.RequestID)
+	}
+}
+
+func TestAgent_RequestIDSharedAcrossToolLoop(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Write([]byte(`{"choices":[{"message":{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]}}]}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"sunny"}}]}`))
+	}))
+	defer server.Close()
+
+	var seen []string
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		seen = append(seen, RequestIDFromContext(r.Context()))
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	agent := NewAgent(p, WithHTTPClient(&http.Client{Transport: transport}))
+	agent.AddTool(testWeatherTool())
+
+	resp, err := agent.Chat(context.Background(), "Weather in Paris?")
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("requests = %d, want 2", len(seen))
+	}
+	if seen[0] == "" || seen[0] != seen[1] || seen[0] != resp.RequestID {
+		t.Errorf("request IDs = %v, response = %q; want one shared ID", seen, resp.RequestID)
+	}
+}
@@ -0,0 +1,133 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures automatic retries of failed HTTP requests.
+// Zero fields take the defaults noted below.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first (default 3)
+	BaseDelay   time.Duration // delay before the first retry, doubled after each (default 500ms)
+	MaxDelay    time.Duration // longest backoff delay (default 30s)
+	Jitter      float64       // randomize each delay by up to this fraction, 0 to 1 (0 disables)
+}
+
+// WithRetry retries requests that fail with 429, 5xx or a network error,
+// backing off exponentially. A Retry-After header from the provider takes
+// precedence over the computed delay. Waits end early if the context is done.
+func WithRetry(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &p
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 500 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 30 * time.Second
+	}
+	p.Jitter = min(max(p.Jitter, 0), 1)
+	return p
+}
+
+// retryClient returns a copy of c whose transport retries according to p.
+func retryClient(c *http.Client, p RetryPolicy, o *options) *http.Client {
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *c
+	wrapped.Transport = &retryTransport{base: base, policy: p.withDefaults(), clock: o.clock, rand: o.rand, log: o.log}
+	return &wrapped
+}
+
+// retryTransport is an http.RoundTripper that retries transient failures.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+	clock  Clock
+	rand   *lockedRand
+	log    func(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.policy.MaxAttempts || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		args := []any{"host", req.URL.Host, "attempt", attempt}
+		if resp != nil {
+			if after := extractRetryAfter(resp.Header); after > 0 {
+				wait = after
+			}
+			args = append(args, "status", resp.StatusCode)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			args = append(args, "error", err)
+		}
+		t.log(req.Context(), slog.LevelWarn, "llmkit retry", append(args, "wait", wait)...)
+
+		select {
+		case <-t.clock.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// backoff returns the delay before retry number attempt (1-based).
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	d := t.policy.BaseDelay << (attempt - 1)
+	if d <= 0 || d > t.policy.MaxDelay {
+		d = t.policy.MaxDelay
+	}
+	if t.policy.Jitter > 0 {
+		r := rand.Float64()
+		if t.rand != nil {
+			r = t.rand.float64()
+		}
+		d = time.Duration(float64(d) * (1 - t.policy.Jitter*r))
+	}
+	return d
+}
+
+// retryable reports whether a request can and should be sent again.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529: // 529: Anthropic overloaded
+		return true
+	}
+	return false
+}
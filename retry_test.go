@@ -0,0 +1,102 @@
+package llmkit
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// instantClock fires every After immediately and records the requested waits.
+type instantClock struct {
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (c *instantClock) Now() time.Time { return time.Now() }
+
+func (c *instantClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.waits = append(c.waits, d)
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+func TestWithRetry_BacksOffAndResendsBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		switch len(bodies) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	clock := &instantClock{}
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hi"},
+		WithClock(clock), WithRetry(RetryPolicy{MaxAttempts: 4, BaseDelay: time.Second}))
+	if err != nil {
+		t.Fatalf("Prompt() error = %v", err)
+	}
+	if resp.Text != "ok" || len(bodies) != 3 {
+		t.Fatalf("Text = %q after %d attempts", resp.Text, len(bodies))
+	}
+	if bodies[2] != bodies[0] || bodies[0] == "" {
+		t.Error("retried request did not resend the body")
+	}
+	if len(clock.waits) != 2 || clock.waits[0] != time.Second || clock.waits[1] != 7*time.Second {
+		t.Errorf("waits = %v, want [1s 7s]", clock.waits)
+	}
+}
+
+func TestWithRetry_GivesUp(t *testing.T) {
+	attempts, status := 0, http.StatusBadGateway
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	opts := []Option{WithClock(&instantClock{}), WithRetry(RetryPolicy{MaxAttempts: 3})}
+
+	if _, err := Prompt(context.Background(), p, Request{User: "Hi"}, opts...); err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+
+	attempts, status = 0, http.StatusBadRequest
+	Prompt(context.Background(), p, Request{User: "Hi"}, opts...)
+	if attempts != 1 {
+		t.Errorf("400 was retried: attempts = %d, want 1", attempts)
+	}
+}
+
+func TestRetryTransport_Jitter(t *testing.T) {
+	tr := &retryTransport{
+		policy: RetryPolicy{BaseDelay: time.Second, MaxDelay: 4 * time.Second, Jitter: 0.5}.withDefaults(),
+		rand:   &lockedRand{r: rand.New(rand.NewPCG(1, 1))},
+	}
+	for attempt, full := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		d := tr.backoff(attempt + 1)
+		if d > full || d < full/2 {
+			t.Errorf("backoff(%d) = %v, want within [%v, %v]", attempt+1, d, full/2, full)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// sampleTemperature is used by PromptN when no temperature is set, so samples differ.
+const sampleTemperature = 0.7
+
+// PromptN sends req n times concurrently and returns the successful responses in
+// sample order. When a seed is set, sample i uses seed+i; when no temperature is
+// set, samples use 0.7. Failed samples are dropped; an error is returned only if
+// every sample fails. Combine the results with MajorityVote or JudgeSelect.
+func PromptN(ctx context.Context, p Provider, req Request, n int, opts ...Option) ([]Response, error) {
+	if n < 1 {
+		return nil, &ValidationError{Field: "n", Message: "must be at least 1"}
+	}
+
+	base := applyOptions(opts...)
+	results := make([]Response, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		o := *base
+		if o.seed != nil {
+			seed := *o.seed + int64(i)
+			o.seed = &seed
+		}
+		if o.temperature == nil {
+			t := sampleTemperature
+			o.temperature = &t
+		}
+
+		wg.Add(1)
+		go func(i int, o *options) {
+			defer wg.Done()
+			results[i], errs[i] = prompt(ctx, p, req, o)
+		}(i, &o)
+	}
+	wg.Wait()
+
+	var out []Response
+	for i, resp := range results {
+		if errs[i] == nil {
+			out = append(out, resp)
+		}
+	}
+	if len(out) == 0 {
+		return nil, errs[0]
+	}
+	return out, nil
+}
+
+// MajorityVote returns the most common answer among responses and how many agreed.
+// With field == "" whole texts are compared after trimming whitespace; otherwise each
+// text is parsed as a JSON object and the value of that top-level field is compared.
+// Responses that fail to parse are ignored. Ties go to the earliest response.
+func MajorityVote(responses []Response, field string) (Response, int, error) {
+	counts := map[string]int{}
+	first := map[string]int{}
+	for i, resp := range responses {
+		key, ok := voteKey(resp.Text, field)
+		if !ok {
+			continue
+		}
+		if _, seen := first[key]; !seen {
+			first[key] = i
+		}
+		counts[key]++
+	}
+	if len(counts) == 0 {
+		return Response{}, 0, fmt.Errorf("majority vote: no comparable responses")
+	}
+
+	best, bestCount := -1, 0
+	for key, c := range counts {
+		if c > bestCount || (c == bestCount && first[key] < best) {
+			best, bestCount = first[key], c
+		}
+	}
+	return responses[best], bestCount, nil
+}
+
+// voteKey returns the comparable form of a response for MajorityVote.
+func voteKey(text, field string) (string, bool) {
+	if field == "" {
+		return strings.TrimSpace(text), true
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(text), &obj); err != nil {
+		return "", false
+	}
+	v, ok := obj[field]
+	if !ok {
+		return "", false
+	}
+	// Marshaling sorts map keys, so equal values produce equal keys
+	key, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(key), true
+}
+
+const judgeSystem = "You are given a task and several candidate answers. " +
+	"Pick the candidate that is the most accurate and complete answer to the task."
+
+// JudgeSelect asks a model to pick the best of responses for the given task
+// (usually the original prompt) and returns the chosen response. The judge's
+// token usage is added to the chosen response's.
+func JudgeSelect(ctx context.Context, judge Provider, task string, responses []Response, opts ...Option) (Response, error) {
+	switch len(responses) {
+	case 0:
+		return Response{}, &ValidationError{Field: "responses", Message: "required"}
+	case 1:
+		return responses[0], nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task:\n%s\n\n", task)
+	for i, resp := range responses {
+		fmt.Fprintf(&b, "Candidate %d:\n%s\n\n", i+1, resp.Text)
+	}
+
+	schema := fmt.Sprintf(`{"type":"object","properties":{"candidate":{"type":"integer","minimum":1,"maximum":%d}},"required":["candidate"],"additionalProperties":false}`, len(responses))
+	verdict, err := Prompt(ctx, judge, Request{System: judgeSystem, User: b.String(), Schema: schema}, opts...)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var out struct {
+		Candidate int `json:"candidate"`
+	}
+	if err := json.Unmarshal([]byte(verdict.Text), &out); err != nil {
+		return Response{}, fmt.Errorf("judge: %w", err)
+	}
+	if out.Candidate < 1 || out.Candidate > len(responses) {
+		return Response{}, fmt.Errorf("judge: candidate %d out of range", out.Candidate)
+	}
+
+	chosen := responses[out.Candidate-1]
+	chosen.Tokens = chosen.Tokens.Add(verdict.Tokens)
+	chosen.Cost += verdict.Cost
+	return chosen, nil
+}
@@ -0,0 +1,87 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestPromptN_VariesSeed(t *testing.T) {
+	var mu sync.Mutex
+	var seeds []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openaiRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		seeds = append(seeds, int(*req.Seed))
+		mu.Unlock()
+		if *req.Temperature != sampleTemperature {
+			t.Errorf("temperature = %v, want %v", *req.Temperature, sampleTemperature)
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":"answer %d"}}]}`, *req.Seed)
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	responses, err := PromptN(context.Background(), p, Request{User: "Hi"}, 3, WithSeed(10))
+	if err != nil {
+		t.Fatalf("PromptN() error = %v", err)
+	}
+
+	sort.Ints(seeds)
+	if fmt.Sprint(seeds) != "[10 11 12]" {
+		t.Errorf("seeds = %v, want [10 11 12]", seeds)
+	}
+	if len(responses) != 3 || responses[2].Text != "answer 12" {
+		t.Errorf("responses = %+v", responses)
+	}
+}
+
+func TestMajorityVote(t *testing.T) {
+	responses := []Response{
+		{Text: `{"city":"Paris","confidence":0.9}`},
+		{Text: `{"city":"Lyon","confidence":0.5}`},
+		{Text: `not json`},
+		{Text: `{"city":"Paris","confidence":0.7}`},
+	}
+
+	got, votes, err := MajorityVote(responses, "city")
+	if err != nil {
+		t.Fatalf("MajorityVote() error = %v", err)
+	}
+	if votes != 2 || got.Text != responses[0].Text {
+		t.Errorf("MajorityVote() = %q with %d votes", got.Text, votes)
+	}
+
+	got, votes, _ = MajorityVote([]Response{{Text: "yes"}, {Text: "no "}, {Text: "no"}}, "")
+	if got.Text != "no " || votes != 2 {
+		t.Errorf("text vote = %q with %d votes", got.Text, votes)
+	}
+
+	if _, _, err := MajorityVote([]Response{{Text: "x"}}, "city"); err == nil {
+		t.Error("expected error when no response has the field")
+	}
+}
+
+func TestJudgeSelect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"candidate\":2}"}}],"usage":{"prompt_tokens":50,"completion_tokens":5}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	responses := []Response{{Text: "A", Tokens: Usage{Input: 1}}, {Text: "B", Tokens: Usage{Input: 1}}}
+
+	got, err := JudgeSelect(context.Background(), p, "Pick a letter", responses)
+	if err != nil {
+		t.Fatalf("JudgeSelect() error = %v", err)
+	}
+	if got.Text != "B" || got.Tokens.Input != 51 {
+		t.Errorf("JudgeSelect() = %+v", got)
+	}
+}
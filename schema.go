@@ -0,0 +1,253 @@
+package llmkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// schemaDialect is what a provider's structured-output API accepts.
+type schemaDialect struct {
+	closeObjects bool // every object needs "additionalProperties": false
+	requireAll   bool // every property must be required; optional ones become nullable
+	openAPI      bool // OpenAPI subset: no additionalProperties, $ref, const or type arrays
+}
+
+var schemaDialects = map[string]schemaDialect{
+	Anthropic:        {closeObjects: true},
+	OpenAI:           {closeObjects: true, requireAll: true},
+	Grok:             {closeObjects: true, requireAll: true},
+	Ollama:           {closeObjects: true, requireAll: true},
+	OpenRouter:       {closeObjects: true, requireAll: true},
+	OpenAICompatible: {closeObjects: true, requireAll: true},
+	Google:           {openAPI: true},
+}
+
+// TranslateSchema adapts a JSON schema to what provider's structured-output
+// API accepts and reports each change it made, e.g. closing objects for OpenAI's
+// strict mode or inlining $ref for Gemini. Schemas that cannot be represented
+// (open objects under strict mode, recursive references for Gemini) fail with
+// a *ValidationError naming the location.
+func TranslateSchema(provider, schema string) (map[string]any, []string, error) {
+	var root map[string]any
+	if err := json.Unmarshal([]byte(schema), &root); err != nil {
+		return nil, nil, &ValidationError{Field: "schema", Message: "must be a JSON object: " + err.Error()}
+	}
+	t := &schemaTranslator{provider: provider, dialect: schemaDialects[provider], root: root, resolving: map[string]bool{}}
+	if t.dialect.requireAll && root["type"] != "object" {
+		return nil, nil, t.fail("$", `root must have "type": "object"`)
+	}
+	out, err := t.node(root, "$")
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.(map[string]any), t.changes, nil
+}
+
+// schemaTranslator rewrites one schema for one dialect.
+type schemaTranslator struct {
+	provider  string
+	dialect   schemaDialect
+	root      map[string]any
+	resolving map[string]bool // $refs being inlined, to detect recursion
+	changes   []string
+}
+
+func (t *schemaTranslator) fail(path, problem string) error {
+	return &ValidationError{Field: "schema", Message: fmt.Sprintf("%s: %s at %s", t.provider, problem, path)}
+}
+
+func (t *schemaTranslator) change(path, format string, args ...any) {
+	t.changes = append(t.changes, fmt.Sprintf(format, args...)+" at "+path)
+}
+
+// node translates a schema or a value inside one.
+func (t *schemaTranslator) node(v any, path string) (any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v, nil
+	}
+	if ref, ok := m["$ref"].(string); ok && t.dialect.openAPI {
+		return t.inline(ref, path)
+	}
+
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		var err error
+		switch k {
+		case "properties", "$defs", "definitions":
+			if t.dialect.openAPI && k != "properties" {
+				continue // references were inlined
+			}
+			props, _ := v.(map[string]any)
+			translated := make(map[string]any, len(props))
+			for name, sub := range props {
+				if translated[name], err = t.node(sub, path+"."+k+"."+name); err != nil {
+					return nil, err
+				}
+			}
+			out[k] = translated
+		case "items", "not":
+			if items, ok := v.([]any); ok {
+				out[k], err = t.list(items, path+"."+k)
+			} else {
+				out[k], err = t.node(v, path+"."+k)
+			}
+		case "anyOf", "oneOf", "allOf", "prefixItems":
+			items, _ := v.([]any)
+			out[k], err = t.list(items, path+"."+k)
+		case "additionalProperties":
+			if t.dialect.openAPI {
+				t.change(path, "removed additionalProperties")
+				continue
+			}
+			out[k], err = t.node(v, path+"."+k)
+		case "$schema", "$id", "$comment":
+			if t.dialect.openAPI {
+				continue
+			}
+			out[k] = v
+		case "const":
+			if t.dialect.openAPI {
+				t.change(path, "replaced const with enum")
+				out["enum"] = []any{v}
+				continue
+			}
+			out[k] = v
+		case "type":
+			out[k], err = t.typeKeyword(v, out, path)
+		default:
+			out[k] = v
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if out["type"] == "object" || out["properties"] != nil {
+		if err := t.object(out, path); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (t *schemaTranslator) list(items []any, path string) ([]any, error) {
+	out := make([]any, len(items))
+	for i, item := range items {
+		var err error
+		if out[i], err = t.node(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// typeKeyword rewrites ["T", "null"] as "T" plus "nullable" for OpenAPI dialects.
+func (t *schemaTranslator) typeKeyword(v any, out map[string]any, path string) (any, error) {
+	types, ok := v.([]any)
+	if !ok || !t.dialect.openAPI {
+		return v, nil
+	}
+	var kept []any
+	for _, typ := range types {
+		if typ == "null" {
+			out["nullable"] = true
+		} else {
+			kept = append(kept, typ)
+		}
+	}
+	if len(kept) != 1 {
+		return nil, t.fail(path, fmt.Sprintf("type %v is not supported; use anyOf", types))
+	}
+	t.change(path, "replaced type %v with nullable %v", types, kept[0])
+	return kept[0], nil
+}
+
+// object applies the dialect's rules for object schemas.
+func (t *schemaTranslator) object(out map[string]any, path string) error {
+	if t.dialect.closeObjects {
+		ap, ok := out["additionalProperties"]
+		switch {
+		case !ok:
+			out["additionalProperties"] = false
+			t.change(path, "set additionalProperties to false")
+		case ap != false:
+			return t.fail(path, "additionalProperties must be false")
+		}
+	}
+	if !t.dialect.requireAll {
+		return nil
+	}
+
+	props, _ := out["properties"].(map[string]any)
+	required := map[string]bool{}
+	list, _ := out["required"].([]any)
+	for _, name := range list {
+		if s, ok := name.(string); ok {
+			required[s] = true
+		}
+	}
+	var optional []string
+	for name := range props {
+		if !required[name] {
+			optional = append(optional, name)
+		}
+	}
+	sort.Strings(optional)
+	for _, name := range optional {
+		props[name] = nullable(props[name])
+		list = append(list, name)
+	}
+	if len(optional) > 0 {
+		t.change(path, "made optional properties %s required and nullable", strings.Join(optional, ", "))
+	}
+	if len(props) > 0 {
+		out["required"] = list
+	}
+	return nil
+}
+
+// nullable returns schema extended to also accept null.
+func nullable(schema any) any {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		return schema
+	}
+	switch typ := m["type"].(type) {
+	case string:
+		m["type"] = []any{typ, "null"}
+		return m
+	case []any:
+		for _, t := range typ {
+			if t == "null" {
+				return m
+			}
+		}
+		m["type"] = append(typ, "null")
+		return m
+	}
+	return map[string]any{"anyOf": []any{m, map[string]any{"type": "null"}}}
+}
+
+// inline replaces a local $ref ("#/$defs/X" or "#/definitions/X") with its target.
+func (t *schemaTranslator) inline(ref, path string) (any, error) {
+	if t.resolving[ref] {
+		return nil, t.fail(path, "recursive $ref "+ref+" is not supported")
+	}
+	target := any(t.root)
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := target.(map[string]any)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return nil, t.fail(path, "cannot resolve $ref "+ref)
+		}
+		if target, ok = m[part]; !ok {
+			return nil, t.fail(path, "cannot resolve $ref "+ref)
+		}
+	}
+	t.change(path, "inlined $ref %s", ref)
+	t.resolving[ref] = true
+	defer delete(t.resolving, ref)
+	return t.node(target, path)
+}
@@ -0,0 +1,94 @@
+package llmkit
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const personSchema = `{
+	"type": "object",
+	"$defs": {"address": {"type": "object", "properties": {"city": {"type": "string"}}, "required": ["city"]}},
+	"properties": {
+		"name": {"type": "string"},
+		"nickname": {"type": "string"},
+		"kind": {"const": "person"},
+		"home": {"$ref": "#/$defs/address"},
+		"tags": {"type": "array", "items": {"type": ["string", "null"]}}
+	},
+	"required": ["name", "kind", "home", "tags"],
+	"additionalProperties": false
+}`
+
+func TestTranslateSchema_OpenAI(t *testing.T) {
+	schema, changes, err := TranslateSchema(OpenAI, personSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(schema)
+	for _, want := range []string{
+		`"nickname":{"type":["string","null"]}`,
+		`"required":["name","kind","home","tags","nickname"]`,
+		`"address":{"additionalProperties":false`,
+		`"home":{"$ref":"#/$defs/address"}`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("schema missing %s:\n%s", want, got)
+		}
+	}
+	want := []string{
+		"set additionalProperties to false at $.$defs.address",
+		"made optional properties nickname required and nullable at $",
+	}
+	if strings.Join(changes, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes = %q, want %q", changes, want)
+	}
+}
+
+func TestTranslateSchema_Google(t *testing.T) {
+	schema, changes, err := TranslateSchema(Google, personSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(schema)
+	for _, bad := range []string{"additionalProperties", "$ref", "$defs", "const"} {
+		if strings.Contains(string(got), bad) {
+			t.Errorf("schema still has %s:\n%s", bad, got)
+		}
+	}
+	for _, want := range []string{
+		`"home":{"properties":{"city":{"type":"string"}},"required":["city"],"type":"object"}`,
+		`"kind":{"enum":["person"]}`,
+		`"items":{"nullable":true,"type":"string"}`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("schema missing %s:\n%s", want, got)
+		}
+	}
+	if len(changes) != 4 {
+		t.Errorf("changes = %q, want 4", changes)
+	}
+}
+
+func TestTranslateSchema_Errors(t *testing.T) {
+	tests := []struct {
+		provider, schema, want string
+	}{
+		{OpenAI, `not json`, "must be a JSON object"},
+		{OpenAI, `{"type": "array", "items": {"type": "string"}}`, `root must have "type": "object"`},
+		{Anthropic, `{"type": "object", "properties": {"x": {"type": "object", "additionalProperties": true}}}`,
+			"additionalProperties must be false at $.properties.x"},
+		{Google, `{"type": "object", "$defs": {"n": {"type": "object", "properties": {"next": {"$ref": "#/$defs/n"}}}},
+			"properties": {"head": {"$ref": "#/$defs/n"}}}`, "recursive $ref #/$defs/n is not supported at $.properties.head.properties.next"},
+		{Google, `{"type": "object", "properties": {"x": {"$ref": "#/$defs/missing"}}}`, "cannot resolve $ref"},
+		{Google, `{"type": ["string", "number"]}`, "use anyOf"},
+	}
+	for _, tt := range tests {
+		_, _, err := TranslateSchema(tt.provider, tt.schema)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("TranslateSchema(%s, %s) error = %v, want %q", tt.provider, tt.schema, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package llmkit
+
+import (
+	"math"
+	"sort"
+)
+
+// Match is a vector's position in the searched slice and its similarity score.
+type Match struct {
+	Index int
+	Score float64
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b (-1 to 1).
+// It returns 0 when the lengths differ or either vector is zero.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// TopK returns the k vectors most similar to query, best first.
+func TopK(query []float32, vectors [][]float32, k int) []Match {
+	matches := make([]Match, len(vectors))
+	for i, v := range vectors {
+		matches[i] = Match{Index: i, Score: CosineSimilarity(query, v)}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if k >= 0 && k < len(matches) {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+// MMR selects k vectors by maximal marginal relevance, trading similarity to query
+// against redundancy with vectors already selected. lambda=1 ranks purely by
+// relevance (like TopK); lower values favor diversity. Scores are query similarity.
+func MMR(query []float32, vectors [][]float32, k int, lambda float64) []Match {
+	if k < 0 || k > len(vectors) {
+		k = len(vectors)
+	}
+
+	relevance := make([]float64, len(vectors))
+	for i, v := range vectors {
+		relevance[i] = CosineSimilarity(query, v)
+	}
+
+	selected := make([]Match, 0, k)
+	used := make([]bool, len(vectors))
+	for len(selected) < k {
+		best, bestScore := -1, math.Inf(-1)
+		for i, v := range vectors {
+			if used[i] {
+				continue
+			}
+			redundancy := 0.0
+			for j, s := range selected {
+				sim := CosineSimilarity(v, vectors[s.Index])
+				if j == 0 || sim > redundancy {
+					redundancy = sim
+				}
+			}
+			score := lambda*relevance[i] - (1-lambda)*redundancy
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		used[best] = true
+		selected = append(selected, Match{Index: best, Score: relevance[best]})
+	}
+	return selected
+}
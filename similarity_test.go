@@ -0,0 +1,52 @@
+package llmkit
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 2}, []float32{1, 2}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, 1}, []float32{-1, -1}, -1},
+		{"zero vector", []float32{0, 0}, []float32{1, 1}, 0},
+		{"length mismatch", []float32{1}, []float32{1, 1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CosineSimilarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopK(t *testing.T) {
+	vectors := [][]float32{{0, 1}, {1, 0}, {1, 1}}
+	got := TopK([]float32{1, 0}, vectors, 2)
+
+	if len(got) != 2 || got[0].Index != 1 || got[1].Index != 2 {
+		t.Errorf("TopK() = %+v, want indexes [1 2]", got)
+	}
+}
+
+func TestMMR(t *testing.T) {
+	// Two near-duplicates close to the query and one distinct vector
+	vectors := [][]float32{{1, 0.1}, {1, 0.11}, {0.5, 1}}
+	query := []float32{1, 0.2}
+
+	relevant := MMR(query, vectors, 2, 1)
+	if relevant[0].Index != 1 || relevant[1].Index != 0 {
+		t.Errorf("MMR(lambda=1) = %+v, want indexes [1 0]", relevant)
+	}
+
+	diverse := MMR(query, vectors, 2, 0.3)
+	if diverse[0].Index != 1 || diverse[1].Index != 2 {
+		t.Errorf("MMR(lambda=0.3) = %+v, want indexes [1 2]", diverse)
+	}
+}
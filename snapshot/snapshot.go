@@ -0,0 +1,211 @@
+// Package snapshot regression-tests LLM outputs against golden files.
+// Outputs are normalized (timestamps, UUIDs and other volatile text replaced
+// by placeholders) before they are stored or compared, and compared with
+// llmkit.CompareOutputs, so a test can accept small wording changes above a
+// similarity threshold instead of failing on every token.
+//
+// Snapshots live in testdata/snapshots/<name>.snap. A missing snapshot is
+// written on first run, except when the CI environment variable is set; run
+// with UPDATE_SNAPSHOTS=1 to rewrite snapshots after an intended change.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aktagon/llmkit"
+)
+
+// Rule replaces every match of Pattern with Replace before comparison.
+type Rule struct {
+	Pattern *regexp.Regexp
+	Replace string
+}
+
+// Built-in rules. Timestamps and UUIDs are applied by default.
+var (
+	Timestamps = Rule{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?`), "<TIMESTAMP>"}
+	UUIDs      = Rule{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<UUID>"}
+	Numbers    = Rule{regexp.MustCompile(`\d+(\.\d+)?`), "<N>"}
+)
+
+// DefaultRules are applied unless WithRules replaces them.
+var DefaultRules = []Rule{Timestamps, UUIDs}
+
+// Dir is where snapshots are stored, relative to the test's package directory.
+var Dir = filepath.Join("testdata", "snapshots")
+
+type config struct {
+	rules     []Rule
+	threshold float64
+}
+
+// Option configures Match and Compare.
+type Option func(*config)
+
+// WithRules replaces DefaultRules.
+func WithRules(rules ...Rule) Option {
+	return func(c *config) { c.rules = rules }
+}
+
+// WithReplace adds a rule on top of the current ones.
+func WithReplace(pattern, replace string) Option {
+	re := regexp.MustCompile(pattern)
+	return func(c *config) { c.rules = append(c.rules, Rule{re, replace}) }
+}
+
+// WithThreshold accepts outputs whose similarity to the snapshot is at least
+// min (see llmkit.CompareOutputs). Default is 1, an exact match after
+// normalization.
+func WithThreshold(min float64) Option {
+	return func(c *config) { c.threshold = min }
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{rules: append([]Rule(nil), DefaultRules...), threshold: 1}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Normalize applies the rules to s and trims surrounding whitespace.
+func Normalize(s string, opts ...Option) string {
+	return normalize(s, newConfig(opts))
+}
+
+func normalize(s string, c *config) string {
+	for _, r := range c.rules {
+		s = r.Pattern.ReplaceAllString(s, r.Replace)
+	}
+	return strings.TrimSpace(s)
+}
+
+// Result is the outcome of comparing an output with its snapshot.
+type Result struct {
+	llmkit.RegressionResult
+	OK   bool
+	Diff string // human-readable differences, empty when OK
+}
+
+// Compare normalizes want and got and compares them.
+func Compare(want, got string, opts ...Option) Result {
+	c := newConfig(opts)
+	r := Result{RegressionResult: llmkit.CompareOutputs(normalize(want, c), normalize(got, c))}
+	r.OK = r.Similarity >= c.threshold
+	if !r.OK {
+		r.Diff = diff(r.RegressionResult)
+	}
+	return r
+}
+
+// Match compares got with the snapshot called name and fails t when they
+// differ by more than the threshold, reporting the similarity and a diff.
+func Match(t testing.TB, name, got string, opts ...Option) {
+	t.Helper()
+	c := newConfig(opts)
+	path := filepath.Join(Dir, fileName(name)+".snap")
+
+	want, err := os.ReadFile(path)
+	update := os.Getenv("UPDATE_SNAPSHOTS") != ""
+	switch {
+	case err != nil && !os.IsNotExist(err):
+		t.Fatalf("snapshot %s: %v", name, err)
+	case update || err != nil:
+		if !update && os.Getenv("CI") != "" {
+			t.Fatalf("snapshot %s: %s missing; run with UPDATE_SNAPSHOTS=1 and commit it", name, path)
+		}
+		if err := write(path, normalize(got, c)); err != nil {
+			t.Fatalf("snapshot %s: %v", name, err)
+		}
+		t.Logf("snapshot %s: wrote %s", name, path)
+		return
+	}
+
+	if r := Compare(string(want), got, opts...); !r.OK {
+		t.Errorf("snapshot %s: similarity %.2f below %.2f (UPDATE_SNAPSHOTS=1 to accept)\n%s",
+			name, r.Similarity, c.threshold, r.Diff)
+	}
+}
+
+// write stores s, indenting JSON so snapshot changes review well.
+func write(path, s string) error {
+	var buf bytes.Buffer
+	if json.Indent(&buf, []byte(s), "", "  ") == nil {
+		s = buf.String()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(s+"\n"), 0o644)
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName turns a snapshot or subtest name into a file name.
+func fileName(name string) string {
+	return strings.Trim(unsafeChars.ReplaceAllString(name, "_"), "_")
+}
+
+// diff lists changed JSON fields, or changed lines of text.
+func diff(r llmkit.RegressionResult) string {
+	var b strings.Builder
+	if r.Changes != nil {
+		for _, c := range r.Changes {
+			fmt.Fprintf(&b, "  %s: %s -> %s\n", c.Path, jsonValue(c.Old), jsonValue(c.New))
+		}
+		return b.String()
+	}
+	for _, l := range lineDiff(strings.Split(r.Baseline, "\n"), strings.Split(r.Candidate, "\n")) {
+		b.WriteString(l + "\n")
+	}
+	return b.String()
+}
+
+func jsonValue(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// lineDiff returns a unified-style diff of a and b without context lines:
+// "- " lines are only in a, "+ " lines only in b.
+func lineDiff(a, b []string) []string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder captures failures instead of failing the real test.
+type recorder struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (r *recorder) Helper()                   {}
+func (r *recorder) Logf(string, ...any)       {}
+func (r *recorder) Errorf(f string, a ...any) { r.failed, r.msg = true, fmt.Sprintf(f, a...) }
+func (r *recorder) Fatalf(f string, a ...any) { r.Errorf(f, a...) }
+
+func useDir(t *testing.T) {
+	t.Helper()
+	dir := Dir
+	t.Cleanup(func() { Dir = dir })
+	Dir = t.TempDir()
+	t.Setenv("CI", "")
+	t.Setenv("UPDATE_SNAPSHOTS", "")
+}
+
+func TestNormalize(t *testing.T) {
+	in := "Order 550e8400-e29b-41d4-a716-446655440000 shipped at 2024-05-01T12:30:00Z.\n"
+	if got := Normalize(in); got != "Order <UUID> shipped at <TIMESTAMP>." {
+		t.Errorf("Normalize() = %q", got)
+	}
+	got := Normalize("Total: 41.50 EUR, ref ABC-123", WithRules(Numbers), WithReplace(`ABC-<N>`, "<REF>"))
+	if got != "Total: <N> EUR, ref <REF>" {
+		t.Errorf("Normalize() = %q", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	r := Compare("The capital of France is Paris.", "The capital of France is Paris, of course.", WithThreshold(0.7))
+	if !r.OK || r.Similarity >= 1 {
+		t.Errorf("Compare() = %+v, want fuzzy match", r)
+	}
+
+	r = Compare("one\ntwo\nthree", "one\n2\nthree")
+	if r.OK || r.Diff != "- two\n+ 2\n" {
+		t.Errorf("Compare() = %+v", r)
+	}
+
+	r = Compare(`{"city":"Paris","at":"2024-05-01T12:30:00Z"}`, `{"at":"2025-01-01T00:00:00Z","city":"Lyon"}`)
+	if r.OK || r.Diff != `  city: "Paris" -> "Lyon"`+"\n" {
+		t.Errorf("Compare() diff = %q", r.Diff)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	useDir(t)
+
+	rec := &recorder{}
+	Match(rec, "Summary/short", `{"title":"Q3 report","id":"550e8400-e29b-41d4-a716-446655440000"}`)
+	if rec.failed {
+		t.Fatalf("first run failed: %s", rec.msg)
+	}
+	stored, err := os.ReadFile(filepath.Join(Dir, "Summary_short.snap"))
+	if err != nil || !strings.Contains(string(stored), "\"id\": \"<UUID>\"") {
+		t.Fatalf("snapshot = %q, %v", stored, err)
+	}
+
+	rec = &recorder{}
+	Match(rec, "Summary/short", `{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","title":"Q3 report"}`)
+	if rec.failed {
+		t.Errorf("matching output failed: %s", rec.msg)
+	}
+
+	rec = &recorder{}
+	Match(rec, "Summary/short", `{"id":"x","title":"Q4 report"}`)
+	if !rec.failed || !strings.Contains(rec.msg, `title: "Q3 report" -> "Q4 report"`) {
+		t.Errorf("changed output: failed = %v, msg = %s", rec.failed, rec.msg)
+	}
+}
+
+func TestMatch_MissingInCI(t *testing.T) {
+	useDir(t)
+	t.Setenv("CI", "true")
+
+	rec := &recorder{}
+	Match(rec, "new", "hello")
+	if !rec.failed || !strings.Contains(rec.msg, "UPDATE_SNAPSHOTS=1") {
+		t.Errorf("failed = %v, msg = %s", rec.failed, rec.msg)
+	}
+
+	t.Setenv("UPDATE_SNAPSHOTS", "1")
+	rec = &recorder{}
+	Match(rec, "new", "hello")
+	if rec.failed {
+		t.Errorf("update failed: %s", rec.msg)
+	}
+}
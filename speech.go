@@ -0,0 +1,238 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Default speech models per provider, used when SpeechRequest.Model is empty.
+var defaultSpeechModels = map[string]string{
+	OpenAI: "gpt-4o-mini-tts",
+	Google: "gemini-2.5-flash-preview-tts",
+}
+
+// Default voices per provider, used when SpeechRequest.Voice is empty.
+var defaultVoices = map[string]string{
+	OpenAI: "alloy",
+	Google: "Kore",
+}
+
+// OpenAIVoices lists the OpenAI speech voices. The legacy tts-1 and tts-1-hd
+// models support all but ballad and verse, and ignore Instructions.
+var OpenAIVoices = []string{"alloy", "ash", "ballad", "coral", "echo", "fable", "nova", "onyx", "sage", "shimmer", "verse"}
+
+// SpeechRequest describes text to synthesize with Speak.
+type SpeechRequest struct {
+	Text         string
+	Model        string  // optional, uses the provider's default speech model
+	Voice        string  // OpenAI: "alloy" (default) or another of OpenAIVoices; Google: "Kore" (default), "Puck", ...
+	Format       string  // OpenAI: "mp3" (default), "wav", "opus", "aac", "flac", "pcm"; Google: "wav" (default), "pcm"
+	Instructions string  // optional tone, emotion or pace, e.g. "Speak calmly, like a narrator"; not on tts-1 models
+	Speed        float64 // OpenAI only: 0.25 to 4.0 (default 1)
+
+	// Google only: up to two named speakers, each with its own voice, instead
+	// of Voice. Text is a dialogue whose lines start with "Name: ".
+	Speakers []Speaker
+}
+
+// Speaker assigns a voice to a speaker name in multi-speaker speech.
+type Speaker struct {
+	Name  string
+	Voice string
+}
+
+// SpeechResponse is synthesized audio.
+type SpeechResponse struct {
+	Audio    []byte
+	MimeType string // e.g. "audio/mpeg", "audio/wav"; raw PCM is "audio/pcm;rate=24000"
+	Tokens   Usage  // reported by Google only
+}
+
+// Speak converts text to speech. OpenAI (audio/speech) and Google (Gemini TTS)
+// only. PCM output is 16-bit mono at 24 kHz; "wav" wraps it in a WAV header.
+func Speak(ctx context.Context, p Provider, req SpeechRequest, opts ...Option) (SpeechResponse, error) {
+	if err := validateSpeechRequest(p, &req); err != nil {
+		return SpeechResponse{}, err
+	}
+	o := applyOptions(opts...)
+	return withPoolKey(ctx, p, o, func(p Provider) (SpeechResponse, error) {
+		if p.Name == Google {
+			return speakGoogle(ctx, p, req, o)
+		}
+		return speakOpenAI(ctx, p, req, o)
+	})
+}
+
+// SpeechStream is audio that can be read while it is still being synthesized.
+// Close it when done; closing early cancels synthesis.
+type SpeechStream struct {
+	io.ReadCloser
+	MimeType string // as in SpeechResponse
+}
+
+// SpeakStream is like Speak but returns the audio as it is generated, so
+// playback can start before synthesis completes. OpenAI streams the encoded
+// file. Google streams PCM; its "wav" output starts with a header of unknown
+// length, which streaming players accept. Errors after the stream is returned
+// (a dropped connection, blocked content) are reported by Read.
+func SpeakStream(ctx context.Context, p Provider, req SpeechRequest, opts ...Option) (*SpeechStream, error) {
+	if err := validateSpeechRequest(p, &req); err != nil {
+		return nil, err
+	}
+	o := applyOptions(opts...)
+	return withPoolKey(ctx, p, o, func(p Provider) (*SpeechStream, error) {
+		if p.Name == Google {
+			return speakGoogleStream(ctx, p, req, o)
+		}
+		return speakOpenAIStream(ctx, p, req, o)
+	})
+}
+
+// cancelReader cancels a context when closed, stopping the goroutine that feeds it.
+type cancelReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r cancelReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+// validateSpeechRequest checks the request and fills in defaults.
+func validateSpeechRequest(p Provider, req *SpeechRequest) error {
+	if err := validateProvider(p); err != nil {
+		return err
+	}
+	if _, ok := defaultSpeechModels[p.Name]; !ok {
+		return &ValidationError{Field: "provider", Message: "speech not supported by " + p.Name}
+	}
+	if req.Text == "" {
+		return &ValidationError{Field: "text", Message: "required"}
+	}
+
+	formats := "mp3, wav, opus, aac, flac or pcm"
+	valid := map[string]bool{"mp3": true, "wav": true, "opus": true, "aac": true, "flac": true, "pcm": true}
+	if p.Name == Google {
+		formats = "wav or pcm"
+		valid = map[string]bool{"wav": true, "pcm": true}
+		if req.Speed != 0 {
+			return &ValidationError{Field: "speed", Message: "not supported by " + p.Name + "; describe the pace in Instructions"}
+		}
+	}
+	if len(req.Speakers) > 0 {
+		if err := validateSpeakers(p, *req); err != nil {
+			return err
+		}
+	}
+	if req.Format != "" && !valid[req.Format] {
+		return &ValidationError{Field: "format", Message: "must be " + formats}
+	}
+	if req.Speed != 0 {
+		if err := checkRange("speed", req.Speed, 0.25, 4, p.Name); err != nil {
+			return err
+		}
+	}
+
+	if req.Model == "" {
+		req.Model = defaultSpeechModels[p.Name]
+	}
+	if req.Voice == "" && len(req.Speakers) == 0 {
+		req.Voice = defaultVoices[p.Name]
+	}
+	if p.Name == OpenAI && strings.HasPrefix(req.Model, "tts-1") {
+		if req.Instructions != "" {
+			return &ValidationError{Field: "instructions", Message: "not supported by " + req.Model + "; use gpt-4o-mini-tts"}
+		}
+		if req.Voice == "ballad" || req.Voice == "verse" {
+			return &ValidationError{Field: "voice", Message: req.Voice + " not supported by " + req.Model + "; use gpt-4o-mini-tts"}
+		}
+	}
+	if req.Format == "" {
+		req.Format = "mp3"
+		if p.Name == Google {
+			req.Format = "wav"
+		}
+	}
+	return nil
+}
+
+// validateSpeakers checks a multi-speaker request.
+func validateSpeakers(p Provider, req SpeechRequest) error {
+	if p.Name != Google {
+		return &ValidationError{Field: "speakers", Message: "not supported by " + p.Name}
+	}
+	if len(req.Speakers) > 2 {
+		return &ValidationError{Field: "speakers", Message: "at most 2 for " + p.Name}
+	}
+	if req.Voice != "" {
+		return &ValidationError{Field: "voice", Message: "set per speaker when speakers are given"}
+	}
+	for _, s := range req.Speakers {
+		if s.Name == "" || s.Voice == "" {
+			return &ValidationError{Field: "speakers", Message: "name and voice required"}
+		}
+	}
+	return nil
+}
+
+// audioMimeType maps an output format to its MIME type.
+func audioMimeType(format string) string {
+	switch format {
+	case "mp3":
+		return "audio/mpeg"
+	case "wav":
+		return "audio/wav"
+	case "opus":
+		return "audio/opus"
+	case "aac":
+		return "audio/aac"
+	case "flac":
+		return "audio/flac"
+	default:
+		return "audio/pcm;rate=24000"
+	}
+}
+
+// pcmRate returns the sample rate in a MIME type such as "audio/L16;codec=pcm;rate=24000".
+func pcmRate(mimeType string, fallback int) int {
+	for _, param := range strings.Split(mimeType, ";") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(param), "rate="); ok {
+			if rate, err := strconv.Atoi(v); err == nil && rate > 0 {
+				return rate
+			}
+		}
+	}
+	return fallback
+}
+
+// wavFile wraps 16-bit mono PCM in a WAV (RIFF) header.
+func wavFile(pcm []byte, rate int) []byte {
+	return append(wavHeader(len(pcm), rate), pcm...)
+}
+
+// wavHeader returns the WAV header for size bytes of 16-bit mono PCM. A
+// negative size writes the maximum length, as streaming encoders do.
+func wavHeader(size, rate int) []byte {
+	const channels, bits = 1, 16
+	riffSize, dataSize := uint32(36+size), uint32(size)
+	if size < 0 {
+		riffSize, dataSize = 0xFFFFFFFF, 0xFFFFFFFF
+	}
+	out := make([]byte, 0, 44+max(size, 0))
+	out = append(out, "RIFF"...)
+	out = binary.LittleEndian.AppendUint32(out, riffSize)
+	out = append(out, "WAVEfmt "...)
+	out = binary.LittleEndian.AppendUint32(out, 16) // fmt chunk size
+	out = binary.LittleEndian.AppendUint16(out, 1)  // PCM
+	out = binary.LittleEndian.AppendUint16(out, channels)
+	out = binary.LittleEndian.AppendUint32(out, uint32(rate))
+	out = binary.LittleEndian.AppendUint32(out, uint32(rate*channels*bits/8))
+	out = binary.LittleEndian.AppendUint16(out, channels*bits/8)
+	out = binary.LittleEndian.AppendUint16(out, bits)
+	out = append(out, "data"...)
+	return binary.LittleEndian.AppendUint32(out, dataSize)
+}
@@ -0,0 +1,257 @@
+package llmkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSpeak_OpenAI(t *testing.T) {
+	var got openaiSpeechRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != openaiSpeechPath {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("ID3-audio"))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := Speak(context.Background(), p, SpeechRequest{
+		Text:         "Hello there",
+		Voice:        "coral",
+		Instructions: "Cheerful",
+		Speed:        1.25,
+	})
+	if err != nil {
+		t.Fatalf("Speak() error = %v", err)
+	}
+
+	want := openaiSpeechRequest{Model: "gpt-4o-mini-tts", Input: "Hello there", Voice: "coral",
+		ResponseFormat: "mp3", Instructions: "Cheerful", Speed: 1.25}
+	if got != want {
+		t.Errorf("request = %+v, want %+v", got, want)
+	}
+	if string(resp.Audio) != "ID3-audio" || resp.MimeType != "audio/mpeg" {
+		t.Errorf("response = %q %s", resp.Audio, resp.MimeType)
+	}
+}
+
+func TestSpeak_Google(t *testing.T) {
+	var got googleRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-2.5-flash-preview-tts:generateContent" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		// "AQIDBA==" is base64 for 0x01 0x02 0x03 0x04
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"inlineData":
+			{"mimeType":"audio/L16;codec=pcm;rate=16000","data":"AQIDBA=="}}]}}],
+			"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":40}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := Speak(context.Background(), p, SpeechRequest{
+		Text:         "Have a wonderful day!",
+		Voice:        "Puck",
+		Instructions: "Say cheerfully",
+	})
+	if err != nil {
+		t.Fatalf("Speak() error = %v", err)
+	}
+
+	gc := got.GenerationConfig
+	if gc == nil || len(gc.ResponseModalities) != 1 || gc.ResponseModalities[0] != "AUDIO" ||
+		gc.SpeechConfig.VoiceConfig.PrebuiltVoiceConfig.VoiceName != "Puck" {
+		t.Errorf("generationConfig = %+v", gc)
+	}
+	if text := got.Contents[0].Parts[0].Text; text != "Say cheerfully: Have a wonderful day!" {
+		t.Errorf("text = %q", text)
+	}
+
+	if resp.MimeType != "audio/wav" || len(resp.Audio) != 44+4 {
+		t.Fatalf("response = %s, %d bytes", resp.MimeType, len(resp.Audio))
+	}
+	if !bytes.HasPrefix(resp.Audio, []byte("RIFF")) || !bytes.HasSuffix(resp.Audio, []byte{1, 2, 3, 4}) {
+		t.Errorf("audio = %v", resp.Audio)
+	}
+	if rate := binary.LittleEndian.Uint32(resp.Audio[24:28]); rate != 16000 {
+		t.Errorf("sample rate = %d, want 16000", rate)
+	}
+	if resp.Tokens.Input != 5 || resp.Tokens.Output != 40 {
+		t.Errorf("Tokens = %+v", resp.Tokens)
+	}
+}
+
+func TestSpeak_GooglePCM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"inlineData":
+			{"mimeType":"audio/L16;codec=pcm;rate=24000","data":"AQIDBA=="}}]}}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	resp, err := Speak(context.Background(), p, SpeechRequest{Text: "hi", Format: "pcm"})
+	if err != nil {
+		t.Fatalf("Speak() error = %v", err)
+	}
+	if !bytes.Equal(resp.Audio, []byte{1, 2, 3, 4}) || resp.MimeType != "audio/pcm;rate=24000" {
+		t.Errorf("response = %v %s", resp.Audio, resp.MimeType)
+	}
+}
+
+func TestSpeak_GoogleMultiSpeaker(t *testing.T) {
+	var got googleRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"inlineData":
+			{"mimeType":"audio/L16;codec=pcm;rate=24000","data":"AQIDBA=="}}]}}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	_, err := Speak(context.Background(), p, SpeechRequest{
+		Text:     "Joe: How's it going today Jane?\nJane: Not too bad, how about you?",
+		Speakers: []Speaker{{Name: "Joe", Voice: "Kore"}, {Name: "Jane", Voice: "Puck"}},
+	})
+	if err != nil {
+		t.Fatalf("Speak() error = %v", err)
+	}
+
+	sc := got.GenerationConfig.SpeechConfig
+	if sc.VoiceConfig != nil || sc.MultiSpeakerVoiceConfig == nil {
+		t.Fatalf("speechConfig = %+v", sc)
+	}
+	speakers := sc.MultiSpeakerVoiceConfig.SpeakerVoiceConfigs
+	if len(speakers) != 2 || speakers[1].Speaker != "Jane" || speakers[1].VoiceConfig.PrebuiltVoiceConfig.VoiceName != "Puck" {
+		t.Errorf("speakers = %+v", speakers)
+	}
+}
+
+func TestSpeakStream_OpenAI(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != openaiSpeechPath || r.Header.Get("Accept") != "audio/opus" {
+			t.Errorf("path = %s, Accept = %s", r.URL.Path, r.Header.Get("Accept"))
+		}
+		w.Write([]byte("chunk1"))
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("chunk2"))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	stream, err := SpeakStream(context.Background(), p, SpeechRequest{Text: "Hello", Format: "opus"})
+	if err != nil {
+		t.Fatalf("SpeakStream() error = %v", err)
+	}
+	defer stream.Close()
+	if stream.MimeType != "audio/opus" {
+		t.Errorf("MimeType = %q", stream.MimeType)
+	}
+
+	// The first chunk is readable while the server is still synthesizing
+	buf := make([]byte, 6)
+	if _, err := io.ReadFull(stream, buf); err != nil || string(buf) != "chunk1" {
+		t.Fatalf("first read = %q, %v", buf, err)
+	}
+	close(release)
+	rest, _ := io.ReadAll(stream)
+	if string(rest) != "chunk2" {
+		t.Errorf("rest = %q", rest)
+	}
+}
+
+func TestSpeakStream_Google(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, pcm := range []string{"\x01\x02", "\x03\x04"} {
+			part := `{"inlineData":{"mimeType":"audio/L16;codec=pcm;rate=16000","data":"` + base64.StdEncoding.EncodeToString([]byte(pcm)) + `"}}`
+			w.Write([]byte(`data: {"candidates":[{"content":{"parts":[` + part + `]}}]}` + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	stream, err := SpeakStream(context.Background(), p, SpeechRequest{Text: "Hello"})
+	if err != nil {
+		t.Fatalf("SpeakStream() error = %v", err)
+	}
+	defer stream.Close()
+	audio, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/v1beta/models/gemini-2.5-flash-preview-tts:streamGenerateContent" || stream.MimeType != "audio/wav" {
+		t.Errorf("path = %s, MimeType = %s", path, stream.MimeType)
+	}
+	if len(audio) != 48 || string(audio[:4]) != "RIFF" || binary.LittleEndian.Uint32(audio[40:44]) != 0xFFFFFFFF {
+		t.Fatalf("audio = %x", audio)
+	}
+	if rate := binary.LittleEndian.Uint32(audio[24:28]); rate != 16000 || !bytes.Equal(audio[44:], []byte{1, 2, 3, 4}) {
+		t.Errorf("rate = %d, pcm = %x", rate, audio[44:])
+	}
+}
+
+func TestSpeakStream_GoogleBlocked(t *testing.T) {
+	server := sseServer(t, `data: {"candidates":[{"content":{"parts":[]},"finishReason":"SAFETY"}]}`)
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	stream, err := SpeakStream(context.Background(), p, SpeechRequest{Text: "Hello", Format: "pcm"})
+	if err != nil {
+		t.Fatalf("SpeakStream() error = %v", err)
+	}
+	defer stream.Close()
+	_, err = io.ReadAll(stream)
+	var blocked *ContentBlockedError
+	if !errors.As(err, &blocked) || blocked.Reason != "SAFETY" {
+		t.Errorf("Read error = %v, want ContentBlockedError", err)
+	}
+}
+
+func TestSpeak_Validation(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		p     Provider
+		req   SpeechRequest
+		field string
+	}{
+		{"unsupported provider", Provider{Name: Anthropic, APIKey: "k"}, SpeechRequest{Text: "x"}, "provider"},
+		{"missing text", Provider{Name: OpenAI, APIKey: "k"}, SpeechRequest{}, "text"},
+		{"bad format", Provider{Name: OpenAI, APIKey: "k"}, SpeechRequest{Text: "x", Format: "ogg"}, "format"},
+		{"google mp3", Provider{Name: Google, APIKey: "k"}, SpeechRequest{Text: "x", Format: "mp3"}, "format"},
+		{"speed range", Provider{Name: OpenAI, APIKey: "k"}, SpeechRequest{Text: "x", Speed: 5}, "speed"},
+		{"google speed", Provider{Name: Google, APIKey: "k"}, SpeechRequest{Text: "x", Speed: 1.5}, "speed"},
+		{"openai speakers", Provider{Name: OpenAI, APIKey: "k"}, SpeechRequest{Text: "x", Speakers: []Speaker{{"A", "alloy"}}}, "speakers"},
+		{"three speakers", Provider{Name: Google, APIKey: "k"}, SpeechRequest{Text: "x", Speakers: []Speaker{{"A", "Kore"}, {"B", "Puck"}, {"C", "Zephyr"}}}, "speakers"},
+		{"speaker voice missing", Provider{Name: Google, APIKey: "k"}, SpeechRequest{Text: "x", Speakers: []Speaker{{Name: "A"}}}, "speakers"},
+		{"tts-1 instructions", Provider{Name: OpenAI, APIKey: "k"}, SpeechRequest{Text: "x", Model: "tts-1-hd", Instructions: "Whisper"}, "instructions"},
+		{"tts-1 new voice", Provider{Name: OpenAI, APIKey: "k"}, SpeechRequest{Text: "x", Model: "tts-1", Voice: "verse"}, "voice"},
+		{"voice and speakers", Provider{Name: Google, APIKey: "k"}, SpeechRequest{Text: "x", Voice: "Kore", Speakers: []Speaker{{"A", "Puck"}}}, "voice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Speak(ctx, tt.p, tt.req)
+			var ve *ValidationError
+			if !errors.As(err, &ve) || ve.Field != tt.field {
+				t.Errorf("error = %v, want %s ValidationError", err, tt.field)
+			}
+		})
+	}
+}
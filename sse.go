@@ -0,0 +1,69 @@
+package llmkit
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// maxSSELine is the largest server-sent event line accepted (1MB).
+const maxSSELine = 1 << 20
+
+// readSSE parses a server-sent event stream and calls fn for each event.
+// Multi-line data fields are joined with newlines. Returning an error from fn stops reading.
+func readSSE(r io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxSSELine)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := fn(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return dispatch()
+}
+
+// readStream reads a server-sent event response body with readSSE. The body is
+// closed as soon as ctx ends, so a read blocked on a stalled connection returns
+// at once, and ctx.Err() is reported instead of the resulting read error.
+func readStream(ctx context.Context, body io.ReadCloser, fn func(event, data string) error) error {
+	stop := context.AfterFunc(ctx, func() { body.Close() })
+	defer stop()
+
+	err := readSSE(body, func(event, data string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(event, data)
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadSSE(t *testing.T) {
+	stream := ": keep-alive\n" +
+		"event: message_start\n" +
+		"data: {\"a\":1}\n" +
+		"\n" +
+		"data: line1\n" +
+		"data: line2\n" +
+		"\n" +
+		"data: [DONE]"
+
+	type ev struct{ event, data string }
+	var got []ev
+	err := readSSE(strings.NewReader(stream), func(event, data string) error {
+		got = append(got, ev{event, data})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readSSE() error = %v", err)
+	}
+
+	want := []ev{
+		{"message_start", `{"a":1}`},
+		{"", "line1\nline2"},
+		{"", "[DONE]"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadSSE_StopOnError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := readSSE(strings.NewReader("data: 1\n\ndata: 2\n\n"), func(event, data string) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("err = %v, want stop", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestReadStream_CancelClosesBody(t *testing.T) {
+	pr, pw := io.Pipe() // a body that ignores ctx and blocks until closed
+	defer pw.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- readStream(ctx, pr, func(event, data string) error { return nil })
+	}()
+	pw.Write([]byte("data: first\n\n"))
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("readStream() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("readStream did not return after cancel")
+	}
+}
@@ -0,0 +1,52 @@
+package llmkit
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Stream is a streaming response running in the background, started by StartStream.
+type Stream struct {
+	cancel    context.CancelFunc
+	cancelled atomic.Bool
+	done      chan struct{}
+	resp      Response
+	err       error
+}
+
+// StartStream runs PromptStream in the background and returns a handle to it.
+// fn is called from the stream's goroutine. Cancelling ctx or calling Cancel
+// closes the connection, which stops generation and token spend.
+func StartStream(ctx context.Context, p Provider, req Request, fn StreamFunc, opts ...Option) *Stream {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Stream{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		defer cancel()
+		s.resp, s.err = PromptStream(ctx, p, req, func(delta string) {
+			if !s.cancelled.Load() {
+				fn(delta)
+			}
+		}, opts...)
+	}()
+	return s
+}
+
+// Cancel abandons the stream. Deltas that arrive afterwards are dropped and,
+// unless the stream had already finished, Wait returns context.Canceled.
+// Cancel does not block and may be called more than once.
+func (s *Stream) Cancel() {
+	s.cancelled.Store(true)
+	s.cancel()
+}
+
+// Done is closed when the stream has finished, failed or been cancelled.
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Wait blocks until the stream ends and returns its result, as PromptStream would.
+func (s *Stream) Wait() (Response, error) {
+	<-s.done
+	return s.resp, s.err
+}
@@ -0,0 +1,70 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStartStream(t *testing.T) {
+	server := sseServer(t,
+		`data: {"choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`data: {"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	)
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	var text string
+	s := StartStream(context.Background(), p, Request{User: "Hi"}, func(d string) { text += d })
+	resp, err := s.Wait()
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if resp.Text != "Hello" || text != "Hello" {
+		t.Errorf("Text = %q, streamed %q", resp.Text, text)
+	}
+	select {
+	case <-s.Done():
+	default:
+		t.Error("Done() not closed after Wait")
+	}
+}
+
+func TestStartStream_Cancel(t *testing.T) {
+	disconnected := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"Hello"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done() // the model keeps generating until the client goes away
+		close(disconnected)
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	first := make(chan struct{})
+	var deltas int
+	s := StartStream(context.Background(), p, Request{User: "Hi"}, func(string) {
+		deltas++
+		close(first)
+	})
+
+	<-first
+	s.Cancel()
+	if _, err := s.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want context.Canceled", err)
+	}
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not closed after Cancel")
+	}
+	if deltas != 1 {
+		t.Errorf("deltas = %d, want 1", deltas)
+	}
+	s.Cancel() // idempotent
+}
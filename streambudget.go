@@ -0,0 +1,137 @@
+package llmkit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// WithStreamBudget aborts a streaming request (PromptStream, StartStream) once
+// its output would exceed maxTokens estimated tokens (see EstimateTokens) or
+// maxBytes bytes of text; 0 disables a limit. Unlike WithMaxTokens, which the
+// provider applies, this is enforced client-side as deltas arrive, closing the
+// connection so a runaway generation stops costing tokens. The delta that
+// crosses the budget is not delivered. The request then returns the text
+// streamed so far, with FinishLength, and a *StreamBudgetError.
+func WithStreamBudget(maxTokens, maxBytes int) Option {
+	return func(o *options) {
+		o.streamBudget = streamBudget{tokens: maxTokens, bytes: maxBytes}
+	}
+}
+
+type streamBudget struct {
+	tokens, bytes int
+}
+
+func (b streamBudget) set() bool {
+	return b.tokens > 0 || b.bytes > 0
+}
+
+// StreamBudgetError reports a stream aborted by WithStreamBudget.
+type StreamBudgetError struct {
+	Limit  string // "tokens" or "bytes"
+	Max    int
+	Tokens int // estimated output tokens delivered
+	Bytes  int // output bytes delivered
+}
+
+func (e *StreamBudgetError) Error() string {
+	return fmt.Sprintf("stream budget exceeded: more than %d %s", e.Max, e.Limit)
+}
+
+// streamGuard counts streamed output and cancels the request over budget.
+type streamGuard struct {
+	budget streamBudget
+	next   StreamFunc
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	text   strings.Builder
+	tokens int
+	usage  Usage // latest usage snapshot from the provider
+	err    *StreamBudgetError
+}
+
+// guardStream returns a cancellable ctx and a copy of o whose stream and usage
+// callbacks go through the guard.
+func guardStream(ctx context.Context, o *options) (context.Context, *options, *streamGuard) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &streamGuard{budget: o.streamBudget, next: o.stream, cancel: cancel}
+
+	guarded := *o
+	guarded.stream = g.write
+	onUsage := o.onUsage
+	guarded.onUsage = func(u Usage) {
+		g.mu.Lock()
+		g.usage = u
+		g.mu.Unlock()
+		if onUsage != nil {
+			onUsage(u)
+		}
+	}
+	return ctx, &guarded, g
+}
+
+// write forwards delta unless it would exceed the budget.
+func (g *streamGuard) write(delta string) {
+	g.mu.Lock()
+	if g.err != nil {
+		g.mu.Unlock()
+		return
+	}
+	// Estimating per delta avoids rescanning the text; words split across
+	// deltas are counted slightly high
+	tokens, bytes := g.tokens+EstimateTokens(delta), g.text.Len()+len(delta)
+	switch {
+	case g.budget.tokens > 0 && tokens > g.budget.tokens:
+		g.err = &StreamBudgetError{Limit: "tokens", Max: g.budget.tokens}
+	case g.budget.bytes > 0 && bytes > g.budget.bytes:
+		g.err = &StreamBudgetError{Limit: "bytes", Max: g.budget.bytes}
+	}
+	if g.err != nil {
+		g.err.Tokens, g.err.Bytes = g.tokens, g.text.Len()
+		g.mu.Unlock()
+		g.cancel()
+		return
+	}
+	g.tokens = tokens
+	g.text.WriteString(delta)
+	g.mu.Unlock()
+	g.next(delta)
+}
+
+// stop releases the guard's context. It is safe on a nil guard.
+func (g *streamGuard) stop() {
+	if g != nil {
+		g.cancel()
+	}
+}
+
+// tripped reports whether the budget was exceeded. It is safe on a nil guard.
+func (g *streamGuard) tripped() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err != nil
+}
+
+// result replaces the cancellation error of an aborted stream with the partial
+// response and a StreamBudgetError. It is safe on a nil guard.
+func (g *streamGuard) result(resp Response, err error) (Response, error) {
+	if g == nil {
+		return resp, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err == nil {
+		return resp, err
+	}
+	resp.Text = g.text.String()
+	resp.FinishReason = FinishLength
+	resp.Tokens = g.usage
+	resp.Tokens.Output = max(resp.Tokens.Output, g.tokens)
+	return resp, g.err
+}
@@ -0,0 +1,78 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithStreamBudget_Bytes(t *testing.T) {
+	server := sseServer(t,
+		`event: message_start`+"\n"+`data: {"type":"message_start","message":{"usage":{"input_tokens":12,"output_tokens":1}}}`,
+		`event: content_block_start`+"\n"+`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`event: content_block_delta`+"\n"+`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`,
+		`event: content_block_delta`+"\n"+`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" world"}}`,
+		`event: message_delta`+"\n"+`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}`,
+	)
+	defer server.Close()
+
+	p := Provider{Name: Anthropic, APIKey: "test-key", BaseURL: server.URL}
+	var deltas []string
+	resp, err := PromptStream(context.Background(), p, Request{User: "Hi"}, func(d string) {
+		deltas = append(deltas, d)
+	}, WithStreamBudget(0, 10))
+
+	var budgetErr *StreamBudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Limit != "bytes" || budgetErr.Bytes != 5 {
+		t.Fatalf("error = %v, want bytes StreamBudgetError", err)
+	}
+	if strings.Join(deltas, "|") != "Hello" || resp.Text != "Hello" || resp.FinishReason != FinishLength {
+		t.Errorf("deltas = %q, resp = %+v", deltas, resp)
+	}
+	// Input comes from message_start; output is estimated from the delivered text
+	if resp.Tokens.Input != 12 || resp.Tokens.Output != 1 {
+		t.Errorf("Tokens = %+v, want 12 in / 1 out", resp.Tokens)
+	}
+}
+
+func TestWithStreamBudget_TokensAbortsStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for range 20 {
+			w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"on and on "}}]}` + "\n\n"))
+		}
+		w.(http.Flusher).Flush()
+		// A runaway generation: the stream only ends when the client disconnects
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	var streamed strings.Builder
+	resp, err := PromptStream(context.Background(), p, Request{User: "Go"}, func(d string) {
+		streamed.WriteString(d)
+	}, WithStreamBudget(10, 0))
+
+	var budgetErr *StreamBudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Limit != "tokens" || budgetErr.Max != 10 {
+		t.Fatalf("error = %v, want tokens StreamBudgetError", err)
+	}
+	if EstimateTokens(resp.Text) > 10 || resp.Text != streamed.String() || resp.Text == "" {
+		t.Errorf("Text = %q, streamed = %q", resp.Text, streamed.String())
+	}
+}
+
+func TestWithStreamBudget_Validation(t *testing.T) {
+	var ve *ValidationError
+	_, err := PromptStream(context.Background(), Provider{Name: OpenAI, APIKey: "k"}, Request{User: "Hi"}, func(string) {}, WithStreamBudget(-1, 0))
+	if !errors.As(err, &ve) || ve.Field != "stream_budget" {
+		t.Errorf("error = %v, want stream_budget ValidationError", err)
+	}
+	_, err = PromptStream(context.Background(), Provider{Name: Grok, APIKey: "k"}, Request{User: "Hi"}, func(string) {}, WithStreamBudget(100, 0))
+	if !errors.As(err, &ve) || ve.Field != "stream_budget" {
+		t.Errorf("error = %v, want stream_budget ValidationError", err)
+	}
+}
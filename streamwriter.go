@@ -0,0 +1,50 @@
+package llmkit
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StreamTo returns a StreamFunc that writes each delta to w. Writers with a
+// Flush method, such as *bufio.Writer and http.ResponseWriter, are flushed
+// after every delta so text appears as it is generated. Write errors are
+// ignored; the response still completes.
+func StreamTo(w io.Writer) StreamFunc {
+	return func(delta string) {
+		io.WriteString(w, delta)
+		flush(w)
+	}
+}
+
+// StreamSSE returns a StreamFunc that sends each delta to an HTTP client as a
+// server-sent event ("data: ..." lines; multi-line deltas span several data
+// lines, which the browser EventSource joins back with newlines). It sets the
+// event-stream headers before the first write.
+func StreamSSE(w http.ResponseWriter) StreamFunc {
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	return func(delta string) {
+		var b strings.Builder
+		for _, line := range strings.Split(delta, "\n") {
+			b.WriteString("data: ")
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		io.WriteString(w, b.String())
+		flush(w)
+	}
+}
+
+// flush flushes w when it buffers output.
+func flush(w io.Writer) {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		f.Flush()
+	case http.Flusher:
+		f.Flush()
+	}
+}
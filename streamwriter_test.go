@@ -0,0 +1,36 @@
+package llmkit
+
+import (
+	"bufio"
+	"bytes"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamTo(t *testing.T) {
+	var out bytes.Buffer
+	w := bufio.NewWriter(&out)
+	fn := StreamTo(w)
+	fn("Hel")
+	fn("lo")
+	if out.String() != "Hello" {
+		t.Errorf("after flush = %q, want Hello", out.String())
+	}
+}
+
+func TestStreamSSE(t *testing.T) {
+	rec := httptest.NewRecorder()
+	fn := StreamSSE(rec)
+	fn("Hi")
+	fn("a\nb")
+
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if !rec.Flushed {
+		t.Error("response was not flushed")
+	}
+	if want := "data: Hi\n\ndata: a\ndata: b\n\n"; rec.Body.String() != want {
+		t.Errorf("body = %q, want %q", rec.Body.String(), want)
+	}
+}
@@ -0,0 +1,152 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// PromptInto sends req with a JSON schema generated from T and decodes the
+// answer into a T, replacing any req.Schema. T must be a struct; see SchemaFor
+// for how fields map to the schema.
+func PromptInto[T any](ctx context.Context, p Provider, req Request, opts ...Option) (T, error) {
+	var out T
+	schema, err := SchemaFor[T]()
+	if err != nil {
+		return out, err
+	}
+	req.Schema = schema
+
+	resp, err := Prompt(ctx, p, req, opts...)
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal([]byte(resp.Text), &out); err != nil {
+		return out, fmt.Errorf("decode %T: %w", out, err)
+	}
+	return out, nil
+}
+
+// SchemaFor returns a JSON schema for struct type T. Exported fields use their
+// json tag names and are required unless tagged omitempty or declared as
+// pointers. A `description:"..."` tag documents a field for the model and
+// `enum:"a,b,c"` limits a string to those values. Slices become arrays, []byte
+// a base64 string and time.Time a date-time string; maps, interfaces and
+// recursive types are rejected.
+func SchemaFor[T any]() (string, error) {
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return "", &ValidationError{Field: "schema", Message: fmt.Sprintf("%s must be a struct", t)}
+	}
+	schema, err := typeSchema(t, map[reflect.Type]bool{})
+	if err != nil {
+		return "", &ValidationError{Field: "schema", Message: err.Error()}
+	}
+	data, err := json.Marshal(schema)
+	return string(data), err
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// typeSchema returns the schema for t. seen holds the structs being expanded,
+// to reject recursive types.
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) (map[string]any, error) {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), seen)
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes []byte as a base64 string
+			return map[string]any{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := typeSchema(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Struct:
+		return structSchema(t, seen)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// structSchema returns an object schema for the exported fields of t.
+// Embedded structs without a json name contribute their fields directly.
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) (map[string]any, error) {
+	if seen[t] {
+		return nil, fmt.Errorf("recursive type %s", t)
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	props := map[string]any{}
+	required := []string{}
+	var addFields func(t reflect.Type) error
+	addFields = func(t reflect.Type) error {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				if err := addFields(f.Type); err != nil {
+					return err
+				}
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+
+			schema, err := typeSchema(f.Type, seen)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+			}
+			if desc := f.Tag.Get("description"); desc != "" {
+				schema["description"] = desc
+			}
+			if enum := f.Tag.Get("enum"); enum != "" {
+				schema["enum"] = strings.Split(enum, ",")
+			}
+			props[name] = schema
+			if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+				required = append(required, name)
+			}
+		}
+		return nil
+	}
+	if err := addFields(t); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
@@ -0,0 +1,125 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+}
+
+type testBase struct {
+	ID int `json:"id"`
+}
+
+type testPerson struct {
+	testBase
+	Name     string       `json:"name" description:"Full name"`
+	Nickname string       `json:"nickname,omitempty"`
+	Role     string       `json:"role" enum:"admin,user"`
+	Tags     []string     `json:"tags"`
+	Home     *testAddress `json:"home"`
+	Born     time.Time    `json:"born"`
+	Score    float64
+	secret   string
+	Skip     string `json:"-"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	got, err := SchemaFor[testPerson]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	json.Unmarshal([]byte(got), &schema)
+
+	props := schema["properties"].(map[string]any)
+	if len(props) != 8 {
+		t.Errorf("properties = %v", props)
+	}
+	for name, want := range map[string]string{
+		"id":    `{"type":"integer"}`,
+		"name":  `{"description":"Full name","type":"string"}`,
+		"role":  `{"enum":["admin","user"],"type":"string"}`,
+		"tags":  `{"items":{"type":"string"},"type":"array"}`,
+		"home":  `{"additionalProperties":false,"properties":{"city":{"type":"string"}},"required":["city"],"type":"object"}`,
+		"born":  `{"format":"date-time","type":"string"}`,
+		"Score": `{"type":"number"}`,
+	} {
+		data, _ := json.Marshal(props[name])
+		if string(data) != want {
+			t.Errorf("%s = %s, want %s", name, data, want)
+		}
+	}
+	data, _ := json.Marshal(schema["required"])
+	if string(data) != `["id","name","role","tags","born","Score"]` {
+		t.Errorf("required = %s", data)
+	}
+}
+
+func TestSchemaFor_Bytes(t *testing.T) {
+	type attachment struct {
+		Data   []byte  `json:"data"`
+		Digest [2]byte `json:"digest"`
+	}
+	got, err := SchemaFor[attachment]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"data":{"contentEncoding":"base64","type":"string"},"digest":{"items":{"type":"integer"},"type":"array"}`
+	if !strings.Contains(got, want) {
+		t.Errorf("SchemaFor() = %s, want %s", got, want)
+	}
+
+	// An answer following the schema decodes
+	var out attachment
+	if err := json.Unmarshal([]byte(`{"data":"aGk=","digest":[1,2]}`), &out); err != nil || string(out.Data) != "hi" {
+		t.Errorf("decoded %+v, %v", out, err)
+	}
+}
+
+type testNode struct {
+	Next *testNode `json:"next"`
+}
+
+func TestSchemaFor_Unsupported(t *testing.T) {
+	var ve *ValidationError
+	if _, err := SchemaFor[string](); !errors.As(err, &ve) {
+		t.Errorf("string: %v", err)
+	}
+	if _, err := SchemaFor[struct {
+		Meta map[string]string `json:"meta"`
+	}](); err == nil || !strings.Contains(err.Error(), "unsupported type map[string]string") {
+		t.Errorf("map: %v", err)
+	}
+	if _, err := SchemaFor[testNode](); err == nil || !strings.Contains(err.Error(), "recursive type") {
+		t.Errorf("recursive: %v", err)
+	}
+}
+
+func TestPromptInto(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"choices":[{"message":{"content":"{\"city\":\"Oslo\"}"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`,
+		`{"choices":[{"message":{"content":"not json"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`,
+	}}
+	p := Provider{Name: OpenAI, APIKey: "test-key"}
+	client := WithHTTPClient(&http.Client{Transport: mock})
+
+	addr, err := PromptInto[testAddress](context.Background(), p, Request{User: "Where is the fjord?"}, client)
+	if err != nil || addr.City != "Oslo" {
+		t.Fatalf("PromptInto() = %+v, %v", addr, err)
+	}
+	if !strings.Contains(mock.bodies[0], `"response_format"`) || !strings.Contains(mock.bodies[0], `"city"`) {
+		t.Errorf("request = %s", mock.bodies[0])
+	}
+
+	if _, err := PromptInto[testAddress](context.Background(), p, Request{User: "Again"}, client); err == nil {
+		t.Error("expected decode error")
+	}
+}
@@ -0,0 +1,287 @@
+package llmkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	_ "image/gif" // registers decoders for image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// messageOverhead approximates the tokens chat formats add around each message.
+const messageOverhead = 4
+
+// Tokenizer counts the tokens of text as model would, such as a tiktoken port
+// with the cl100k_base or o200k_base vocabulary for OpenAI models.
+type Tokenizer func(model, text string) int
+
+// WithTokenizer makes CountTokens count text with t instead of EstimateTokens
+// for providers without a token-counting endpoint. llmkit ships no BPE
+// vocabularies, so exact OpenAI counts need one.
+func WithTokenizer(t Tokenizer) Option {
+	return func(o *options) {
+		o.tokenizer = t
+	}
+}
+
+// CountTokens returns the number of input tokens req would use with p's model,
+// so callers can trim context before sending. Anthropic and Google count with
+// their token-counting endpoints. The other providers (OpenAI, Grok, Ollama,
+// OpenRouter, OpenAI-compatible, and Anthropic on Vertex AI) have none and get
+// an estimate (see EstimateRequestTokens) that can be off by about 15%, unless
+// WithTokenizer supplies an exact tokenizer. Attachments are always estimated.
+func CountTokens(ctx context.Context, p Provider, req Request, opts ...Option) (int, error) {
+	o := applyOptions(opts...)
+	if o.model != "" {
+		p.Model = o.model
+	}
+	if err := validateProvider(p); err != nil {
+		return 0, err
+	}
+	if err := validateRequest(req); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case p.Name == Anthropic && p.Vertex == nil:
+		return withPoolKey(ctx, p, o, func(p Provider) (int, error) {
+			return countTokensAnthropic(ctx, p, req, o)
+		})
+	case p.Name == Google:
+		return withPoolKey(ctx, p, o, func(p Provider) (int, error) {
+			return countTokensGoogle(ctx, p, req, o)
+		})
+	}
+
+	count := EstimateTokens
+	if o.tokenizer != nil {
+		model := p.model()
+		count = func(text string) int {
+			if text == "" {
+				return 0
+			}
+			return o.tokenizer(model, text)
+		}
+	}
+	return estimateRequestTokens(p.Name, req, count), nil
+}
+
+// EstimateRequestTokens approximates the input tokens of req for provider
+// offline: text with EstimateTokens, attachments with EstimateImageTokens and
+// EstimateFileTokens.
+func EstimateRequestTokens(provider string, req Request) int {
+	return estimateRequestTokens(provider, req, EstimateTokens)
+}
+
+// estimateRequestTokens is EstimateRequestTokens with text counted by count.
+func estimateRequestTokens(provider string, req Request, count func(string) int) int {
+	n := count(req.System) + count(req.User)
+	for _, m := range req.Messages {
+		n += count(m.Content) + messageOverhead
+		for _, img := range m.Images {
+			w, h := imageSize(extractImageData(img.URL))
+			n += EstimateImageTokens(provider, w, h, img.Detail)
+		}
+		for _, f := range m.Files {
+			n += EstimateFileTokens(provider, f)
+		}
+	}
+	if req.System != "" {
+		n += messageOverhead
+	}
+	if req.User != "" {
+		n += messageOverhead
+	}
+	for _, f := range req.Files {
+		n += EstimateFileTokens(provider, f)
+	}
+	for _, img := range req.Images {
+		w, h := imageSize(extractImageData(img.URL))
+		n += EstimateImageTokens(provider, w, h, img.Detail)
+	}
+	return n
+}
+
+// Assumed size of images whose dimensions cannot be read, such as remote URLs.
+const defaultImageSide = 1024
+
+// EstimateImageTokens returns the tokens an image of width x height pixels
+// costs as input, following each provider's published sizing:
+//   - OpenAI and compatible: 85, plus 170 per 512px tile after scaling to fit
+//     2048px and then to 768px on the short side ("low" detail is 85 flat)
+//   - Anthropic: pixels / 750 after scaling to fit 1568px, at most 1600
+//   - Google: 258 up to 384px, otherwise 258 per 768px tile
+//
+// Zero dimensions count as a 1024px square.
+func EstimateImageTokens(provider string, width, height int, detail string) int {
+	w, h := float64(width), float64(height)
+	if w <= 0 || h <= 0 {
+		w, h = defaultImageSide, defaultImageSide
+	}
+	fit := func(limit float64) {
+		if s := limit / max(w, h); s < 1 {
+			w, h = w*s, h*s
+		}
+	}
+
+	switch provider {
+	case Anthropic:
+		fit(1568)
+		return min(int(math.Ceil(w*h/750)), 1600)
+	case Google:
+		if w <= 384 && h <= 384 {
+			return 258
+		}
+		return 258 * int(math.Ceil(w/768)*math.Ceil(h/768))
+	default:
+		if detail == "low" {
+			return 85
+		}
+		fit(2048)
+		if s := 768 / min(w, h); s < 1 {
+			w, h = w*s, h*s
+		}
+		return 85 + 170*int(math.Ceil(w/512)*math.Ceil(h/512))
+	}
+}
+
+// Per-page PDF costs. Gemini renders each page as a 258-token image;
+// Anthropic and OpenAI add the page's extracted text to a page image, which
+// Anthropic puts at 1,500 to 3,000 tokens for a typical page.
+var pdfPageTokens = map[string]int{
+	Anthropic: 2250,
+	Google:    258,
+}
+
+const defaultPDFPageTokens = 1300
+
+// Audio input tokens per second: Gemini documents 32; OpenAI's audio models
+// use about one token per 100ms.
+var audioTokensPerSecond = map[string]float64{
+	Google: 32,
+}
+
+const defaultAudioTokensPerSecond = 10
+
+// EstimateFileTokens approximates the input tokens an attachment costs with
+// provider: images by size (see EstimateImageTokens), PDFs by page count,
+// audio by duration (read from WAV and MP3 headers, otherwise assuming 128
+// kbit/s) and text-like files with EstimateTokens. Only inline Data can be
+// inspected; uploaded files (ID or URI only) count as 0.
+func EstimateFileTokens(provider string, f File) int {
+	if len(f.Data) == 0 {
+		return 0
+	}
+	mimeType := f.MimeType
+	if mimeType == "" {
+		mimeType = detectMimeType(f.Name)
+	}
+
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		w, h := imageSize(f.Data)
+		return EstimateImageTokens(provider, w, h, "")
+	case mimeType == "application/pdf":
+		perPage, ok := pdfPageTokens[provider]
+		if !ok {
+			perPage = defaultPDFPageTokens
+		}
+		return perPage * pdfPages(f.Data)
+	case strings.HasPrefix(mimeType, "audio/"):
+		perSecond, ok := audioTokensPerSecond[provider]
+		if !ok {
+			perSecond = defaultAudioTokensPerSecond
+		}
+		return int(math.Ceil(audioDuration(f.Data).Seconds() * perSecond))
+	default:
+		return EstimateTokens(string(f.Data))
+	}
+}
+
+// extractImageData decodes a base64 data URI; other URLs return nil.
+func extractImageData(url string) []byte {
+	if !strings.HasPrefix(url, "data:") {
+		return nil
+	}
+	data, _ := base64.StdEncoding.DecodeString(extractBase64Data(url))
+	return data
+}
+
+// imageSize reads the dimensions of a PNG, JPEG or GIF; 0, 0 if unknown.
+func imageSize(data []byte) (int, int) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0
+	}
+	return cfg.Width, cfg.Height
+}
+
+var pdfPageObject = regexp.MustCompile(`/Type\s*/Page\b`)
+
+// pdfPages counts page objects in a PDF, at least 1.
+func pdfPages(data []byte) int {
+	return max(len(pdfPageObject.FindAllIndex(data, -1)), 1)
+}
+
+// audioDuration reads the length of WAV or MP3 audio, falling back to the
+// size at 128 kbit/s.
+func audioDuration(data []byte) time.Duration {
+	if bytes.HasPrefix(data, []byte("RIFF")) && len(data) >= 44 {
+		if rate := binary.LittleEndian.Uint32(data[28:32]); rate > 0 {
+			return time.Duration(len(data)-44) * time.Second / time.Duration(rate)
+		}
+	}
+	if frames, err := mp3Frames(data); err == nil && len(frames) > 1 {
+		last := frames[len(frames)-1]
+		return last.start + last.start/time.Duration(len(frames)-1)
+	}
+	return time.Duration(len(data)) * time.Second / 16000
+}
+
+// EstimateTokens approximates the token count of text for BPE tokenizers such
+// as OpenAI's, without a vocabulary: a word counts one token per six letters,
+// digits one per three, other symbols one each, and letters outside the Latin,
+// Greek and Cyrillic scripts one per character. Expect it to be within about
+// 15% for English prose; use CountTokens for exact counts where the provider
+// offers them.
+func EstimateTokens(text string) int {
+	n, letters, digits := 0, 0, 0
+	flush := func() {
+		n += (letters+5)/6 + (digits+2)/3
+		letters, digits = 0, 0
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsDigit(r):
+			if letters > 0 {
+				flush()
+			}
+			digits++
+		case unicode.IsLetter(r) && (r < utf8.RuneSelf || unicode.In(r, unicode.Latin, unicode.Greek, unicode.Cyrillic)):
+			if digits > 0 {
+				flush()
+			}
+			letters++
+			if r >= utf8.RuneSelf {
+				letters++ // multi-byte letters split into more tokens
+			}
+		case unicode.IsSpace(r):
+			// Spaces merge into the following word's token
+			flush()
+		default:
+			flush()
+			n++
+		}
+	}
+	flush()
+	return n
+}
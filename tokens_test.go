@@ -0,0 +1,148 @@
+package llmkit
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"Hello, world!", 4},
+		{"The quick brown fox jumps over the lazy dog.", 10},
+		{"internationalization", 4},
+		{"123456789", 3},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	var path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		path, body = r.URL.Path, string(data)
+		if strings.Contains(path, "countTokens") {
+			w.Write([]byte(`{"totalTokens": 17}`))
+			return
+		}
+		w.Write([]byte(`{"input_tokens": 42}`))
+	}))
+	defer server.Close()
+
+	req := Request{System: "Be brief.", User: "Hello"}
+
+	n, err := CountTokens(context.Background(), Provider{Name: Anthropic, APIKey: "k", BaseURL: server.URL}, req)
+	if err != nil || n != 42 {
+		t.Errorf("Anthropic CountTokens() = %d, %v; want 42", n, err)
+	}
+	if path != anthropicCountTokensPath || !strings.Contains(body, `"system":"Be brief."`) || strings.Contains(body, "max_tokens") {
+		t.Errorf("Anthropic request %s %s", path, body)
+	}
+
+	n, err = CountTokens(context.Background(), Provider{Name: Google, APIKey: "k", BaseURL: server.URL, Model: "gemini-test"}, req)
+	if err != nil || n != 17 {
+		t.Errorf("Google CountTokens() = %d, %v; want 17", n, err)
+	}
+	if path != "/v1beta/models/gemini-test:countTokens" || !strings.Contains(body, `"generateContentRequest":{"model":"models/gemini-test"`) {
+		t.Errorf("Google request %s %s", path, body)
+	}
+
+	path = ""
+	n, err = CountTokens(context.Background(), Provider{Name: OpenAI, APIKey: "k", BaseURL: server.URL}, req)
+	if err != nil || n != EstimateTokens("Be brief.")+EstimateTokens("Hello")+2*messageOverhead {
+		t.Errorf("OpenAI CountTokens() = %d, %v", n, err)
+	}
+	if path != "" {
+		t.Errorf("OpenAI estimate sent a request to %s", path)
+	}
+
+	// A plugged-in tokenizer replaces the text estimate
+	var models []string
+	words := WithTokenizer(func(model, text string) int {
+		models = append(models, model)
+		return len(strings.Fields(text))
+	})
+	n, err = CountTokens(context.Background(), Provider{Name: OpenAI, APIKey: "k", Model: "gpt-test"}, req, words)
+	if err != nil || n != 3+2*messageOverhead {
+		t.Errorf("OpenAI CountTokens(WithTokenizer) = %d, %v", n, err)
+	}
+	if len(models) != 2 || models[0] != "gpt-test" {
+		t.Errorf("tokenizer called for models %v", models)
+	}
+}
+
+func TestEstimateImageTokens(t *testing.T) {
+	tests := []struct {
+		provider      string
+		width, height int
+		detail        string
+		want          int
+	}{
+		{OpenAI, 1024, 1024, "", 765},
+		{OpenAI, 2048, 4096, "high", 1105},
+		{OpenAI, 4000, 3000, "low", 85},
+		{Grok, 0, 0, "", 765}, // unknown size counts as 1024x1024
+		{Anthropic, 1000, 1000, "", 1334},
+		{Anthropic, 3000, 3000, "", 1600},
+		{Google, 300, 300, "", 258},
+		{Google, 1000, 800, "", 1032},
+	}
+	for _, tt := range tests {
+		if got := EstimateImageTokens(tt.provider, tt.width, tt.height, tt.detail); got != tt.want {
+			t.Errorf("EstimateImageTokens(%s, %d, %d, %q) = %d, want %d", tt.provider, tt.width, tt.height, tt.detail, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateFileTokens(t *testing.T) {
+	var img bytes.Buffer
+	png.Encode(&img, image.NewGray(image.Rect(0, 0, 300, 200)))
+	pdf := []byte("%PDF-1.7 << /Type /Pages /Count 3 >> << /Type /Page >> << /Type/Page >> << /Type /Page /Parent 1 0 R >>")
+
+	tests := []struct {
+		name, provider string
+		file           File
+		want           int
+	}{
+		{"png", Google, File{MimeType: "image/png", Data: img.Bytes()}, 258},
+		{"png by name", Anthropic, File{Name: "chart.png", Data: img.Bytes()}, 80},
+		{"pdf", Google, File{MimeType: "application/pdf", Data: pdf}, 3 * 258},
+		{"pdf", Anthropic, File{Name: "report.pdf", Data: pdf}, 3 * 2250},
+		{"wav", Google, File{MimeType: "audio/wav", Data: wavFile(make([]byte, 32000), 8000)}, 64},
+		{"wav", OpenAI, File{MimeType: "audio/wav", Data: wavFile(make([]byte, 32000), 8000)}, 20},
+		{"mp3", Google, File{MimeType: "audio/mpeg", Data: mp3File(100)}, 84},
+		{"text", OpenAI, File{MimeType: "text/plain", Data: []byte("Hello, world!")}, 4},
+		{"uploaded", OpenAI, File{ID: "file-abc", MimeType: "application/pdf"}, 0},
+	}
+	for _, tt := range tests {
+		if got := EstimateFileTokens(tt.provider, tt.file); got != tt.want {
+			t.Errorf("%s %s: EstimateFileTokens() = %d, want %d", tt.provider, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCountTokens_Attachments(t *testing.T) {
+	req := Request{
+		User:   "Describe this.",
+		Images: []Image{{URL: "https://example.com/cat.jpg"}},
+		Files:  []File{{MimeType: "text/plain", Data: []byte("Hello, world!")}},
+	}
+	n, err := CountTokens(context.Background(), Provider{Name: OpenAI, APIKey: "k"}, req)
+	if want := 4 + messageOverhead + 765 + 4; err != nil || n != want {
+		t.Errorf("CountTokens() = %d, %v; want %d", n, err, want)
+	}
+}
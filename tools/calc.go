@@ -0,0 +1,426 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aktagon/llmkit"
+)
+
+// RateSource supplies currency exchange rates.
+type RateSource interface {
+	// Rate returns how many units of to one unit of from buys.
+	Rate(from, to string) (float64, error)
+}
+
+// StaticRates is a RateSource from fixed values of each currency in a common
+// base, e.g. {"USD": 1, "EUR": 1.08, "JPY": 0.0067}.
+type StaticRates map[string]float64
+
+func (r StaticRates) Rate(from, to string) (float64, error) {
+	f, ok := r[strings.ToUpper(from)]
+	if !ok || f <= 0 {
+		return 0, fmt.Errorf("no rate for %s", from)
+	}
+	t, ok := r[strings.ToUpper(to)]
+	if !ok || t <= 0 {
+		return 0, fmt.Errorf("no rate for %s", to)
+	}
+	return f / t, nil
+}
+
+// CalcTools returns a calculate tool for arithmetic and a convert_units tool for
+// physical units and, when rates is non-nil, currencies.
+func CalcTools(rates RateSource) []llmkit.Tool {
+	return []llmkit.Tool{
+		{
+			Name:        "calculate",
+			Description: "Evaluate an arithmetic expression exactly instead of computing it yourself. Supports + - * / % ^, parentheses, pi, e and sqrt, abs, round, floor, ceil, ln, log10, exp, min, max.",
+			Schema:      pathSchema(map[string]string{"expression": "Expression, e.g. 1250 * (1 + 0.04)^10"}),
+			Run: func(input map[string]any) (string, error) {
+				expr, err := stringArg(input, "expression")
+				if err != nil {
+					return "", err
+				}
+				v, err := Evaluate(expr)
+				if err != nil {
+					return "", err
+				}
+				return formatNumber(v), nil
+			},
+		},
+		{
+			Name:        "convert_units",
+			Description: "Convert a value between units (length, mass, volume, area, time, speed, data, temperature) or currencies (ISO codes such as USD, EUR).",
+			Schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"value": map[string]any{"type": "number"},
+					"from":  map[string]any{"type": "string", "description": "Unit or currency, e.g. km, lb, degF, EUR"},
+					"to":    map[string]any{"type": "string"},
+				},
+				"required": []string{"value", "from", "to"},
+			},
+			Run: func(input map[string]any) (string, error) {
+				value, ok := input["value"].(float64)
+				if !ok {
+					return "", fmt.Errorf("missing number argument %q", "value")
+				}
+				from, err := stringArg(input, "from")
+				if err != nil {
+					return "", err
+				}
+				to, err := stringArg(input, "to")
+				if err != nil {
+					return "", err
+				}
+				v, err := Convert(value, from, to, rates)
+				if err != nil {
+					return "", err
+				}
+				return formatNumber(v) + " " + to, nil
+			},
+		},
+	}
+}
+
+// formatNumber prints v with 15 significant digits, hiding float noise such as 0.30000000000000004.
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', 15, 64)
+}
+
+// unit is a unit of measure: value in base units = value*factor + offset.
+type unit struct {
+	dimension string
+	factor    float64
+	offset    float64
+}
+
+var units = map[string]unit{}
+
+func init() {
+	define := func(dimension string, factor float64, names ...string) {
+		for _, n := range names {
+			units[n] = unit{dimension: dimension, factor: factor}
+		}
+	}
+	define("length", 1, "m", "meter", "meters", "metre", "metres")
+	define("length", 1000, "km", "kilometer", "kilometers")
+	define("length", 0.01, "cm", "centimeter", "centimeters")
+	define("length", 0.001, "mm", "millimeter", "millimeters")
+	define("length", 1609.344, "mi", "mile", "miles")
+	define("length", 0.9144, "yd", "yard", "yards")
+	define("length", 0.3048, "ft", "foot", "feet")
+	define("length", 0.0254, "in", "inch", "inches")
+	define("length", 1852, "nmi", "nautical_mile")
+
+	define("mass", 1, "kg", "kilogram", "kilograms")
+	define("mass", 0.001, "g", "gram", "grams")
+	define("mass", 1e-6, "mg", "milligram", "milligrams")
+	define("mass", 1000, "t", "tonne", "tonnes")
+	define("mass", 0.45359237, "lb", "lbs", "pound", "pounds")
+	define("mass", 0.028349523125, "oz", "ounce", "ounces")
+
+	define("volume", 1, "l", "liter", "liters", "litre", "litres")
+	define("volume", 0.001, "ml", "milliliter", "milliliters")
+	define("volume", 1000, "m3")
+	define("volume", 3.785411784, "gal", "gallon", "gallons")
+	define("volume", 0.946352946, "qt", "quart", "quarts")
+	define("volume", 0.473176473, "pt", "pint", "pints")
+	define("volume", 0.2365882365, "cup", "cups")
+	define("volume", 0.0295735295625, "floz", "fl_oz")
+
+	define("area", 1, "m2", "sqm")
+	define("area", 1e6, "km2")
+	define("area", 0.09290304, "ft2", "sqft")
+	define("area", 4046.8564224, "acre", "acres")
+	define("area", 10000, "ha", "hectare", "hectares")
+
+	define("time", 1, "s", "sec", "second", "seconds")
+	define("time", 0.001, "ms", "millisecond", "milliseconds")
+	define("time", 60, "min", "minute", "minutes")
+	define("time", 3600, "h", "hr", "hour", "hours")
+	define("time", 86400, "d", "day", "days")
+	define("time", 604800, "week", "weeks")
+	define("time", 31557600, "year", "years") // Julian year, 365.25 days
+
+	define("speed", 1, "m/s")
+	define("speed", 1/3.6, "km/h", "kph")
+	define("speed", 0.44704, "mph")
+	define("speed", 1852/3600.0, "kn", "knot", "knots")
+
+	define("data", 1, "byte", "bytes")
+	define("data", 1e3, "kb")
+	define("data", 1e6, "mb")
+	define("data", 1e9, "gb")
+	define("data", 1e12, "tb")
+	define("data", 1<<10, "kib")
+	define("data", 1<<20, "mib")
+	define("data", 1<<30, "gib")
+
+	units["c"] = unit{dimension: "temperature", factor: 1, offset: 273.15}
+	units["degc"] = units["c"]
+	units["celsius"] = units["c"]
+	units["f"] = unit{dimension: "temperature", factor: 5.0 / 9, offset: 273.15 - 32*5.0/9}
+	units["degf"] = units["f"]
+	units["fahrenheit"] = units["f"]
+	units["k"] = unit{dimension: "temperature", factor: 1}
+	units["kelvin"] = units["k"]
+}
+
+// Convert converts value from one unit to another. Names not in the unit table
+// are treated as currency codes and converted with rates.
+func Convert(value float64, from, to string, rates RateSource) (float64, error) {
+	f, fok := units[strings.ToLower(from)]
+	t, tok := units[strings.ToLower(to)]
+	switch {
+	case fok && tok:
+		if f.dimension != t.dimension {
+			return 0, fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, f.dimension, to, t.dimension)
+		}
+		return (value*f.factor + f.offset - t.offset) / t.factor, nil
+	case fok || tok:
+		return 0, fmt.Errorf("cannot convert %s to %s", from, to)
+	case rates == nil:
+		return 0, fmt.Errorf("unknown units %s and %s", from, to)
+	}
+	rate, err := rates.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return value * rate, nil
+}
+
+// Evaluate computes an arithmetic expression. See the calculate tool for the syntax.
+func Evaluate(expr string) (float64, error) {
+	p := &exprParser{s: expr}
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return 0, fmt.Errorf("unexpected %q at position %d", p.s[p.pos], p.pos)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return v, nil
+}
+
+// exprParser is a recursive-descent parser that evaluates as it goes.
+type exprParser struct {
+	s   string
+	pos int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// accept consumes c if it is the next non-space character.
+func (p *exprParser) accept(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expr = term { ("+" | "-") term }
+func (p *exprParser) expr() (float64, error) {
+	v, err := p.term()
+	for err == nil {
+		switch {
+		case p.accept('+'):
+			var r float64
+			r, err = p.term()
+			v += r
+		case p.accept('-'):
+			var r float64
+			r, err = p.term()
+			v -= r
+		default:
+			return v, nil
+		}
+	}
+	return 0, err
+}
+
+// term = unary { ("*" | "/" | "%") unary }
+func (p *exprParser) term() (float64, error) {
+	v, err := p.unary()
+	for err == nil {
+		var op byte
+		switch {
+		case p.accept('*'):
+			op = '*'
+		case p.accept('/'):
+			op = '/'
+		case p.accept('%'):
+			op = '%'
+		default:
+			return v, nil
+		}
+		var r float64
+		if r, err = p.unary(); err != nil {
+			break
+		}
+		switch {
+		case op == '*':
+			v *= r
+		case r == 0:
+			err = fmt.Errorf("division by zero")
+		case op == '/':
+			v /= r
+		default:
+			v = math.Mod(v, r)
+		}
+	}
+	return 0, err
+}
+
+// unary = ("-" | "+") unary | power
+func (p *exprParser) unary() (float64, error) {
+	if p.accept('-') {
+		v, err := p.unary()
+		return -v, err
+	}
+	if p.accept('+') {
+		return p.unary()
+	}
+	return p.power()
+}
+
+// power = primary [ "^" unary ]; right-associative, and -2^2 is -4
+func (p *exprParser) power() (float64, error) {
+	v, err := p.primary()
+	if err != nil || !p.accept('^') {
+		return v, err
+	}
+	exp, err := p.unary()
+	return math.Pow(v, exp), err
+}
+
+var constants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+var functions = map[string]func(args []float64) (float64, error){
+	"sqrt":  oneArg(math.Sqrt),
+	"abs":   oneArg(math.Abs),
+	"round": oneArg(math.Round),
+	"floor": oneArg(math.Floor),
+	"ceil":  oneArg(math.Ceil),
+	"ln":    oneArg(math.Log),
+	"log10": oneArg(math.Log10),
+	"exp":   oneArg(math.Exp),
+	"min":   manyArgs(math.Min),
+	"max":   manyArgs(math.Max),
+}
+
+func oneArg(fn func(float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("takes 1 argument, got %d", len(args))
+		}
+		return fn(args[0]), nil
+	}
+}
+
+func manyArgs(fn func(a, b float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, fmt.Errorf("takes at least 1 argument")
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			v = fn(v, a)
+		}
+		return v, nil
+	}
+}
+
+// primary = number | name [ "(" args ")" ] | "(" expr ")"
+func (p *exprParser) primary() (float64, error) {
+	p.skipSpace()
+	if p.accept('(') {
+		v, err := p.expr()
+		if err == nil && !p.accept(')') {
+			err = fmt.Errorf("missing ) at position %d", p.pos)
+		}
+		return v, err
+	}
+	start := p.pos
+	if p.pos < len(p.s) && unicode.IsLetter(rune(p.s[p.pos])) {
+		for p.pos < len(p.s) && (unicode.IsLetter(rune(p.s[p.pos])) || isDigit(p.s[p.pos])) {
+			p.pos++
+		}
+		return p.call(strings.ToLower(p.s[start:p.pos]))
+	}
+	for p.pos < len(p.s) && (isDigit(p.s[p.pos]) || p.s[p.pos] == '.' || p.s[p.pos] == '_') {
+		p.pos++
+	}
+	// Exponent, as in 1.5e-3
+	if p.pos > start && p.pos < len(p.s) && (p.s[p.pos] == 'e' || p.s[p.pos] == 'E') {
+		j := p.pos + 1
+		if j < len(p.s) && (p.s[j] == '+' || p.s[j] == '-') {
+			j++
+		}
+		for j < len(p.s) && isDigit(p.s[j]) {
+			j++
+			p.pos = j
+		}
+	}
+	if start == p.pos {
+		if p.pos >= len(p.s) {
+			return 0, fmt.Errorf("unexpected end of expression")
+		}
+		return 0, fmt.Errorf("unexpected %q at position %d", p.s[p.pos], p.pos)
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(p.s[start:p.pos], "_", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad number %q", p.s[start:p.pos])
+	}
+	return v, nil
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// call evaluates a constant or a function call.
+func (p *exprParser) call(name string) (float64, error) {
+	if v, ok := constants[name]; ok {
+		return v, nil
+	}
+	fn, ok := functions[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown name %q", name)
+	}
+	if !p.accept('(') {
+		return 0, fmt.Errorf("%s: missing (", name)
+	}
+	var args []float64
+	if !p.accept(')') {
+		for {
+			v, err := p.expr()
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, v)
+			if p.accept(')') {
+				break
+			}
+			if !p.accept(',') {
+				return 0, fmt.Errorf("%s: expected , or ) at position %d", name, p.pos)
+			}
+		}
+	}
+	v, err := fn(args)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return v, nil
+}
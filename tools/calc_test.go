@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"2^3^2", 512},
+		{"-2^2", -4},
+		{"10 % 4", 2},
+		{"1_000 * 1.5e-3", 1.5},
+		{"sqrt(16) + max(1, 5, 3)", 9},
+		{"round(2 * pi)", 6},
+		{"1250 * (1 + 0.04)^10", 1250 * math.Pow(1.04, 10)},
+	}
+	for _, tt := range tests {
+		got, err := Evaluate(tt.expr)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Evaluate(%q) = %v, %v; want %v", tt.expr, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "1 +", "1 / 0", "(1 + 2", "foo(1)", "sqrt(1, 2)", "sqrt(-1)", "1 2", "2e"} {
+		if _, err := Evaluate(bad); err == nil {
+			t.Errorf("Evaluate(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	rates := StaticRates{"USD": 1, "EUR": 1.25}
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{5, "km", "m", 5000},
+		{1, "mile", "ft", 5280},
+		{100, "degC", "degF", 212},
+		{0, "K", "C", -273.15},
+		{1, "GiB", "MB", 1073.741824},
+		{10, "EUR", "usd", 12.5},
+	}
+	for _, tt := range tests {
+		got, err := Convert(tt.value, tt.from, tt.to, rates)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Convert(%v, %s, %s) = %v, %v; want %v", tt.value, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range [][2]string{{"kg", "m"}, {"kg", "USD"}, {"USD", "GBP"}} {
+		if _, err := Convert(1, bad[0], bad[1], rates); err == nil {
+			t.Errorf("Convert(%s, %s) succeeded", bad[0], bad[1])
+		}
+	}
+	if _, err := Convert(1, "USD", "EUR", nil); err == nil {
+		t.Error("currency conversion without rates succeeded")
+	}
+}
+
+func TestCalcTools(t *testing.T) {
+	tools := CalcTools(StaticRates{"USD": 1, "EUR": 1.1})
+	if out, err := findTool(t, tools, "calculate").Run(map[string]any{"expression": "0.1 + 0.2"}); err != nil || out != "0.3" {
+		t.Errorf("calculate = %q, %v", out, err)
+	}
+	if out, err := findTool(t, tools, "convert_units").Run(map[string]any{"value": 2.0, "from": "lb", "to": "kg"}); err != nil || out != "0.90718474 kg" {
+		t.Errorf("convert_units = %q, %v", out, err)
+	}
+	if _, err := findTool(t, tools, "convert_units").Run(map[string]any{"value": "2", "from": "lb", "to": "kg"}); err == nil {
+		t.Error("string value was accepted")
+	}
+}
@@ -0,0 +1,229 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aktagon/llmkit"
+)
+
+const (
+	defaultExecTimeout   = 30 * time.Second
+	defaultExecMaxOutput = 64 << 10
+)
+
+// ExecPolicy declares what the run_command tool may execute. Commands run
+// directly, never through a shell, with only the listed environment variables.
+type ExecPolicy struct {
+	Commands  []AllowedCommand // binaries the model may run
+	Dir       string           // working directory (default: current directory)
+	Timeout   time.Duration    // per command (default 30s)
+	MaxOutput int              // bytes of combined stdout/stderr returned (default 64 KiB)
+	Env       []string         // host variables passed through; default PATH and HOME
+
+	// DryRun reports what would run instead of running it.
+	DryRun bool
+	// Approve, when set, is asked before each command; returning false rejects it.
+	Approve func(name string, args []string) bool
+}
+
+// AllowedCommand permits one binary, optionally restricting its arguments.
+type AllowedCommand struct {
+	Name string   `json:"name"`           // binary name looked up in PATH, e.g. "go"
+	Args []string `json:"args,omitempty"` // regexps; every argument must fully match one (empty allows any)
+}
+
+// LoadExecPolicy reads a policy from a JSON file such as:
+//
+//	{"commands": [{"name": "go", "args": ["test|vet|build", "\\./\\.\\.\\."]}],
+//	 "timeout": "2m", "max_output": 32768, "env": ["PATH", "HOME", "GOPATH"]}
+func LoadExecPolicy(path string) (ExecPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ExecPolicy{}, err
+	}
+	var cfg struct {
+		Commands  []AllowedCommand `json:"commands"`
+		Dir       string           `json:"dir"`
+		Timeout   string           `json:"timeout"`
+		MaxOutput int              `json:"max_output"`
+		Env       []string         `json:"env"`
+		DryRun    bool             `json:"dry_run"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return ExecPolicy{}, err
+	}
+	p := ExecPolicy{Commands: cfg.Commands, Dir: cfg.Dir, MaxOutput: cfg.MaxOutput, Env: cfg.Env, DryRun: cfg.DryRun}
+	if cfg.Timeout != "" {
+		if p.Timeout, err = time.ParseDuration(cfg.Timeout); err != nil {
+			return ExecPolicy{}, &llmkit.ValidationError{Field: "timeout", Message: err.Error()}
+		}
+	}
+	return p, nil
+}
+
+// check returns an error unless the policy allows name with args.
+func (p ExecPolicy) check(name string, args []string) error {
+	if strings.ContainsAny(name, `/\`) {
+		return &llmkit.ValidationError{Field: "command", Message: "must be a bare binary name"}
+	}
+	for _, c := range p.Commands {
+		if c.Name != name {
+			continue
+		}
+		if len(c.Args) == 0 {
+			return nil
+		}
+		for _, arg := range args {
+			if !matchesAny(c.Args, arg) {
+				return &llmkit.ValidationError{Field: "args", Message: fmt.Sprintf("argument %q not allowed for %s", arg, name)}
+			}
+		}
+		return nil
+	}
+	return &llmkit.ValidationError{Field: "command", Message: name + " is not allowed"}
+}
+
+func matchesAny(patterns []string, s string) bool {
+	for _, pat := range patterns {
+		if re, err := regexp.Compile("^(?:" + pat + ")$"); err == nil && re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// environ returns the scrubbed environment for commands.
+func (p ExecPolicy) environ() []string {
+	names := p.Env
+	if len(names) == 0 {
+		names = []string{"PATH", "HOME"}
+	}
+	var env []string
+	for _, name := range names {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
+
+// ExecTool returns a run_command tool that executes commands allowed by p.
+// Non-zero exit codes are reported in the result so the model can react.
+func ExecTool(p ExecPolicy) llmkit.Tool {
+	var names []string
+	for _, c := range p.Commands {
+		names = append(names, c.Name)
+	}
+	return llmkit.Tool{
+		Name:        "run_command",
+		Description: "Run a command without a shell. Allowed commands: " + strings.Join(names, ", "),
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"command": map[string]any{"type": "string", "description": "Binary name"},
+				"args":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+			"required": []string{"command"},
+		},
+		Run: func(input map[string]any) (string, error) {
+			name, err := stringArg(input, "command")
+			if err != nil {
+				return "", err
+			}
+			var args []string
+			raw, ok := input["args"].([]any)
+			if !ok && input["args"] != nil {
+				return "", errors.New("args must be an array of strings")
+			}
+			for _, a := range raw {
+				s, ok := a.(string)
+				if !ok {
+					return "", errors.New("args must be strings")
+				}
+				args = append(args, s)
+			}
+			return p.run(name, args)
+		},
+	}
+}
+
+// run executes name with args after the policy, dry-run and approval checks.
+func (p ExecPolicy) run(name string, args []string) (string, error) {
+	if err := p.check(name, args); err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(name + " " + strings.Join(args, " "))
+	if p.DryRun {
+		return "dry run, not executed: " + line, nil
+	}
+	if p.Approve != nil && !p.Approve(name, args) {
+		return "", errors.New("command rejected by the user: " + line)
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// LookPath uses the host PATH; the child only gets the scrubbed environment
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = p.Dir
+	cmd.Env = p.environ()
+	cmd.WaitDelay = time.Second // don't hang on children that keep the output pipes open
+	out := &cappedBuffer{limit: p.MaxOutput}
+	if out.limit <= 0 {
+		out.limit = defaultExecMaxOutput
+	}
+	cmd.Stdout, cmd.Stderr = out, out
+
+	err = cmd.Run()
+	result := out.String()
+	if out.truncated {
+		result += "\n[output truncated]"
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return "", fmt.Errorf("%s timed out after %s", line, timeout)
+	case errors.As(err, &exitErr):
+		return fmt.Sprintf("exit code %d\n%s", exitErr.ExitCode(), result), nil
+	case err != nil:
+		return "", err
+	}
+	return result, nil
+}
+
+// cappedBuffer keeps the first limit bytes written and discards the rest.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		b.buf.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecPolicy_Run(t *testing.T) {
+	t.Setenv("LLMKIT_TEST_SECRET", "hunter2")
+	p := ExecPolicy{
+		Commands: []AllowedCommand{
+			{Name: "echo", Args: []string{"hello|world", "x+"}},
+			{Name: "env"},
+			{Name: "sh", Args: []string{"-c", "exit 3", "sleep 5"}},
+		},
+		Env:       []string{"PATH"},
+		MaxOutput: 16,
+		Timeout:   200 * time.Millisecond,
+	}
+
+	if out, err := p.run("echo", []string{"hello", "world"}); err != nil || out != "hello world\n" {
+		t.Errorf("echo = %q, %v", out, err)
+	}
+	if _, err := p.run("echo", []string{"rm -rf"}); err == nil {
+		t.Error("argument outside the patterns was allowed")
+	}
+	if _, err := p.run("rm", []string{"-rf", "/"}); err == nil {
+		t.Error("unlisted command was allowed")
+	}
+	if _, err := p.run("/bin/echo", nil); err == nil {
+		t.Error("path to a binary was allowed")
+	}
+
+	if out, _ := p.run("env", nil); strings.Contains(out, "hunter2") {
+		t.Errorf("environment was not scrubbed: %q", out)
+	}
+	if out, _ := p.run("echo", []string{strings.Repeat("x", 40)}); !strings.HasSuffix(out, "[output truncated]") {
+		t.Errorf("output = %q, want truncated", out)
+	}
+	if out, err := p.run("sh", []string{"-c", "exit 3"}); err != nil || !strings.HasPrefix(out, "exit code 3") {
+		t.Errorf("exit status = %q, %v", out, err)
+	}
+	if _, err := p.run("sh", []string{"-c", "sleep 5"}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("timeout error = %v", err)
+	}
+}
+
+func TestExecPolicy_DryRunAndApproval(t *testing.T) {
+	p := ExecPolicy{Commands: []AllowedCommand{{Name: "echo"}}, DryRun: true}
+	if out, err := p.run("echo", []string{"hi"}); err != nil || out != "dry run, not executed: echo hi" {
+		t.Errorf("dry run = %q, %v", out, err)
+	}
+
+	var asked []string
+	p = ExecPolicy{Commands: []AllowedCommand{{Name: "echo"}}, Approve: func(name string, args []string) bool {
+		asked = append(asked, name)
+		return false
+	}}
+	tool := ExecTool(p)
+	if _, err := tool.Run(map[string]any{"command": "echo", "args": []any{"hi"}}); err == nil {
+		t.Error("rejected command ran")
+	}
+	if len(asked) != 1 {
+		t.Errorf("approval asked %d times, want 1", len(asked))
+	}
+}
+
+func TestExecTool_BadArgs(t *testing.T) {
+	ran := false
+	tool := ExecTool(ExecPolicy{Commands: []AllowedCommand{{Name: "echo"}}, Approve: func(string, []string) bool {
+		ran = true
+		return true
+	}})
+	for _, args := range []any{"hello world", map[string]any{"0": "hi"}, 3.0, []any{"hi", 1.0}} {
+		if _, err := tool.Run(map[string]any{"command": "echo", "args": args}); err == nil {
+			t.Errorf("args %#v were accepted", args)
+		}
+	}
+	if ran {
+		t.Error("a command with bad args reached approval")
+	}
+	if out, err := tool.Run(map[string]any{"command": "echo"}); err != nil || out != "\n" {
+		t.Errorf("echo without args = %q, %v", out, err)
+	}
+}
+
+func TestLoadExecPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	os.WriteFile(path, []byte(`{"commands":[{"name":"go","args":["test","./..."]}],"timeout":"2m","env":["PATH"]}`), 0o644)
+
+	p, err := LoadExecPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadExecPolicy() error = %v", err)
+	}
+	if p.Timeout != 2*time.Minute || len(p.Commands) != 1 || p.Commands[0].Name != "go" {
+		t.Errorf("policy = %+v", p)
+	}
+	if err := p.check("go", []string{"test", "./..."}); err != nil {
+		t.Errorf("check() error = %v", err)
+	}
+}
@@ -0,0 +1,411 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aktagon/llmkit"
+)
+
+const (
+	defaultFetchTimeout  = 20 * time.Second
+	defaultFetchMaxBytes = 2 << 20
+	defaultFetchMaxChars = 20000
+	defaultFetchCacheTTL = 15 * time.Minute
+	defaultUserAgent     = "llmkit-fetch/1.0"
+)
+
+// FetchPolicy configures the http_fetch tool.
+type FetchPolicy struct {
+	Client       *http.Client  // default http.DefaultClient
+	Allow        []string      // hosts (and their subdomains) that may be fetched; empty allows any
+	Deny         []string      // hosts that may never be fetched; checked first
+	Timeout      time.Duration // per request (default 20s)
+	MaxBytes     int64         // response body bytes read (default 2 MiB)
+	MaxChars     int           // characters of text returned (default 20,000)
+	CacheTTL     time.Duration // how long results are reused (default 15m; negative disables)
+	UserAgent    string        // sent with requests and matched against robots.txt (default "llmkit-fetch/1.0")
+	IgnoreRobots bool          // skip robots.txt checks
+	AllowPrivate bool          // permit loopback, private and link-local addresses, denied by default
+}
+
+// FetchTool returns an http_fetch tool that downloads a web page and returns it
+// as readable Markdown-like text, with scripts, styles and page chrome removed.
+// Results and robots.txt files are cached per tool.
+func FetchTool(p FetchPolicy) llmkit.Tool {
+	f := &fetcher{policy: p, pages: map[string]cachedPage{}, robots: map[string]robotsRules{}}
+	return llmkit.Tool{
+		Name:        "http_fetch",
+		Description: "Fetch a web page over HTTP(S) and return its readable text.",
+		Schema:      pathSchema(map[string]string{"url": "Absolute http or https URL"}),
+		Run: func(input map[string]any) (string, error) {
+			raw, err := stringArg(input, "url")
+			if err != nil {
+				return "", err
+			}
+			timeout := p.Timeout
+			if timeout <= 0 {
+				timeout = defaultFetchTimeout
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			return f.fetch(ctx, raw)
+		},
+	}
+}
+
+type cachedPage struct {
+	text    string
+	fetched time.Time
+}
+
+// fetcher holds the caches of one http_fetch tool.
+type fetcher struct {
+	policy FetchPolicy
+	mu     sync.Mutex
+	pages  map[string]cachedPage
+	robots map[string]robotsRules
+
+	clientOnce sync.Once
+	httpClient *http.Client
+}
+
+// client returns the policy's client with redirects checked like the first
+// URL, so an allowed host cannot redirect to a denied host or a path robots.txt
+// disallows. Unless AllowPrivate is set, connections to private addresses are
+// refused when they are dialed, which also covers redirects and DNS answers
+// that change between lookups; this requires the client's Transport to be nil
+// or an *http.Transport.
+func (f *fetcher) client() *http.Client {
+	f.clientOnce.Do(func() { f.httpClient = f.newClient() })
+	return f.httpClient
+}
+
+func (f *fetcher) newClient() *http.Client {
+	c := *http.DefaultClient
+	if f.policy.Client != nil {
+		c = *f.policy.Client
+	}
+	if !f.policy.AllowPrivate {
+		base, ok := c.Transport.(*http.Transport)
+		if c.Transport == nil {
+			base, ok = http.DefaultTransport.(*http.Transport)
+		}
+		if ok {
+			t := base.Clone()
+			dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: denyPrivate}
+			t.DialContext = dialer.DialContext
+			c.Transport = t
+		}
+	}
+	next := c.CheckRedirect
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+			return &llmkit.ValidationError{Field: "url", Message: "redirect to a non-http URL"}
+		}
+		if err := f.checkHost(req.URL.Hostname()); err != nil {
+			return err
+		}
+		if !f.policy.IgnoreRobots && req.URL.Path != "/robots.txt" {
+			rules, err := f.robotsFor(req.Context(), req.URL)
+			if err != nil {
+				return err
+			}
+			if !rules.allowed(req.URL.EscapedPath()) {
+				return fmt.Errorf("robots.txt disallows %s", req.URL)
+			}
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &c
+}
+
+// denyPrivate is a net.Dialer Control func that refuses loopback, private,
+// link-local and other non-public addresses.
+func denyPrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return &llmkit.ValidationError{Field: "url", Message: ip.String() + " is a private address"}
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598).
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func (f *fetcher) userAgent() string {
+	if f.policy.UserAgent != "" {
+		return f.policy.UserAgent
+	}
+	return defaultUserAgent
+}
+
+func (f *fetcher) fetch(ctx context.Context, raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", &llmkit.ValidationError{Field: "url", Message: "must be an absolute http or https URL"}
+	}
+	if err := f.checkHost(u.Hostname()); err != nil {
+		return "", err
+	}
+
+	ttl := f.policy.CacheTTL
+	if ttl == 0 {
+		ttl = defaultFetchCacheTTL
+	}
+	f.mu.Lock()
+	page, ok := f.pages[u.String()]
+	f.mu.Unlock()
+	if ok && ttl > 0 && time.Since(page.fetched) < ttl {
+		return page.text, nil
+	}
+
+	if !f.policy.IgnoreRobots {
+		rules, err := f.robotsFor(ctx, u)
+		if err != nil {
+			return "", err
+		}
+		if !rules.allowed(u.EscapedPath()) {
+			return "", fmt.Errorf("robots.txt disallows %s", u)
+		}
+	}
+
+	body, contentType, err := f.get(ctx, u.String())
+	if err != nil {
+		return "", err
+	}
+	text := body
+	if strings.Contains(contentType, "html") {
+		text = htmlToText(body)
+	}
+	maxChars := f.policy.MaxChars
+	if maxChars <= 0 {
+		maxChars = defaultFetchMaxChars
+	}
+	if r := []rune(text); len(r) > maxChars {
+		text = string(r[:maxChars]) + "\n[truncated]"
+	}
+
+	if ttl > 0 {
+		f.mu.Lock()
+		f.pages[u.String()] = cachedPage{text: text, fetched: time.Now()}
+		f.mu.Unlock()
+	}
+	return text, nil
+}
+
+// checkHost applies the deny list, then the allow list.
+func (f *fetcher) checkHost(host string) error {
+	if matchHost(f.policy.Deny, host) {
+		return &llmkit.ValidationError{Field: "url", Message: host + " is denied"}
+	}
+	if len(f.policy.Allow) > 0 && !matchHost(f.policy.Allow, host) {
+		return &llmkit.ValidationError{Field: "url", Message: host + " is not in the allow list"}
+	}
+	return nil
+}
+
+func matchHost(hosts []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// get downloads url, reading at most MaxBytes of the body.
+func (f *fetcher) get(ctx context.Context, url string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("User-Agent", f.userAgent())
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", "", fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	limit := f.policy.MaxBytes
+	if limit <= 0 {
+		limit = defaultFetchMaxBytes
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return "", "", err
+	}
+	return string(data), resp.Header.Get("Content-Type"), nil
+}
+
+// robotsRules are the Allow/Disallow path prefixes that apply to this tool.
+type robotsRules struct {
+	allow, disallow []string
+}
+
+// allowed applies the longest matching rule; Allow wins ties.
+func (r robotsRules) allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	best, ok := -1, true
+	for _, p := range r.allow {
+		if strings.HasPrefix(path, p) && len(p) >= best {
+			best, ok = len(p), true
+		}
+	}
+	for _, p := range r.disallow {
+		if strings.HasPrefix(path, p) && len(p) > best {
+			best, ok = len(p), false
+		}
+	}
+	return ok
+}
+
+// robotsFor returns the cached robots.txt rules for u's host, fetching them once.
+// A missing or unreadable robots.txt allows everything.
+func (f *fetcher) robotsFor(ctx context.Context, u *url.URL) (robotsRules, error) {
+	origin := u.Scheme + "://" + u.Host
+	f.mu.Lock()
+	rules, ok := f.robots[origin]
+	f.mu.Unlock()
+	if ok {
+		return rules, nil
+	}
+
+	body, _, err := f.get(ctx, origin+"/robots.txt")
+	if err == nil {
+		rules = parseRobots(body, f.userAgent())
+	} else if ctx.Err() != nil {
+		return robotsRules{}, ctx.Err()
+	}
+	f.mu.Lock()
+	f.robots[origin] = rules
+	f.mu.Unlock()
+	return rules, nil
+}
+
+// parseRobots extracts the rules for agent, falling back to the "*" group.
+func parseRobots(body, agent string) robotsRules {
+	product := strings.ToLower(strings.SplitN(agent, "/", 2)[0])
+	groups := map[string]*robotsRules{}
+	var current []string
+	inAgents := false
+
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = nil
+			}
+			inAgents = true
+			name := strings.ToLower(value)
+			current = append(current, name)
+			if groups[name] == nil {
+				groups[name] = &robotsRules{}
+			}
+		case "allow", "disallow":
+			inAgents = false
+			if value == "" {
+				continue
+			}
+			for _, name := range current {
+				if key == "allow" {
+					groups[name].allow = append(groups[name].allow, value)
+				} else {
+					groups[name].disallow = append(groups[name].disallow, value)
+				}
+			}
+		}
+	}
+	if g, ok := groups[product]; ok {
+		return *g
+	}
+	if g, ok := groups["*"]; ok {
+		return *g
+	}
+	return robotsRules{}
+}
+
+var (
+	reDropBlocks = regexp.MustCompile(`(?is)<(head|script|style|noscript|svg|nav|header|footer|aside|form|iframe)\b.*?</(?:head|script|style|noscript|svg|nav|header|footer|aside|form|iframe)>`)
+	reComments   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	reHeading    = regexp.MustCompile(`(?is)<h([1-6])\b[^>]*>(.*?)</h[1-6]>`)
+	reLink       = regexp.MustCompile(`(?is)<a\b[^>]*href\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a>`)
+	reListItem   = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	reBreak      = regexp.MustCompile(`(?i)<br\s*/?>|</?(p|div|section|article|main|tr|ul|ol|table|blockquote|pre)\b[^>]*>`)
+	reTag        = regexp.MustCompile(`(?s)<[^>]+>`)
+	reSpaces     = regexp.MustCompile(`[ \t\r\f\v]+`)
+	reBlankLines = regexp.MustCompile(`\n\s*\n\s*\n+`)
+)
+
+// htmlToText reduces an HTML page to readable Markdown-like text.
+func htmlToText(s string) string {
+	s = reComments.ReplaceAllString(s, "")
+	s = reDropBlocks.ReplaceAllString(s, "")
+	s = reHeading.ReplaceAllStringFunc(s, func(m string) string {
+		sub := reHeading.FindStringSubmatch(m)
+		level := int(sub[1][0] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + strings.TrimSpace(reTag.ReplaceAllString(sub[2], "")) + "\n\n"
+	})
+	s = reLink.ReplaceAllStringFunc(s, func(m string) string {
+		sub := reLink.FindStringSubmatch(m)
+		text := strings.TrimSpace(reTag.ReplaceAllString(sub[2], ""))
+		if text == "" {
+			return ""
+		}
+		return "[" + text + "](" + sub[1] + ")"
+	})
+	s = reListItem.ReplaceAllString(s, "\n- ")
+	s = reBreak.ReplaceAllString(s, "\n")
+	s = reTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = reSpaces.ReplaceAllString(s, " ")
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	s = strings.Join(lines, "\n")
+	s = reBlankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testPage = `<html><head><title>T</title><style>body{}</style><script>var x = 1;</script></head>
+<body><nav><a href="/">Home</a></nav>
+<h1>Go &amp; You</h1>
+<p>Read the <a href="https://go.dev/doc">docs</a>.</p>
+<ul><li>one</li><li>two</li></ul>
+<footer>Copyright</footer></body></html>`
+
+func TestFetchTool(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private\nAllow: /private/ok\n"))
+		case "/big":
+			w.Write([]byte(strings.Repeat("a", 200)))
+		default:
+			hits.Add(1)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(testPage))
+		}
+	}))
+	defer srv.Close()
+
+	tool := FetchTool(FetchPolicy{MaxChars: 80, AllowPrivate: true})
+	out, err := tool.Run(map[string]any{"url": srv.URL + "/page"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Go & You\n\nRead the [docs](https://go.dev/doc).\n\n- one\n- two"
+	if out != want {
+		t.Errorf("text = %q, want %q", out, want)
+	}
+	if _, err := tool.Run(map[string]any{"url": srv.URL + "/page"}); err != nil || hits.Load() != 1 {
+		t.Errorf("second fetch was not cached: hits = %d, err = %v", hits.Load(), err)
+	}
+
+	if _, err := tool.Run(map[string]any{"url": srv.URL + "/private/x"}); err == nil {
+		t.Error("robots.txt disallowed path was fetched")
+	}
+	if _, err := tool.Run(map[string]any{"url": srv.URL + "/private/ok"}); err != nil {
+		t.Errorf("robots.txt allowed path: %v", err)
+	}
+	if out, _ := tool.Run(map[string]any{"url": srv.URL + "/big"}); !strings.HasSuffix(out, "[truncated]") {
+		t.Errorf("long text was not truncated: %q", out)
+	}
+	if _, err := tool.Run(map[string]any{"url": "file:///etc/passwd"}); err == nil {
+		t.Error("non-http URL was fetched")
+	}
+}
+
+func TestFetchTool_RedirectToDeniedHost(t *testing.T) {
+	var internalHits atomic.Int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalHits.Add(1)
+		w.Write([]byte("secret"))
+	}))
+	defer internal.Close()
+	// The internal server is reached by name, the public one by IP
+	target := strings.Replace(internal.URL, "127.0.0.1", "localhost", 1)
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target+"/latest/meta-data", http.StatusFound)
+	}))
+	defer public.Close()
+
+	for _, client := range []*http.Client{nil, {}} {
+		tool := FetchTool(FetchPolicy{Client: client, Deny: []string{"localhost"}, IgnoreRobots: true, AllowPrivate: true})
+		if out, err := tool.Run(map[string]any{"url": public.URL}); err == nil || !strings.Contains(err.Error(), "denied") {
+			t.Errorf("redirect to denied host = %q, %v", out, err)
+		}
+	}
+	if internalHits.Load() != 0 {
+		t.Errorf("denied host was requested %d times", internalHits.Load())
+	}
+}
+
+func TestFetchTool_RedirectToDisallowedPath(t *testing.T) {
+	var privateHits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+		case "/go":
+			http.Redirect(w, r, "/private/page", http.StatusFound)
+		default:
+			privateHits.Add(1)
+		}
+	}))
+	defer srv.Close()
+
+	tool := FetchTool(FetchPolicy{AllowPrivate: true})
+	if _, err := tool.Run(map[string]any{"url": srv.URL + "/go"}); err == nil || !strings.Contains(err.Error(), "robots.txt disallows") {
+		t.Errorf("redirect to disallowed path error = %v", err)
+	}
+	if privateHits.Load() != 0 {
+		t.Error("disallowed path was requested")
+	}
+}
+
+func TestFetchTool_DeniesPrivateAddresses(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	// By IP, and by a name that resolves to loopback
+	for _, u := range []string{srv.URL, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)} {
+		for _, client := range []*http.Client{nil, {Transport: &http.Transport{}}} {
+			tool := FetchTool(FetchPolicy{Client: client, IgnoreRobots: true})
+			if _, err := tool.Run(map[string]any{"url": u}); err == nil || !strings.Contains(err.Error(), "private address") {
+				t.Errorf("fetch %s error = %v, want private address", u, err)
+			}
+		}
+	}
+	if hits.Load() != 0 {
+		t.Errorf("private server was requested %d times", hits.Load())
+	}
+
+	for _, addr := range []string{"10.1.2.3:80", "169.254.169.254:80", "[::1]:443", "[::ffff:192.168.0.1]:80", "100.64.0.1:80"} {
+		if err := denyPrivate("tcp", addr, nil); err == nil {
+			t.Errorf("denyPrivate(%s) allowed", addr)
+		}
+	}
+	if err := denyPrivate("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("denyPrivate(public) = %v", err)
+	}
+}
+
+func TestFetcher_checkHost(t *testing.T) {
+	f := &fetcher{policy: FetchPolicy{Allow: []string{"example.com"}, Deny: []string{"ads.example.com"}}}
+	for host, ok := range map[string]bool{
+		"example.com":     true,
+		"www.example.com": true,
+		"ads.example.com": false,
+		"badexample.com":  false,
+		"other.org":       false,
+	} {
+		if err := f.checkHost(host); (err == nil) != ok {
+			t.Errorf("checkHost(%q) = %v, want allowed %v", host, err, ok)
+		}
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	body := "User-agent: llmkit-fetch\nDisallow: /\n\nUser-agent: *\nDisallow: /tmp\n"
+	if r := parseRobots(body, "llmkit-fetch/1.0"); r.allowed("/page") {
+		t.Error("agent-specific group was ignored")
+	}
+	if r := parseRobots(body, "other"); !r.allowed("/page") || r.allowed("/tmp/x") {
+		t.Errorf("wildcard group = %+v", r)
+	}
+}
+
+func TestFetchTool_Timeout(t *testing.T) {
+	f := &fetcher{policy: FetchPolicy{IgnoreRobots: true}, pages: map[string]cachedPage{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.fetch(ctx, "http://127.0.0.1:1/"); err == nil {
+		t.Error("cancelled fetch succeeded")
+	}
+}
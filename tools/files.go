@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aktagon/llmkit"
+)
+
+// FileTools returns read_file and list_files, plus write_file and edit_file
+// unless the sandbox is read-only. Every path is checked with s.Resolve.
+func FileTools(s Sandbox) []llmkit.Tool {
+	tools := []llmkit.Tool{readFileTool(s), listFilesTool(s)}
+	if !s.ReadOnly {
+		tools = append(tools, writeFileTool(s), editFileTool(s))
+	}
+	return tools
+}
+
+// pathSchema builds an object schema whose properties are all required strings.
+func pathSchema(props map[string]string) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	for name, desc := range props {
+		properties[name] = map[string]any{"type": "string", "description": desc}
+		required = append(required, name)
+	}
+	sort.Strings(required)
+	return map[string]any{"type": "object", "properties": properties, "required": required}
+}
+
+func stringArg(input map[string]any, name string) (string, error) {
+	v, ok := input[name].(string)
+	if !ok {
+		return "", fmt.Errorf("missing string argument %q", name)
+	}
+	return v, nil
+}
+
+func readFileTool(s Sandbox) llmkit.Tool {
+	return llmkit.Tool{
+		Name:        "read_file",
+		Description: "Read a text file. Paths are relative to the project root.",
+		Schema:      pathSchema(map[string]string{"path": "File path"}),
+		Run: func(input map[string]any) (string, error) {
+			path, err := stringArg(input, "path")
+			if err != nil {
+				return "", err
+			}
+			full, err := s.Resolve(path)
+			if err != nil {
+				return "", err
+			}
+			info, err := os.Stat(full)
+			if err != nil {
+				return "", err
+			}
+			if info.Size() > s.maxFileSize() {
+				return "", fmt.Errorf("%s is %d bytes, over the %d byte limit", path, info.Size(), s.maxFileSize())
+			}
+			data, err := os.ReadFile(full)
+			if err != nil {
+				return "", err
+			}
+			return string(data), nil
+		},
+	}
+}
+
+func listFilesTool(s Sandbox) llmkit.Tool {
+	return llmkit.Tool{
+		Name:        "list_files",
+		Description: "List a directory. Subdirectories end with a slash.",
+		Schema:      pathSchema(map[string]string{"path": `Directory path ("." for the project root)`}),
+		Run: func(input map[string]any) (string, error) {
+			path, err := stringArg(input, "path")
+			if err != nil {
+				return "", err
+			}
+			full, err := s.Resolve(path)
+			if err != nil {
+				return "", err
+			}
+			entries, err := os.ReadDir(full)
+			if err != nil {
+				return "", err
+			}
+			var b strings.Builder
+			for _, e := range entries {
+				b.WriteString(e.Name())
+				if e.IsDir() {
+					b.WriteString("/")
+				}
+				b.WriteString("\n")
+			}
+			return b.String(), nil
+		},
+	}
+}
+
+func writeFileTool(s Sandbox) llmkit.Tool {
+	return llmkit.Tool{
+		Name:        "write_file",
+		Description: "Create or overwrite a file with the given content.",
+		Schema:      pathSchema(map[string]string{"path": "File path", "content": "Full file content"}),
+		Run: func(input map[string]any) (string, error) {
+			path, err := stringArg(input, "path")
+			if err != nil {
+				return "", err
+			}
+			content, err := stringArg(input, "content")
+			if err != nil {
+				return "", err
+			}
+			full, err := s.resolveWrite(path)
+			if err != nil {
+				return "", err
+			}
+			if int64(len(content)) > s.maxFileSize() {
+				return "", fmt.Errorf("content is %d bytes, over the %d byte limit", len(content), s.maxFileSize())
+			}
+			if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+				return "", err
+			}
+			if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("wrote %d bytes to %s", len(content), path), nil
+		},
+	}
+}
+
+func editFileTool(s Sandbox) llmkit.Tool {
+	return llmkit.Tool{
+		Name:        "edit_file",
+		Description: "Replace one exact occurrence of old_text with new_text in a file.",
+		Schema: pathSchema(map[string]string{
+			"path":     "File path",
+			"old_text": "Text to replace; must occur exactly once",
+			"new_text": "Replacement text",
+		}),
+		Run: func(input map[string]any) (string, error) {
+			path, err := stringArg(input, "path")
+			if err != nil {
+				return "", err
+			}
+			oldText, err := stringArg(input, "old_text")
+			if err != nil {
+				return "", err
+			}
+			newText, err := stringArg(input, "new_text")
+			if err != nil {
+				return "", err
+			}
+			full, err := s.resolveWrite(path)
+			if err != nil {
+				return "", err
+			}
+			data, err := os.ReadFile(full)
+			if err != nil {
+				return "", err
+			}
+			if oldText == "" {
+				return "", errors.New("old_text is empty")
+			}
+			if n := strings.Count(string(data), oldText); n != 1 {
+				return "", fmt.Errorf("old_text occurs %d times in %s, want exactly 1", n, path)
+			}
+			updated := strings.Replace(string(data), oldText, newText, 1)
+			if int64(len(updated)) > s.maxFileSize() {
+				return "", fmt.Errorf("edited file would be %d bytes, over the %d byte limit", len(updated), s.maxFileSize())
+			}
+			if err := os.WriteFile(full, []byte(updated), 0o644); err != nil {
+				return "", err
+			}
+			return "edited " + path, nil
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aktagon/llmkit"
+)
+
+func findTool(t *testing.T, tools []llmkit.Tool, name string) llmkit.Tool {
+	t.Helper()
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool
+		}
+	}
+	t.Fatalf("tool %q not found", name)
+	return llmkit.Tool{}
+}
+
+func TestFileTools(t *testing.T) {
+	root := t.TempDir()
+	tools := FileTools(Sandbox{Root: root, MaxFileSize: 64})
+
+	write := findTool(t, tools, "write_file")
+	if _, err := write.Run(map[string]any{"path": "notes/todo.txt", "content": "buy milk"}); err != nil {
+		t.Fatalf("write_file error = %v", err)
+	}
+	if _, err := write.Run(map[string]any{"path": "big.txt", "content": strings.Repeat("x", 65)}); err == nil {
+		t.Error("write_file accepted content over the size limit")
+	}
+
+	edit := findTool(t, tools, "edit_file")
+	if _, err := edit.Run(map[string]any{"path": "notes/todo.txt", "old_text": "milk", "new_text": "bread"}); err != nil {
+		t.Fatalf("edit_file error = %v", err)
+	}
+	if _, err := edit.Run(map[string]any{"path": "notes/todo.txt", "old_text": "eggs", "new_text": "x"}); err == nil {
+		t.Error("edit_file accepted text that does not occur")
+	}
+
+	got, err := findTool(t, tools, "read_file").Run(map[string]any{"path": "notes/todo.txt"})
+	if err != nil || got != "buy bread" {
+		t.Errorf("read_file = %q, %v", got, err)
+	}
+
+	list, err := findTool(t, tools, "list_files").Run(map[string]any{"path": "."})
+	if err != nil || list != "notes/\n" {
+		t.Errorf("list_files = %q, %v", list, err)
+	}
+
+	if _, err := findTool(t, tools, "read_file").Run(map[string]any{"path": "../etc/passwd"}); err == nil {
+		t.Error("read_file escaped the sandbox")
+	}
+}
+
+func TestFileTools_ReadOnly(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644)
+	s := Sandbox{Root: root, ReadOnly: true}
+
+	for _, tool := range FileTools(s) {
+		if tool.Name == "write_file" || tool.Name == "edit_file" {
+			t.Errorf("read-only sandbox exposes %s", tool.Name)
+		}
+	}
+	if _, err := s.resolveWrite("a.txt"); err == nil {
+		t.Error("resolveWrite allowed a write in a read-only sandbox")
+	}
+}
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aktagon/llmkit"
+)
+
+const defaultPassages = 4
+
+// Passage is a piece of retrieved text and where it came from.
+type Passage struct {
+	Text   string
+	Source string  // file name, URL or other citation; optional
+	Score  float64 // relevance, higher is better
+}
+
+// Retriever finds the k passages most relevant to a query.
+type Retriever interface {
+	Retrieve(ctx context.Context, query string, k int) ([]Passage, error)
+}
+
+// RetrieverFunc adapts a function to Retriever.
+type RetrieverFunc func(ctx context.Context, query string, k int) ([]Passage, error)
+
+func (f RetrieverFunc) Retrieve(ctx context.Context, query string, k int) ([]Passage, error) {
+	return f(ctx, query, k)
+}
+
+// KnowledgeBaseTool returns a search_knowledge_base tool that passes the model's
+// query to r and returns the top k passages (default 4) with their sources, so
+// answers can cite them.
+func KnowledgeBaseTool(r Retriever, k int) llmkit.Tool {
+	if k <= 0 {
+		k = defaultPassages
+	}
+	return llmkit.Tool{
+		Name:        "search_knowledge_base",
+		Description: "Search the knowledge base and return relevant passages with their sources. Cite sources in answers.",
+		Schema:      pathSchema(map[string]string{"query": "What to search for"}),
+		Run: func(input map[string]any) (string, error) {
+			query, err := stringArg(input, "query")
+			if err != nil {
+				return "", err
+			}
+			passages, err := r.Retrieve(context.Background(), query, k)
+			if err != nil {
+				return "", err
+			}
+			return formatPassages(passages), nil
+		},
+	}
+}
+
+// formatPassages numbers passages for citation.
+func formatPassages(passages []Passage) string {
+	if len(passages) == 0 {
+		return "No relevant passages found."
+	}
+	var b strings.Builder
+	for i, p := range passages {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "[%d]", i+1)
+		if p.Source != "" {
+			fmt.Fprintf(&b, " source: %s", p.Source)
+		}
+		b.WriteString("\n")
+		b.WriteString(strings.TrimSpace(p.Text))
+	}
+	return b.String()
+}
+
+// EmbeddingRetriever is an in-memory Retriever that ranks passages by embedding
+// similarity. It suits knowledge bases of up to a few thousand passages.
+type EmbeddingRetriever struct {
+	provider llmkit.Provider
+	opts     []llmkit.Option
+	passages []Passage
+	vectors  [][]float32
+}
+
+// NewEmbeddingRetriever embeds passages with p (see llmkit.EmbedBatch) and
+// returns a retriever over them. opts are used for every embedding call.
+func NewEmbeddingRetriever(ctx context.Context, p llmkit.Provider, passages []Passage, opts ...llmkit.Option) (*EmbeddingRetriever, error) {
+	texts := make([]string, len(passages))
+	for i, passage := range passages {
+		texts[i] = passage.Text
+	}
+	vectors, err := llmkit.EmbedBatch(ctx, p, texts, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbeddingRetriever{provider: p, opts: opts, passages: passages, vectors: vectors}, nil
+}
+
+// Retrieve embeds query and returns the k most similar passages, best first.
+func (r *EmbeddingRetriever) Retrieve(ctx context.Context, query string, k int) ([]Passage, error) {
+	q, err := llmkit.EmbedBatch(ctx, r.provider, []string{query}, r.opts...)
+	if err != nil {
+		return nil, err
+	}
+	matches := llmkit.TopK(q[0], r.vectors, k)
+	out := make([]Passage, len(matches))
+	for i, m := range matches {
+		out[i] = r.passages[m.Index]
+		out[i].Score = m.Score
+	}
+	return out, nil
+}
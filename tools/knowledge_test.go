@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aktagon/llmkit"
+)
+
+func TestKnowledgeBaseTool(t *testing.T) {
+	var gotK int
+	r := RetrieverFunc(func(ctx context.Context, query string, k int) ([]Passage, error) {
+		gotK = k
+		if query == "fail" {
+			return nil, errors.New("index offline")
+		}
+		return []Passage{
+			{Text: "Refunds take 5 days.\n", Source: "policy.md"},
+			{Text: "Call support."},
+		}, nil
+	})
+	tool := KnowledgeBaseTool(r, 0)
+
+	out, err := tool.Run(map[string]any{"query": "refunds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[1] source: policy.md\nRefunds take 5 days.\n\n[2]\nCall support."
+	if out != want {
+		t.Errorf("result = %q, want %q", out, want)
+	}
+	if gotK != defaultPassages {
+		t.Errorf("k = %d, want %d", gotK, defaultPassages)
+	}
+	if _, err := tool.Run(map[string]any{"query": "fail"}); err == nil {
+		t.Error("retriever error was not returned")
+	}
+	if formatPassages(nil) != "No relevant passages found." {
+		t.Error("empty result not reported")
+	}
+}
+
+func TestEmbeddingRetriever(t *testing.T) {
+	// Embeds each text as a fixed 2D vector keyed by its first word
+	vectors := map[string][]float32{"cats": {1, 0}, "dogs": {0, 1}, "kittens": {0.9, 0.1}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var data []string
+		for i, text := range req.Input {
+			v := vectors[strings.Fields(text)[0]]
+			data = append(data, fmt.Sprintf(`{"index":%d,"embedding":[%g,%g]}`, i, v[0], v[1]))
+		}
+		fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(data, ","))
+	}))
+	defer server.Close()
+
+	p := llmkit.Provider{Name: llmkit.OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	r, err := NewEmbeddingRetriever(context.Background(), p, []Passage{
+		{Text: "dogs bark", Source: "dogs.md"},
+		{Text: "cats purr", Source: "cats.md"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.Retrieve(context.Background(), "kittens please", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Source != "cats.md" || got[0].Score <= 0.9 {
+		t.Errorf("Retrieve() = %+v, want cats.md", got)
+	}
+}
@@ -0,0 +1,259 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aktagon/llmkit"
+)
+
+const defaultSchedulerInterval = time.Second
+
+// systemClock is the default llmkit.Clock, backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Reminder is a message scheduled for a future time.
+type Reminder struct {
+	ID      string    `json:"id"`
+	Message string    `json:"message"`
+	Due     time.Time `json:"due"`
+}
+
+// ReminderStore persists reminders. Implementations must be safe for concurrent use.
+type ReminderStore interface {
+	Save(r Reminder) error
+	Delete(id string) (bool, error) // reports whether the reminder existed
+	List() ([]Reminder, error)
+}
+
+// FileReminderStore is a ReminderStore kept in a JSON file, so reminders
+// survive restarts.
+type FileReminderStore struct {
+	mu        sync.Mutex
+	path      string
+	reminders map[string]Reminder
+}
+
+// NewFileReminderStore returns a store persisted at path, loading any reminders
+// already saved there. An empty path keeps reminders in memory only.
+func NewFileReminderStore(path string) (*FileReminderStore, error) {
+	s := &FileReminderStore{path: path, reminders: map[string]Reminder{}}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.reminders); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileReminderStore) Save(r Reminder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reminders[r.ID] = r
+	return s.persist()
+}
+
+func (s *FileReminderStore) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.reminders[id]; !ok {
+		return false, nil
+	}
+	delete(s.reminders, id)
+	return true, s.persist()
+}
+
+// List returns all reminders, soonest first.
+func (s *FileReminderStore) List() ([]Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Reminder, 0, len(s.reminders))
+	for _, r := range s.reminders {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Due.Before(out[j].Due) })
+	return out, nil
+}
+
+// persist writes the reminders atomically; the caller holds s.mu.
+func (s *FileReminderStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+	out, err := json.MarshalIndent(s.reminders, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, out, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Scheduler lets agents schedule reminders and fires them when due.
+// Register its Tools with an agent and start Run in a goroutine.
+type Scheduler struct {
+	Store    ReminderStore
+	Fire     func(ctx context.Context, r Reminder) // called once a reminder is due
+	Interval time.Duration                         // how often Run checks for due reminders (default 1s)
+	Clock    llmkit.Clock                          // time source (default the system clock)
+}
+
+func (s *Scheduler) clock() llmkit.Clock {
+	if s.Clock != nil {
+		return s.Clock
+	}
+	return systemClock{}
+}
+
+// Run fires due reminders until ctx is cancelled. A reminder is deleted after
+// Fire returns, so one that was due while the process was down fires on restart.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s.Store == nil || s.Fire == nil {
+		return &llmkit.ValidationError{Field: "scheduler", Message: "Store and Fire must be set"}
+	}
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultSchedulerInterval
+	}
+	for {
+		if err := s.fireDue(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock().After(interval):
+		}
+	}
+}
+
+// fireDue fires and deletes every reminder whose time has come.
+func (s *Scheduler) fireDue(ctx context.Context) error {
+	reminders, err := s.Store.List()
+	if err != nil {
+		return err
+	}
+	now := s.clock().Now()
+	for _, r := range reminders {
+		if r.Due.After(now) {
+			continue
+		}
+		s.Fire(ctx, r)
+		if _, err := s.Store.Delete(r.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tools returns create_reminder, list_reminders and cancel_reminder tools backed by s.Store.
+func (s *Scheduler) Tools() []llmkit.Tool {
+	return []llmkit.Tool{
+		{
+			Name:        "create_reminder",
+			Description: "Schedule a reminder. Returns its ID.",
+			Schema: pathSchema(map[string]string{
+				"message": "What to remind about",
+				"when":    "RFC 3339 time (2025-01-31T09:00:00Z) or a delay from now (90m, 2h, 48h)",
+			}),
+			Run: func(input map[string]any) (string, error) {
+				message, err := stringArg(input, "message")
+				if err != nil {
+					return "", err
+				}
+				when, err := stringArg(input, "when")
+				if err != nil {
+					return "", err
+				}
+				due, err := s.parseWhen(when)
+				if err != nil {
+					return "", err
+				}
+				r := Reminder{ID: newReminderID(), Message: message, Due: due}
+				if err := s.Store.Save(r); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("scheduled %s for %s", r.ID, due.Format(time.RFC3339)), nil
+			},
+		},
+		{
+			Name:        "list_reminders",
+			Description: "List scheduled reminders, soonest first.",
+			Schema:      map[string]any{"type": "object", "properties": map[string]any{}},
+			Run: func(map[string]any) (string, error) {
+				reminders, err := s.Store.List()
+				if err != nil {
+					return "", err
+				}
+				if len(reminders) == 0 {
+					return "No reminders.", nil
+				}
+				var b strings.Builder
+				for _, r := range reminders {
+					fmt.Fprintf(&b, "%s\t%s\t%s\n", r.ID, r.Due.Format(time.RFC3339), r.Message)
+				}
+				return b.String(), nil
+			},
+		},
+		{
+			Name:        "cancel_reminder",
+			Description: "Cancel a scheduled reminder by ID.",
+			Schema:      pathSchema(map[string]string{"id": "Reminder ID"}),
+			Run: func(input map[string]any) (string, error) {
+				id, err := stringArg(input, "id")
+				if err != nil {
+					return "", err
+				}
+				ok, err := s.Store.Delete(id)
+				if err != nil {
+					return "", err
+				}
+				if !ok {
+					return "", fmt.Errorf("no reminder %s", id)
+				}
+				return "cancelled " + id, nil
+			},
+		},
+	}
+}
+
+// parseWhen accepts an RFC 3339 time or a positive delay from now.
+func (s *Scheduler) parseWhen(when string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, when); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(when)
+	if err != nil || d <= 0 {
+		return time.Time{}, &llmkit.ValidationError{Field: "when", Message: "must be an RFC 3339 time or a positive duration"}
+	}
+	return s.clock().Now().Add(d).Truncate(time.Second), nil
+}
+
+func newReminderID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "rem_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aktagon/llmkit"
+)
+
+// manualClock is an llmkit.Clock that moves only when advanced.
+type manualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []clockWaiter
+	added   chan struct{} // receives each After call
+}
+
+type clockWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newManualClock(now time.Time) *manualClock {
+	return &manualClock{now: now, added: make(chan struct{}, 16)}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, clockWaiter{c.now.Add(d), ch})
+	c.added <- struct{}{}
+	return ch
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	var waiting []clockWaiter
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiting = append(waiting, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+	c.waiters = waiting
+}
+
+func TestScheduler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reminders.json")
+	store, err := NewFileReminderStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := newManualClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	var fired []string
+	s := &Scheduler{
+		Store: store,
+		Fire:  func(ctx context.Context, r Reminder) { fired = append(fired, r.Message) },
+		Clock: clock,
+	}
+	tools := s.Tools()
+
+	create := findTool(t, tools, "create_reminder")
+	if _, err := create.Run(map[string]any{"message": "stand-up", "when": "30m"}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := create.Run(map[string]any{"message": "review", "when": "2025-01-02T09:00:00Z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reviewID := strings.Fields(out)[1]
+	if _, err := create.Run(map[string]any{"message": "x", "when": "yesterday"}); err == nil {
+		t.Error("bad time was accepted")
+	}
+
+	list, _ := findTool(t, tools, "list_reminders").Run(nil)
+	if !strings.Contains(list, "2025-01-01T09:30:00Z\tstand-up") || strings.Index(list, "stand-up") > strings.Index(list, "review") {
+		t.Errorf("list = %q", list)
+	}
+
+	// Reminders survive a restart
+	reloaded, err := NewFileReminderStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs, _ := reloaded.List(); len(rs) != 2 {
+		t.Fatalf("reloaded %d reminders, want 2", len(rs))
+	}
+	s.Store = reloaded
+
+	clock.advance(time.Hour)
+	if err := s.fireDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(fired) != 1 || fired[0] != "stand-up" {
+		t.Errorf("fired = %v, want [stand-up]", fired)
+	}
+
+	cancel := findTool(t, tools, "cancel_reminder")
+	if _, err := cancel.Run(map[string]any{"id": reviewID}); err != nil {
+		t.Errorf("cancel: %v", err)
+	}
+	if _, err := cancel.Run(map[string]any{"id": reviewID}); err == nil {
+		t.Error("cancelling twice succeeded")
+	}
+	if list, _ := findTool(t, tools, "list_reminders").Run(nil); list != "No reminders." {
+		t.Errorf("list after cancel = %q", list)
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	clock := newManualClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	store, _ := NewFileReminderStore("")
+	store.Save(Reminder{ID: "r1", Message: "later", Due: clock.Now().Add(time.Minute)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{Store: store, Interval: time.Minute, Clock: clock, Fire: func(context.Context, Reminder) { cancel() }}
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	<-clock.added // first check found nothing due
+	clock.advance(time.Minute)
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() = %v, want context.Canceled", err)
+	}
+	if rs, _ := store.List(); len(rs) != 0 {
+		t.Errorf("fired reminder was kept: %v", rs)
+	}
+}
+
+func TestScheduler_RunRequiresFireAndStore(t *testing.T) {
+	store, _ := NewFileReminderStore("")
+	for _, s := range []*Scheduler{{Store: store}, {Fire: func(context.Context, Reminder) {}}} {
+		var ve *llmkit.ValidationError
+		if err := s.Run(context.Background()); !errors.As(err, &ve) {
+			t.Errorf("Run() error = %v, want ValidationError", err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Package tools provides ready-made llmkit.Tool implementations for agents.
+// Each constructor returns tools that can be registered with Agent.AddTool.
+package tools
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aktagon/llmkit"
+)
+
+// defaultMaxFileSize caps reads and writes when Sandbox.MaxFileSize is zero.
+const defaultMaxFileSize = 1 << 20
+
+// Sandbox confines file tools to a directory tree.
+type Sandbox struct {
+	Root           string // directory the tools may access; required
+	ReadOnly       bool   // reject writes and edits
+	MaxFileSize    int64  // largest file read or written, in bytes (default 1 MiB)
+	FollowSymlinks bool   // allow symlinks that resolve inside Root; otherwise any symlink is rejected
+}
+
+// maxFileSize returns the configured size limit or the default.
+func (s Sandbox) maxFileSize() int64 {
+	if s.MaxFileSize > 0 {
+		return s.MaxFileSize
+	}
+	return defaultMaxFileSize
+}
+
+// Resolve maps a path given by the model (relative to Root, or absolute inside
+// it) to an absolute host path, rejecting anything that escapes Root.
+// The path does not need to exist, so it can be used for new files.
+func (s Sandbox) Resolve(path string) (string, error) {
+	if s.Root == "" {
+		return "", &llmkit.ValidationError{Field: "root", Message: "required"}
+	}
+	root, err := filepath.Abs(s.Root)
+	if err != nil {
+		return "", err
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+
+	target := path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	target = filepath.Clean(target)
+	if !within(root, target) {
+		return "", outside(path)
+	}
+
+	// Resolve symlinks on the longest existing prefix; the rest does not exist yet
+	existing, rest := target, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+	real, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	if real != existing && !s.FollowSymlinks {
+		return "", &llmkit.ValidationError{Field: "path", Message: "symlinks are not allowed: " + path}
+	}
+	if !within(root, real) {
+		return "", outside(path)
+	}
+	return filepath.Join(real, rest), nil
+}
+
+// resolveWrite is Resolve for paths that will be modified.
+func (s Sandbox) resolveWrite(path string) (string, error) {
+	if s.ReadOnly {
+		return "", &llmkit.ValidationError{Field: "path", Message: "sandbox is read-only"}
+	}
+	return s.Resolve(path)
+}
+
+// within reports whether path is root or below it.
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func outside(path string) error {
+	return &llmkit.ValidationError{Field: "path", Message: "outside the sandbox: " + path}
+}
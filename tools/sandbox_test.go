@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aktagon/llmkit"
+)
+
+func TestSandbox_Resolve(t *testing.T) {
+	root := t.TempDir()
+	outsideDir := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "src"), 0o755)
+	os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main"), 0o644)
+	os.WriteFile(filepath.Join(outsideDir, "secret"), []byte("token"), 0o644)
+	os.Symlink(outsideDir, filepath.Join(root, "escape"))
+	os.Symlink(filepath.Join(root, "src"), filepath.Join(root, "alias"))
+
+	s := Sandbox{Root: root}
+	tests := []struct {
+		path   string
+		follow bool
+		ok     bool
+	}{
+		{"src/main.go", false, true},
+		{"src/new/file.txt", false, true}, // does not exist yet
+		{filepath.Join(root, "src"), false, true},
+		{"../outside", false, false},
+		{"src/../../etc/passwd", false, false},
+		{"/etc/passwd", false, false},
+		{"escape/secret", false, false},
+		{"escape/secret", true, false}, // resolves outside root
+		{"alias/main.go", false, false},
+		{"alias/main.go", true, true},
+	}
+	for _, tt := range tests {
+		s.FollowSymlinks = tt.follow
+		_, err := s.Resolve(tt.path)
+		if (err == nil) != tt.ok {
+			t.Errorf("Resolve(%q, follow=%v) error = %v, want ok=%v", tt.path, tt.follow, err, tt.ok)
+		}
+		var ve *llmkit.ValidationError
+		if err != nil && !errors.As(err, &ve) {
+			t.Errorf("Resolve(%q) error = %v, want ValidationError", tt.path, err)
+		}
+	}
+}
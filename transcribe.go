@@ -0,0 +1,287 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Default transcription models per provider, overridden with WithModel.
+var defaultTranscriptionModels = map[string]string{
+	OpenAI: "gpt-4o-transcribe",
+	Google: "gemini-2.5-flash",
+}
+
+// Transcript is the text of an audio file.
+type Transcript struct {
+	Text     string
+	Language string        // ISO 639-1 code, when the provider reports it
+	Duration time.Duration // when the provider reports it
+	Segments []TranscriptSegment
+	Tokens   Usage
+}
+
+// TranscriptSegment is a timed span of a Transcript.
+type TranscriptSegment struct {
+	Start, End time.Duration
+	Text       string
+}
+
+// Transcribe converts speech in audio to text. OpenAI (gpt-4o-transcribe by
+// default; whisper-1 adds segments) and Google (Gemini audio understanding)
+// only. audio needs inline Data; Google also takes an uploaded file's URI and
+// uploads large inline audio itself. OpenAI WAV or MP3 audio over the 25 MB
+// upload limit is split into overlapping chunks whose transcripts are
+// stitched together. Gemini segments are timed by the model.
+// WithLanguage hints the spoken language; WithModel overrides the model.
+func Transcribe(ctx context.Context, p Provider, audio File, opts ...Option) (Transcript, error) {
+	return transcribe(ctx, p, audio, false, opts)
+}
+
+// TranslateAudio is Transcribe for speech in any language, returning English
+// text. OpenAI uses the translations endpoint, where only whisper-1 (the
+// default) is available; Google asks Gemini to translate. Long audio is split
+// as in Transcribe. WithLanguage hints the spoken language (Google only).
+func TranslateAudio(ctx context.Context, p Provider, audio File, opts ...Option) (Transcript, error) {
+	return transcribe(ctx, p, audio, true, opts)
+}
+
+// Default models for TranslateAudio.
+var defaultTranslationModels = map[string]string{
+	OpenAI: "whisper-1",
+	Google: "gemini-2.5-flash",
+}
+
+// transcribe implements Transcribe and, with translate, TranslateAudio.
+func transcribe(ctx context.Context, p Provider, audio File, translate bool, opts []Option) (Transcript, error) {
+	if err := validateProvider(p); err != nil {
+		return Transcript{}, err
+	}
+	models := defaultTranscriptionModels
+	if translate {
+		models = defaultTranslationModels
+	}
+	model, ok := models[p.Name]
+	if !ok {
+		return Transcript{}, &ValidationError{Field: "provider", Message: "transcription not supported by " + p.Name}
+	}
+	if len(audio.Data) == 0 && (p.Name != Google || audio.URI == "") {
+		return Transcript{}, &ValidationError{Field: "audio", Message: "must contain inline data"}
+	}
+
+	o := applyOptions(opts...)
+	if o.model != "" {
+		model = o.model
+	}
+	return withPoolKey(ctx, p, o, func(p Provider) (Transcript, error) {
+		if p.Name == Google {
+			return transcribeGoogle(ctx, p, model, audio, translate, o)
+		}
+		if len(audio.Data) > openaiAudioLimit {
+			return transcribeChunked(ctx, p, model, audio, translate, o)
+		}
+		return transcribeOpenAI(ctx, p, model, audio, translate, o)
+	})
+}
+
+// TranscribeReader is Transcribe for audio read from r. name is the file name,
+// such as "call.mp3", from which the format is detected.
+func TranscribeReader(ctx context.Context, p Provider, r io.Reader, name string, opts ...Option) (Transcript, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Transcript{}, err
+	}
+	return Transcribe(ctx, p, File{Name: name, MimeType: detectMimeType(name), Data: data}, opts...)
+}
+
+// openaiAudioLimit is the largest upload the transcriptions endpoint accepts.
+var openaiAudioLimit = 25 << 20
+
+// transcribeOverlap is how much consecutive chunks of long audio overlap.
+const transcribeOverlap = 2 * time.Second
+
+// transcribeChunked transcribes audio too large for one upload chunk by chunk.
+func transcribeChunked(ctx context.Context, p Provider, model string, audio File, translate bool, o *options) (Transcript, error) {
+	// Leave room for the multipart envelope
+	chunks, err := splitAudio(audio.Data, openaiAudioLimit-4096, transcribeOverlap)
+	if err != nil {
+		return Transcript{}, err
+	}
+	parts := make([]Transcript, len(chunks))
+	for i, c := range chunks {
+		part := audio
+		part.Data = c.data
+		if parts[i], err = transcribeOpenAI(ctx, p, model, part, translate, o); err != nil {
+			return Transcript{}, fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+	}
+	return stitchTranscripts(parts, chunks), nil
+}
+
+// stitchTranscripts joins chunk transcripts. Segments are shifted to the
+// original timeline and, where chunks overlap, taken from whichever chunk they
+// start in before or after the middle of the overlap. Chunks without segments
+// are joined by dropping the words the next chunk repeats.
+func stitchTranscripts(parts []Transcript, chunks []audioChunk) Transcript {
+	var t Transcript
+	timed := true
+	for _, part := range parts {
+		t.Tokens = t.Tokens.Add(part.Tokens)
+		if t.Language == "" {
+			t.Language = part.Language
+		}
+		timed = timed && len(part.Segments) > 0
+	}
+	if last := len(parts) - 1; parts[last].Duration > 0 {
+		t.Duration = chunks[last].start + parts[last].Duration
+	}
+
+	if !timed {
+		for _, part := range parts {
+			t.Text = joinOverlapping(t.Text, part.Text)
+		}
+		return t
+	}
+
+	var text []string
+	for i, part := range parts {
+		from, until := time.Duration(0), time.Duration(math.MaxInt64)
+		if i > 0 {
+			from = chunks[i].start + transcribeOverlap/2
+		}
+		if i < len(parts)-1 {
+			until = chunks[i+1].start + transcribeOverlap/2
+		}
+		for _, s := range part.Segments {
+			s.Start += chunks[i].start
+			s.End += chunks[i].start
+			if (i > 0 && s.Start < from) || s.Start >= until {
+				continue
+			}
+			t.Segments = append(t.Segments, s)
+			text = append(text, s.Text)
+			t.Duration = max(t.Duration, s.End)
+		}
+	}
+	t.Text = strings.Join(text, " ")
+	return t
+}
+
+// joinOverlapping appends b to a, dropping the longest run of two to 40 words
+// that ends a and starts b, as transcripts of overlapping audio do.
+func joinOverlapping(a, b string) string {
+	wa, wb := strings.Fields(a), strings.Fields(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return strings.TrimSpace(a + " " + b)
+	}
+	norm := func(w string) string {
+		return strings.ToLower(strings.TrimFunc(w, func(r rune) bool { return unicode.IsPunct(r) }))
+	}
+	for k := min(40, len(wa), len(wb)); k > 1; k-- {
+		match := true
+		for i := range k {
+			if norm(wa[len(wa)-k+i]) != norm(wb[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			wb = wb[k:]
+			break
+		}
+	}
+	return strings.Join(append(wa, wb...), " ")
+}
+
+// googleInlineAudioLimit is the largest audio Transcribe sends inline to Gemini.
+var googleInlineAudioLimit = 18 << 20
+
+// googleTranscript is the structured answer Gemini is asked for.
+type googleTranscript struct {
+	Language string `json:"language" description:"ISO 639-1 code of the spoken language"`
+	Segments []struct {
+		Start float64 `json:"start" description:"start time in seconds"`
+		End   float64 `json:"end" description:"end time in seconds"`
+		Text  string  `json:"text" description:"verbatim speech in this segment"`
+	} `json:"segments" description:"consecutive sentences or phrases"`
+}
+
+const transcriberSystem = "You transcribe audio verbatim. Do not summarize, translate or correct the " +
+	"speech. Split the transcript into consecutive segments of a sentence or phrase each, " +
+	"with start and end times in seconds."
+
+const audioTranslatorSystem = "You translate the speech in audio into English text. Translate " +
+	"faithfully and completely; do not summarize. Split the translation into consecutive segments " +
+	"of a sentence or phrase each, with the start and end times of the speech in seconds."
+
+// transcribeGoogle asks Gemini for timed segments as structured output.
+func transcribeGoogle(ctx context.Context, p Provider, model string, audio File, translate bool, o *options) (Transcript, error) {
+	schema, err := SchemaFor[googleTranscript]()
+	if err != nil {
+		return Transcript{}, err
+	}
+	system, user := transcriberSystem, "Transcribe this audio."
+	if translate {
+		system, user = audioTranslatorSystem, "Translate this audio into English."
+	}
+	if o.language != "" {
+		user += " The speech is in " + languageName(o.language) + "."
+	}
+
+	// Requests over 20 MB are rejected, so large audio goes through the Files API
+	if len(audio.Data) > googleInlineAudioLimit {
+		uploaded, err := uploadGoogle(ctx, p, audio.Data, audioFilename(audio), audio.MimeType, o)
+		if err != nil {
+			return Transcript{}, fmt.Errorf("google: upload audio: %w", err)
+		}
+		if uploaded.MimeType == "" {
+			uploaded.MimeType = audio.MimeType
+		}
+		audio = uploaded
+	}
+
+	p.Model = model
+	resp, err := promptGoogle(ctx, p, Request{System: system, User: user, Files: []File{audio}, Schema: schema}, o)
+	if err != nil {
+		return Transcript{}, err
+	}
+	var out googleTranscript
+	if err := json.Unmarshal([]byte(resp.Text), &out); err != nil {
+		return Transcript{}, fmt.Errorf("google: decode transcript: %w", err)
+	}
+
+	t := Transcript{Language: baseLanguage(out.Language), Tokens: resp.Tokens}
+	if translate {
+		t.Language = "en"
+	}
+	var text []string
+	for _, s := range out.Segments {
+		seg := TranscriptSegment{Start: seconds(s.Start), End: seconds(s.End), Text: strings.TrimSpace(s.Text)}
+		t.Segments = append(t.Segments, seg)
+		text = append(text, seg.Text)
+		t.Duration = max(t.Duration, seg.End)
+	}
+	t.Text = strings.Join(text, " ")
+	return t, nil
+}
+
+// seconds converts fractional seconds to a Duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// languageCode returns the ISO 639-1 code for a code or English language name
+// such as "english", which Whisper reports.
+func languageCode(lang string) string {
+	for code, name := range languageNames {
+		if strings.EqualFold(lang, name) {
+			return code
+		}
+	}
+	return baseLanguage(lang)
+}
@@ -0,0 +1,272 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTranscribe_OpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != openaiTranscribePath {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		if r.FormValue("model") != "gpt-4o-transcribe" || r.FormValue("response_format") != "json" || r.FormValue("language") != "fi" {
+			t.Errorf("fields = %v", r.MultipartForm.Value)
+		}
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(f)
+		if h.Filename != "audio.wav" || string(data) != "RIFF" {
+			t.Errorf("file = %s %q", h.Filename, data)
+		}
+		w.Write([]byte(`{"text":"Hyvää huomenta.","usage":{"input_tokens":12,"output_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	tr, err := Transcribe(context.Background(), p, File{MimeType: "audio/wav", Data: []byte("RIFF")}, WithLanguage("fi"))
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if tr.Text != "Hyvää huomenta." || tr.Tokens.Input != 12 || len(tr.Segments) != 0 {
+		t.Errorf("Transcript = %+v", tr)
+	}
+}
+
+func TestTranscribe_Whisper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		if r.FormValue("response_format") != "verbose_json" || r.FormValue("timestamp_granularities[]") != "segment" {
+			t.Errorf("fields = %v", r.MultipartForm.Value)
+		}
+		w.Write([]byte(`{"text":"Hello there. General Kenobi.","language":"english","duration":3.5,
+			"segments":[{"start":0,"end":1.2,"text":" Hello there."},{"start":1.5,"end":3.5,"text":" General Kenobi."}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	tr, err := Transcribe(context.Background(), p, File{Name: "clip.mp3", Data: []byte("ID3")}, WithModel("whisper-1"))
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if tr.Language != "en" || tr.Duration != 3500*time.Millisecond || len(tr.Segments) != 2 {
+		t.Fatalf("Transcript = %+v", tr)
+	}
+	if s := tr.Segments[1]; s.Start != 1500*time.Millisecond || s.End != 3500*time.Millisecond || s.Text != "General Kenobi." {
+		t.Errorf("segment = %+v", s)
+	}
+}
+
+func TestTranscribe_Google(t *testing.T) {
+	var got googleRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-2.5-flash:generateContent" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		answer, _ := json.Marshal(`{"language":"de","segments":[{"start":0,"end":2,"text":"Guten Tag."},{"start":2.5,"end":4,"text":"Wie geht's?"}]}`)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":` + string(answer) + `}]}}],
+			"usageMetadata":{"promptTokenCount":100,"candidatesTokenCount":30}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	tr, err := Transcribe(context.Background(), p, File{MimeType: "audio/mpeg", Data: []byte("ID3")}, WithLanguage("de"))
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+
+	parts := got.Contents[0].Parts
+	if len(parts) != 2 || parts[0].InlineData == nil || parts[0].InlineData.MimeType != "audio/mpeg" {
+		t.Errorf("parts = %+v", parts)
+	}
+	if parts[1].Text != "Transcribe this audio. The speech is in German." {
+		t.Errorf("instruction = %q", parts[1].Text)
+	}
+	if got.GenerationConfig.ResponseMimeType != "application/json" {
+		t.Errorf("generationConfig = %+v", got.GenerationConfig)
+	}
+
+	if tr.Text != "Guten Tag. Wie geht's?" || tr.Language != "de" || tr.Duration != 4*time.Second {
+		t.Errorf("Transcript = %+v", tr)
+	}
+	if len(tr.Segments) != 2 || tr.Segments[1].Start != 2500*time.Millisecond || tr.Tokens.Input != 100 {
+		t.Errorf("Segments = %+v, Tokens = %+v", tr.Segments, tr.Tokens)
+	}
+}
+
+func TestTranscribe_GoogleUploadsLargeAudio(t *testing.T) {
+	defer func(n int) { googleInlineAudioLimit = n }(googleInlineAudioLimit)
+	googleInlineAudioLimit = 2
+
+	var got googleRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == googleUploadPath {
+			w.Write([]byte(`{"file":{"name":"files/abc","uri":"https://files.example/abc","mimeType":"audio/mpeg"}}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		answer, _ := json.Marshal(`{"language":"en","segments":[{"start":0,"end":1,"text":"Hi."}]}`)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":` + string(answer) + `}]}}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	tr, err := Transcribe(context.Background(), p, File{Name: "long.mp3", MimeType: "audio/mpeg", Data: []byte("ID3-long")})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	fd := got.Contents[0].Parts[0].FileData
+	if fd == nil || fd.FileURI != "https://files.example/abc" || fd.MimeType != "audio/mpeg" {
+		t.Errorf("parts = %+v, want uploaded file reference", got.Contents[0].Parts)
+	}
+	if tr.Text != "Hi." {
+		t.Errorf("Text = %q", tr.Text)
+	}
+}
+
+func TestTranscribe_OpenAIChunksLongAudio(t *testing.T) {
+	defer func(n int) { openaiAudioLimit = n }(openaiAudioLimit)
+	openaiAudioLimit = 4096 + 44 + 8*16000 // 8 s of 16 kHz PCM per chunk
+
+	// Each chunk is heard as three segments; the second chunk starts 2 s before
+	// the first ends, so its first segment repeats the first chunk's last.
+	replies := []string{
+		`{"text":"a b c","duration":8,"segments":[{"start":0,"end":3,"text":"One."},{"start":3,"end":6.5,"text":"Two."},{"start":6.5,"end":8,"text":"Three."}]}`,
+		`{"text":"c d","duration":4,"segments":[{"start":0.5,"end":2,"text":"Three."},{"start":2,"end":4,"text":"Four."}]}`,
+	}
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		f, _, _ := r.FormFile("file")
+		data, _ := io.ReadAll(f)
+		if len(data) > openaiAudioLimit || string(data[:4]) != "RIFF" {
+			t.Errorf("chunk of %d bytes", len(data))
+		}
+		w.Write([]byte(replies[calls.Add(1)-1]))
+	}))
+	defer server.Close()
+
+	audio := wavFile(make([]byte, 10*16000), 8000) // 10 s
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	tr, err := Transcribe(context.Background(), p, File{Data: audio, MimeType: "audio/wav"}, WithModel("whisper-1"))
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2", calls.Load())
+	}
+	if tr.Text != "One. Two. Three. Four." || tr.Duration != 10*time.Second {
+		t.Errorf("Transcript = %+v", tr)
+	}
+	if len(tr.Segments) != 4 || tr.Segments[3].Start != 8*time.Second {
+		t.Errorf("Segments = %+v", tr.Segments)
+	}
+}
+
+func TestTranslateAudio_OpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != openaiTranslatePath {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		r.ParseMultipartForm(1 << 20)
+		if r.FormValue("model") != "whisper-1" || r.FormValue("response_format") != "verbose_json" ||
+			r.FormValue("language") != "" || r.FormValue("timestamp_granularities[]") != "" {
+			t.Errorf("fields = %v", r.MultipartForm.Value)
+		}
+		w.Write([]byte(`{"text":"Good morning.","language":"english","duration":1.5,"segments":[{"start":0,"end":1.5,"text":" Good morning."}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	tr, err := TranslateAudio(context.Background(), p, File{MimeType: "audio/mpeg", Data: []byte("ID3")}, WithLanguage("fi"))
+	if err != nil {
+		t.Fatalf("TranslateAudio() error = %v", err)
+	}
+	if tr.Text != "Good morning." || tr.Language != "en" || len(tr.Segments) != 1 {
+		t.Errorf("Transcript = %+v", tr)
+	}
+}
+
+func TestTranslateAudio_Google(t *testing.T) {
+	var got googleRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		answer, _ := json.Marshal(`{"language":"de","segments":[{"start":0,"end":2,"text":"Good day."}]}`)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":` + string(answer) + `}]}}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Google, APIKey: "test-key", BaseURL: server.URL}
+	tr, err := TranslateAudio(context.Background(), p, File{MimeType: "audio/mpeg", Data: []byte("ID3")}, WithLanguage("de"))
+	if err != nil {
+		t.Fatalf("TranslateAudio() error = %v", err)
+	}
+	if text := got.Contents[0].Parts[1].Text; text != "Translate this audio into English. The speech is in German." {
+		t.Errorf("instruction = %q", text)
+	}
+	if sys := got.SystemInstruct; sys == nil || !strings.Contains(sys.Parts[0].Text, "translate the speech") {
+		t.Errorf("system = %+v", sys)
+	}
+	if tr.Text != "Good day." || tr.Language != "en" {
+		t.Errorf("Transcript = %+v", tr)
+	}
+}
+
+func TestTranscribeReader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		if _, h, _ := r.FormFile("file"); h.Filename != "note.m4a" {
+			t.Errorf("filename = %s", h.Filename)
+		}
+		w.Write([]byte(`{"text":"Buy milk."}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	tr, err := TranscribeReader(context.Background(), p, strings.NewReader("ftyp"), "note.m4a")
+	if err != nil || tr.Text != "Buy milk." {
+		t.Errorf("TranscribeReader() = %+v, %v", tr, err)
+	}
+}
+
+func TestJoinOverlapping(t *testing.T) {
+	tests := []struct{ a, b, want string }{
+		{"We shipped the release on Friday.", "on friday, and then we rested.", "We shipped the release on Friday. and then we rested."},
+		{"No overlap here.", "Next part.", "No overlap here. Next part."},
+		{"Ends with the", "the start", "Ends with the the start"}, // one word is not enough
+		{"", "First.", "First."},
+	}
+	for _, tt := range tests {
+		if got := joinOverlapping(tt.a, tt.b); got != tt.want {
+			t.Errorf("joinOverlapping(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTranscribe_Validation(t *testing.T) {
+	ctx := context.Background()
+	var ve *ValidationError
+
+	_, err := Transcribe(ctx, Provider{Name: Anthropic, APIKey: "k"}, File{Data: []byte("x")})
+	if !errors.As(err, &ve) || ve.Field != "provider" {
+		t.Errorf("error = %v, want provider ValidationError", err)
+	}
+	_, err = Transcribe(ctx, Provider{Name: OpenAI, APIKey: "k"}, File{URI: "https://example.com/a.mp3"})
+	if !errors.As(err, &ve) || ve.Field != "audio" {
+		t.Errorf("error = %v, want audio ValidationError", err)
+	}
+}
@@ -0,0 +1,120 @@
+package llmkit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Feedback is a rated transcript used to tune a system prompt.
+type Feedback struct {
+	Input   string  `json:"input"`
+	Output  string  `json:"output"`
+	Rating  float64 `json:"rating"` // higher is better, on any consistent scale
+	Comment string  `json:"comment,omitempty"`
+}
+
+// EvalFunc scores a system prompt, typically by running an eval suite against
+// it. Higher is better.
+type EvalFunc func(ctx context.Context, system string) (float64, error)
+
+// TuningCandidate is one proposed prompt and its eval score.
+type TuningCandidate struct {
+	Prompt string
+	Score  float64
+	Err    error // the proposal or its eval failed
+}
+
+// PromptTuning is the outcome of TunePrompt.
+type PromptTuning struct {
+	Prompt     string  // best prompt found; the original when nothing scored higher
+	Score      float64 // eval score of Prompt
+	Baseline   float64 // eval score of the original prompt
+	Improved   bool
+	Candidates []TuningCandidate // every proposal, in order
+}
+
+// TunePrompt improves a system prompt from rated transcripts. Each round asks p
+// to revise the best prompt so far in light of feedback, scores the revision
+// with eval, and keeps it only if it beats the best score. The original prompt
+// is scored first, so the result states whether the metrics improved.
+func TunePrompt(ctx context.Context, p Provider, system string, feedback []Feedback, eval EvalFunc, rounds int, opts ...Option) (PromptTuning, error) {
+	if len(feedback) == 0 {
+		return PromptTuning{}, &ValidationError{Field: "feedback", Message: "required"}
+	}
+	if eval == nil {
+		return PromptTuning{}, &ValidationError{Field: "eval", Message: "required"}
+	}
+	if rounds <= 0 {
+		return PromptTuning{}, &ValidationError{Field: "rounds", Message: "must be positive"}
+	}
+
+	baseline, err := eval(ctx, system)
+	if err != nil {
+		return PromptTuning{}, fmt.Errorf("eval original prompt: %w", err)
+	}
+	result := PromptTuning{Prompt: system, Score: baseline, Baseline: baseline}
+
+	for range rounds {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		revised, err := PromptInto[struct {
+			Prompt string `json:"prompt" description:"the complete revised system prompt"`
+		}](ctx, p, Request{System: tunerSystem, User: tuningRequest(result.Prompt, feedback)}, opts...)
+		if err != nil {
+			result.Candidates = append(result.Candidates, TuningCandidate{Err: err})
+			continue
+		}
+
+		c := TuningCandidate{Prompt: revised.Prompt}
+		c.Score, c.Err = eval(ctx, c.Prompt)
+		result.Candidates = append(result.Candidates, c)
+		if c.Err == nil && c.Score > result.Score {
+			result.Prompt, result.Score = c.Prompt, c.Score
+		}
+	}
+	result.Improved = result.Score > result.Baseline
+	return result, nil
+}
+
+const tunerSystem = "You revise system prompts for LLM applications. Given a prompt and rated " +
+	"transcripts produced with it, rewrite the prompt so that low-rated behavior is fixed and " +
+	"high-rated behavior is kept. Keep instructions that work, be specific, and do not add " +
+	"examples copied from the transcripts."
+
+// tuningRequest formats the current prompt and feedback for the tuner, worst rated first.
+func tuningRequest(system string, feedback []Feedback) string {
+	sorted := append([]Feedback(nil), feedback...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Rating < sorted[j].Rating })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Current prompt:\n<prompt>\n%s\n</prompt>\n\nRated transcripts:\n", system)
+	for i, f := range sorted {
+		fmt.Fprintf(&b, "\n<transcript %d rating=%g>\nInput: %s\nOutput: %s\n", i+1, f.Rating, f.Input, f.Output)
+		if f.Comment != "" {
+			fmt.Fprintf(&b, "Reviewer: %s\n", f.Comment)
+		}
+		b.WriteString("</transcript>\n")
+	}
+	return b.String()
+}
+
+// RegressionEval returns an EvalFunc that replays cases against p with the
+// candidate system prompt and scores the fraction whose output stays at least
+// minSimilarity to the baseline (see RunRegression).
+func RegressionEval(p Provider, cases []RegressionCase, minSimilarity float64, opts ...Option) EvalFunc {
+	return func(ctx context.Context, system string) (float64, error) {
+		if len(cases) == 0 {
+			return 0, &ValidationError{Field: "cases", Message: "required"}
+		}
+		run := make([]RegressionCase, len(cases))
+		for i, c := range cases {
+			c.Request.System = system
+			run[i] = c
+		}
+		report := RunRegression(ctx, p, run, opts...)
+		return 1 - float64(len(report.Changed(minSimilarity)))/float64(len(cases)), nil
+	}
+}
@@ -0,0 +1,95 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTunePrompt(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"content":[{"type":"text","text":"{\"prompt\":\"Be rude.\"}"}],"usage":{"input_tokens":50,"output_tokens":5}}`,
+		`{"content":[{"type":"text","text":"{\"prompt\":\"Be helpful and concise.\"}"}],"usage":{"input_tokens":50,"output_tokens":6}}`,
+	}}
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	feedback := []Feedback{
+		{Input: "Hi", Output: "Hello! How can I help?", Rating: 5},
+		{Input: "Explain DNS", Output: "(2000 words)", Rating: 1, Comment: "far too long"},
+	}
+	eval := func(ctx context.Context, system string) (float64, error) {
+		score := 0.5
+		if strings.Contains(system, "concise") {
+			score += 0.3
+		}
+		if strings.Contains(system, "rude") {
+			score -= 0.4
+		}
+		return score, nil
+	}
+
+	got, err := TunePrompt(context.Background(), p, "Be helpful.", feedback, eval, 2,
+		WithHTTPClient(&http.Client{Transport: mock}))
+	if err != nil {
+		t.Fatalf("TunePrompt() error = %v", err)
+	}
+	if got.Prompt != "Be helpful and concise." || !got.Improved || got.Baseline != 0.5 || got.Score != 0.8 {
+		t.Errorf("result = %+v", got)
+	}
+	if len(got.Candidates) != 2 || got.Candidates[0].Score >= got.Baseline {
+		t.Errorf("candidates = %+v", got.Candidates)
+	}
+
+	// The tuner sees the best prompt so far and the worst-rated transcript first
+	first := mock.bodies[0]
+	if !strings.Contains(first, "Be helpful.") || strings.Index(first, "far too long") > strings.Index(first, "How can I help") {
+		t.Errorf("tuner request = %s", first)
+	}
+}
+
+func TestTunePrompt_NoImprovement(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"content":[{"type":"text","text":"{\"prompt\":\"Worse.\"}"}],"usage":{"input_tokens":5,"output_tokens":2}}`,
+	}}
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	eval := func(ctx context.Context, system string) (float64, error) { return float64(len(system)), nil }
+
+	got, err := TunePrompt(context.Background(), p, "The original prompt.", []Feedback{{Input: "a", Output: "b", Rating: 2}},
+		eval, 1, WithHTTPClient(&http.Client{Transport: mock}))
+	if err != nil {
+		t.Fatalf("TunePrompt() error = %v", err)
+	}
+	if got.Improved || got.Prompt != "The original prompt." {
+		t.Errorf("result = %+v", got)
+	}
+}
+
+func TestTunePrompt_Validation(t *testing.T) {
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	eval := func(context.Context, string) (float64, error) { return 0, nil }
+	var ve *ValidationError
+	if _, err := TunePrompt(context.Background(), p, "x", nil, eval, 1); !errors.As(err, &ve) || ve.Field != "feedback" {
+		t.Errorf("error = %v, want feedback ValidationError", err)
+	}
+	if _, err := TunePrompt(context.Background(), p, "x", []Feedback{{}}, eval, 0); !errors.As(err, &ve) || ve.Field != "rounds" {
+		t.Errorf("error = %v, want rounds ValidationError", err)
+	}
+}
+
+func TestRegressionEval(t *testing.T) {
+	mock := &scriptedTransport{responses: []string{
+		`{"content":[{"type":"text","text":"Paris"}],"usage":{"input_tokens":5,"output_tokens":1}}`,
+	}}
+	p := Provider{Name: Anthropic, APIKey: "test-key"}
+	cases := []RegressionCase{{Name: "capital", Request: Request{User: "Capital of France?"}, Baseline: "Paris"}}
+
+	eval := RegressionEval(p, cases, 0.9, WithHTTPClient(&http.Client{Transport: mock}))
+	score, err := eval(context.Background(), "Answer in one word.")
+	if err != nil || score != 1 {
+		t.Errorf("eval() = %v, %v, want 1", score, err)
+	}
+	if !strings.Contains(mock.bodies[0], "Answer in one word.") {
+		t.Errorf("request = %s, want candidate system prompt", mock.bodies[0])
+	}
+}
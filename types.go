@@ -1,27 +1,91 @@
 package llmkit
 
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
 // Provider constants
 const (
-	Anthropic = "anthropic"
-	OpenAI    = "openai"
-	Google    = "google"
-	Grok      = "grok"
+	Anthropic  = "anthropic"
+	OpenAI     = "openai"
+	Google     = "google"
+	Grok       = "grok"
+	Ollama     = "ollama"
+	OpenRouter = "openrouter"
+
+	// OpenAICompatible targets any server speaking the OpenAI chat completions API
+	// (vLLM, LM Studio, Together, Groq, ...). BaseURL and Model are required.
+	OpenAICompatible = "openai-compatible"
 )
 
 // Default models per provider
 var defaultModels = map[string]string{
-	Anthropic: "claude-sonnet-4-5",
-	OpenAI:    "gpt-4o-2024-08-06",
-	Google:    "gemini-2.5-flash",
-	Grok:      "grok-3-fast",
+	Anthropic:  "claude-sonnet-4-5",
+	OpenAI:     "gpt-4o-2024-08-06",
+	Google:     "gemini-2.5-flash",
+	Grok:       "grok-3-fast",
+	Ollama:     "llama3.2",
+	OpenRouter: "openai/gpt-4o-mini",
+}
+
+// modelOverrides replaces defaultModels per provider, from SetDefaultModel or
+// LLMKIT_<PROVIDER>_MODEL environment variables.
+var modelOverrides = struct {
+	sync.RWMutex
+	m map[string]string
+}{m: map[string]string{}}
+
+// SetDefaultModel changes the model used for provider when Provider.Model is
+// empty, e.g. to adopt a new release before llmkit's built-in default moves.
+// An empty model restores the built-in default. It overrides the
+// LLMKIT_<PROVIDER>_MODEL environment variable, such as LLMKIT_ANTHROPIC_MODEL.
+func SetDefaultModel(provider, model string) {
+	modelOverrides.Lock()
+	defer modelOverrides.Unlock()
+	if model == "" {
+		delete(modelOverrides.m, provider)
+		return
+	}
+	modelOverrides.m[provider] = model
+}
+
+// DefaultModel returns the model used for provider when Provider.Model is empty.
+func DefaultModel(provider string) string {
+	modelOverrides.RLock()
+	defer modelOverrides.RUnlock()
+	if m, ok := modelOverrides.m[provider]; ok {
+		return m
+	}
+	return defaultModels[provider]
 }
 
 // Provider configures which LLM to use.
 type Provider struct {
-	Name    string // "anthropic", "openai", "google", "grok"
-	APIKey  string
-	Model   string // optional, uses default if empty
-	BaseURL string // optional, overrides default API endpoint
+	Name    string   // "anthropic", "openai", "google", "grok", "ollama", "openrouter", "openai-compatible"
+	APIKey  string   // not required for ollama and openai-compatible
+	Keys    *KeyPool // optional: rotates among several API keys instead of APIKey
+	Model   string   // optional, uses default if empty; OpenRouter takes "vendor/model"
+	BaseURL string   // optional, overrides default API endpoint
+
+	// KeyProvider looks up the API key at request time instead of APIKey,
+	// e.g. per tenant or from a secret manager (optional)
+	KeyProvider KeyProvider
+
+	// OpenAI only: billing organization and project (OpenAI-Organization / OpenAI-Project headers)
+	Organization string
+	Project      string
+
+	// OpenRouter only: app attribution for openrouter.ai rankings (HTTP-Referer / X-Title headers)
+	AppURL  string
+	AppName string
+
+	// Anthropic only: call Claude through Google Cloud Vertex AI (optional)
+	Vertex *VertexAI
+
+	// Headers are sent with every request, e.g. gateway auth (see Helicone)
+	Headers map[string]string
 }
 
 // model returns the configured model or the default for the provider.
@@ -29,23 +93,30 @@ func (p Provider) model() string {
 	if p.Model != "" {
 		return p.Model
 	}
-	return defaultModels[p.Name]
+	return DefaultModel(p.Name)
 }
 
 // Default base URLs per provider
 var defaultBaseURLs = map[string]string{
-	Anthropic: "https://api.anthropic.com",
-	OpenAI:    "https://api.openai.com",
-	Google:    "https://generativelanguage.googleapis.com",
-	Grok:      "https://api.x.ai",
+	Anthropic:  "https://api.anthropic.com",
+	OpenAI:     "https://api.openai.com",
+	Google:     "https://generativelanguage.googleapis.com",
+	Grok:       "https://api.x.ai",
+	Ollama:     "http://localhost:11434",
+	OpenRouter: "https://openrouter.ai/api",
 }
 
 // buildURL constructs the full URL using custom BaseURL or default.
+// A BaseURL that already ends in the path's version segment (e.g.
+// "https://api.together.xyz/v1") is not given a second one.
 func (p Provider) buildURL(path string) string {
-	base := p.BaseURL
+	base := strings.TrimSuffix(p.BaseURL, "/")
 	if base == "" {
 		base = defaultBaseURLs[p.Name]
 	}
+	if version, _, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/"); ok && strings.HasSuffix(base, "/"+version) {
+		base = strings.TrimSuffix(base, "/"+version)
+	}
 	return base + path
 }
 
@@ -53,6 +124,8 @@ func (p Provider) buildURL(path string) string {
 type Message struct {
 	Role    string // "user" or "assistant"
 	Content string
+	Images  []Image // shown with Content in user messages
+	Files   []File  // documents or audio clips, likewise
 }
 
 // Request contains the input for an LLM call.
@@ -63,26 +136,99 @@ type Request struct {
 	Schema   string    // JSON schema for structured output (optional)
 	Files    []File    // file attachments (optional)
 	Images   []Image   // image inputs (optional)
+	Tools    []Tool    // tools Prompt may run before answering (optional, text only)
 }
 
 // Response contains the LLM output.
 type Response struct {
-	Text   string
-	Tokens Usage
+	Text        string
+	Tokens      Usage
+	Cost        float64    // estimated US dollars from the pricing table; 0 when the model has no price
+	RequestID   string     // client-side correlation ID
+	ServiceTier string     // tier that served the request, when reported (Anthropic: "standard", "priority")
+	RateLimit   *RateLimit // rate-limit state from response headers, when reported
+	Seed        *int64     // seed sent with WithSeed
+
+	// SystemFingerprint identifies the backend configuration that served the
+	// request, when reported (OpenAI system_fingerprint, Gemini modelVersion).
+	// Seeded outputs are only expected to repeat while it stays the same.
+	SystemFingerprint string
+
+	// FinishReason is why generation stopped; "" when the provider did not say.
+	// FinishLength means Text was truncated and may be retried with WithMaxTokens.
+	FinishReason FinishReason
+
+	// Segments splits Text into prose and code blocks when the ExtractCode
+	// post-processor is used (see WithPostProcess).
+	Segments []Segment
+}
+
+// FinishReason is why the model stopped generating, normalized across providers.
+type FinishReason string
+
+const (
+	FinishStop          FinishReason = "stop"           // natural end or stop sequence
+	FinishLength        FinishReason = "length"         // max tokens reached
+	FinishToolUse       FinishReason = "tool_use"       // the model requested tool calls
+	FinishContentFilter FinishReason = "content_filter" // output withheld or cut by safety filtering
+	FinishOther         FinishReason = "other"          // a provider reason with no normalized equivalent
+)
+
+// RateLimit is the provider's rate-limit state after a request.
+// Fields the provider did not report are zero.
+type RateLimit struct {
+	RequestsLimit     int
+	RequestsRemaining int
+	RequestsReset     time.Time
+	TokensLimit       int
+	TokensRemaining   int
+	TokensReset       time.Time
+
+	// Anthropic Priority Tier capacity
+	PriorityInputTokensRemaining  int
+	PriorityOutputTokensRemaining int
 }
 
 // Usage tracks token consumption.
+// The detail counts are set where the provider reports them; how they relate to
+// Input and Output follows the provider (e.g. Anthropic's Input excludes cached tokens,
+// OpenAI's includes them).
 type Usage struct {
 	Input  int
 	Output int
+
+	CacheReadTokens  int // input tokens served from the prompt cache
+	CacheWriteTokens int // input tokens written to the prompt cache (Anthropic)
+	ReasoningTokens  int // output tokens spent on reasoning/thinking
+	AudioTokens      int // input and output audio tokens (OpenAI)
+}
+
+// Add returns the field-wise sum of u and v.
+func (u Usage) Add(v Usage) Usage {
+	return Usage{
+		Input:            u.Input + v.Input,
+		Output:           u.Output + v.Output,
+		CacheReadTokens:  u.CacheReadTokens + v.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + v.CacheWriteTokens,
+		ReasoningTokens:  u.ReasoningTokens + v.ReasoningTokens,
+		AudioTokens:      u.AudioTokens + v.AudioTokens,
+	}
 }
 
-// File represents an uploaded file reference.
+// File represents an uploaded file reference or an inline document.
+// When Data is set and ID/URI are empty, the file is sent inline (base64)
+// instead of by reference, avoiding a separate upload round-trip.
 type File struct {
 	ID       string
 	URI      string
 	MimeType string
 	Name     string
+	Data     []byte // inline content (optional)
+}
+
+// inline reports whether the file should be sent as inline data.
+func (f File) inline() bool {
+	return f.ID == "" && f.URI == "" && len(f.Data) > 0
 }
 
 // Image represents an image input.
@@ -92,6 +238,9 @@ type Image struct {
 	Detail   string // "auto", "low", "high" (provider-specific)
 }
 
+// StreamFunc receives incremental text as the model generates it.
+type StreamFunc func(delta string)
+
 // Tool defines a function the LLM can call.
 type Tool struct {
 	Name        string
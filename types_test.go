@@ -33,6 +33,12 @@ func TestProviderBuildURL(t *testing.T) {
 			path:     "/v1/messages",
 			want:     "https://proxy.example.com/v1/messages",
 		},
+		{
+			name:     "base url with version segment",
+			provider: Provider{Name: OpenAICompatible, BaseURL: "https://api.groq.com/openai/v1/", Model: "llama-3.3-70b"},
+			path:     "/v1/chat/completions",
+			want:     "https://api.groq.com/openai/v1/chat/completions",
+		},
 	}
 
 	for _, tt := range tests {
@@ -92,3 +98,13 @@ func TestProviderModel(t *testing.T) {
 		})
 	}
 }
+
+func TestUsageAdd(t *testing.T) {
+	a := Usage{Input: 10, Output: 5, CacheReadTokens: 4, ReasoningTokens: 2}
+	b := Usage{Input: 1, Output: 2, CacheWriteTokens: 3, AudioTokens: 6}
+
+	want := Usage{Input: 11, Output: 7, CacheReadTokens: 4, CacheWriteTokens: 3, ReasoningTokens: 2, AudioTokens: 6}
+	if got := a.Add(b); got != want {
+		t.Errorf("Add() = %+v, want %+v", got, want)
+	}
+}
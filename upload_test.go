@@ -184,6 +184,8 @@ func TestDetectMimeType(t *testing.T) {
 		{"test.jpeg", "image/jpeg"},
 		{"test.gif", "image/gif"},
 		{"test.webp", "image/webp"},
+		{"test.mp3", "audio/mpeg"},
+		{"test.M4A", "audio/mp4"},
 		{"test.md", "text/markdown"},
 		{"test.csv", "text/csv"},
 		{"unknown", "application/octet-stream"},
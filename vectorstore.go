@@ -0,0 +1,138 @@
+package llmkit
+
+import (
+	"context"
+	"time"
+)
+
+const defaultVectorStorePollInterval = 2 * time.Second
+
+// VectorStore is an OpenAI vector store: uploaded files chunked and embedded
+// for the file_search tool (see WithFileSearch).
+type VectorStore struct {
+	ID        string
+	Name      string
+	Status    string // "in_progress", "completed" or "expired"
+	Total     int    // files attached
+	Completed int    // files ready to search
+	Failed    int
+}
+
+// Ready reports whether ingestion has finished, successfully or not.
+func (v VectorStore) Ready() bool {
+	return v.Status != "in_progress"
+}
+
+// VectorStoreHit is a chunk of a file returned by SearchVectorStore.
+type VectorStoreHit struct {
+	FileID   string
+	Filename string
+	Score    float64 // relevance, 0 to 1
+	Text     string
+}
+
+// CreateVectorStore creates a vector store named name from files uploaded with
+// UploadFile. Ingestion continues in the background; files become searchable
+// once processed (see WaitVectorStore). OpenAI only.
+func CreateVectorStore(ctx context.Context, p Provider, name string, files []File, opts ...Option) (VectorStore, error) {
+	o := applyOptions(opts...)
+	if err := validateVectorStores(p); err != nil {
+		return VectorStore{}, err
+	}
+	ids, err := uploadedFileIDs(files)
+	if err != nil {
+		return VectorStore{}, err
+	}
+	return withPoolKey(ctx, p, o, func(p Provider) (VectorStore, error) {
+		return createVectorStoreOpenAI(ctx, p, name, ids, o)
+	})
+}
+
+// AddVectorStoreFiles attaches more uploaded files to a vector store.
+// Ingestion continues in the background.
+func AddVectorStoreFiles(ctx context.Context, p Provider, id string, files []File, opts ...Option) error {
+	o := applyOptions(opts...)
+	if err := validateVectorStores(p); err != nil {
+		return err
+	}
+	ids, err := uploadedFileIDs(files)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return &ValidationError{Field: "files", Message: "required"}
+	}
+	_, err = withPoolKey(ctx, p, o, func(p Provider) (struct{}, error) {
+		return struct{}{}, addVectorStoreFilesOpenAI(ctx, p, id, ids, o)
+	})
+	return err
+}
+
+// GetVectorStore returns the current state of a vector store.
+func GetVectorStore(ctx context.Context, p Provider, id string, opts ...Option) (VectorStore, error) {
+	o := applyOptions(opts...)
+	if err := validateVectorStores(p); err != nil {
+		return VectorStore{}, err
+	}
+	return withPoolKey(ctx, p, o, func(p Provider) (VectorStore, error) {
+		return getVectorStoreOpenAI(ctx, p, id, o)
+	})
+}
+
+// WaitVectorStore polls a vector store every interval (2s when 0) until its
+// files are ingested or ctx ends.
+func WaitVectorStore(ctx context.Context, p Provider, id string, interval time.Duration, opts ...Option) (VectorStore, error) {
+	o := applyOptions(opts...)
+	if interval <= 0 {
+		interval = defaultVectorStorePollInterval
+	}
+	for {
+		v, err := GetVectorStore(ctx, p, id, opts...)
+		if err != nil || v.Ready() {
+			return v, err
+		}
+		select {
+		case <-o.clock.After(interval):
+		case <-ctx.Done():
+			return v, ctx.Err()
+		}
+	}
+}
+
+// SearchVectorStore returns up to limit chunks (the provider's default when 0)
+// most relevant to query, without calling a model.
+func SearchVectorStore(ctx context.Context, p Provider, id, query string, limit int, opts ...Option) ([]VectorStoreHit, error) {
+	o := applyOptions(opts...)
+	if err := validateVectorStores(p); err != nil {
+		return nil, err
+	}
+	if query == "" {
+		return nil, &ValidationError{Field: "query", Message: "required"}
+	}
+	return withPoolKey(ctx, p, o, func(p Provider) ([]VectorStoreHit, error) {
+		return searchVectorStoreOpenAI(ctx, p, id, query, limit, o)
+	})
+}
+
+// validateVectorStores checks that p supports vector stores.
+func validateVectorStores(p Provider) error {
+	if err := validateProvider(p); err != nil {
+		return err
+	}
+	if p.Name != OpenAI {
+		return &ValidationError{Field: "provider", Message: "vector stores not supported by " + p.Name}
+	}
+	return nil
+}
+
+// uploadedFileIDs returns the IDs of files uploaded with UploadFile.
+func uploadedFileIDs(files []File) ([]string, error) {
+	ids := make([]string, len(files))
+	for i, f := range files {
+		if f.ID == "" {
+			return nil, &ValidationError{Field: "files", Message: "must be uploaded with UploadFile first"}
+		}
+		ids[i] = f.ID
+	}
+	return ids, nil
+}
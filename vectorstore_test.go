@@ -0,0 +1,152 @@
+package llmkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCreateVectorStore(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/vector_stores" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"id":"vs_1","name":"docs","status":"in_progress","file_counts":{"in_progress":2,"completed":0,"failed":0,"total":2}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	vs, err := CreateVectorStore(context.Background(), p, "docs", []File{{ID: "file-a"}, {ID: "file-b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VectorStore{ID: "vs_1", Name: "docs", Status: "in_progress", Total: 2}
+	if vs != want {
+		t.Errorf("vector store = %+v, want %+v", vs, want)
+	}
+	if ids, _ := got["file_ids"].([]any); len(ids) != 2 || ids[1] != "file-b" {
+		t.Errorf("file_ids = %v", got["file_ids"])
+	}
+}
+
+func TestVectorStore_AddWaitSearch(t *testing.T) {
+	var polls atomic.Int32
+	var added, search map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v1/vector_stores/vs_1/file_batches":
+			json.NewDecoder(r.Body).Decode(&added)
+			w.Write([]byte(`{"id":"vsfb_1","status":"in_progress"}`))
+		case "GET /v1/vector_stores/vs_1":
+			status := "in_progress"
+			if polls.Add(1) > 1 {
+				status = "completed"
+			}
+			w.Write([]byte(`{"id":"vs_1","status":"` + status + `","file_counts":{"completed":1,"total":1}}`))
+		case "POST /v1/vector_stores/vs_1/search":
+			json.NewDecoder(r.Body).Decode(&search)
+			w.Write([]byte(`{"data":[{"file_id":"file-a","filename":"handbook.pdf","score":0.82,` +
+				`"content":[{"type":"text","text":"Refunds take "},{"type":"text","text":"5 days."}]}]}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	if err := AddVectorStoreFiles(ctx, p, "vs_1", []File{{ID: "file-a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := added["file_ids"].([]any); len(ids) != 1 || ids[0] != "file-a" {
+		t.Errorf("file_ids = %v", added["file_ids"])
+	}
+
+	vs, err := WaitVectorStore(ctx, p, "vs_1", time.Millisecond)
+	if err != nil || !vs.Ready() || vs.Completed != 1 || polls.Load() != 2 {
+		t.Fatalf("WaitVectorStore() = %+v, %v after %d polls", vs, err, polls.Load())
+	}
+
+	hits, err := SearchVectorStore(ctx, p, "vs_1", "refund window", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VectorStoreHit{FileID: "file-a", Filename: "handbook.pdf", Score: 0.82, Text: "Refunds take 5 days."}
+	if len(hits) != 1 || hits[0] != want {
+		t.Errorf("hits = %+v, want %+v", hits, want)
+	}
+	if search["query"] != "refund window" || search["max_num_results"] != float64(3) {
+		t.Errorf("search request = %v", search)
+	}
+}
+
+func TestCreateVectorStore_Validation(t *testing.T) {
+	ctx := context.Background()
+	var ve *ValidationError
+	_, err := CreateVectorStore(ctx, Provider{Name: Anthropic, APIKey: "k"}, "docs", nil)
+	if !errors.As(err, &ve) || ve.Field != "provider" {
+		t.Errorf("anthropic error = %v", err)
+	}
+	_, err = CreateVectorStore(ctx, Provider{Name: OpenAI, APIKey: "k"}, "docs", []File{{Data: []byte("x")}})
+	if !errors.As(err, &ve) || ve.Field != "files" {
+		t.Errorf("inline file error = %v", err)
+	}
+	_, err = SearchVectorStore(ctx, Provider{Name: OpenAI, APIKey: "k"}, "vs_1", "", 0)
+	if !errors.As(err, &ve) || ve.Field != "query" {
+		t.Errorf("empty query error = %v", err)
+	}
+}
+
+func TestPrompt_FileSearch(t *testing.T) {
+	var got openaiResponsesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/responses" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"output":[{"type":"file_search_call","status":"completed"},` +
+			`{"type":"message","content":[{"type":"output_text","text":"Refunds take 5 days."}]}],` +
+			`"usage":{"input_tokens":900,"output_tokens":12,"input_tokens_details":{"cached_tokens":0},"output_tokens_details":{"reasoning_tokens":0}}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "test-key", BaseURL: server.URL}
+	agent := NewAgent(p, WithFileSearch("vs_1"))
+	agent.SetSystem("Answer from the handbook.")
+	resp, err := agent.Chat(context.Background(), "How long do refunds take?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Refunds take 5 days." || resp.Tokens.Input != 900 {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Type != "file_search" || got.Tools[0].VectorStoreIDs[0] != "vs_1" {
+		t.Errorf("tools = %+v", got.Tools)
+	}
+	if got.Instructions != "Answer from the handbook." || got.Input[0].Content[0].Text != "How long do refunds take?" {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestFileSearch_Validation(t *testing.T) {
+	ctx := context.Background()
+	var ve *ValidationError
+	_, err := Prompt(ctx, Provider{Name: Google, APIKey: "k"}, Request{User: "hi"}, WithFileSearch("vs_1"))
+	if !errors.As(err, &ve) || ve.Field != "file_search" {
+		t.Errorf("google error = %v", err)
+	}
+
+	agent := NewAgent(Provider{Name: OpenAI, APIKey: "k"}, WithFileSearch("vs_1"))
+	agent.AddTool(testWeatherTool())
+	_, err = agent.Chat(ctx, "hi")
+	if !errors.As(err, &ve) || ve.Field != "file_search" {
+		t.Errorf("agent with tools error = %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package llmkit
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// vertexAnthropicVersion replaces the anthropic-version header on Vertex AI.
+	vertexAnthropicVersion = "vertex-2023-10-16"
+	googleTokenURL         = "https://oauth2.googleapis.com/token"
+	cloudPlatformScope     = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// VertexAI routes Anthropic requests through Google Cloud's Vertex AI instead
+// of api.anthropic.com. Provider.APIKey is not used.
+type VertexAI struct {
+	Project string      // Google Cloud project ID
+	Region  string      // e.g. "us-east5", or "global"
+	Token   TokenSource // OAuth2 access tokens, e.g. from GoogleCredentials
+}
+
+// TokenSource returns a valid OAuth2 access token. Implementations should
+// cache tokens until they expire; golang.org/x/oauth2 token sources adapt as
+// func(ctx context.Context) (string, error) { t, err := ts.Token(); return t.AccessToken, err }.
+type TokenSource func(ctx context.Context) (string, error)
+
+// vertexDated matches the date suffix of Anthropic model IDs ("claude-sonnet-4-5-20250929").
+var vertexDated = regexp.MustCompile(`-(\d{8})$`)
+
+// vertexModel maps an Anthropic model ID to Vertex AI's "name@date" form.
+// Undated aliases are passed through.
+func vertexModel(model string) string {
+	return vertexDated.ReplaceAllString(model, "@$1")
+}
+
+// vertexURL returns the rawPredict (or streamRawPredict) endpoint for model.
+// Provider.BaseURL, when set, replaces the regional host.
+func vertexURL(p Provider, stream bool) string {
+	v := p.Vertex
+	base := strings.TrimSuffix(p.BaseURL, "/")
+	if base == "" {
+		base = "https://" + v.Region + "-aiplatform.googleapis.com"
+		if v.Region == "global" {
+			base = "https://aiplatform.googleapis.com"
+		}
+	}
+	method := "rawPredict"
+	if stream {
+		method = "streamRawPredict"
+	}
+	return fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/anthropic/models/%s:%s",
+		base, url.PathEscape(v.Project), url.PathEscape(v.Region), url.PathEscape(vertexModel(p.model())), method)
+}
+
+// vertexRequest adapts an Anthropic Messages API request for Vertex AI: the model
+// moves into the URL, the version into the body, and auth becomes an OAuth token.
+func vertexRequest(ctx context.Context, p Provider, payload anthropicRequest, headers map[string]string) (anthropicRequest, map[string]string, error) {
+	token, err := p.Vertex.Token(ctx)
+	if err != nil {
+		return anthropicRequest{}, nil, fmt.Errorf("vertex token: %w", err)
+	}
+	payload.Model = ""
+	payload.AnthropicVersion = vertexAnthropicVersion
+
+	h := map[string]string{"Authorization": "Bearer " + token}
+	for k, v := range headers {
+		if k != "x-api-key" && k != "anthropic-version" {
+			h[k] = v
+		}
+	}
+	return payload, h, nil
+}
+
+// validateVertex checks the Vertex AI settings of p.
+func validateVertex(p Provider) error {
+	v := p.Vertex
+	switch {
+	case p.Name != Anthropic:
+		return &ValidationError{Field: "vertex", Message: "not supported by " + p.Name}
+	case v.Project == "":
+		return &ValidationError{Field: "vertex.project", Message: "required"}
+	case v.Region == "":
+		return &ValidationError{Field: "vertex.region", Message: "required"}
+	case v.Token == nil:
+		return &ValidationError{Field: "vertex.token", Message: "required"}
+	}
+	return nil
+}
+
+// googleCredentials is a service account key or gcloud user credentials file.
+type googleCredentials struct {
+	Type         string `json:"type"` // "service_account" or "authorized_user"
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// GoogleCredentials returns a TokenSource for a service account key file or the
+// user credentials written by "gcloud auth application-default login". Tokens
+// carry the cloud-platform scope and are refreshed a minute before they expire.
+func GoogleCredentials(credentialsJSON []byte) (TokenSource, error) {
+	var c googleCredentials
+	if err := json.Unmarshal(credentialsJSON, &c); err != nil {
+		return nil, &ValidationError{Field: "credentials", Message: "invalid JSON: " + err.Error()}
+	}
+	if c.TokenURI == "" {
+		c.TokenURI = googleTokenURL
+	}
+
+	var form func(now time.Time) (url.Values, error)
+	switch c.Type {
+	case "service_account":
+		key, err := parseRSAKey(c.PrivateKey)
+		if err != nil {
+			return nil, &ValidationError{Field: "credentials", Message: err.Error()}
+		}
+		form = func(now time.Time) (url.Values, error) {
+			assertion, err := signJWT(key, c.PrivateKeyID, map[string]any{
+				"iss":   c.ClientEmail,
+				"scope": cloudPlatformScope,
+				"aud":   c.TokenURI,
+				"iat":   now.Unix(),
+				"exp":   now.Add(time.Hour).Unix(),
+			})
+			return url.Values{
+				"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+				"assertion":  {assertion},
+			}, err
+		}
+	case "authorized_user":
+		form = func(time.Time) (url.Values, error) {
+			return url.Values{
+				"grant_type":    {"refresh_token"},
+				"client_id":     {c.ClientID},
+				"client_secret": {c.ClientSecret},
+				"refresh_token": {c.RefreshToken},
+			}, nil
+		}
+	default:
+		return nil, &ValidationError{Field: "credentials", Message: "unsupported type: " + c.Type}
+	}
+
+	var mu sync.Mutex
+	var token string
+	var expiry time.Time
+	return func(ctx context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		if token != "" && now.Before(expiry) {
+			return token, nil
+		}
+		values, err := form(now)
+		if err != nil {
+			return "", err
+		}
+		t, ttl, err := fetchGoogleToken(ctx, c.TokenURI, values)
+		if err != nil {
+			return "", err
+		}
+		token, expiry = t, now.Add(ttl-time.Minute)
+		return token, nil
+	}, nil
+}
+
+// fetchGoogleToken exchanges an OAuth2 grant for an access token and its lifetime.
+func fetchGoogleToken(ctx context.Context, tokenURI string, form url.Values) (string, time.Duration, error) {
+	headers := map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+	respBody, statusCode, err := doPostRaw(ctx, http.DefaultClient, tokenURI, []byte(form.Encode()), headers)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var resp struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil && statusCode < 400 {
+		return "", 0, err
+	}
+	if statusCode >= 400 || resp.AccessToken == "" {
+		return "", 0, &APIError{Provider: "google-oauth", StatusCode: statusCode, Type: resp.Error, Message: resp.ErrorDescription}
+	}
+	return resp.AccessToken, time.Duration(resp.ExpiresIn) * time.Second, nil
+}
+
+// parseRSAKey decodes a PEM-encoded PKCS#8 or PKCS#1 RSA private key.
+func parseRSAKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("private_key is not PEM")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("private_key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private_key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// signJWT returns an RS256-signed JWT with the given claims.
+func signJWT(key *rsa.PrivateKey, keyID string, claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,207 @@
+package llmkit
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func staticToken(token string) TokenSource {
+	return func(context.Context) (string, error) { return token, nil }
+}
+
+func TestPrompt_AnthropicVertex(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := "/v1/projects/my-proj/locations/us-east5/publishers/anthropic/models/claude-sonnet-4-5@20250929:rawPredict"
+		if r.URL.Path != want {
+			t.Errorf("path = %s, want %s", r.URL.Path, want)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer ya29.token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if r.Header.Get("x-api-key") != "" || r.Header.Get("anthropic-version") != "" {
+			t.Errorf("Anthropic API headers sent to Vertex: %v", r.Header)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"content":[{"type":"text","text":"Hello from Vertex"}],"usage":{"input_tokens":8,"output_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	p := Provider{
+		Name:    Anthropic,
+		Model:   "claude-sonnet-4-5-20250929",
+		BaseURL: server.URL,
+		Vertex:  &VertexAI{Project: "my-proj", Region: "us-east5", Token: staticToken("ya29.token")},
+	}
+	resp, err := Prompt(context.Background(), p, Request{User: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Hello from Vertex" || resp.Tokens.Input != 8 {
+		t.Errorf("response = %+v", resp)
+	}
+	if _, ok := body["model"]; ok {
+		t.Errorf("body has model: %v", body)
+	}
+	if body["anthropic_version"] != vertexAnthropicVersion {
+		t.Errorf("anthropic_version = %v", body["anthropic_version"])
+	}
+}
+
+func TestPromptStream_AnthropicVertex(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}` + "\n\n" +
+			`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}` + "\n\n" +
+			`data: {"type":"message_stop"}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: Anthropic, BaseURL: server.URL, Vertex: &VertexAI{Project: "p", Region: "global", Token: staticToken("t")}}
+	resp, err := PromptStream(context.Background(), p, Request{User: "Hi"}, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Hi" || !strings.HasSuffix(path, "/locations/global/publishers/anthropic/models/claude-sonnet-4-5:streamRawPredict") {
+		t.Errorf("text = %q, path = %s", resp.Text, path)
+	}
+}
+
+func TestVertexURL_Host(t *testing.T) {
+	p := Provider{Name: Anthropic, Model: "claude-opus-4-1-20250805", Vertex: &VertexAI{Project: "p", Region: "europe-west1"}}
+	want := "https://europe-west1-aiplatform.googleapis.com/v1/projects/p/locations/europe-west1/publishers/anthropic/models/claude-opus-4-1@20250805:rawPredict"
+	if got := vertexURL(p, false); got != want {
+		t.Errorf("vertexURL() = %s, want %s", got, want)
+	}
+}
+
+func TestVertex_Validation(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		p     Provider
+		field string
+	}{
+		{Provider{Name: OpenAI, Vertex: &VertexAI{Project: "p", Region: "r", Token: staticToken("t")}}, "vertex"},
+		{Provider{Name: Anthropic, Vertex: &VertexAI{Region: "r", Token: staticToken("t")}}, "vertex.project"},
+		{Provider{Name: Anthropic, Vertex: &VertexAI{Project: "p", Token: staticToken("t")}}, "vertex.region"},
+		{Provider{Name: Anthropic, Vertex: &VertexAI{Project: "p", Region: "r"}}, "vertex.token"},
+	}
+	for _, tt := range tests {
+		_, err := Prompt(ctx, tt.p, Request{User: "Hi"})
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != tt.field {
+			t.Errorf("Prompt(%+v) error = %v, want field %s", tt.p.Vertex, err, tt.field)
+		}
+	}
+
+	p := Provider{Name: Anthropic, Vertex: &VertexAI{Project: "p", Region: "r", Token: staticToken("t")}}
+	_, err := CreateBatch(ctx, p, []BatchRequest{{CustomID: "a", Request: Request{User: "Hi"}}})
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field != "vertex" {
+		t.Errorf("CreateBatch() error = %v", err)
+	}
+	if n, err := CountTokens(ctx, p, Request{User: "Hello there"}); err != nil || n == 0 {
+		t.Errorf("CountTokens() = %d, %v, want an estimate", n, err)
+	}
+}
+
+func TestGoogleCredentials_ServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, key)})
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("grant_type = %q", r.Form.Get("grant_type"))
+		}
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("assertion = %q", r.Form.Get("assertion"))
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+			t.Errorf("signature: %v", err)
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		if !strings.Contains(string(claims), `"iss":"svc@my-proj.iam.gserviceaccount.com"`) {
+			t.Errorf("claims = %s", claims)
+		}
+		w.Write([]byte(`{"access_token":"ya29.sa","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	creds, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "svc@my-proj.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    server.URL,
+	})
+	ts, err := GoogleCredentials(creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if token, err := ts(context.Background()); err != nil || token != "ya29.sa" {
+			t.Fatalf("token = %q, %v", token, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("token endpoint called %d times, want 1 (cached)", calls)
+	}
+}
+
+func TestGoogleCredentials_AuthorizedUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "1//refresh" {
+			t.Errorf("form = %v", r.Form)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`))
+	}))
+	defer server.Close()
+
+	creds := `{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"1//refresh","token_uri":"` + server.URL + `"}`
+	ts, err := GoogleCredentials([]byte(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Type != "invalid_grant" {
+		t.Errorf("error = %v, want invalid_grant", err)
+	}
+
+	if _, err := GoogleCredentials([]byte(`{"type":"external_account"}`)); err == nil {
+		t.Error("unsupported credential type accepted")
+	}
+}
+
+func mustPKCS8(t *testing.T, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
@@ -0,0 +1,182 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Task is one unit of work from an Inbox.
+type Task struct {
+	ID    string
+	Input string            // the message for the agent
+	Meta  map[string]string // adapter data, e.g. an SQS receipt handle
+}
+
+// TaskResult reports how a Task went.
+type TaskResult struct {
+	Task     Task
+	Response Response
+	Err      error
+	Duration time.Duration
+}
+
+// Inbox is a task queue consumed by RunWorker. Receive blocks until a task
+// is available and returns io.EOF once no more tasks will arrive. Complete is
+// called once per received task: adapters acknowledge it there (delete the SQS
+// message, remove it from a Redis processing list) and publish the result, or
+// leave a failed task for redelivery.
+type Inbox interface {
+	Receive(ctx context.Context) (Task, error)
+	Complete(ctx context.Context, r TaskResult) error
+}
+
+// TaskFunc processes one task.
+type TaskFunc func(ctx context.Context, t Task) (Response, error)
+
+// AgentTask returns a TaskFunc that sends each task's Input to a fresh agent
+// from newAgent, so tasks never share conversation history.
+func AgentTask(newAgent func(Task) *Agent) TaskFunc {
+	return func(ctx context.Context, t Task) (Response, error) {
+		return newAgent(t).Chat(ctx, t.Input)
+	}
+}
+
+// ChanInbox is an in-process Inbox: tasks arrive on Tasks and results, when
+// Results is non-nil, are sent to it. Close Tasks to stop the worker.
+type ChanInbox struct {
+	Tasks   <-chan Task
+	Results chan<- TaskResult
+}
+
+func (c ChanInbox) Receive(ctx context.Context) (Task, error) {
+	select {
+	case t, ok := <-c.Tasks:
+		if !ok {
+			return Task{}, io.EOF
+		}
+		return t, nil
+	case <-ctx.Done():
+		return Task{}, ctx.Err()
+	}
+}
+
+func (c ChanInbox) Complete(ctx context.Context, r TaskResult) error {
+	if c.Results == nil {
+		return nil
+	}
+	select {
+	case c.Results <- r:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WorkerStats summarizes a RunWorker run.
+type WorkerStats struct {
+	Received  int
+	Succeeded int
+	Failed    int
+	Tokens    Usage
+}
+
+// Backoff after a failed Receive, so a broken queue connection is not hammered.
+const (
+	workerMinBackoff = time.Second
+	workerMaxBackoff = 30 * time.Second
+)
+
+// RunWorker runs fn on tasks from inbox until the inbox returns io.EOF or ctx
+// is cancelled, turning an agent into a background worker. Tasks are processed
+// concurrently (see WithConcurrency), and a task is only received once a slot
+// is free. Each result goes to inbox.Complete, even after ctx is cancelled, so
+// adapters can release unfinished tasks. Failed Receive calls are logged (see
+// WithLogger) and retried with backoff; a panicking fn fails only its task.
+// When inbox is a Leaser, each running task's lease is renewed every
+// WithHeartbeat interval, and the task's ctx is cancelled if the lease is lost.
+func RunWorker(ctx context.Context, inbox Inbox, fn TaskFunc, opts ...Option) (WorkerStats, error) {
+	o := applyOptions(opts...)
+	var stats WorkerStats
+	var mu sync.Mutex
+	record := func(r TaskResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Err != nil {
+			stats.Failed++
+		} else {
+			stats.Succeeded++
+		}
+		stats.Tokens = stats.Tokens.Add(r.Response.Tokens)
+	}
+
+	sem := make(chan struct{}, max(o.concurrency, 1))
+	var wg sync.WaitGroup
+	backoff := workerMinBackoff
+	for ctx.Err() == nil {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+
+		task, err := inbox.Receive(ctx)
+		if err != nil {
+			<-sem
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				break
+			}
+			o.log(ctx, slog.LevelWarn, "llmkit worker receive failed", "error", err, "backoff", backoff)
+			select {
+			case <-o.clock.After(backoff):
+			case <-ctx.Done():
+			}
+			backoff = min(backoff*2, workerMaxBackoff)
+			continue
+		}
+		backoff = workerMinBackoff
+		mu.Lock()
+		stats.Received++
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			taskCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			if l, ok := inbox.(Leaser); ok {
+				go heartbeat(taskCtx, l, task, o, cancel)
+			}
+
+			start := o.clock.Now()
+			resp, err := runTask(taskCtx, fn, task)
+			cancel()
+			r := TaskResult{Task: task, Response: resp, Err: err, Duration: o.clock.Now().Sub(start)}
+			record(r)
+			if err != nil {
+				o.log(ctx, slog.LevelWarn, "llmkit worker task failed", "task", task.ID, "error", err)
+			}
+			if err := inbox.Complete(context.WithoutCancel(ctx), r); err != nil {
+				o.log(ctx, slog.LevelError, "llmkit worker complete failed", "task", task.ID, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+	return stats, ctx.Err()
+}
+
+// runTask calls fn, turning a panic into an error.
+func runTask(ctx context.Context, fn TaskFunc, t Task) (resp Response, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("task %s panicked: %v", t.ID, v)
+		}
+	}()
+	return fn(ctx, t)
+}
@@ -0,0 +1,173 @@
+package llmkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunWorker_ChanInbox(t *testing.T) {
+	tasks := make(chan Task, 3)
+	results := make(chan TaskResult, 3)
+	tasks <- Task{ID: "1", Input: "a"}
+	tasks <- Task{ID: "2", Input: "boom"}
+	tasks <- Task{ID: "3", Input: "c"}
+	close(tasks)
+
+	fn := func(ctx context.Context, task Task) (Response, error) {
+		if task.Input == "boom" {
+			return Response{}, errors.New("tool failed")
+		}
+		return Response{Text: "done " + task.Input, Tokens: Usage{Input: 10, Output: 2}}, nil
+	}
+	stats, err := RunWorker(context.Background(), ChanInbox{Tasks: tasks, Results: results}, fn, WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("RunWorker() error = %v", err)
+	}
+	if stats.Received != 3 || stats.Succeeded != 2 || stats.Failed != 1 || stats.Tokens.Input != 20 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	close(results)
+	got := map[string]TaskResult{}
+	for r := range results {
+		got[r.Task.ID] = r
+	}
+	if got["1"].Response.Text != "done a" || got["2"].Err == nil || len(got) != 3 {
+		t.Errorf("results = %+v", got)
+	}
+}
+
+func TestRunWorker_Concurrency(t *testing.T) {
+	tasks := make(chan Task)
+	var running, peak atomic.Int32
+	fn := func(ctx context.Context, task Task) (Response, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		return Response{}, nil
+	}
+
+	go func() {
+		for i := range 10 {
+			tasks <- Task{ID: fmt.Sprint(i)}
+		}
+		close(tasks)
+	}()
+	stats, err := RunWorker(context.Background(), ChanInbox{Tasks: tasks}, fn, WithConcurrency(3))
+	if err != nil || stats.Succeeded != 10 {
+		t.Fatalf("RunWorker() = %+v, %v", stats, err)
+	}
+	if peak.Load() > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", peak.Load())
+	}
+}
+
+// flakyInbox fails Receive a number of times before serving tasks.
+type flakyInbox struct {
+	mu        sync.Mutex
+	failures  int
+	tasks     []Task
+	completed []TaskResult
+}
+
+func (f *flakyInbox) Receive(ctx context.Context) (Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return Task{}, errors.New("connection reset")
+	}
+	if len(f.tasks) == 0 {
+		return Task{}, io.EOF
+	}
+	t := f.tasks[0]
+	f.tasks = f.tasks[1:]
+	return t, nil
+}
+
+func (f *flakyInbox) Complete(ctx context.Context, r TaskResult) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.completed = append(f.completed, r)
+	return nil
+}
+
+func TestRunWorker_ReceiveBackoffAndPanic(t *testing.T) {
+	clock := &instantClock{}
+	inbox := &flakyInbox{failures: 2, tasks: []Task{{ID: "p"}}}
+	fn := func(ctx context.Context, task Task) (Response, error) {
+		panic("nil map")
+	}
+
+	stats, err := RunWorker(context.Background(), inbox, fn, WithClock(clock))
+	if err != nil {
+		t.Fatalf("RunWorker() error = %v", err)
+	}
+	if got := clock.waits; len(got) != 2 || got[0] != time.Second || got[1] != 2*time.Second {
+		t.Errorf("backoff = %v, want [1s 2s]", got)
+	}
+	if stats.Failed != 1 || len(inbox.completed) != 1 || inbox.completed[0].Err == nil {
+		t.Errorf("stats = %+v, completed = %+v", stats, inbox.completed)
+	}
+}
+
+func TestRunWorker_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tasks := make(chan Task, 1)
+	results := make(chan TaskResult, 1)
+	tasks <- Task{ID: "slow"}
+
+	fn := func(ctx context.Context, task Task) (Response, error) {
+		cancel()
+		<-ctx.Done()
+		return Response{}, ctx.Err()
+	}
+	_, err := RunWorker(ctx, ChanInbox{Tasks: tasks, Results: results}, fn)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunWorker() error = %v, want context.Canceled", err)
+	}
+	// The interrupted task is still reported so the queue can redeliver it
+	if r := <-results; r.Task.ID != "slow" || !errors.Is(r.Err, context.Canceled) {
+		t.Errorf("result = %+v", r)
+	}
+}
+
+func TestAgentTask(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p := Provider{Name: OpenAI, APIKey: "k", BaseURL: server.URL}
+	var agents []*Agent
+	fn := AgentTask(func(task Task) *Agent {
+		a := NewAgent(p)
+		a.SetSystem("You handle ticket " + task.ID)
+		agents = append(agents, a)
+		return a
+	})
+	for _, id := range []string{"1", "2"} {
+		if _, err := fn(context.Background(), Task{ID: id, Input: "hi"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(agents) != 2 || agents[0] == agents[1] || calls.Load() != 2 {
+		t.Errorf("agents = %d, calls = %d", len(agents), calls.Load())
+	}
+}